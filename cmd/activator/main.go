@@ -73,6 +73,9 @@ const (
 	// Add enough buffer to not block request serving on stats collection
 	requestCountingQueueLength = 100
 
+	// Add enough buffer to not block probing on the pod balancer updates.
+	destsUpdateQueueLength = 100
+
 	// The number of requests that are queued on the breaker before the 503s are sent.
 	// The value must be adjusted depending on the actual production requirements.
 	breakerQueueDepth = 10000
@@ -111,6 +114,50 @@ func statReporter(statSink *websocket.ManagedConnection, stopCh <-chan struct{},
 
 type config struct {
 	PodName string `split_words:"true" required:"true"`
+
+	// LoadBalancingPolicy selects how requests are spread over the healthy
+	// pods of a revision.
+	LoadBalancingPolicy string `split_words:"true" default:"round-robin"`
+
+	// QueueProxyCAFile is the path of the PEM file with the CAs the
+	// certificates of the queue-proxies are verified against, if they serve
+	// TLS. If empty, the system's CAs are used.
+	QueueProxyCAFile string `split_words:"true"`
+
+	// FairQueueHeader names the request header, e.g. a tenant ID, by which
+	// the requests waiting for a revision are queued fairly. If empty, they
+	// are queued in the order of their arrival.
+	FairQueueHeader string `split_words:"true"`
+	// FairQueueKeyDepth limits the number of waiting requests per header
+	// value. 0 means no limit other than the queue depth.
+	FairQueueKeyDepth int `split_words:"true"`
+	// FairQueueWeights are the weights of the header values, in the form
+	// "value1:weight1,value2:weight2". Other values have weight 1.
+	FairQueueWeights map[string]float64 `split_words:"true"`
+
+	// EnableRequestPriority admits the requests waiting for a revision by
+	// the priority class their Route assigns them.
+	EnableRequestPriority bool `split_words:"true"`
+	// LowPriorityWaitBudget is how long low priority requests wait for a
+	// revision before they are shed. 0 means they wait until they time out.
+	LowPriorityWaitBudget time.Duration `split_words:"true"`
+
+	// MaxRetries is how often a request that fails before the revision
+	// responded is retried on another pod at most. 0 disables retries.
+	MaxRetries int `split_words:"true"`
+	// RetryBudgetRatio is the share of the requests to a revision that may
+	// be retried, beyond a burst of 10 retries.
+	RetryBudgetRatio float64 `split_words:"true" default:"0.2"`
+	// RetryMaxBodyBytes is the size up to which request bodies are buffered,
+	// so that requests other than GET and HEAD can be retried too.
+	RetryMaxBodyBytes int64 `split_words:"true"`
+
+	// StreamIdleTimeout is how long WebSocket connections and server-sent
+	// events may transfer no data before they're closed. 0 means never.
+	StreamIdleTimeout time.Duration `split_words:"true"`
+	// ExcludeStreamsFromConcurrency keeps the requests that turned into
+	// streams out of the concurrency reported to the autoscaler.
+	ExcludeStreamsFromConcurrency bool `split_words:"true"`
 }
 
 func main() {
@@ -182,9 +229,6 @@ func main() {
 		logger.Fatalw("Failed to start informers", zap.Error(err))
 	}
 
-	params := queue.BreakerParams{QueueDepth: breakerQueueDepth, MaxConcurrency: breakerMaxConcurrency, InitialCapacity: 0}
-	throttler := activator.NewThrottler(params, endpointInformer, sksInformer.Lister(), revisionInformer.Lister(), logger)
-
 	activatorL3 := fmt.Sprintf("%s:%d", activator.K8sServiceName, networking.ServiceHTTPPort)
 	zipkinEndpoint, err := zipkin.NewEndpoint("activator", activatorL3)
 	if err != nil {
@@ -220,23 +264,81 @@ func main() {
 	}
 	podName := env.PodName
 
+	params := queue.BreakerParams{
+		QueueDepth:      breakerQueueDepth,
+		MaxConcurrency:  breakerMaxConcurrency,
+		InitialCapacity: 0,
+	}
+	if env.EnableRequestPriority {
+		params.Priority = &queue.PriorityParams{LowPriorityWaitBudget: env.LowPriorityWaitBudget}
+	}
+	if env.FairQueueHeader != "" {
+		params.FairQueue = &queue.FairQueueParams{
+			KeyQueueDepth: env.FairQueueKeyDepth,
+			Weights:       env.FairQueueWeights,
+		}
+	}
+	throttler := activator.NewThrottler(params, endpointInformer, sksInformer.Lister(), revisionInformer.Lister(), logger)
+
+	lbPolicy, err := activator.ParseLoadBalancingPolicy(env.LoadBalancingPolicy)
+	if err != nil {
+		logger.Fatalw("Invalid load balancing policy", zap.Error(err))
+	}
+
+	// Talk TLS to the queue-proxies of the revisions serving it.
+	tlsConfig, err := network.NewQueueProxyTLSConfig(env.QueueProxyCAFile)
+	if err != nil {
+		logger.Fatalw("Failed to load the queue-proxy CAs", zap.Error(err))
+	}
+	transport := activator.NewQueueProxyTransport(network.AutoTransport, network.NewAutoTLSTransport(tlsConfig))
+	probeTransport := activator.NewQueueProxyTransport(network.NewProberTransport(), network.NewProberTLSTransport(tlsConfig))
+
+	// Send the requests directly to the healthy pods of the revisions.
+	destsUpdateCh := make(chan *activator.RevisionDestsUpdate, destsUpdateQueueLength)
+	balancer := activator.NewPodBalancer(lbPolicy, endpointInformer, revisionInformer.Lister(), logger)
+	go balancer.Run(destsUpdateCh, stopCh)
+	backendsManager := activator.NewRevisionBackendsManager(destsUpdateCh, probeTransport, endpointInformer, revisionInformer.Lister(), logger)
+	defer backendsManager.Clear()
+
 	// Create and run our concurrency reporter
 	reportTicker := time.NewTicker(time.Second)
 	defer reportTicker.Stop()
-	cr := activatorhandler.NewConcurrencyReporter(logger, podName, reqCh, reportTicker.C, statCh, revisionInformer.Lister(), reporter)
+	cr := activatorhandler.NewConcurrencyReporter(logger, podName, reqCh, reportTicker.C, statCh, revisionInformer.Lister(), reporter,
+		env.ExcludeStreamsFromConcurrency)
 	go cr.Run(stopCh)
 
+	// Report how many requests wait for every revision.
+	queueDepthTicker := time.NewTicker(time.Second)
+	defer queueDepthTicker.Stop()
+	go throttler.ReportQueueDepth(reporter, queueDepthTicker.C, stopCh)
+
 	// Create activation handler chain
 	// Note: innermost handlers are specified first, ie. the last handler in the chain will be executed first
 	var ah http.Handler = activatorhandler.New(
 		logger,
 		reporter,
 		throttler,
+		balancer,
+		activatorhandler.RetryParams{
+			MaxRetries:   env.MaxRetries,
+			MaxBodyBytes: env.RetryMaxBodyBytes,
+			BudgetRatio:  env.RetryBudgetRatio,
+		},
+		transport,
+		probeTransport,
 		revisionInformer.Lister(),
 		serviceInformer.Lister(),
 		sksInformer.Lister(),
 	)
+	ah = activatorhandler.NewStreamEventHandler(reqCh, ah, env.StreamIdleTimeout)
+	if env.FairQueueHeader != "" {
+		ah = &activatorhandler.FairQueueKeyHandler{Header: env.FairQueueHeader, NextHandler: ah}
+	}
+	if env.EnableRequestPriority {
+		ah = &activatorhandler.PriorityHandler{NextHandler: ah}
+	}
 	ah = activatorhandler.NewRequestEventHandler(reqCh, ah)
+	ah = &activatorhandler.MirrorHandler{NextHandler: ah}
 	ah = tracing.HTTPSpanMiddleware(ah)
 	ah = configStore.HTTPMiddleware(ah)
 	reqLogHandler, err := pkghttp.NewRequestLogHandler(ah, logging.NewSyncFileWriter(os.Stdout), "",
@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The autoscaler-replay command runs the autoscaler offline on a recorded
+// trace of stats and prints the pod counts over time and the panic mode
+// intervals, to compare autoscaler configurations before rolling them out.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/autoscaler/replay"
+)
+
+var (
+	tracePath            = flag.String("trace", "", "Path to the trace of stats to replay, as CSV or JSON lines.")
+	traceFormat          = flag.String("format", "", "The format of the trace, csv or jsonl. Defaults to the extension of the trace.")
+	configPath           = flag.String("config", "", "Path to a config-autoscaler ConfigMap in YAML. Defaults to the default configuration.")
+	containerConcurrency = flag.Int("container-concurrency", 0, "The container concurrency of the revision, 0 for unlimited.")
+	initialPods          = flag.Int("initial-pods", 1, "The number of ready pods at the start of the trace.")
+	podStartupDelay      = flag.Duration("pod-startup-delay", 0, "How long a new pod takes to become ready.")
+	output               = flag.String("output", "csv", "The output format, csv or json.")
+	logLevel             = flag.String("log-level", "warn", "The level of the autoscaler logs written to stderr.")
+)
+
+// annotations collects the repeated annotation flag.
+type annotations map[string]string
+
+func (a annotations) String() string {
+	pairs := make([]string, 0, len(a))
+	for k, v := range a {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (a annotations) Set(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("annotation %q is not of the form key=value", s)
+	}
+	a[kv[0]] = kv[1]
+	return nil
+}
+
+func main() {
+	anns := annotations{}
+	flag.Var(anns, "annotation", "An autoscaling annotation of the revision as key=value, e.g. "+
+		autoscaling.TargetBurstCapacityKey+"=200. May be repeated.")
+	flag.Parse()
+
+	if err := run(anns, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(anns annotations, w io.Writer) error {
+	if *tracePath == "" {
+		return errors.New("--trace is required")
+	}
+	trace, err := readTrace(*tracePath, *traceFormat)
+	if err != nil {
+		return fmt.Errorf("error reading trace %s: %v", *tracePath, err)
+	}
+	config, err := readConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("error reading config %s: %v", *configPath, err)
+	}
+	if err := autoscaling.ValidateAnnotations(anns); err != nil {
+		return err
+	}
+
+	loggerConfig := zap.NewProductionConfig()
+	if err := loggerConfig.Level.UnmarshalText([]byte(*logLevel)); err != nil {
+		return err
+	}
+	logger, err := loggerConfig.Build()
+	if err != nil {
+		return err
+	}
+	defer logger.Sync()
+	ctx := logging.WithLogger(context.Background(), logger.Sugar())
+
+	result, err := replay.Run(ctx, trace, replay.Options{
+		Config: config,
+		PodAutoscaler: &v1alpha1.PodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "replay",
+				Name:        "replay",
+				Annotations: anns,
+			},
+			Spec: v1alpha1.PodAutoscalerSpec{
+				ContainerConcurrency: v1beta1.RevisionContainerConcurrencyType(*containerConcurrency),
+			},
+		},
+		InitialPods:     *initialPods,
+		PodStartupDelay: *podStartupDelay,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch *output {
+	case "csv":
+		return result.WriteCSV(w)
+	case "json":
+		return result.WriteJSON(w)
+	}
+	return fmt.Errorf("unknown output format %q", *output)
+}
+
+func readTrace(path, format string) ([]autoscaler.Stat, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch format {
+	case "csv":
+		return replay.ReadCSV(f)
+	case "jsonl":
+		return replay.ReadJSONL(f)
+	}
+	return nil, fmt.Errorf("unknown trace format %q, want csv or jsonl", format)
+}
+
+func readConfig(path string) (*autoscaler.Config, error) {
+	if path == "" {
+		return autoscaler.NewConfigFromMap(map[string]string{})
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cm := &corev1.ConfigMap{}
+	if err := yaml.Unmarshal(data, cm); err != nil {
+		return nil, err
+	}
+	return autoscaler.NewConfigFromConfigMap(cm)
+}
@@ -17,9 +17,13 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -38,6 +42,7 @@ import (
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/autoscaler/bucket"
 	"knative.dev/serving/pkg/autoscaler/statserver"
 	"knative.dev/serving/pkg/reconciler/autoscaling/hpa"
 	"knative.dev/serving/pkg/reconciler/autoscaling/kpa"
@@ -52,15 +57,27 @@ import (
 )
 
 const (
-	statsServerAddr = ":8080"
+	statsServerPort = 8080
+	debugServerAddr = ":8008"
 	statsBufferLen  = 1000
 	component       = "autoscaler"
 	controllerNum   = 2
+
+	// metricSnapshotConfigMapName is the ConfigMap the metric windows are persisted
+	// to when the configmap snapshot store is used.
+	metricSnapshotConfigMapName = "autoscaler-metric-snapshot"
 )
 
 var (
 	masterURL  = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+
+	metricSnapshotStore    = flag.String("metric-snapshot-store", "configmap", "Where to persist the metric windows across restarts: configmap, file or none.")
+	metricSnapshotPath     = flag.String("metric-snapshot-path", "", "The directory to persist the metric windows to. Only required for the file snapshot store.")
+	metricSnapshotInterval = flag.Duration("metric-snapshot-interval", 10*time.Second, "How often the metric windows are persisted.")
+
+	enableLeaderElection = flag.Bool("enable-leader-election", false, "Whether to split the revisions into buckets led by one of the autoscaler replicas each. Requires the POD_IP environment variable.")
+	buckets              = flag.Uint("buckets", 1, "The number of buckets the revisions are split into with leader election.")
 )
 
 func main() {
@@ -116,9 +133,45 @@ func main() {
 	collector := autoscaler.NewMetricCollector(statsScraperFactoryFunc(endpointsInformer.Lister()), logger)
 	customMetricsAdapter.WithCustomMetrics(autoscaler.NewMetricProvider(collector))
 
+	// With leader election, every replica only decides for the revisions of the
+	// buckets it leads. The metric collections are kept by every replica, though,
+	// as the custom metrics API may be served by any of them.
+	var elector *bucket.Elector
+	if *enableLeaderElection {
+		podIP := os.Getenv("POD_IP")
+		if podIP == "" {
+			logger.Fatal("POD_IP must be set to enable leader election")
+		}
+		if *buckets == 0 {
+			logger.Fatal("--buckets must be at least 1")
+		}
+		elector = bucket.NewElector(bucket.NewSet(uint32(*buckets)),
+			kubeclient.Get(ctx).CoordinationV1beta1().Leases(system.Namespace()), podIP, logger)
+		ctx = bucket.WithOwnership(ctx, elector)
+	}
+
+	// Restore the metric windows persisted before a restart. This must happen before
+	// the metric controller starts creating collections. Every replica restores all
+	// buckets, but only saves the buckets it leads.
+	snapshotStore, err := newSnapshotStore(ctx, *metricSnapshotStore, *metricSnapshotPath)
+	if err != nil {
+		logger.Fatalw("Failed to create metric snapshot store", zap.Error(err))
+	}
+	if snapshotStore != nil {
+		bucketNames := []string{bucket.Name(0, 1)}
+		if elector != nil {
+			bucketNames = elector.Names()
+		}
+		if err := collector.Restore(snapshotStore, bucketNames); err != nil {
+			logger.Errorw("Failed to restore metric snapshot", zap.Error(err))
+		}
+		go collector.PersistSnapshots(snapshotStore, bucket.OwnershipFromContext(ctx), bucketNames,
+			*metricSnapshotInterval, ctx.Done())
+	}
+
 	// Set up scalers.
 	// uniScalerFactory depends endpointsInformer to be set.
-	multiScaler := autoscaler.NewMultiScaler(ctx.Done(), uniScalerFactoryFunc(endpointsInformer, collector), logger)
+	multiScaler := autoscaler.NewMultiScaler(ctx.Done(), uniScalerFactoryFunc(endpointsInformer, collector, autoscaler.NewDefaultAlgorithmRegistry()), logger)
 
 	psInformerFactory := resources.NewPodScalableInformerFactory(ctx)
 	controllers := []*controller.Impl{
@@ -128,7 +181,7 @@ func main() {
 	}
 
 	// Set up a statserver.
-	statsServer := statserver.New(statsServerAddr, statsCh, logger)
+	statsServer := statserver.New(fmt.Sprintf(":%d", statsServerPort), statsCh, logger)
 
 	// Start watching the configs.
 	if err := cmw.Start(ctx.Done()); err != nil {
@@ -142,30 +195,68 @@ func main() {
 
 	go controller.StartAll(ctx.Done(), controllers...)
 
+	if elector != nil {
+		go elector.Run(ctx.Done())
+	}
+
+	// Forward the stats of the revisions of the buckets led by other replicas.
+	forwarder := bucket.NewForwarder(bucket.OwnershipFromContext(ctx), statsServerPort, func(sm *autoscaler.StatMessage) {
+		collector.Record(sm.Key, sm.Stat)
+		multiScaler.Poke(sm.Key, sm.Stat)
+	}, logger)
+	defer forwarder.Shutdown()
+
 	go func() {
 		for sm := range statsCh {
-			collector.Record(sm.Key, sm.Stat)
-			multiScaler.Poke(sm.Key, sm.Stat)
+			forwarder.Process(sm)
 		}
 	}()
 
+	// Set up a read-only debug server serving the recent decisions of the deciders.
+	debugMux := http.NewServeMux()
+	debugMux.Handle(autoscaler.DecisionsPath, autoscaler.DecisionsHandler(multiScaler, logger))
+	debugServer := &http.Server{Addr: debugServerAddr, Handler: debugMux}
+
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(func() error {
 		return customMetricsAdapter.Run(ctx.Done())
 	})
 	eg.Go(statsServer.ListenAndServe)
+	eg.Go(func() error {
+		if err := debugServer.ListenAndServe(); err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	})
 
 	// This will block until either a signal arrives or one of the grouped functions
 	// returns an error.
 	<-egCtx.Done()
 
 	statsServer.Shutdown(5 * time.Second)
+	debugServer.Close()
 	if err := eg.Wait(); err != nil {
 		logger.Errorw("Error while shutting down", zap.Error(err))
 	}
 }
 
-func uniScalerFactoryFunc(endpointsInformer corev1informers.EndpointsInformer, metricClient autoscaler.MetricClient) func(decider *autoscaler.Decider) (autoscaler.UniScaler, error) {
+func newSnapshotStore(ctx context.Context, kind, path string) (autoscaler.SnapshotStore, error) {
+	switch kind {
+	case "configmap":
+		return autoscaler.NewConfigMapSnapshotStore(kubeclient.Get(ctx), system.Namespace(), metricSnapshotConfigMapName), nil
+	case "file":
+		if path == "" {
+			return nil, errors.New("the file snapshot store requires --metric-snapshot-path")
+		}
+		return autoscaler.NewFileSnapshotStore(path), nil
+	case "none":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown metric snapshot store %q", kind)
+}
+
+func uniScalerFactoryFunc(endpointsInformer corev1informers.EndpointsInformer, metricClient autoscaler.MetricClient,
+	algorithms *autoscaler.AlgorithmRegistry) func(decider *autoscaler.Decider) (autoscaler.UniScaler, error) {
 	return func(decider *autoscaler.Decider) (autoscaler.UniScaler, error) {
 		if v, ok := decider.Labels[serving.ConfigurationLabelKey]; !ok || v == "" {
 			return nil, fmt.Errorf("label %q not found or empty in Decider %s", serving.ConfigurationLabelKey, decider.Name)
@@ -173,6 +264,10 @@ func uniScalerFactoryFunc(endpointsInformer corev1informers.EndpointsInformer, m
 		if decider.Spec.ServiceName == "" {
 			return nil, fmt.Errorf("%s decider has empty ServiceName", decider.Name)
 		}
+		newAlgorithm, err := algorithms.Get(decider.Spec.Algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("%s decider: %v", decider.Name, err)
+		}
 
 		serviceName := decider.Labels[serving.ServiceLabelKey] // This can be empty.
 		configName := decider.Labels[serving.ConfigurationLabelKey]
@@ -184,7 +279,7 @@ func uniScalerFactoryFunc(endpointsInformer corev1informers.EndpointsInformer, m
 		}
 
 		podCounter := resources.NewScopedEndpointsCounter(endpointsInformer.Lister(), decider.Namespace, decider.Spec.ServiceName)
-		return autoscaler.New(decider.Namespace, decider.Name, metricClient, podCounter, decider.Spec, reporter)
+		return newAlgorithm(decider.Namespace, decider.Name, metricClient, podCounter, decider.Spec, reporter)
 	}
 }
 
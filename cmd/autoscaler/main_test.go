@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	kubeinformers "k8s.io/client-go/informers"
 	fakeK8s "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/autoscaler"
 )
@@ -106,6 +107,17 @@ func TestUniscalerFactoryFailures(t *testing.T) {
 	if got, want := err.Error(), "decider has empty ServiceName"; !strings.Contains(got, want) {
 		t.Errorf("Error = %q, want to contain = %q", got, want)
 	}
+
+	// Now give a service name, but an unknown algorithm.
+	decider.Spec.ServiceName = "wholesome-service"
+	decider.Spec.Algorithm = "magic"
+	_, err = uniScalerFactory(decider)
+	if err == nil {
+		t.Fatal("No error was returned")
+	}
+	if got, want := err.Error(), `unknown scaling algorithm "magic"`; !strings.Contains(got, want) {
+		t.Errorf("Error = %q, want to contain = %q", got, want)
+	}
 }
 
 func endpoints(ns, n string) {
@@ -124,29 +136,33 @@ func TestUniScalerFactoryFunc(t *testing.T) {
 	endpoints(testNamespace, "magic-services-offered")
 	uniScalerFactory := getTestUniScalerFactory()
 	for _, srv := range []string{"some", ""} {
-		decider := &autoscaler.Decider{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: testNamespace,
-				Name:      testRevision,
-				Labels: map[string]string{
-					serving.RevisionLabelKey:      testRevision,
-					serving.ServiceLabelKey:       srv,
-					serving.ConfigurationLabelKey: "test-config",
+		for _, algorithm := range []string{"", autoscaling.StablePanicAlgorithm, autoscaling.PIDAlgorithm, autoscaling.PredictiveAlgorithm} {
+			decider := &autoscaler.Decider{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: testNamespace,
+					Name:      testRevision,
+					Labels: map[string]string{
+						serving.RevisionLabelKey:      testRevision,
+						serving.ServiceLabelKey:       srv,
+						serving.ConfigurationLabelKey: "test-config",
+					},
 				},
-			},
-			Spec: autoscaler.DeciderSpec{
-				ServiceName: "magic-services-offered",
-			},
-		}
+				Spec: autoscaler.DeciderSpec{
+					Algorithm:   algorithm,
+					ServiceName: "magic-services-offered",
+				},
+			}
 
-		if _, err := uniScalerFactory(decider); err != nil {
-			t.Errorf("got error from uniScalerFactory: %v", err)
+			if _, err := uniScalerFactory(decider); err != nil {
+				t.Errorf("got error from uniScalerFactory for algorithm %q: %v", algorithm, err)
+			}
 		}
 	}
 }
 
 func getTestUniScalerFactory() func(decider *autoscaler.Decider) (autoscaler.UniScaler, error) {
-	return uniScalerFactoryFunc(kubeInformer.Core().V1().Endpoints(), &testMetricClient{})
+	return uniScalerFactoryFunc(kubeInformer.Core().V1().Endpoints(), &testMetricClient{},
+		autoscaler.NewDefaultAlgorithmRegistry())
 }
 
 type testMetricClient struct{}
@@ -154,3 +170,11 @@ type testMetricClient struct{}
 func (t *testMetricClient) StableAndPanicConcurrency(key types.NamespacedName, now time.Time) (float64, float64, error) {
 	return 1.0, 1.0, nil
 }
+
+func (t *testMetricClient) StableAndPanicRPS(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return 1.0, 1.0, nil
+}
+
+func (t *testMetricClient) StableAndPanicCustomMetric(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return 1.0, 1.0, nil
+}
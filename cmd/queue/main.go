@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"net"
@@ -26,6 +27,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +39,7 @@ import (
 	"go.opencensus.io/stats"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"knative.dev/pkg/logging/logkey"
@@ -66,12 +69,6 @@ const (
 	// Add enough buffer to not block request serving on stats collection
 	requestCountingQueueLength = 100
 
-	// Duration the /quitquitquit handler should wait before returning.
-	// This is to give Istio a little bit more time to remove the pod
-	// from its configuration and propagate that to all istio-proxies
-	// in the mesh.
-	quitSleepDuration = 20 * time.Second
-
 	badProbeTemplate = "unexpected probe header value: %s"
 
 	// Metrics' names (without component prefix).
@@ -79,6 +76,7 @@ const (
 	responseTimeInMsecN    = "request_latencies"
 	appRequestCountN       = "app_request_count"
 	appResponseTimeInMsecN = "app_request_latencies"
+	requestTimeoutCountN   = "request_timeout_count"
 
 	// requestQueueHealthPath specifies the path for health checks for
 	// queue-proxy.
@@ -112,34 +110,52 @@ var (
 		appResponseTimeInMsecN,
 		"The response time in millisecond",
 		stats.UnitMilliseconds)
+	requestTimeoutCountM = stats.Int64(
+		requestTimeoutCountN,
+		"The number of requests that timed out in queue-proxy",
+		stats.UnitDimensionless)
 
 	readinessProbeTimeout = flag.Int("probe-period", -1, "run readiness probe with given timeout")
 )
 
 type config struct {
-	ContainerConcurrency         int     `split_words:"true" required:"true"`
-	QueueServingPort             int     `split_words:"true" required:"true"`
-	RevisionTimeoutSeconds       int     `split_words:"true" required:"true"`
-	UserPort                     int     `split_words:"true" required:"true"`
-	EnableVarLogCollection       bool    `split_words:"true"` // optional
-	ServingConfiguration         string  `split_words:"true" required:"true"`
-	ServingNamespace             string  `split_words:"true" required:"true"`
-	ServingPodIP                 string  `split_words:"true" required:"true"`
-	ServingPod                   string  `split_words:"true" required:"true"`
-	ServingRevision              string  `split_words:"true" required:"true"`
-	ServingService               string  `split_words:"true"` // optional
-	UserContainerName            string  `split_words:"true" required:"true"`
-	VarLogVolumeName             string  `split_words:"true" required:"true"`
-	InternalVolumePath           string  `split_words:"true" required:"true"`
-	ServingLoggingConfig         string  `split_words:"true" required:"true"`
-	ServingLoggingLevel          string  `split_words:"true" required:"true"`
-	ServingRequestMetricsBackend string  `split_words:"true" required:"true"`
-	ServingRequestLogTemplate    string  `split_words:"true" required:"true"`
-	ServingReadinessProbe        string  `split_words:"true" required:"true"`
-	TracingConfigDebug           bool    `split_words:"true"` // optional
-	TracingConfigEnable          bool    `split_words:"true"` // optional
-	TracingConfigSampleRate      float64 `split_words:"true"` // optional
-	TracingConfigZipkinEndpoint  string  `split_words:"true"` // optional
+	ContainerConcurrency          int           `split_words:"true" required:"true"`
+	QueueServingPort              int           `split_words:"true" required:"true"`
+	RevisionTimeoutSeconds        int           `split_words:"true" required:"true"`
+	UserPort                      int           `split_words:"true" required:"true"`
+	EnableVarLogCollection        bool          `split_words:"true"` // optional
+	ServingConfiguration          string        `split_words:"true" required:"true"`
+	ServingNamespace              string        `split_words:"true" required:"true"`
+	ServingPodIP                  string        `split_words:"true" required:"true"`
+	ServingPod                    string        `split_words:"true" required:"true"`
+	ServingRevision               string        `split_words:"true" required:"true"`
+	ServingService                string        `split_words:"true"` // optional
+	UserContainerName             string        `split_words:"true" required:"true"`
+	VarLogVolumeName              string        `split_words:"true" required:"true"`
+	InternalVolumePath            string        `split_words:"true" required:"true"`
+	ServingLoggingConfig          string        `split_words:"true" required:"true"`
+	ServingLoggingLevel           string        `split_words:"true" required:"true"`
+	ServingRequestMetricsBackend  string        `split_words:"true" required:"true"`
+	ServingRequestLogTemplate     string        `split_words:"true" required:"true"`
+	ServingReadinessProbe         string        `split_words:"true" required:"true"`
+	TracingConfigDebug            bool          `split_words:"true"` // optional
+	TracingConfigEnable           bool          `split_words:"true"` // optional
+	TracingConfigSampleRate       float64       `split_words:"true"` // optional
+	TracingConfigZipkinEndpoint   string        `split_words:"true"` // optional
+	CustomMetricName              string        `split_words:"true"` // optional
+	CustomMetricPath              string        `split_words:"true"` // optional
+	EnableRequestPriority         bool          `split_words:"true"` // optional
+	LowPriorityWaitBudget         time.Duration `split_words:"true"` // optional
+	StreamIdleTimeout             time.Duration `split_words:"true"` // optional
+	ExcludeStreamsFromConcurrency bool          `split_words:"true"` // optional
+	QueueServingCertDir           string        `split_words:"true"` // optional
+	PreStopDelay                  time.Duration `split_words:"true"` // optional
+	DrainTimeout                  time.Duration `split_words:"true"` // optional
+	DrainNotifyPath               string        `split_words:"true"` // optional
+	MaxRequestBodyBytes           int64         `split_words:"true"` // optional
+	MaxRequestHeaderBytes         int           `split_words:"true"` // optional
+	MaxRequestDuration            time.Duration `split_words:"true"` // optional
+	ResponseIdleTimeout           time.Duration `split_words:"true"` // optional
 }
 
 // Make handler a closure for testing.
@@ -197,10 +213,12 @@ func handler(reqChan chan queue.ReqEvent, breaker *queue.Breaker, handler http.H
 
 		// Enforce queuing and concurrency limits.
 		if breaker != nil {
-			if !breaker.Maybe(r.Context(), func() {
+			// The priority is ignored unless the breaker admits by it.
+			ctx := queue.WithPriority(r.Context(), queue.RequestPriority(r))
+			if !breaker.Maybe(ctx, func() {
 				handler.ServeHTTP(w, r.WithContext(proxyCtx))
 			}) {
-				http.Error(w, "overload", http.StatusServiceUnavailable)
+				pkghttp.Error(w, r, "overload", http.StatusServiceUnavailable)
 			}
 		} else {
 			handler.ServeHTTP(w, r.WithContext(proxyCtx))
@@ -208,6 +226,30 @@ func handler(reqChan chan queue.ReqEvent, breaker *queue.Breaker, handler http.H
 	}
 }
 
+// streamHandler reports the requests turning into streams, e.g. WebSocket
+// connections, to the reqChan and closes the streams idling for longer than
+// the idleTimeout.
+func streamHandler(reqChan chan queue.ReqEvent, h http.Handler, idleTimeout time.Duration) http.Handler {
+	events := func(r *http.Request) (in, out queue.ReqEventType) {
+		if activator.Name == network.KnativeProxyHeader(r) {
+			return queue.ProxiedStreamIn, queue.ProxiedStreamOut
+		}
+		return queue.StreamIn, queue.StreamOut
+	}
+	return &pkghttp.StreamHandler{
+		NextHandler: h,
+		IdleTimeout: idleTimeout,
+		OnStreamStart: func(r *http.Request) {
+			in, _ := events(r)
+			reqChan <- queue.ReqEvent{Time: time.Now(), EventType: in}
+		},
+		OnStreamEnd: func(r *http.Request) {
+			_, out := events(r)
+			reqChan <- queue.ReqEvent{Time: time.Now(), EventType: out}
+		},
+	}
+}
+
 func probeQueueHealthPath(port int, timeoutSeconds int) error {
 	url := fmt.Sprintf(healthURLTemplate, port)
 	timeoutDuration := readiness.PollTimeout
@@ -315,6 +357,14 @@ func main() {
 
 	httpProxy.FlushInterval = -1
 	activatorutil.SetupHeaderPruning(httpProxy)
+	httpProxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if queue.RequestBodyTooLarge(r) {
+			pkghttp.Error(w, r, "request entity too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		logger.Errorw("Error proxying the request", zap.Error(err))
+		w.WriteHeader(http.StatusBadGateway)
+	}
 
 	// Setup the breaker to enforce containreConcurrency.
 	// If env.ContainerConcurrency == 0 then concurrency is unlimited.
@@ -323,7 +373,14 @@ func main() {
 		// We set the queue depth to be equal to the container concurrency * 10 to
 		// allow the autoscaler to get a strong enough signal.
 		queueDepth := env.ContainerConcurrency * 10
-		params := queue.BreakerParams{QueueDepth: queueDepth, MaxConcurrency: env.ContainerConcurrency, InitialCapacity: env.ContainerConcurrency}
+		params := queue.BreakerParams{
+			QueueDepth:      queueDepth,
+			MaxConcurrency:  env.ContainerConcurrency,
+			InitialCapacity: env.ContainerConcurrency,
+		}
+		if env.EnableRequestPriority {
+			params.Priority = &queue.PriorityParams{LowPriorityWaitBudget: env.LowPriorityWaitBudget}
+		}
 		breaker = queue.NewBreaker(params)
 		logger.Infof("Queue container is starting with %#v", params)
 	}
@@ -334,10 +391,30 @@ func main() {
 		logger.Fatalw("Failed to create stats reporter", zap.Error(err))
 	}
 
+	// The custom metric is scraped from the user container along with
+	// every stat, so the autoscaler receives both at once.
+	var customMetricScraper *queue.CustomMetricScraper
+	if env.CustomMetricName != "" {
+		customMetricURL := *target
+		customMetricURL.Path = env.CustomMetricPath
+		customMetricScraper = queue.NewCustomMetricScraper(customMetricURL.String(), env.CustomMetricName)
+	}
+
 	statChan := make(chan *autoscaler.Stat, statReportingQueueLength)
 	defer close(statChan)
 	go func() {
+		// A failed scrape reports the last known value, rather than
+		// making the revision look idle.
+		var customMetric float64
 		for s := range statChan {
+			if customMetricScraper != nil {
+				if v, err := customMetricScraper.Scrape(); err != nil {
+					logger.Warnw("Failed to scrape the custom metric", zap.Error(err))
+				} else {
+					customMetric = v
+				}
+				s.CustomMetric = customMetric
+			}
 			if err := promStatReporter.Report(s); err != nil {
 				logger.Errorw("Error while sending stat", zap.Error(err))
 			}
@@ -352,7 +429,7 @@ func main() {
 		ReqChan:    reqChan,
 		ReportChan: reportTicker.C,
 		StatChan:   statChan,
-	}, time.Now())
+	}, time.Now(), env.ExcludeStreamsFromConcurrency)
 
 	// Setup request metrics reporting for end-user metrics.
 	metricsSupported := false
@@ -375,19 +452,41 @@ func main() {
 	// Note: innermost handlers are specified first, ie. the last handler in the chain will be executed first.
 	var composedHandler http.Handler = httpProxy
 	if metricsSupported {
-		composedHandler = pushRequestMetricHandler(httpProxy, appRequestCountM, appResponseTimeInMsecM, env)
+		composedHandler = pushRequestMetricHandler(httpProxy, appRequestCountM, appResponseTimeInMsecM, nil, env)
 	}
+	composedHandler = streamHandler(reqChan, composedHandler, env.StreamIdleTimeout)
 	composedHandler = http.HandlerFunc(handler(reqChan, breaker, composedHandler, rp.ProbeContainer))
+	composedHandler = queue.MirrorSamplingHandler(composedHandler)
 	composedHandler = queue.ForwardedShimHandler(composedHandler)
-	composedHandler = queue.TimeToFirstByteTimeoutHandler(composedHandler,
-		time.Duration(env.RevisionTimeoutSeconds)*time.Second, "request timeout")
+	composedHandler = queue.TimeoutHandler(composedHandler, queue.Timeouts{
+		FirstByte: time.Duration(env.RevisionTimeoutSeconds) * time.Second,
+		Total:     env.MaxRequestDuration,
+		Idle:      env.ResponseIdleTimeout,
+	}, "request timeout")
+	composedHandler = queue.RequestLimitHandler(composedHandler, queue.RequestLimits{
+		MaxBodyBytes:   env.MaxRequestBodyBytes,
+		MaxHeaderBytes: env.MaxRequestHeaderBytes,
+	})
 	composedHandler = pushRequestLogHandler(composedHandler, env)
 
 	if metricsSupported {
-		composedHandler = pushRequestMetricHandler(composedHandler, requestCountM, responseTimeInMsecM, env)
+		composedHandler = pushRequestMetricHandler(composedHandler, requestCountM, responseTimeInMsecM, requestTimeoutCountM, env)
 	}
 	composedHandler = tracing.HTTPSpanMiddleware(composedHandler)
 	server := network.NewServer(":"+strconv.Itoa(env.QueueServingPort), composedHandler)
+	if env.MaxRequestHeaderBytes > http.DefaultMaxHeaderBytes {
+		// Let the larger headers through to the RequestLimitHandler.
+		server.MaxHeaderBytes = env.MaxRequestHeaderBytes
+	}
+	if env.QueueServingCertDir != "" {
+		certs, err := network.NewCertReloader(
+			filepath.Join(env.QueueServingCertDir, corev1.TLSCertKey),
+			filepath.Join(env.QueueServingCertDir, corev1.TLSPrivateKeyKey))
+		if err != nil {
+			logger.Fatalw("Failed to load the serving certificate", zap.Error(err))
+		}
+		server.TLSConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+	}
 
 	adminMux := http.NewServeMux()
 	healthState := &health.State{}
@@ -414,8 +513,13 @@ func main() {
 	errCh := make(chan error, len(servers))
 	for name, server := range servers {
 		go func(name string, s *http.Server) {
+			serve := s.ListenAndServe
+			if s.TLSConfig != nil {
+				// The certificate is served by TLSConfig.GetCertificate.
+				serve = func() error { return s.ListenAndServeTLS("", "") }
+			}
 			// Don't forward ErrServerClosed as that indicates we're already shutting down.
-			if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			if err := serve(); err != nil && err != http.ErrServerClosed {
 				errCh <- errors.Wrapf(err, "%s server failed", name)
 			}
 		}(name, server)
@@ -447,13 +551,22 @@ func main() {
 	case <-signals.SetupSignalHandler():
 		logger.Info("Received TERM signal, attempting to gracefully shutdown servers.")
 		healthState.Shutdown(func() {
-			// Give Istio time to sync our "not ready" state.
-			time.Sleep(quitSleepDuration)
-
-			// Calling server.Shutdown() allows pending requests to
-			// complete, while no new work is accepted.
-			if err := server.Shutdown(context.Background()); err != nil {
-				logger.Errorw("Failed to shutdown proxy server", zap.Error(err))
+			// The pre-stop delay gives the network time to sync our "not
+			// ready" state. Then the server is shut down, which allows
+			// pending requests to complete, while no new work is accepted.
+			params := queue.DrainParams{
+				PreStopDelay: env.PreStopDelay,
+				Timeout:      env.DrainTimeout,
+			}
+			if env.DrainNotifyPath != "" {
+				params.NotifyURL = (&url.URL{
+					Scheme: "http",
+					Host:   target.Host,
+					Path:   env.DrainNotifyPath,
+				}).String()
+			}
+			if err := queue.Drain(server, params); err != nil {
+				logger.Errorw("Failed to drain proxy server", zap.Error(err))
 			}
 		})
 
@@ -498,8 +611,8 @@ func pushRequestLogHandler(currentHandler http.Handler, env config) http.Handler
 	return handler
 }
 
-func pushRequestMetricHandler(currentHandler http.Handler, countMetric *stats.Int64Measure, latencyMetric *stats.Float64Measure, env config) http.Handler {
-	r, err := queuestats.NewStatsReporter(env.ServingNamespace, env.ServingService, env.ServingConfiguration, env.ServingRevision, countMetric, latencyMetric)
+func pushRequestMetricHandler(currentHandler http.Handler, countMetric *stats.Int64Measure, latencyMetric *stats.Float64Measure, timeoutMetric *stats.Int64Measure, env config) http.Handler {
+	r, err := queuestats.NewStatsReporter(env.ServingNamespace, env.ServingService, env.ServingConfiguration, env.ServingRevision, countMetric, latencyMetric, timeoutMetric)
 	if err != nil {
 		logger.Errorw("Error setting up request metrics reporter. Request metrics will be unavailable.", zap.Error(err))
 		return currentHandler
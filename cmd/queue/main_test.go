@@ -97,6 +97,29 @@ func TestHandlerReqEvent(t *testing.T) {
 	}
 }
 
+func TestStreamHandlerReqEvent(t *testing.T) {
+	var httpHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+	}
+	reqChan := make(chan queue.ReqEvent, 10)
+	h := handler(reqChan, nil, streamHandler(reqChan, httpHandler, 0), func() bool { return true })
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	req.Header.Set(network.ProxyHeaderName, activator.Name)
+	h(httptest.NewRecorder(), req)
+
+	close(reqChan)
+	var got []queue.ReqEventType
+	for e := range reqChan {
+		got = append(got, e.EventType)
+	}
+	want := []queue.ReqEventType{queue.ProxiedIn, queue.ProxiedStreamIn, queue.ProxiedStreamOut, queue.ProxiedOut}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Events = %v, want: %v", got, want)
+	}
+}
+
 func TestProbeHandler(t *testing.T) {
 	testcases := []struct {
 		name          string
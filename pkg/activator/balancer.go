@@ -0,0 +1,368 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging/logkey"
+	"knative.dev/pkg/system"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
+	"knative.dev/serving/pkg/resources"
+)
+
+// ErrNoHealthyDests indicates that no healthy pod of the revision is known,
+// so the request has to be sent to the revision's private service instead.
+var ErrNoHealthyDests = errors.New("no healthy destinations")
+
+// LoadBalancingPolicy selects the pod a request is sent to among the healthy
+// pods of a revision that have capacity left.
+type LoadBalancingPolicy string
+
+const (
+	// RoundRobinPolicy cycles through the pods.
+	RoundRobinPolicy LoadBalancingPolicy = "round-robin"
+	// LeastOutstandingPolicy picks the pod with the fewest requests in flight.
+	LeastOutstandingPolicy LoadBalancingPolicy = "least-outstanding"
+	// PowerOfTwoChoicesPolicy picks two pods at random and takes the one
+	// with fewer requests in flight.
+	PowerOfTwoChoicesPolicy LoadBalancingPolicy = "power-of-two-choices"
+)
+
+// ParseLoadBalancingPolicy returns the LoadBalancingPolicy named s.
+func ParseLoadBalancingPolicy(s string) (LoadBalancingPolicy, error) {
+	switch p := LoadBalancingPolicy(s); p {
+	case RoundRobinPolicy, LeastOutstandingPolicy, PowerOfTwoChoicesPolicy:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown load balancing policy %q, want one of %q, %q or %q",
+		s, RoundRobinPolicy, LeastOutstandingPolicy, PowerOfTwoChoicesPolicy)
+}
+
+// podTracker counts the requests this activator has in flight to a pod.
+type podTracker struct {
+	dest     string
+	inFlight int
+}
+
+// revisionPods are the healthy pods of a revision.
+type revisionPods struct {
+	// mux guards all the fields below.
+	mux  sync.Mutex
+	pods []*podTracker
+	// containerConcurrency is the number of requests a single pod accepts
+	// at a time. 0 means unlimited.
+	containerConcurrency int
+	// capacity is the number of requests this activator sends to a single
+	// pod at a time, its share of the containerConcurrency. 0 means
+	// unlimited.
+	capacity int
+	// next is the index the round robin continues from.
+	next int
+	// waiters is the number of requests waiting for a pod to free up.
+	waiters int
+	// changed is closed and replaced when a pod frees up or the pods
+	// change, to wake up the waiting requests.
+	changed chan struct{}
+}
+
+// PodBalancer keeps track of the healthy pods of the revisions, as published
+// by the RevisionBackendsManager, and picks a pod for every request according
+// to the LoadBalancingPolicy. Like the Throttler, it divides the revision's
+// containerConcurrency among the activators, so that all of them together
+// don't have more requests in flight to a pod than it permits.
+type PodBalancer struct {
+	revisionsMux sync.RWMutex
+	revisions    map[RevisionID]*revisionPods
+
+	numActivatorsMux sync.RWMutex
+	numActivators    int
+
+	policy         LoadBalancingPolicy
+	revisionLister servinglisters.RevisionLister
+	logger         *zap.SugaredLogger
+}
+
+// NewPodBalancer creates a new PodBalancer.
+func NewPodBalancer(policy LoadBalancingPolicy, endpointsInformer corev1informers.EndpointsInformer,
+	revisionLister servinglisters.RevisionLister, logger *zap.SugaredLogger) *PodBalancer {
+	b := &PodBalancer{
+		revisions:      make(map[RevisionID]*revisionPods),
+		policy:         policy,
+		revisionLister: revisionLister,
+		logger:         logger,
+	}
+
+	// Update the capacity of the pods when the number of activators changes.
+	endpointsInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: reconciler.ChainFilterFuncs(
+			reconciler.NameFilterFunc(K8sServiceName),
+			reconciler.NamespaceFilterFunc(system.Namespace()),
+		),
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    b.activatorEndpointsUpdated,
+			UpdateFunc: controller.PassNew(b.activatorEndpointsUpdated),
+		},
+	})
+
+	return b
+}
+
+// Run applies the updates received on updateCh until stopCh is closed.
+func (b *PodBalancer) Run(updateCh <-chan *RevisionDestsUpdate, stopCh <-chan struct{}) {
+	for {
+		select {
+		case update := <-updateCh:
+			b.UpdateDests(update)
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// UpdateDests replaces the healthy pods of a revision. The requests in flight
+// to the pods that remain healthy are carried over.
+func (b *PodBalancer) UpdateDests(update *RevisionDestsUpdate) {
+	capacity := 0
+	if rev, err := b.revisionLister.Revisions(update.Rev.Namespace).Get(update.Rev.Name); err != nil {
+		b.logger.With(zap.String(logkey.Key, update.Rev.String())).Errorw(
+			"Failed to get revision, not limiting the requests per pod", zap.Error(err))
+	} else {
+		capacity = int(rev.Spec.ContainerConcurrency)
+	}
+
+	b.revisionsMux.Lock()
+	rp, ok := b.revisions[update.Rev]
+	if len(update.Dests) == 0 {
+		delete(b.revisions, update.Rev)
+	} else if !ok {
+		rp = &revisionPods{changed: make(chan struct{})}
+		b.revisions[update.Rev] = rp
+	}
+	b.revisionsMux.Unlock()
+	if rp == nil {
+		return
+	}
+
+	rp.mux.Lock()
+	defer rp.mux.Unlock()
+	old := make(map[string]*podTracker, len(rp.pods))
+	for _, pt := range rp.pods {
+		old[pt.dest] = pt
+	}
+	pods := make([]*podTracker, 0, len(update.Dests))
+	for _, dest := range update.Dests {
+		pt, ok := old[dest]
+		if !ok {
+			pt = &podTracker{dest: dest}
+		}
+		pods = append(pods, pt)
+	}
+	rp.pods = pods
+	rp.containerConcurrency = capacity
+	rp.capacity = podCapacity(capacity, b.activatorCount())
+	rp.broadcast()
+}
+
+func (b *PodBalancer) activatorCount() int {
+	b.numActivatorsMux.RLock()
+	defer b.numActivatorsMux.RUnlock()
+	return b.numActivators
+}
+
+func (b *PodBalancer) activatorEndpointsUpdated(newObj interface{}) {
+	endpoints := newObj.(*corev1.Endpoints)
+
+	b.numActivatorsMux.Lock()
+	b.numActivators = resources.ReadyAddressCount(endpoints)
+	b.numActivatorsMux.Unlock()
+
+	b.revisionsMux.RLock()
+	defer b.revisionsMux.RUnlock()
+	for _, rp := range b.revisions {
+		rp.mux.Lock()
+		rp.capacity = podCapacity(rp.containerConcurrency, b.activatorCount())
+		rp.broadcast()
+		rp.mux.Unlock()
+	}
+}
+
+// podCapacity returns the share of the containerConcurrency of a pod that
+// every activator gets, at least 1 request. 0 means unlimited.
+func podCapacity(cc, activatorCount int) int {
+	if cc == 0 {
+		return 0
+	}
+	return minOneOrValue(cc / minOneOrValue(activatorCount))
+}
+
+// Acquire picks a pod of the revision for a request and returns its address
+// along with the function to call once the request is done. If all the pods
+// are at capacity it waits for one to free up or for ctx to be done. It
+// returns ErrNoHealthyDests if the revision has no healthy pods.
+func (b *PodBalancer) Acquire(ctx context.Context, rev RevisionID) (string, func(), error) {
+	return b.AcquireExcept(ctx, rev, nil)
+}
+
+// AcquireExcept is like Acquire, but avoids the pods in except, e.g. the ones
+// a retried request already failed on. They are only picked if the revision
+// has no other pods.
+func (b *PodBalancer) AcquireExcept(ctx context.Context, rev RevisionID, except map[string]bool) (string, func(), error) {
+	b.revisionsMux.RLock()
+	rp, ok := b.revisions[rev]
+	b.revisionsMux.RUnlock()
+	if !ok {
+		return "", nil, ErrNoHealthyDests
+	}
+
+	rp.mux.Lock()
+	defer rp.mux.Unlock()
+	for {
+		if len(rp.pods) == 0 {
+			return "", nil, ErrNoHealthyDests
+		}
+		if pt := rp.pick(b.policy, rp.avoidable(except)); pt != nil {
+			pt.inFlight++
+			return pt.dest, func() { rp.release(pt) }, nil
+		}
+
+		ch := rp.changed
+		rp.waiters++
+		rp.mux.Unlock()
+		select {
+		case <-ch:
+			rp.mux.Lock()
+			rp.waiters--
+		case <-ctx.Done():
+			rp.mux.Lock()
+			rp.waiters--
+			return "", nil, ctx.Err()
+		}
+	}
+}
+
+// release frees up the slot of a request on the pod.
+func (rp *revisionPods) release(pt *podTracker) {
+	rp.mux.Lock()
+	defer rp.mux.Unlock()
+	pt.inFlight--
+	rp.broadcast()
+}
+
+// broadcast wakes up the waiting requests. It must be called with the mux held.
+func (rp *revisionPods) broadcast() {
+	if rp.waiters > 0 {
+		close(rp.changed)
+		rp.changed = make(chan struct{})
+	}
+}
+
+// avoidable returns the pods of except that can be avoided, i.e. all of them
+// unless no other pod is left. It must be called with the mux held.
+func (rp *revisionPods) avoidable(except map[string]bool) map[string]bool {
+	for _, pt := range rp.pods {
+		if !except[pt.dest] {
+			return except
+		}
+	}
+	return nil
+}
+
+// hasCapacity returns whether the pod accepts another request. It must be
+// called with the mux held.
+func (rp *revisionPods) hasCapacity(pt *podTracker) bool {
+	return rp.capacity == 0 || pt.inFlight < rp.capacity
+}
+
+// pick returns the pod the next request goes to according to the policy, or
+// nil if all the pods not in except are at capacity. It must be called with
+// the mux held.
+func (rp *revisionPods) pick(policy LoadBalancingPolicy, except map[string]bool) *podTracker {
+	available := func(pt *podTracker) bool {
+		return !except[pt.dest] && rp.hasCapacity(pt)
+	}
+	switch policy {
+	case LeastOutstandingPolicy:
+		return rp.pickLeastOutstanding(available)
+	case PowerOfTwoChoicesPolicy:
+		return rp.pickPowerOfTwoChoices(available)
+	default:
+		return rp.pickRoundRobin(available)
+	}
+}
+
+func (rp *revisionPods) pickRoundRobin(available func(*podTracker) bool) *podTracker {
+	n := len(rp.pods)
+	for i := 0; i < n; i++ {
+		idx := (rp.next + i) % n
+		if pt := rp.pods[idx]; available(pt) {
+			rp.next = (idx + 1) % n
+			return pt
+		}
+	}
+	return nil
+}
+
+func (rp *revisionPods) pickLeastOutstanding(available func(*podTracker) bool) *podTracker {
+	n := len(rp.pods)
+	var best *podTracker
+	// Start from the round robin position, so that the ties are spread
+	// over the pods.
+	for i := 0; i < n; i++ {
+		pt := rp.pods[(rp.next+i)%n]
+		if available(pt) && (best == nil || pt.inFlight < best.inFlight) {
+			best = pt
+		}
+	}
+	rp.next = (rp.next + 1) % n
+	return best
+}
+
+func (rp *revisionPods) pickPowerOfTwoChoices(available func(*podTracker) bool) *podTracker {
+	candidates := make([]*podTracker, 0, len(rp.pods))
+	for _, pt := range rp.pods {
+		if available(pt) {
+			candidates = append(candidates, pt)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil
+	case 1:
+		return candidates[0]
+	}
+	i := rand.Intn(len(candidates))
+	j := rand.Intn(len(candidates) - 1)
+	if j >= i {
+		j++
+	}
+	if candidates[j].inFlight < candidates[i].inFlight {
+		return candidates[j]
+	}
+	return candidates[i]
+}
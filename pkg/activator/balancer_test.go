@@ -0,0 +1,275 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+)
+
+var testDests = []string{"10.0.0.1:8012", "10.0.0.2:8012", "10.0.0.3:8012"}
+
+func podBalancer(t *testing.T, policy LoadBalancingPolicy, cc int, dests ...string) *PodBalancer {
+	b := NewPodBalancer(policy, endpointsInformer(testNamespace, testRevision, 1),
+		revisionLister(testNamespace, testRevision, v1beta1.RevisionContainerConcurrencyType(cc)), TestLogger(t))
+	b.UpdateDests(&RevisionDestsUpdate{Rev: revID, Dests: dests})
+	return b
+}
+
+func TestParseLoadBalancingPolicy(t *testing.T) {
+	for _, policy := range []LoadBalancingPolicy{RoundRobinPolicy, LeastOutstandingPolicy, PowerOfTwoChoicesPolicy} {
+		got, err := ParseLoadBalancingPolicy(string(policy))
+		if err != nil {
+			t.Errorf("ParseLoadBalancingPolicy(%q) = %v", policy, err)
+		} else if got != policy {
+			t.Errorf("ParseLoadBalancingPolicy(%q) = %q", policy, got)
+		}
+	}
+	if _, err := ParseLoadBalancingPolicy("random"); err == nil {
+		t.Error(`ParseLoadBalancingPolicy("random") = nil, wanted an error`)
+	}
+}
+
+func TestPodBalancerRoundRobin(t *testing.T) {
+	b := podBalancer(t, RoundRobinPolicy, 0, testDests...)
+
+	var got []string
+	for i := 0; i < 2*len(testDests); i++ {
+		dest, release, err := b.Acquire(context.Background(), revID)
+		if err != nil {
+			t.Fatalf("Acquire() = %v", err)
+		}
+		release()
+		got = append(got, dest)
+	}
+	if want := append(testDests, testDests...); !cmp.Equal(got, want) {
+		t.Errorf("Acquire() returned %v, want %v", got, want)
+	}
+}
+
+func TestPodBalancerLeastOutstanding(t *testing.T) {
+	b := podBalancer(t, LeastOutstandingPolicy, 0, testDests...)
+
+	// Keep a request in flight to each of the first two pods.
+	for i := 0; i < 2; i++ {
+		if _, _, err := b.Acquire(context.Background(), revID); err != nil {
+			t.Fatalf("Acquire() = %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		dest, release, err := b.Acquire(context.Background(), revID)
+		if err != nil {
+			t.Fatalf("Acquire() = %v", err)
+		}
+		release()
+		if got, want := dest, testDests[2]; got != want {
+			t.Errorf("Acquire() = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestPodBalancerRespectsContainerConcurrency(t *testing.T) {
+	for _, policy := range []LoadBalancingPolicy{RoundRobinPolicy, LeastOutstandingPolicy, PowerOfTwoChoicesPolicy} {
+		t.Run(string(policy), func(t *testing.T) {
+			b := podBalancer(t, policy, 1, testDests[:2]...)
+
+			got := map[string]func(){}
+			for i := 0; i < 2; i++ {
+				dest, release, err := b.Acquire(context.Background(), revID)
+				if err != nil {
+					t.Fatalf("Acquire() = %v", err)
+				}
+				got[dest] = release
+			}
+			if len(got) != 2 {
+				t.Fatalf("Acquire() returned %d distinct pods, want 2", len(got))
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if _, _, err := b.Acquire(ctx, revID); err != context.DeadlineExceeded {
+				t.Errorf("Acquire() = %v, want %v", err, context.DeadlineExceeded)
+			}
+
+			// The waiting request gets the pod that frees up.
+			type result struct {
+				dest string
+				err  error
+			}
+			resultCh := make(chan result)
+			go func() {
+				dest, _, err := b.Acquire(context.Background(), revID)
+				resultCh <- result{dest, err}
+			}()
+			select {
+			case <-resultCh:
+				t.Fatal("Acquire() returned while all the pods were at capacity")
+			case <-time.After(10 * time.Millisecond):
+			}
+			got[testDests[1]]()
+			select {
+			case res := <-resultCh:
+				if res.err != nil || res.dest != testDests[1] {
+					t.Errorf("Acquire() = (%s, %v), want (%s, nil)", res.dest, res.err, testDests[1])
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Timed out waiting for Acquire()")
+			}
+		})
+	}
+}
+
+// acquireAll acquires pods of the revision until they're all at capacity and
+// returns the number of requests acquired.
+func acquireAll(t *testing.T, b *PodBalancer) int {
+	t.Helper()
+	for n := 0; ; n++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, _, err := b.Acquire(ctx, revID)
+		cancel()
+		if err == context.DeadlineExceeded {
+			return n
+		} else if err != nil {
+			t.Fatalf("Acquire() = %v", err)
+		}
+	}
+}
+
+func TestPodBalancerActivatorEndpoints(t *testing.T) {
+	scenarios := []struct {
+		name                string
+		activatorCount      int
+		revisionConcurrency int
+		wantCapacity        int
+	}{{
+		name:                "less activators, more cc",
+		activatorCount:      2,
+		revisionConcurrency: 10,
+		wantCapacity:        5, //revConcurrency / activatorCount
+	}, {
+		name:                "many activators, less cc",
+		activatorCount:      3,
+		revisionConcurrency: 2,
+		wantCapacity:        1,
+	}}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			activatorEp := &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      K8sServiceName,
+					Namespace: system.Namespace(),
+				},
+				Subsets: endpointsSubset(1, s.activatorCount),
+			}
+
+			// Every activator only sends its share of the requests the pod
+			// accepts.
+			for i := 0; i < s.activatorCount; i++ {
+				b := podBalancer(t, RoundRobinPolicy, s.revisionConcurrency, testDests[0])
+				b.activatorEndpointsUpdated(activatorEp)
+				if got := acquireAll(t, b); got != s.wantCapacity {
+					t.Errorf("Activator %d acquired %d requests, want %d", i, got, s.wantCapacity)
+				}
+			}
+		})
+	}
+}
+
+func TestPodBalancerActivatorsScaleDown(t *testing.T) {
+	activatorEp := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      K8sServiceName,
+			Namespace: system.Namespace(),
+		},
+		Subsets: endpointsSubset(1, 2),
+	}
+	b := podBalancer(t, RoundRobinPolicy, 2, testDests[0])
+	b.activatorEndpointsUpdated(activatorEp)
+	if got, want := acquireAll(t, b), 1; got != want {
+		t.Fatalf("Acquired %d requests, want %d", got, want)
+	}
+
+	// The waiting request gets the capacity the other activator leaves.
+	errCh := make(chan error)
+	go func() {
+		_, _, err := b.Acquire(context.Background(), revID)
+		errCh <- err
+	}()
+	activatorEp.Subsets = endpointsSubset(1, 1)
+	b.activatorEndpointsUpdated(activatorEp)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("Acquire() = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for Acquire()")
+	}
+}
+
+func TestPodBalancerUpdateDests(t *testing.T) {
+	b := podBalancer(t, RoundRobinPolicy, 1, testDests[0])
+
+	if _, _, err := b.Acquire(context.Background(), RevisionID{testNamespace, "other"}); err != ErrNoHealthyDests {
+		t.Errorf("Acquire() = %v, want %v", err, ErrNoHealthyDests)
+	}
+
+	dest, _, err := b.Acquire(context.Background(), revID)
+	if err != nil {
+		t.Fatalf("Acquire() = %v", err)
+	}
+	if dest != testDests[0] {
+		t.Errorf("Acquire() = %s, want %s", dest, testDests[0])
+	}
+
+	// The request in flight is carried over, so the new pod is picked.
+	b.UpdateDests(&RevisionDestsUpdate{Rev: revID, Dests: testDests[:2]})
+	dest, _, err = b.Acquire(context.Background(), revID)
+	if err != nil {
+		t.Fatalf("Acquire() = %v", err)
+	}
+	if dest != testDests[1] {
+		t.Errorf("Acquire() = %s, want %s", dest, testDests[1])
+	}
+
+	// A waiting request falls back once the pods are gone.
+	errCh := make(chan error)
+	go func() {
+		_, _, err := b.Acquire(context.Background(), revID)
+		errCh <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	b.UpdateDests(&RevisionDestsUpdate{Rev: revID})
+	select {
+	case err := <-errCh:
+		if err != ErrNoHealthyDests {
+			t.Errorf("Acquire() = %v, want %v", err, ErrNoHealthyDests)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for Acquire()")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"sync"
+	"time"
+)
+
+// coldStartSmoothing is the weight of a newly observed cold start in the
+// estimate of a revision's cold start time.
+const coldStartSmoothing = 0.5
+
+// coldStarts keeps track of how long revisions take to scale from zero, to
+// estimate how much longer the requests arriving during a cold start would
+// have to wait.
+type coldStarts struct {
+	now func() time.Time
+
+	// mux guards the maps below.
+	mux sync.Mutex
+	// started is when the revisions that are scaling from zero were first
+	// requested.
+	started map[RevisionID]time.Time
+	// estimates are the smoothed cold start times of the revisions that
+	// were observed scaling from zero before.
+	estimates map[RevisionID]time.Duration
+}
+
+func newColdStarts() *coldStarts {
+	return &coldStarts{
+		now:       time.Now,
+		started:   make(map[RevisionID]time.Time),
+		estimates: make(map[RevisionID]time.Duration),
+	}
+}
+
+// start marks the revision as scaling from zero, unless it already is, and
+// returns how much longer that's estimated to take. The estimate is 0 if the
+// revision wasn't observed scaling from zero before.
+func (c *coldStarts) start(rev RevisionID) time.Duration {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	now := c.now()
+	started, ok := c.started[rev]
+	if !ok {
+		started = now
+		c.started[rev] = now
+	}
+	estimate, ok := c.estimates[rev]
+	if !ok {
+		return 0
+	}
+	if remaining := estimate - now.Sub(started); remaining > 0 {
+		return remaining
+	}
+	// The cold start takes longer than usual, but should be over soon.
+	return time.Second
+}
+
+// finish records the duration of the revision's cold start, if it's
+// scaling from zero.
+func (c *coldStarts) finish(rev RevisionID) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	started, ok := c.started[rev]
+	if !ok {
+		return
+	}
+	delete(c.started, rev)
+	d := c.now().Sub(started)
+	if estimate, ok := c.estimates[rev]; ok {
+		d = time.Duration(coldStartSmoothing*float64(d) + (1-coldStartSmoothing)*float64(estimate))
+	}
+	c.estimates[rev] = d
+}
+
+// remove drops the bookkeeping of the revision.
+func (c *coldStarts) remove(rev RevisionID) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	delete(c.started, rev)
+	delete(c.estimates, rev)
+}
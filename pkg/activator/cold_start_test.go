@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestColdStarts(t *testing.T) {
+	now := time.Now()
+	c := newColdStarts()
+	c.now = func() time.Time { return now }
+	other := RevisionID{Namespace: testNamespace, Name: "other"}
+
+	tests := []struct {
+		name          string
+		coldStart     time.Duration
+		wait          time.Duration
+		wantRemaining time.Duration
+	}{{
+		name:          "first cold start",
+		coldStart:     20 * time.Second,
+		wantRemaining: 0,
+	}, {
+		name:          "estimated from the first",
+		coldStart:     10 * time.Second,
+		wantRemaining: 20 * time.Second,
+	}, {
+		name:          "smoothed over both",
+		coldStart:     5 * time.Second,
+		wait:          3 * time.Second,
+		wantRemaining: 12 * time.Second,
+	}, {
+		name:          "overdue",
+		coldStart:     70 * time.Second,
+		wait:          time.Minute,
+		wantRemaining: time.Second,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c.start(revID)
+			now = now.Add(test.wait)
+			if got := c.start(revID); got != test.wantRemaining {
+				t.Errorf("start() = %v, want: %v", got, test.wantRemaining)
+			}
+			now = now.Add(test.coldStart - test.wait)
+			c.finish(revID)
+
+			// Other revisions are estimated on their own.
+			if got := c.start(other); got != 0 {
+				t.Errorf("start() of other revision = %v, want: 0", got)
+			}
+		})
+	}
+
+	c.remove(revID)
+	if got := c.start(revID); got != 0 {
+		t.Errorf("start() after remove() = %v, want: 0", got)
+	}
+}
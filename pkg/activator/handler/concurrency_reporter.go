@@ -43,37 +43,47 @@ type ConcurrencyReporter struct {
 	rl servinglisters.RevisionLister
 	sr activator.StatsReporter
 
+	// excludeStreams keeps the requests that turned into streams out of the
+	// concurrency reported to the autoscaler.
+	excludeStreams bool
+
 	clock system.Clock
 }
 
 // NewConcurrencyReporter creates a ConcurrencyReporter which listens to incoming
 // ReqEvents on reqCh and ticks on reportCh and reports stats on statCh.
 func NewConcurrencyReporter(logger *zap.SugaredLogger, podName string, reqCh chan ReqEvent, reportCh <-chan time.Time,
-	statCh chan *autoscaler.StatMessage, rl servinglisters.RevisionLister, sr activator.StatsReporter) *ConcurrencyReporter {
-	return NewConcurrencyReporterWithClock(logger, podName, reqCh, reportCh, statCh, rl, sr, system.RealClock{})
+	statCh chan *autoscaler.StatMessage, rl servinglisters.RevisionLister, sr activator.StatsReporter, excludeStreams bool) *ConcurrencyReporter {
+	return NewConcurrencyReporterWithClock(logger, podName, reqCh, reportCh, statCh, rl, sr, excludeStreams, system.RealClock{})
 }
 
 // NewConcurrencyReporterWithClock instantiates a new concurrency reporter
 // which uses the passed clock.
 func NewConcurrencyReporterWithClock(logger *zap.SugaredLogger, podName string, reqCh chan ReqEvent, reportCh <-chan time.Time,
-	statCh chan *autoscaler.StatMessage, rl servinglisters.RevisionLister, sr activator.StatsReporter, clock system.Clock) *ConcurrencyReporter {
+	statCh chan *autoscaler.StatMessage, rl servinglisters.RevisionLister, sr activator.StatsReporter, excludeStreams bool,
+	clock system.Clock) *ConcurrencyReporter {
 	return &ConcurrencyReporter{
-		logger:   logger,
-		podName:  podName,
-		reqCh:    reqCh,
-		reportCh: reportCh,
-		statCh:   statCh,
-		rl:       rl,
-		sr:       sr,
-		clock:    clock,
+		logger:         logger,
+		podName:        podName,
+		reqCh:          reqCh,
+		reportCh:       reportCh,
+		statCh:         statCh,
+		rl:             rl,
+		sr:             sr,
+		excludeStreams: excludeStreams,
+		clock:          clock,
 	}
 }
 
-func (cr *ConcurrencyReporter) reportToAutoscaler(key types.NamespacedName, concurrency, requestCount int32) {
+func (cr *ConcurrencyReporter) reportToAutoscaler(key types.NamespacedName, concurrency, streams, requestCount int32) {
+	if cr.excludeStreams {
+		concurrency -= streams
+	}
 	stat := autoscaler.Stat{
 		PodName:                   cr.podName,
 		AverageConcurrentRequests: float64(concurrency),
 		RequestCount:              float64(requestCount),
+		AverageConcurrentStreams:  float64(streams),
 	}
 
 	// Send the stat to another goroutine to transmit
@@ -84,7 +94,7 @@ func (cr *ConcurrencyReporter) reportToAutoscaler(key types.NamespacedName, conc
 	}
 }
 
-func (cr *ConcurrencyReporter) reportToMetricsBackend(key types.NamespacedName, concurrency int32) {
+func (cr *ConcurrencyReporter) reportToMetricsBackend(key types.NamespacedName, concurrency, streams int32) {
 	ns := key.Namespace
 	revName := key.Name
 	revision, err := cr.rl.Revisions(ns).Get(revName)
@@ -95,6 +105,7 @@ func (cr *ConcurrencyReporter) reportToMetricsBackend(key types.NamespacedName,
 	configurationName := revision.Labels[serving.ConfigurationLabelKey]
 	serviceName := revision.Labels[serving.ServiceLabelKey]
 	cr.sr.ReportRequestConcurrency(ns, serviceName, configurationName, revName, int64(concurrency))
+	cr.sr.ReportActiveStreams(ns, serviceName, configurationName, revName, int64(streams))
 }
 
 // Run runs until stopCh is closed and processes events on all incoming channels
@@ -104,6 +115,9 @@ func (cr *ConcurrencyReporter) Run(stopCh <-chan struct{}) {
 	// Contains the number of incoming requests in the current
 	// reporting period, per key.
 	incomingRequestsPerKey := make(map[types.NamespacedName]int32)
+	// Contains the number of in-flight requests that turned into streams,
+	// per key.
+	streamsPerKey := make(map[types.NamespacedName]int32)
 
 	for {
 		select {
@@ -114,20 +128,26 @@ func (cr *ConcurrencyReporter) Run(stopCh <-chan struct{}) {
 
 				// Report the first request for a key immediately.
 				if _, ok := outstandingRequestsPerKey[event.Key]; !ok {
-					cr.reportToAutoscaler(event.Key, 1, incomingRequestsPerKey[event.Key])
+					cr.reportToAutoscaler(event.Key, 1, 0, incomingRequestsPerKey[event.Key])
 				}
 				outstandingRequestsPerKey[event.Key]++
 			case ReqOut:
 				outstandingRequestsPerKey[event.Key]--
+			case StreamIn:
+				streamsPerKey[event.Key]++
+			case StreamOut:
+				streamsPerKey[event.Key]--
 			}
 		case <-cr.reportCh:
 			for key, concurrency := range outstandingRequestsPerKey {
+				streams := streamsPerKey[key]
 				if concurrency == 0 {
 					delete(outstandingRequestsPerKey, key)
+					delete(streamsPerKey, key)
 				} else {
-					cr.reportToAutoscaler(key, concurrency, incomingRequestsPerKey[key])
+					cr.reportToAutoscaler(key, concurrency, streams, incomingRequestsPerKey[key])
 				}
-				cr.reportToMetricsBackend(key, concurrency)
+				cr.reportToMetricsBackend(key, concurrency, streams)
 			}
 
 			incomingRequestsPerKey = make(map[types.NamespacedName]int32)
@@ -32,6 +32,9 @@ const (
 	requestOpTick  = "RequestOpTick"
 	requestOpStart = "RequestOpStart"
 	requestOpEnd   = "RequestOpEnd"
+
+	streamOpStart = "StreamOpStart"
+	streamOpEnd   = "StreamOpEnd"
 )
 
 var (
@@ -56,9 +59,10 @@ type reqOp struct {
 
 func TestStats(t *testing.T) {
 	tt := []struct {
-		name          string
-		ops           []reqOp
-		expectedStats []*autoscaler.StatMessage
+		name           string
+		excludeStreams bool
+		ops            []reqOp
+		expectedStats  []*autoscaler.StatMessage
 	}{{
 		name: "Scale-from-zero sends stat",
 		ops: []reqOp{{
@@ -194,13 +198,81 @@ func TestStats(t *testing.T) {
 				RequestCount:              1,
 				PodName:                   "activator",
 			}},
+		}}, {
+		name: "Streams are counted",
+		ops: []reqOp{{
+			op:  requestOpStart,
+			key: pod1,
+		}, {
+			op:  streamOpStart,
+			key: pod1,
+		}, {
+			op:  requestOpStart,
+			key: pod1,
+		}, {
+			op: requestOpTick,
+		}},
+		expectedStats: []*autoscaler.StatMessage{{
+			Key: pod1,
+			Stat: autoscaler.Stat{
+				AverageConcurrentRequests: 1,
+				RequestCount:              1,
+				PodName:                   "activator",
+			}}, {
+			Key: pod1,
+			Stat: autoscaler.Stat{
+				AverageConcurrentRequests: 2,
+				AverageConcurrentStreams:  1,
+				RequestCount:              2,
+				PodName:                   "activator",
+			}},
+		}}, {
+		name:           "Streams are excluded",
+		excludeStreams: true,
+		ops: []reqOp{{
+			op:  requestOpStart,
+			key: pod1,
+		}, {
+			op:  streamOpStart,
+			key: pod1,
+		}, {
+			op:  requestOpStart,
+			key: pod1,
+		}, {
+			op: requestOpTick,
+		}, {
+			op:  streamOpEnd,
+			key: pod1,
+		}, {
+			op: requestOpTick,
+		}},
+		expectedStats: []*autoscaler.StatMessage{{
+			Key: pod1,
+			Stat: autoscaler.Stat{
+				AverageConcurrentRequests: 1,
+				RequestCount:              1,
+				PodName:                   "activator",
+			}}, {
+			Key: pod1,
+			Stat: autoscaler.Stat{
+				AverageConcurrentRequests: 1,
+				AverageConcurrentStreams:  1,
+				RequestCount:              2,
+				PodName:                   "activator",
+			}}, {
+			Key: pod1,
+			Stat: autoscaler.Stat{
+				AverageConcurrentRequests: 2,
+				RequestCount:              0,
+				PodName:                   "activator",
+			}},
 		}},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			closeCh := make(chan struct{})
-			s, cr := newTestStats(t, fakeClock{})
+			s, cr := newTestStats(t, fakeClock{}, tc.excludeStreams)
 			go func() {
 				cr.Run(closeCh)
 			}()
@@ -212,6 +284,10 @@ func TestStats(t *testing.T) {
 					s.reqChan <- ReqEvent{Key: op.key, EventType: ReqIn}
 				case requestOpEnd:
 					s.reqChan <- ReqEvent{Key: op.key, EventType: ReqOut}
+				case streamOpStart:
+					s.reqChan <- ReqEvent{Key: op.key, EventType: StreamIn}
+				case streamOpEnd:
+					s.reqChan <- ReqEvent{Key: op.key, EventType: StreamOut}
 				case requestOpTick:
 					s.reportBiChan <- op.time
 				}
@@ -243,7 +319,7 @@ type testStats struct {
 	reportBiChan chan time.Time
 }
 
-func newTestStats(t *testing.T, clock system.Clock) (*testStats, *ConcurrencyReporter) {
+func newTestStats(t *testing.T, clock system.Clock, excludeStreams bool) (*testStats, *ConcurrencyReporter) {
 	reportBiChan := make(chan time.Time)
 	ts := &testStats{
 		reqChan:      make(chan ReqEvent),
@@ -251,6 +327,6 @@ func newTestStats(t *testing.T, clock system.Clock) (*testStats, *ConcurrencyRep
 		statChan:     make(chan *autoscaler.StatMessage, 20),
 		reportBiChan: reportBiChan,
 	}
-	cr := NewConcurrencyReporterWithClock(TestLogger(t), "activator", ts.reqChan, ts.reportChan, ts.statChan, revisionLister(revision(testNamespace, testRevName)), &fakeReporter{}, clock)
+	cr := NewConcurrencyReporterWithClock(TestLogger(t), "activator", ts.reqChan, ts.reportChan, ts.statChan, revisionLister(revision(testNamespace, testRevName)), &fakeReporter{}, excludeStreams, clock)
 	return ts, cr
 }
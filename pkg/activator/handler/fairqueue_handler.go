@@ -0,0 +1,36 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+
+	pkghttp "knative.dev/serving/pkg/http"
+	"knative.dev/serving/pkg/queue"
+)
+
+// FairQueueKeyHandler takes the key the throttler queues the request by
+// from the given request header.
+type FairQueueKeyHandler struct {
+	Header      string
+	NextHandler http.Handler
+}
+
+func (h *FairQueueKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := pkghttp.LastHeaderValue(r.Header, h.Header)
+	h.NextHandler.ServeHTTP(w, r.WithContext(queue.WithFairQueueKey(r.Context(), key)))
+}
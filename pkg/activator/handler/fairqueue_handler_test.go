@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"knative.dev/serving/pkg/queue"
+)
+
+func TestFairQueueKeyHandler(t *testing.T) {
+	const header = "X-Tenant"
+	tests := []struct {
+		name    string
+		headers []string
+		want    string
+	}{{
+		name: "no header",
+	}, {
+		name:    "header",
+		headers: []string{"tenant-a"},
+		want:    "tenant-a",
+	}, {
+		name:    "last header wins",
+		headers: []string{"tenant-a", "tenant-b"},
+		want:    "tenant-b",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got string
+			handler := &FairQueueKeyHandler{
+				Header: header,
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					got = queue.FairQueueKeyFrom(r.Context())
+				}),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			for _, h := range test.headers {
+				req.Header.Add(header, h)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != test.want {
+				t.Errorf("Key = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
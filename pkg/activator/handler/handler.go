@@ -16,9 +16,13 @@ limitations under the License.
 package handler
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -29,6 +33,7 @@ import (
 	"go.opencensus.io/plugin/ochttp"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 
 	"knative.dev/pkg/logging/logkey"
 	"knative.dev/serving/pkg/activator"
@@ -55,6 +60,10 @@ type activationHandler struct {
 	transport http.RoundTripper
 	reporter  activator.StatsReporter
 	throttler *activator.Throttler
+	balancer  *activator.PodBalancer
+	retry     RetryParams
+
+	retryBudgets *retryBudgets
 
 	probeTimeout    time.Duration
 	probeTransport  http.RoundTripper
@@ -65,19 +74,45 @@ type activationHandler struct {
 	sksLister      netlisters.ServerlessServiceLister
 }
 
+// RetryParams defines the retries of the requests that fail before the
+// backend responded, e.g. because the connection to a pod that is just
+// becoming ready was reset.
+type RetryParams struct {
+	// MaxRetries is how often a single request is retried at most. The
+	// retries across the requests to a revision are limited by BudgetRatio
+	// on top. 0 disables retries.
+	MaxRetries int
+	// BudgetRatio is the share of the requests to a revision that may be
+	// retried, beyond a burst of retries the budget of every revision
+	// starts with. E.g. with 0.2 a failing revision sees at most about 1.2
+	// times its traffic once the burst is used up.
+	BudgetRatio float64
+	// MaxBodyBytes is the size up to which request bodies are buffered in
+	// memory, so that the requests can be replayed. GET and HEAD requests
+	// without a body are always safe to replay, other requests only if 0 <
+	// MaxBodyBytes and their body, if any, fits.
+	MaxBodyBytes int64
+}
+
 // The default time we'll try to probe the revision for activation.
 const defaulTimeout = 2 * time.Minute
 
 // New constructs a new http.Handler that deals with revision activation.
+// If b is not nil, the requests are sent directly to the healthy pods it
+// picks, and to the revision's private service otherwise. The requests are
+// proxied with rt and the revisions probed with prt.
 func New(l *zap.SugaredLogger, r activator.StatsReporter, t *activator.Throttler,
-	rl servinglisters.RevisionLister, sl corev1listers.ServiceLister,
-	sksL netlisters.ServerlessServiceLister) http.Handler {
+	b *activator.PodBalancer, retry RetryParams, rt, prt http.RoundTripper, rl servinglisters.RevisionLister,
+	sl corev1listers.ServiceLister, sksL netlisters.ServerlessServiceLister) http.Handler {
 
 	return &activationHandler{
 		logger:         l,
-		transport:      network.AutoTransport,
+		transport:      rt,
 		reporter:       r,
 		throttler:      t,
+		balancer:       b,
+		retry:          retry,
+		retryBudgets:   newRetryBudgets(retry.BudgetRatio),
 		revisionLister: rl,
 		sksLister:      sksL,
 		serviceLister:  sl,
@@ -85,7 +120,7 @@ func New(l *zap.SugaredLogger, r activator.StatsReporter, t *activator.Throttler
 		// In activator we collect metrics, so we're wrapping
 		// the RoundTripper the prober would use inside an annotating transport.
 		probeTransport: &ochttp.Transport{
-			Base: network.NewProberTransport(),
+			Base: prt,
 		},
 		endpointTimeout: defaulTimeout,
 	}
@@ -142,7 +177,7 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	revision, err := a.revisionLister.Revisions(namespace).Get(name)
 	if err != nil {
 		logger.Errorw("Error while getting revision", zap.Error(err))
-		sendError(err, w)
+		sendError(err, w, r)
 		return
 	}
 
@@ -150,20 +185,35 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	sks, err := a.sksLister.ServerlessServices(namespace).Get(name)
 	if err != nil {
 		logger.Errorw("Error while getting SKS", zap.Error(err))
-		sendError(err, w)
+		sendError(err, w, r)
 		return
 	}
 	host, err := a.serviceHostName(revision, sks.Status.PrivateServiceName)
 	if err != nil {
 		logger.Errorw("Error while getting hostname", zap.Error(err))
-		sendError(err, w)
+		sendError(err, w, r)
 		return
 	}
 
 	target := &url.URL{
-		Scheme: "http",
+		Scheme: activator.QueueProxyScheme(revision),
 		Host:   host,
 	}
+	configurationName := revision.Labels[serving.ConfigurationLabelKey]
+	serviceName := revision.Labels[serving.ServiceLabelKey]
+
+	if revision.Annotations[serving.ColdStartPolicyAnnotationKey] == serving.ColdStartPolicyFailFast {
+		// The request was counted already, so the revision scales up
+		// regardless.
+		if coldStart, remaining, err := a.throttler.ColdStart(revID); err == nil && coldStart {
+			a.reporter.ReportRequestRejected(namespace, serviceName, configurationName, name, activator.RejectReasonColdStart, 1)
+			if remaining > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+			}
+			sendUnavailable(r.Context(), w, r, activator.ErrColdStart.Error())
+			return
+		}
+	}
 
 	tryContext, trySpan := trace.StartSpan(r.Context(), "throttler_try")
 	if a.endpointTimeout > 0 {
@@ -175,26 +225,48 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	tryStart := time.Now()
 	err = a.throttler.Try(tryContext, revID, func() {
 		trySpan.End()
-		a.logger.Debugf("Waiting for throttler took %v time", time.Since(tryStart))
-
-		probeCtx, probeSpan := trace.StartSpan(r.Context(), "probe")
-		success, attempts := a.probeEndpoint(logger, r.WithContext(probeCtx), target)
-		probeSpan.End()
-
-		var httpStatus int
-		if success {
-			// Once we see a successful probe, send traffic.
-			attempts++
-			proxyCtx, proxySpan := trace.StartSpan(r.Context(), "proxy")
-			httpStatus = a.proxyRequest(w, r.WithContext(proxyCtx), target)
-			proxySpan.End()
-		} else {
-			httpStatus = http.StatusInternalServerError
-			w.WriteHeader(httpStatus)
+		queueTime := time.Since(tryStart)
+		a.logger.Debugf("Waiting for throttler took %v time", queueTime)
+		a.reporter.ReportQueueTime(namespace, serviceName, configurationName, name, queueTime)
+
+		var (
+			httpStatus int
+			attempts   int
+		)
+		dest, release, err := a.acquirePod(tryContext, revID)
+		switch err {
+		case nil:
+			// The pod has been probed healthy already, send traffic right away.
+			// Retries go to the other healthy pods.
+			httpStatus, attempts = a.proxyWithRetries(logger, w, r, revID, target.Scheme, dest, release,
+				func(tried map[string]bool) (string, func(), error) {
+					return a.balancer.AcquireExcept(tryContext, revID, tried)
+				})
+		case activator.ErrNoHealthyDests:
+			probeCtx, probeSpan := trace.StartSpan(r.Context(), "probe")
+			var success bool
+			success, attempts = a.probeEndpoint(logger, r.WithContext(probeCtx), target)
+			probeSpan.End()
+
+			if success {
+				// Once we see a successful probe, send traffic. Retries go
+				// through the private service again, which picks a pod anew.
+				var proxyAttempts int
+				httpStatus, proxyAttempts = a.proxyWithRetries(logger, w, r, revID, target.Scheme, target.Host, func() {},
+					func(map[string]bool) (string, func(), error) {
+						return target.Host, func() {}, nil
+					})
+				attempts += proxyAttempts
+			} else {
+				httpStatus = http.StatusInternalServerError
+				w.WriteHeader(httpStatus)
+			}
+		default:
+			// No pod freed up in time.
+			httpStatus = http.StatusServiceUnavailable
+			sendUnavailable(tryContext, w, r, activator.ErrActivatorOverload.Error())
 		}
 
-		configurationName := revision.Labels[serving.ConfigurationLabelKey]
-		serviceName := revision.Labels[serving.ServiceLabelKey]
 		a.reporter.ReportRequestCount(namespace, serviceName, configurationName, name, httpStatus, attempts, 1.0)
 		a.reporter.ReportResponseTime(namespace, serviceName, configurationName, name, httpStatus, time.Since(start))
 	})
@@ -204,17 +276,119 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			trace.StringAttribute("activator.throttler.error", err.Error()),
 		}, "ThrottlerTry")
 		trySpan.End()
-
-		if err == activator.ErrActivatorOverload {
-			http.Error(w, activator.ErrActivatorOverload.Error(), http.StatusServiceUnavailable)
-		} else {
+		// The requests rejected or timing out waited for capacity too.
+		a.reporter.ReportQueueTime(namespace, serviceName, configurationName, name, time.Since(tryStart))
+
+		switch err {
+		case activator.ErrActivatorOverload:
+			a.reporter.ReportRequestRejected(namespace, serviceName, configurationName, name, activator.RejectReasonOverload, 1)
+			sendUnavailable(tryContext, w, r, activator.ErrActivatorOverload.Error())
+		case activator.ErrKeyOverload:
+			a.reporter.ReportRequestRejected(namespace, serviceName, configurationName, name, activator.RejectReasonKeyQueueFull, 1)
+			sendUnavailable(tryContext, w, r, activator.ErrKeyOverload.Error())
+		case activator.ErrRequestShed:
+			a.reporter.ReportRequestRejected(namespace, serviceName, configurationName, name, activator.RejectReasonWaitBudgetExceeded, 1)
+			sendUnavailable(tryContext, w, r, activator.ErrRequestShed.Error())
+		default:
 			w.WriteHeader(http.StatusInternalServerError)
 			logger.Errorw("Error processing request in the activator", zap.Error(err))
 		}
 	}
 }
 
-func (a *activationHandler) proxyRequest(w http.ResponseWriter, r *http.Request, target *url.URL) int {
+// acquirePod picks a healthy pod of the revision to send the request to.
+// It returns activator.ErrNoHealthyDests if there is none to pick from.
+func (a *activationHandler) acquirePod(ctx context.Context, revID activator.RevisionID) (string, func(), error) {
+	if a.balancer == nil {
+		return "", nil, activator.ErrNoHealthyDests
+	}
+	return a.balancer.Acquire(ctx, revID)
+}
+
+// proxyWithRetries proxies the request to dest, reached with the URL scheme,
+// and calls release once it's done. If the request fails before the backend
+// responded and is safe to replay, it's retried on the destinations returned
+// by next, which is passed the destinations tried so far, until MaxRetries
+// retries were made or the retry budget of the revision is used up. It
+// returns the response status and the number of attempts.
+func (a *activationHandler) proxyWithRetries(logger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request,
+	revID activator.RevisionID, scheme, dest string, release func(),
+	next func(tried map[string]bool) (string, func(), error)) (int, int) {
+	a.retryBudgets.deposit(revID)
+	replayBody, replayable := a.replayableBody(r)
+	tried := make(map[string]bool)
+	for attempts := 1; ; attempts++ {
+		if replayBody != nil {
+			r.Body = replayBody()
+		}
+		canRetry := replayable && attempts <= a.retry.MaxRetries
+		httpStatus, err := func() (int, error) {
+			// The proxy panics with http.ErrAbortHandler when the client
+			// goes away mid-response, so release in a defer.
+			defer release()
+			proxyCtx, proxySpan := trace.StartSpan(r.Context(), "proxy")
+			defer proxySpan.End()
+			return a.proxyRequest(w, r.WithContext(proxyCtx), &url.URL{
+				Scheme: scheme,
+				Host:   dest,
+			}, canRetry)
+		}()
+		if err == nil {
+			return httpStatus, attempts
+		}
+
+		tried[dest] = true
+		if r.Context().Err() == nil {
+			if !a.retryBudgets.withdraw(revID) {
+				logger.Warnw("Retry budget used up", zap.String("dest", dest), zap.Int("attempts", attempts), zap.Error(err))
+				w.WriteHeader(http.StatusBadGateway)
+				return http.StatusBadGateway, attempts
+			}
+			logger.Warnw("Retrying request", zap.String("dest", dest), zap.Int("attempts", attempts), zap.Error(err))
+			dest, release, err = next(tried)
+		}
+		if r.Context().Err() != nil || err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return http.StatusBadGateway, attempts
+		}
+	}
+}
+
+// replayableBody returns whether the request is safe to replay and, if it
+// has a body, a function returning a copy of the body for every attempt. The
+// body is buffered if it's no larger than the RetryParams permit.
+func (a *activationHandler) replayableBody(r *http.Request) (func() io.ReadCloser, bool) {
+	if a.retry.MaxRetries <= 0 {
+		return nil, false
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && a.retry.MaxBodyBytes <= 0 {
+		return nil, false
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	if r.ContentLength > a.retry.MaxBodyBytes {
+		return nil, false
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, a.retry.MaxBodyBytes+1))
+	if err != nil || int64(len(body)) > a.retry.MaxBodyBytes {
+		// Pass on what was read along with the rest of the body.
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		return nil, false
+	}
+	return func() io.ReadCloser {
+		return ioutil.NopCloser(bytes.NewReader(body))
+	}, true
+}
+
+// proxyRequest proxies the request to target and returns the response
+// status. If canRetry is true and the request fails before the backend
+// responded, nothing is written to w and the error is returned instead.
+func (a *activationHandler) proxyRequest(w http.ResponseWriter, r *http.Request, target *url.URL, canRetry bool) (int, error) {
 	network.RewriteHostIn(r)
 	recorder := pkghttp.NewResponseRecorder(w, http.StatusOK)
 	proxy := httputil.NewSingleHostReverseProxy(target)
@@ -223,12 +397,22 @@ func (a *activationHandler) proxyRequest(w http.ResponseWriter, r *http.Request,
 	}
 	proxy.FlushInterval = -1
 
+	var proxyErr error
+	if canRetry {
+		proxy.ErrorHandler = func(_ http.ResponseWriter, _ *http.Request, err error) {
+			proxyErr = err
+		}
+	}
+
 	r.Header.Set(network.ProxyHeaderName, activator.Name)
 
 	util.SetupHeaderPruning(proxy)
 
 	proxy.ServeHTTP(recorder, r)
-	return recorder.ResponseCode
+	if proxyErr != nil {
+		return 0, proxyErr
+	}
+	return recorder.ResponseCode, nil
 }
 
 // serviceHostName obtains the hostname of the underlying service and the correct
@@ -257,11 +441,22 @@ func (a *activationHandler) serviceHostName(rev *v1alpha1.Revision, serviceName
 	return net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(port)), nil
 }
 
-func sendError(err error, w http.ResponseWriter) {
+func sendError(err error, w http.ResponseWriter, r *http.Request) {
 	msg := fmt.Sprintf("Error getting active endpoint: %v", err)
 	if k8serrors.IsNotFound(err) {
-		http.Error(w, msg, http.StatusNotFound)
+		pkghttp.Error(w, r, msg, http.StatusNotFound)
+		return
+	}
+	pkghttp.Error(w, r, msg, http.StatusInternalServerError)
+}
+
+// sendUnavailable replies with 503 to a request that can't be served right
+// now. gRPC requests are answered with the status UNAVAILABLE, or with
+// DEADLINE_EXCEEDED if they ran out of time waiting, i.e. ctx expired.
+func sendUnavailable(ctx context.Context, w http.ResponseWriter, r *http.Request, msg string) {
+	if ctx.Err() == context.DeadlineExceeded && pkghttp.IsGRPC(r) {
+		pkghttp.GRPCError(w, codes.DeadlineExceeded, msg)
 		return
 	}
-	http.Error(w, msg, http.StatusInternalServerError)
+	pkghttp.Error(w, r, msg, http.StatusServiceUnavailable)
 }
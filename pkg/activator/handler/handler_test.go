@@ -15,11 +15,13 @@ limitations under the License.
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -32,6 +34,7 @@ import (
 	openzipkin "github.com/openzipkin/zipkin-go"
 	zipkinreporter "github.com/openzipkin/zipkin-go/reporter"
 	reporterrecorder "github.com/openzipkin/zipkin-go/reporter/recorder"
+	"google.golang.org/grpc/codes"
 
 	. "knative.dev/pkg/logging/testing"
 	_ "knative.dev/pkg/system/testing"
@@ -45,6 +48,7 @@ import (
 	servinginformers "knative.dev/serving/pkg/client/informers/externalversions"
 	netlisters "knative.dev/serving/pkg/client/listers/networking/v1alpha1"
 	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	pkghttp "knative.dev/serving/pkg/http"
 	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/queue"
 	"knative.dev/serving/pkg/tracing"
@@ -92,6 +96,12 @@ func TestActivationHandler(t *testing.T) {
 		wantErr:           nil,
 		endpointsInformer: endpointsInformer(endpoints(testNamespace, testRevName, 1000)),
 		reporterCalls: []reporterCall{{
+			Op:        "ReportQueueTime",
+			Namespace: testNamespace,
+			Revision:  testRevName,
+			Service:   "service-real-name",
+			Config:    "config-real-name",
+		}, {
 			Op:         "ReportRequestCount",
 			Namespace:  testNamespace,
 			Revision:   testRevName,
@@ -119,6 +129,12 @@ func TestActivationHandler(t *testing.T) {
 		probeResp:         []string{activator.Name, queue.Name},
 		endpointsInformer: endpointsInformer(endpoints(testNamespace, testRevName, 1000)),
 		reporterCalls: []reporterCall{{
+			Op:        "ReportQueueTime",
+			Namespace: testNamespace,
+			Revision:  testRevName,
+			Service:   "service-real-name",
+			Config:    "config-real-name",
+		}, {
 			Op:         "ReportRequestCount",
 			Namespace:  testNamespace,
 			Revision:   testRevName,
@@ -145,6 +161,12 @@ func TestActivationHandler(t *testing.T) {
 		wantErr:           nil,
 		endpointsInformer: endpointsInformer(endpoints(testNamespace, testRevName, 1000)),
 		reporterCalls: []reporterCall{{
+			Op:        "ReportQueueTime",
+			Namespace: testNamespace,
+			Revision:  testRevName,
+			Service:   "service-real-name",
+			Config:    "config-real-name",
+		}, {
 			Op:         "ReportRequestCount",
 			Namespace:  testNamespace,
 			Revision:   testRevName,
@@ -179,6 +201,12 @@ func TestActivationHandler(t *testing.T) {
 		endpointsInformer: endpointsInformer(endpoints(testNamespace, testRevName, 1000)),
 		probeTimeout:      1 * time.Millisecond,
 		reporterCalls: []reporterCall{{
+			Op:        "ReportQueueTime",
+			Namespace: testNamespace,
+			Revision:  testRevName,
+			Service:   "service-real-name",
+			Config:    "config-real-name",
+		}, {
 			Op:         "ReportRequestCount",
 			Namespace:  testNamespace,
 			Revision:   testRevName,
@@ -204,6 +232,12 @@ func TestActivationHandler(t *testing.T) {
 		endpointsInformer: endpointsInformer(endpoints(testNamespace, testRevName, 1000)),
 		probeTimeout:      10 * time.Millisecond,
 		reporterCalls: []reporterCall{{
+			Op:        "ReportQueueTime",
+			Namespace: testNamespace,
+			Revision:  testRevName,
+			Service:   "service-real-name",
+			Config:    "config-real-name",
+		}, {
 			Op:         "ReportRequestCount",
 			Namespace:  testNamespace,
 			Revision:   testRevName,
@@ -229,6 +263,12 @@ func TestActivationHandler(t *testing.T) {
 		wantErr:           errors.New("request error"),
 		endpointsInformer: endpointsInformer(endpoints(testNamespace, testRevName, 1000)),
 		reporterCalls: []reporterCall{{
+			Op:        "ReportQueueTime",
+			Namespace: testNamespace,
+			Revision:  testRevName,
+			Service:   "service-real-name",
+			Config:    "config-real-name",
+		}, {
 			Op:         "ReportRequestCount",
 			Namespace:  testNamespace,
 			Revision:   testRevName,
@@ -313,7 +353,7 @@ func TestActivationHandler(t *testing.T) {
 				revisionLister(revision(testNamespace, testRevName)),
 				TestLogger(t))
 
-			handler := (New(TestLogger(t), reporter, throttler,
+			handler := (New(TestLogger(t), reporter, throttler, nil, RetryParams{}, network.AutoTransport, network.NewProberTransport(),
 				revisionLister(revision(testNamespace, testRevName)),
 				serviceLister(service(testNamespace, testRevName, "http")),
 				sksLister(sks(testNamespace, testRevName)),
@@ -388,7 +428,7 @@ func TestActivationHandlerOverflow(t *testing.T) {
 		revisionLister(revision(namespace, revName)),
 		TestLogger(t))
 
-	handler := (New(TestLogger(t), reporter, throttler,
+	handler := (New(TestLogger(t), reporter, throttler, nil, RetryParams{}, network.AutoTransport, network.NewProberTransport(),
 		revisionLister(revision(namespace, revName)),
 		serviceLister(service(namespace, revName, "http")),
 		sksLister(sks(namespace, revName)),
@@ -400,6 +440,19 @@ func TestActivationHandlerOverflow(t *testing.T) {
 
 	sendRequests(requests, namespace, revName, respCh, *handler)
 	assertResponses(wantedSuccess, wantedFailure, requests, lockerCh, respCh, t)
+
+	// The queue time of the rejected requests is reported too.
+	reporter.mux.Lock()
+	defer reporter.mux.Unlock()
+	queueTimes := 0
+	for _, call := range reporter.calls {
+		if call.Op == "ReportQueueTime" {
+			queueTimes++
+		}
+	}
+	if queueTimes != requests {
+		t.Errorf("ReportQueueTime calls = %d, want: %d", queueTimes, requests)
+	}
 }
 
 // Make sure if one breaker is overflowed, the requests to other revisions are still served
@@ -435,7 +488,7 @@ func TestActivationHandlerOverflowSeveralRevisions(t *testing.T) {
 		},
 	}
 	rt := network.RoundTripperFunc(fakeRT.RT)
-	handler := (New(TestLogger(t), reporter, throttler,
+	handler := (New(TestLogger(t), reporter, throttler, nil, RetryParams{}, network.AutoTransport, network.NewProberTransport(),
 		revClient, svcClient, sksClient)).(*activationHandler)
 
 	// Setup transports.
@@ -484,6 +537,7 @@ func TestActivationHandlerProxyHeader(t *testing.T) {
 		revisionLister: revisionLister(revision(testNamespace, testRevName)),
 		serviceLister:  serviceLister(service(testNamespace, testRevName, "http")),
 		sksLister:      sksLister(sks(testNamespace, testRevName)),
+		retryBudgets:   newRetryBudgets(0),
 	}
 
 	writer := httptest.NewRecorder()
@@ -502,6 +556,362 @@ func TestActivationHandlerProxyHeader(t *testing.T) {
 	}
 }
 
+func TestActivationHandlerPodDests(t *testing.T) {
+	breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 10}
+	namespace, revName := testNamespace, testRevName
+	dests := []string{"10.0.0.1:8012", "10.0.0.2:8012"}
+
+	hostCh := make(chan string, len(dests))
+	rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get(network.ProbeHeaderName) != "" {
+			t.Errorf("Unexpected probe of %s", r.URL.Host)
+		}
+		hostCh <- r.URL.Host
+		fake := httptest.NewRecorder()
+		fake.WriteString(wantBody)
+		return fake.Result(), nil
+	})
+	throttler := activator.NewThrottler(
+		breakerParams,
+		endpointsInformer(endpoints(namespace, revName, breakerParams.InitialCapacity)),
+		sksLister(sks(namespace, revName)),
+		revisionLister(revision(namespace, revName)),
+		TestLogger(t))
+	balancer := activator.NewPodBalancer(activator.RoundRobinPolicy,
+		endpointsInformer(endpoints(namespace, revName, breakerParams.InitialCapacity)),
+		revisionLister(revision(namespace, revName)), TestLogger(t))
+	balancer.UpdateDests(&activator.RevisionDestsUpdate{
+		Rev:   activator.RevisionID{Namespace: namespace, Name: revName},
+		Dests: dests,
+	})
+
+	reporter := &fakeReporter{}
+	handler := activationHandler{
+		transport:      rt,
+		probeTransport: rt,
+		logger:         TestLogger(t),
+		reporter:       reporter,
+		throttler:      throttler,
+		balancer:       balancer,
+		revisionLister: revisionLister(revision(testNamespace, testRevName)),
+		serviceLister:  serviceLister(service(testNamespace, testRevName, "http")),
+		sksLister:      sksLister(sks(testNamespace, testRevName)),
+		retryBudgets:   newRetryBudgets(0),
+	}
+
+	for range dests {
+		if resp := sendRequest(namespace, revName, handler); resp.Code != http.StatusOK {
+			t.Errorf("Unexpected response status. Want %d, got %d", http.StatusOK, resp.Code)
+		}
+	}
+	close(hostCh)
+
+	var got []string
+	for host := range hostCh {
+		got = append(got, host)
+	}
+	if !cmp.Equal(got, dests) {
+		t.Errorf("Requests were sent to %v, want %v", got, dests)
+	}
+	for _, call := range reporter.calls {
+		if call.Op == "ReportRequestCount" && call.Attempts != 1 {
+			t.Errorf("Attempts = %d, want 1", call.Attempts)
+		}
+	}
+}
+
+func TestActivationHandlerColdStartFailFast(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        string
+		endpoints     int
+		wantCode      int
+		wantBody      string
+		wantRejection bool
+	}{{
+		name:          "fail fast while scaling from zero",
+		policy:        serving.ColdStartPolicyFailFast,
+		endpoints:     0,
+		wantCode:      http.StatusServiceUnavailable,
+		wantBody:      activator.ErrColdStart.Error() + "\n",
+		wantRejection: true,
+	}, {
+		name:      "fail fast with capacity",
+		policy:    serving.ColdStartPolicyFailFast,
+		endpoints: 1,
+		wantCode:  http.StatusOK,
+		wantBody:  wantBody,
+	}, {
+		name:      "queue with capacity",
+		policy:    serving.ColdStartPolicyQueue,
+		endpoints: 1,
+		wantCode:  http.StatusOK,
+		wantBody:  wantBody,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 0}
+			rev := revision(testNamespace, testRevName)
+			rev.Annotations = map[string]string{serving.ColdStartPolicyAnnotationKey: test.policy}
+			throttler := activator.NewThrottler(
+				breakerParams,
+				endpointsInformer(endpoints(testNamespace, testRevName, test.endpoints)),
+				sksLister(sks(testNamespace, testRevName)),
+				revisionLister(rev),
+				TestLogger(t))
+
+			fakeRT := activatortest.FakeRoundTripper{
+				RequestResponse: &activatortest.FakeResponse{
+					Code: http.StatusOK,
+					Body: wantBody,
+				},
+			}
+			rt := network.RoundTripperFunc(fakeRT.RT)
+			reporter := &fakeReporter{}
+			handler := (New(TestLogger(t), reporter, throttler, nil, RetryParams{}, network.AutoTransport, network.NewProberTransport(),
+				revisionLister(rev),
+				serviceLister(service(testNamespace, testRevName, "http")),
+				sksLister(sks(testNamespace, testRevName)),
+			)).(*activationHandler)
+			handler.transport = rt
+			handler.probeTransport = rt
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+			req.Header.Set(activator.RevisionHeaderNamespace, testNamespace)
+			req.Header.Set(activator.RevisionHeaderName, testRevName)
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != test.wantCode {
+				t.Errorf("Unexpected response status. Want %d, got %d", test.wantCode, resp.Code)
+			}
+			if got := resp.Body.String(); got != test.wantBody {
+				t.Errorf("Unexpected response body. Want %q, got %q", test.wantBody, got)
+			}
+			// The first cold start of the revision has no estimate.
+			if got := resp.Header().Get("Retry-After"); got != "" {
+				t.Errorf("Retry-After = %q, want it unset", got)
+			}
+			gotRejection := false
+			for _, call := range reporter.calls {
+				if call.Op == "ReportRequestRejected" && call.Reason == activator.RejectReasonColdStart {
+					gotRejection = true
+				}
+			}
+			if gotRejection != test.wantRejection {
+				t.Errorf("Reported cold start rejection = %v, want: %v", gotRejection, test.wantRejection)
+			}
+		})
+	}
+}
+
+func TestActivationHandlerGRPCErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		wantStatus codes.Code
+	}{{
+		name:       "unavailable while scaling from zero",
+		policy:     serving.ColdStartPolicyFailFast,
+		wantStatus: codes.Unavailable,
+	}, {
+		name:       "deadline exceeded waiting for capacity",
+		policy:     serving.ColdStartPolicyQueue,
+		wantStatus: codes.DeadlineExceeded,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 0}
+			rev := revision(testNamespace, testRevName)
+			rev.Annotations = map[string]string{serving.ColdStartPolicyAnnotationKey: test.policy}
+			throttler := activator.NewThrottler(
+				breakerParams,
+				endpointsInformer(endpoints(testNamespace, testRevName, 0)),
+				sksLister(sks(testNamespace, testRevName)),
+				revisionLister(rev),
+				TestLogger(t))
+
+			handler := (New(TestLogger(t), &fakeReporter{}, throttler, nil, RetryParams{}, network.AutoTransport, network.NewProberTransport(),
+				revisionLister(rev),
+				serviceLister(service(testNamespace, testRevName, "http")),
+				sksLister(sks(testNamespace, testRevName)),
+			)).(*activationHandler)
+			handler.endpointTimeout = 10 * time.Millisecond
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+			req.Header.Set("Content-Type", "application/grpc")
+			req.Header.Set(activator.RevisionHeaderNamespace, testNamespace)
+			req.Header.Set(activator.RevisionHeaderName, testRevName)
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != http.StatusOK {
+				t.Errorf("Unexpected response status. Want %d, got %d", http.StatusOK, resp.Code)
+			}
+			if got, want := resp.Header().Get(pkghttp.GRPCStatusHeaderName), strconv.Itoa(int(test.wantStatus)); got != want {
+				t.Errorf("Unexpected gRPC status. Want %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestActivationHandlerRetries(t *testing.T) {
+	const (
+		bad  = "10.0.0.1:8012"
+		good = "10.0.0.2:8012"
+	)
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		dests        []string
+		retry        RetryParams
+		budgetSpent  bool
+		wantCode     int
+		wantAttempts int
+		wantHosts    []string
+	}{{
+		name:         "no retries",
+		method:       http.MethodGet,
+		dests:        []string{bad, good},
+		wantCode:     http.StatusBadGateway,
+		wantAttempts: 1,
+		wantHosts:    []string{bad},
+	}, {
+		name:         "GET retried on another pod",
+		method:       http.MethodGet,
+		dests:        []string{bad, good},
+		retry:        RetryParams{MaxRetries: 2},
+		wantCode:     http.StatusOK,
+		wantAttempts: 2,
+		wantHosts:    []string{bad, good},
+	}, {
+		name:         "POST with buffered body retried",
+		method:       http.MethodPost,
+		body:         "some body",
+		dests:        []string{bad, good},
+		retry:        RetryParams{MaxRetries: 2, MaxBodyBytes: 100},
+		wantCode:     http.StatusOK,
+		wantAttempts: 2,
+		wantHosts:    []string{bad, good},
+	}, {
+		name:         "POST with body over the limit not retried",
+		method:       http.MethodPost,
+		body:         "some body",
+		dests:        []string{bad, good},
+		retry:        RetryParams{MaxRetries: 2, MaxBodyBytes: 4},
+		wantCode:     http.StatusBadGateway,
+		wantAttempts: 1,
+		wantHosts:    []string{bad},
+	}, {
+		name:         "POST without buffering not retried",
+		method:       http.MethodPost,
+		dests:        []string{bad, good},
+		retry:        RetryParams{MaxRetries: 2},
+		wantCode:     http.StatusBadGateway,
+		wantAttempts: 1,
+		wantHosts:    []string{bad},
+	}, {
+		name:         "retries used up",
+		method:       http.MethodGet,
+		dests:        []string{bad},
+		retry:        RetryParams{MaxRetries: 2},
+		wantCode:     http.StatusBadGateway,
+		wantAttempts: 3,
+		wantHosts:    []string{bad, bad, bad},
+	}, {
+		name:         "retry budget used up",
+		method:       http.MethodGet,
+		dests:        []string{bad, good},
+		retry:        RetryParams{MaxRetries: 2},
+		budgetSpent:  true,
+		wantCode:     http.StatusBadGateway,
+		wantAttempts: 1,
+		wantHosts:    []string{bad},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 10}
+			namespace, revName := testNamespace, testRevName
+
+			var (
+				hostsMux sync.Mutex
+				hosts    []string
+			)
+			rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				hostsMux.Lock()
+				hosts = append(hosts, r.URL.Host)
+				hostsMux.Unlock()
+				if r.URL.Host == bad {
+					return nil, errors.New("connection reset by peer")
+				}
+				if r.Body != nil {
+					if body, _ := ioutil.ReadAll(r.Body); string(body) != test.body {
+						t.Errorf("Body = %q, want %q", body, test.body)
+					}
+				}
+				fake := httptest.NewRecorder()
+				fake.WriteString(wantBody)
+				return fake.Result(), nil
+			})
+			throttler := activator.NewThrottler(
+				breakerParams,
+				endpointsInformer(endpoints(namespace, revName, breakerParams.InitialCapacity)),
+				sksLister(sks(namespace, revName)),
+				revisionLister(revision(namespace, revName)),
+				TestLogger(t))
+			balancer := activator.NewPodBalancer(activator.RoundRobinPolicy,
+				endpointsInformer(endpoints(namespace, revName, breakerParams.InitialCapacity)),
+				revisionLister(revision(namespace, revName)), TestLogger(t))
+			balancer.UpdateDests(&activator.RevisionDestsUpdate{
+				Rev:   activator.RevisionID{Namespace: namespace, Name: revName},
+				Dests: test.dests,
+			})
+
+			budgets := newRetryBudgets(test.retry.BudgetRatio)
+			if test.budgetSpent {
+				budgets.tokens[activator.RevisionID{Namespace: namespace, Name: revName}] = 0
+			}
+
+			reporter := &fakeReporter{}
+			handler := activationHandler{
+				transport:      rt,
+				probeTransport: rt,
+				logger:         TestLogger(t),
+				reporter:       reporter,
+				throttler:      throttler,
+				balancer:       balancer,
+				retry:          test.retry,
+				retryBudgets:   budgets,
+				revisionLister: revisionLister(revision(testNamespace, testRevName)),
+				serviceLister:  serviceLister(service(testNamespace, testRevName, "http")),
+				sksLister:      sksLister(sks(testNamespace, testRevName)),
+			}
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "http://example.com", strings.NewReader(test.body))
+			req.Header.Set(activator.RevisionHeaderNamespace, namespace)
+			req.Header.Set(activator.RevisionHeaderName, revName)
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != test.wantCode {
+				t.Errorf("Unexpected response status. Want %d, got %d", test.wantCode, resp.Code)
+			}
+			if !cmp.Equal(hosts, test.wantHosts) {
+				t.Errorf("Requests were sent to %v, want %v", hosts, test.wantHosts)
+			}
+			for _, call := range reporter.calls {
+				if call.Op == "ReportRequestCount" && call.Attempts != test.wantAttempts {
+					t.Errorf("Attempts = %d, want %d", call.Attempts, test.wantAttempts)
+				}
+			}
+		})
+	}
+}
+
 func TestActivationHandlerTraceSpans(t *testing.T) {
 	// Setup transport
 	fakeRt := activatortest.FakeRoundTripper{
@@ -550,6 +960,7 @@ func TestActivationHandlerTraceSpans(t *testing.T) {
 		revisionLister: revisionLister(revision(testNamespace, testRevName)),
 		serviceLister:  serviceLister(service(testNamespace, testRevName, "http")),
 		sksLister:      sksLister(sks(testNamespace, testRevName)),
+		retryBudgets:   newRetryBudgets(0),
 	}
 	handler.transport = rt
 	handler.probeTransport = rt
@@ -661,6 +1072,7 @@ type reporterCall struct {
 	Revision   string
 	StatusCode int
 	Attempts   int
+	Reason     string
 	Value      int64
 	Duration   time.Duration
 }
@@ -718,6 +1130,67 @@ func (f *fakeReporter) ReportResponseTime(ns, service, config, rev string, respo
 	return nil
 }
 
+func (f *fakeReporter) ReportRequestRejected(ns, service, config, rev, reason string, v int64) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.calls = append(f.calls, reporterCall{
+		Op:        "ReportRequestRejected",
+		Namespace: ns,
+		Service:   service,
+		Config:    config,
+		Revision:  rev,
+		Reason:    reason,
+		Value:     v,
+	})
+
+	return nil
+}
+
+func (f *fakeReporter) ReportQueueDepth(ns, service, config, rev string, v int64) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.calls = append(f.calls, reporterCall{
+		Op:        "ReportQueueDepth",
+		Namespace: ns,
+		Service:   service,
+		Config:    config,
+		Revision:  rev,
+		Value:     v,
+	})
+
+	return nil
+}
+
+func (f *fakeReporter) ReportQueueTime(ns, service, config, rev string, d time.Duration) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.calls = append(f.calls, reporterCall{
+		Op:        "ReportQueueTime",
+		Namespace: ns,
+		Service:   service,
+		Config:    config,
+		Revision:  rev,
+		Duration:  d,
+	})
+
+	return nil
+}
+
+func (f *fakeReporter) ReportActiveStreams(ns, service, config, rev string, v int64) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.calls = append(f.calls, reporterCall{
+		Op:        "ReportActiveStreams",
+		Namespace: ns,
+		Service:   service,
+		Config:    config,
+		Revision:  rev,
+		Value:     v,
+	})
+
+	return nil
+}
+
 func revision(namespace, name string) *v1alpha1.Revision {
 	return &v1alpha1.Revision{
 		ObjectMeta: metav1.ObjectMeta{
@@ -838,3 +1311,46 @@ func endpointsInformer(eps ...*corev1.Endpoints) corev1informers.EndpointsInform
 func errMsg(msg string) string {
 	return fmt.Sprintf("Error getting active endpoint: %v\n", msg)
 }
+
+type errorReader struct{}
+
+func (errorReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func TestActivationHandlerReleaseOnAbort(t *testing.T) {
+	// The response body fails mid-way, so the proxy aborts the handler.
+	rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(errorReader{}),
+		}, nil
+	})
+	handler := activationHandler{
+		transport:    rt,
+		logger:       TestLogger(t),
+		retryBudgets: newRetryBudgets(0),
+	}
+
+	released := 0
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	// The proxy panics only when serving requests of a server.
+	req = req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, &http.Server{}))
+	func() {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("recover() = %v, want %v", r, http.ErrAbortHandler)
+			}
+		}()
+		handler.proxyWithRetries(TestLogger(t), httptest.NewRecorder(), req,
+			activator.RevisionID{Namespace: testNamespace, Name: testRevName}, "http", "10.0.0.1:8012",
+			func() { released++ },
+			func(map[string]bool) (string, func(), error) {
+				return "", nil, errors.New("no more pods")
+			})
+	}()
+	if released != 1 {
+		t.Errorf("release was called %d times, want 1", released)
+	}
+}
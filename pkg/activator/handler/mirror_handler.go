@@ -0,0 +1,39 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+
+	"knative.dev/serving/pkg/network"
+)
+
+// MirrorHandler drops the copies of mirrored requests. The copies carry the
+// revision headers of the request they were copied from, so the activator
+// can't tell which revision they are meant for. Revisions only receive
+// mirrored traffic while their pods are reached directly.
+type MirrorHandler struct {
+	NextHandler http.Handler
+}
+
+func (h *MirrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if network.IsMirroredRequest(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.NextHandler.ServeHTTP(w, r)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"knative.dev/serving/pkg/network"
+)
+
+func TestMirrorHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		wantCode   int
+		wantCalled bool
+	}{{
+		name:       "regular request",
+		host:       "example.com",
+		wantCode:   http.StatusOK,
+		wantCalled: true,
+	}, {
+		name:     "mirrored request",
+		host:     "example.com" + network.MirrorHostSuffix,
+		wantCode: http.StatusNoContent,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called := false
+			handler := &MirrorHandler{
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					called = true
+				}),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.Host = test.host
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != test.wantCode {
+				t.Errorf("Code = %d, want %d", rec.Code, test.wantCode)
+			}
+			if called != test.wantCalled {
+				t.Errorf("Called = %v, want %v", called, test.wantCalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+
+	"knative.dev/serving/pkg/queue"
+)
+
+// PriorityHandler takes the priority class the throttler admits the request
+// by from the request header.
+type PriorityHandler struct {
+	NextHandler http.Handler
+}
+
+func (h *PriorityHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.NextHandler.ServeHTTP(w, r.WithContext(queue.WithPriority(r.Context(), queue.RequestPriority(r))))
+}
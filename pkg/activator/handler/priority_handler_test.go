@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"knative.dev/serving/pkg/network"
+	"knative.dev/serving/pkg/queue"
+)
+
+func TestPriorityHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    queue.Priority
+	}{{
+		name: "no header",
+		want: queue.PriorityNormal,
+	}, {
+		name:    "high",
+		headers: []string{"high"},
+		want:    queue.PriorityHigh,
+	}, {
+		name:    "route setting appended to the client's",
+		headers: []string{"high", "low"},
+		want:    queue.PriorityLow,
+	}, {
+		name:    "unknown",
+		headers: []string{"urgent"},
+		want:    queue.PriorityNormal,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := queue.Priority(42)
+			handler := &PriorityHandler{
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					got = queue.PriorityFrom(r.Context())
+				}),
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			for _, h := range test.headers {
+				req.Header.Add(network.RequestPriorityHeaderName, h)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != test.want {
+				t.Errorf("Priority = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
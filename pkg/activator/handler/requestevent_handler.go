@@ -15,8 +15,10 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"knative.dev/serving/pkg/activator"
+	pkghttp "knative.dev/serving/pkg/http"
 
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -35,6 +37,11 @@ const (
 	ReqIn ReqEventType = iota
 	// ReqOut represents a finished request
 	ReqOut
+	// StreamIn represents an incoming request turning into a stream, e.g. a
+	// WebSocket connection or server-sent events.
+	StreamIn
+	// StreamOut represents a finished stream
+	StreamOut
 )
 
 // NewRequestEventHandler creates a handler that sends events
@@ -55,10 +62,7 @@ type RequestEventHandler struct {
 }
 
 func (h *RequestEventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	namespace := r.Header.Get(activator.RevisionHeaderNamespace)
-	name := r.Header.Get(activator.RevisionHeaderName)
-
-	revisionKey := types.NamespacedName{Namespace: namespace, Name: name}
+	revisionKey := revisionKeyFrom(r)
 
 	h.ReqChan <- ReqEvent{Key: revisionKey, EventType: ReqIn}
 	defer func() {
@@ -66,3 +70,28 @@ func (h *RequestEventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	}()
 	h.nextHandler.ServeHTTP(w, r)
 }
+
+// NewStreamEventHandler creates a handler that sends events about the
+// requests turning into streams, e.g. WebSocket connections, to the given
+// channel, and closes the streams idling for longer than the idleTimeout.
+// 0 leaves idle streams open.
+func NewStreamEventHandler(reqChan chan ReqEvent, next http.Handler, idleTimeout time.Duration) http.Handler {
+	return &pkghttp.StreamHandler{
+		NextHandler: next,
+		IdleTimeout: idleTimeout,
+		OnStreamStart: func(r *http.Request) {
+			reqChan <- ReqEvent{Key: revisionKeyFrom(r), EventType: StreamIn}
+		},
+		OnStreamEnd: func(r *http.Request) {
+			reqChan <- ReqEvent{Key: revisionKeyFrom(r), EventType: StreamOut}
+		},
+	}
+}
+
+// revisionKeyFrom returns the key of the revision the request is routed to.
+func revisionKeyFrom(r *http.Request) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: r.Header.Get(activator.RevisionHeaderNamespace),
+		Name:      r.Header.Get(activator.RevisionHeaderName),
+	}
+}
@@ -61,3 +61,31 @@ func TestRequestEventHandler(t *testing.T) {
 		t.Errorf("Unexpected event (-want +got): %v", diff)
 	}
 }
+
+func TestStreamEventHandler(t *testing.T) {
+	baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	reqChan := make(chan ReqEvent, 4)
+	handler := NewRequestEventHandler(reqChan, NewStreamEventHandler(reqChan, baseHandler, 0))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	req.Header.Add(activator.RevisionHeaderNamespace, "testspace")
+	req.Header.Add(activator.RevisionHeaderName, "testrevision")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	close(reqChan)
+
+	key := types.NamespacedName{Namespace: "testspace", Name: "testrevision"}
+	want := []ReqEvent{{Key: key, EventType: ReqIn}, {Key: key, EventType: StreamIn},
+		{Key: key, EventType: StreamOut}, {Key: key, EventType: ReqOut}}
+	var got []ReqEvent
+	for e := range reqChan {
+		got = append(got, e)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected events (-want +got): %v", diff)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"sync"
+
+	"knative.dev/serving/pkg/activator"
+)
+
+// retryBudgetBurst is the most retries the budget of a revision holds, which
+// it starts with so that the requests to a revision that is just becoming
+// ready can be retried before it served many.
+const retryBudgetBurst = 10.0
+
+// retryBudgets limit the retries to every revision to a share of its
+// requests, so that retries can't multiply the load on a failing revision.
+// Every request adds the ratio to the budget of its revision and every retry
+// takes one from it. A revision without an entry has a full budget, so that
+// the revisions that don't need retries take no memory.
+type retryBudgets struct {
+	ratio float64
+
+	mux    sync.Mutex
+	tokens map[activator.RevisionID]float64
+}
+
+func newRetryBudgets(ratio float64) *retryBudgets {
+	return &retryBudgets{
+		ratio:  ratio,
+		tokens: make(map[activator.RevisionID]float64),
+	}
+}
+
+// deposit adds a request to the revision's budget.
+func (b *retryBudgets) deposit(rev activator.RevisionID) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	tokens, ok := b.tokens[rev]
+	if !ok {
+		return
+	}
+	if tokens += b.ratio; tokens >= retryBudgetBurst {
+		delete(b.tokens, rev)
+	} else {
+		b.tokens[rev] = tokens
+	}
+}
+
+// withdraw takes a retry from the revision's budget. It returns false if the
+// budget is used up.
+func (b *retryBudgets) withdraw(rev activator.RevisionID) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	tokens, ok := b.tokens[rev]
+	if !ok {
+		tokens = retryBudgetBurst
+	}
+	if tokens < 1 {
+		return false
+	}
+	b.tokens[rev] = tokens - 1
+	return true
+}
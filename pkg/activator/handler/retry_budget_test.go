@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"testing"
+
+	"knative.dev/serving/pkg/activator"
+)
+
+func TestRetryBudgets(t *testing.T) {
+	rev := activator.RevisionID{Namespace: "ns", Name: "rev"}
+	other := activator.RevisionID{Namespace: "ns", Name: "other"}
+	b := newRetryBudgets(0.5)
+
+	// The budget starts with the burst.
+	for i := 0; i < retryBudgetBurst; i++ {
+		if !b.withdraw(rev) {
+			t.Fatalf("withdraw() #%d = false, want true", i+1)
+		}
+	}
+	if b.withdraw(rev) {
+		t.Error("withdraw() = true once the burst is used up, want false")
+	}
+	// The budgets of the revisions are separate.
+	if !b.withdraw(other) {
+		t.Error("withdraw() of another revision = false, want true")
+	}
+
+	// Two requests earn a retry.
+	b.deposit(rev)
+	if b.withdraw(rev) {
+		t.Error("withdraw() = true after half a retry was earned, want false")
+	}
+	b.deposit(rev)
+	if !b.withdraw(rev) {
+		t.Error("withdraw() = false after a retry was earned, want true")
+	}
+
+	// Full budgets take no memory.
+	for i := 0; i < 2*retryBudgetBurst; i++ {
+		b.deposit(rev)
+	}
+	if _, ok := b.tokens[rev]; ok {
+		t.Errorf("tokens[%v] = %v, want no entry once the budget is full", rev, b.tokens[rev])
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"knative.dev/pkg/controller"
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
 	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/network/prober"
 	"knative.dev/serving/pkg/queue"
@@ -59,22 +60,35 @@ type revisionWatcher struct {
 	dests        []string
 	healthStates map[string]bool
 
-	transport http.RoundTripper
-	destsChan <-chan []string
-	logger    *zap.SugaredLogger
+	transport      http.RoundTripper
+	destsChan      <-chan []string
+	revisionLister servinglisters.RevisionLister
+	logger         *zap.SugaredLogger
 }
 
 func newRevisionWatcher(rev RevisionID, updateCh chan<- *RevisionDestsUpdate,
 	destsChan <-chan []string, transport http.RoundTripper,
-	logger *zap.SugaredLogger) *revisionWatcher {
+	revisionLister servinglisters.RevisionLister, logger *zap.SugaredLogger) *revisionWatcher {
 	return &revisionWatcher{
-		rev:          rev,
-		updateCh:     updateCh,
-		healthStates: make(map[string]bool),
-		transport:    transport,
-		destsChan:    destsChan,
-		logger:       logger,
+		rev:            rev,
+		updateCh:       updateCh,
+		healthStates:   make(map[string]bool),
+		transport:      transport,
+		destsChan:      destsChan,
+		revisionLister: revisionLister,
+		logger:         logger,
+	}
+}
+
+// probeScheme returns the URL scheme the pods of the revision are probed
+// with.
+func (rw *revisionWatcher) probeScheme() string {
+	rev, err := rw.revisionLister.Revisions(rw.rev.Namespace).Get(rw.rev.Name)
+	if err != nil {
+		// Without the revision the pods can't be reached anyway.
+		return "http"
 	}
+	return QueueProxyScheme(rev)
 }
 
 func endpointsToDests(endpoints *corev1.Endpoints) []string {
@@ -130,6 +144,7 @@ func (rw *revisionWatcher) checkDests() {
 
 	var probeGroup errgroup.Group
 
+	scheme := rw.probeScheme()
 	for _, dest := range rw.dests {
 		// If the dest is already healthy then save this
 		if curHealthy, ok := rw.healthStates[dest]; ok && curHealthy {
@@ -140,7 +155,7 @@ func (rw *revisionWatcher) checkDests() {
 		pDest := dest
 		probeGroup.Go(func() error {
 			httpDest := url.URL{
-				Scheme: "http",
+				Scheme: scheme,
 				Host:   pDest,
 			}
 			ok, err := prober.Do(ctx, rw.transport, httpDest.String(),
@@ -206,6 +221,7 @@ type RevisionBackendsManager struct {
 
 	updateCh       chan<- *RevisionDestsUpdate
 	transport      http.RoundTripper
+	revisionLister servinglisters.RevisionLister
 	logger         *zap.SugaredLogger
 	probeFrequency time.Duration
 }
@@ -214,11 +230,13 @@ type RevisionBackendsManager struct {
 // probe frequency
 func NewRevisionBackendsManagerWithProbeFrequency(updateCh chan<- *RevisionDestsUpdate,
 	transport http.RoundTripper, endpointsInformer corev1informers.EndpointsInformer,
-	logger *zap.SugaredLogger, probeFrequency time.Duration) *RevisionBackendsManager {
+	revisionLister servinglisters.RevisionLister, logger *zap.SugaredLogger,
+	probeFrequency time.Duration) *RevisionBackendsManager {
 	rbm := &RevisionBackendsManager{
 		revisionWatchers: make(map[RevisionID]*revisionWatcherCh),
 		updateCh:         updateCh,
 		transport:        transport,
+		revisionLister:   revisionLister,
 		logger:           logger,
 		probeFrequency:   probeFrequency,
 	}
@@ -242,9 +260,9 @@ func NewRevisionBackendsManagerWithProbeFrequency(updateCh chan<- *RevisionDests
 
 func NewRevisionBackendsManager(updateCh chan<- *RevisionDestsUpdate,
 	transport http.RoundTripper, endpointsInformer corev1informers.EndpointsInformer,
-	logger *zap.SugaredLogger) *RevisionBackendsManager {
+	revisionLister servinglisters.RevisionLister, logger *zap.SugaredLogger) *RevisionBackendsManager {
 	return NewRevisionBackendsManagerWithProbeFrequency(updateCh, transport, endpointsInformer,
-		logger, probeFrequency)
+		revisionLister, logger, probeFrequency)
 }
 
 func (rbm *RevisionBackendsManager) getOrCreateDestsCh(rev RevisionID) chan []string {
@@ -254,7 +272,7 @@ func (rbm *RevisionBackendsManager) getOrCreateDestsCh(rev RevisionID) chan []st
 	rwCh, ok := rbm.revisionWatchers[rev]
 	if !ok {
 		destsCh := make(chan []string)
-		rw := newRevisionWatcher(rev, rbm.updateCh, destsCh, rbm.transport, rbm.logger)
+		rw := newRevisionWatcher(rev, rbm.updateCh, destsCh, rbm.transport, rbm.revisionLister, rbm.logger)
 		rbm.revisionWatchers[rev] = &revisionWatcherCh{rw, destsCh}
 		go rw.run(rbm.probeFrequency)
 		return destsCh
@@ -35,6 +35,9 @@ import (
 	activatortest "knative.dev/serving/pkg/activator/testing"
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	servingfake "knative.dev/serving/pkg/client/clientset/versioned/fake"
+	servinginformers "knative.dev/serving/pkg/client/informers/externalversions"
 	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/queue"
 )
@@ -200,6 +203,7 @@ func TestRevisionWatcher(t *testing.T) {
 				updateCh,
 				destsCh,
 				rt,
+				revisionLister(revID.Namespace, revID.Name, 10),
 				TestLogger(t),
 			)
 
@@ -244,6 +248,36 @@ func TestRevisionWatcher(t *testing.T) {
 	}
 }
 
+func TestRevisionWatcherTLS(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testRevision,
+			Annotations: map[string]string{
+				serving.QueueProxyTLSSecretAnnotationKey: "queue-proxy-certs",
+			},
+		},
+	}
+	revisions := servinginformers.NewSharedInformerFactory(servingfake.NewSimpleClientset(rev), 0).Serving().V1alpha1().Revisions()
+	revisions.Informer().GetIndexer().Add(rev)
+
+	probed := make(chan string, 1)
+	rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		probed <- r.URL.String()
+		return nil, errors.New("not serving")
+	})
+
+	updateCh := make(chan *RevisionDestsUpdate, 1)
+	rw := newRevisionWatcher(RevisionID{Namespace: testNamespace, Name: testRevision},
+		updateCh, nil, rt, revisions.Lister(), TestLogger(t))
+	rw.dests = []string{"128.0.0.1:1234"}
+	rw.checkDests()
+
+	if got, want := <-probed, "https://128.0.0.1:1234"; got != want {
+		t.Errorf("Probed %q, want: %q", got, want)
+	}
+}
+
 func TestRevisionBackendManagerAddEndpoint(t *testing.T) {
 	for _, tc := range []struct {
 		name           string
@@ -353,7 +387,8 @@ func TestRevisionBackendManagerAddEndpoint(t *testing.T) {
 			updateCh := make(chan *RevisionDestsUpdate, 100)
 			defer close(updateCh)
 
-			bm := NewRevisionBackendsManagerWithProbeFrequency(updateCh, rt, endpointsInformer, TestLogger(t), 50*time.Millisecond)
+			bm := NewRevisionBackendsManagerWithProbeFrequency(updateCh, rt, endpointsInformer,
+				revisionLister(testNamespace, testRevision, 10), TestLogger(t), 50*time.Millisecond)
 			defer bm.Clear()
 
 			for _, ep := range tc.endpointsArr {
@@ -42,17 +42,53 @@ var (
 		"request_latencies",
 		"The response time in millisecond",
 		stats.UnitMilliseconds)
+	requestRejectedCountM = stats.Int64(
+		"request_rejected_count",
+		"The number of requests that are rejected by the Activator throttler",
+		stats.UnitDimensionless)
+	queueDepthM = stats.Int64(
+		"request_queue_depth",
+		"The number of requests waiting in the Activator throttler",
+		stats.UnitDimensionless)
+	queueTimeInMsecM = stats.Float64(
+		"request_queue_latencies",
+		"The time requests waited in the Activator throttler in millisecond",
+		stats.UnitMilliseconds)
+	activeStreamsM = stats.Int64(
+		"active_streams",
+		"Concurrent streams, e.g. WebSocket connections, that are routed to Activator",
+		stats.UnitDimensionless)
 
 	// NOTE: 0 should not be used as boundary. See
 	// https://github.com/census-ecosystem/opencensus-go-exporter-stackdriver/issues/98
 	defaultLatencyDistribution = view.Distribution(5, 10, 20, 40, 60, 80, 100, 150, 200, 250, 300, 350, 400, 450, 500, 600, 700, 800, 900, 1000, 2000, 5000, 10000, 20000, 50000, 100000)
 )
 
+// The reasons the throttler rejects requests for.
+const (
+	// RejectReasonOverload means that the queue of the revision was full
+	// or the request timed out waiting for capacity.
+	RejectReasonOverload = "overload"
+	// RejectReasonKeyQueueFull means that the queue of the request's fair
+	// queuing key was full.
+	RejectReasonKeyQueueFull = "key_queue_full"
+	// RejectReasonWaitBudgetExceeded means that the request was of low
+	// priority and waited for capacity longer than its wait budget.
+	RejectReasonWaitBudgetExceeded = "wait_budget_exceeded"
+	// RejectReasonColdStart means that the revision was scaling from zero
+	// and its cold start policy is to fail fast.
+	RejectReasonColdStart = "cold_start"
+)
+
 // StatsReporter defines the interface for sending activator metrics
 type StatsReporter interface {
 	ReportRequestConcurrency(ns, service, config, rev string, v int64) error
 	ReportRequestCount(ns, service, config, rev string, responseCode, numTries int, v int64) error
 	ReportResponseTime(ns, service, config, rev string, responseCode int, d time.Duration) error
+	ReportRequestRejected(ns, service, config, rev, reason string, v int64) error
+	ReportQueueDepth(ns, service, config, rev string, v int64) error
+	ReportQueueTime(ns, service, config, rev string, d time.Duration) error
+	ReportActiveStreams(ns, service, config, rev string, v int64) error
 }
 
 // Reporter holds cached metric objects to report autoscaler metrics
@@ -65,6 +101,7 @@ type Reporter struct {
 	responseCodeKey      tag.Key
 	responseCodeClassKey tag.Key
 	numTriesKey          tag.Key
+	reasonKey            tag.Key
 }
 
 // NewStatsReporter creates a reporter that collects and reports activator metrics
@@ -107,6 +144,11 @@ func NewStatsReporter() (*Reporter, error) {
 		return nil, err
 	}
 	r.numTriesKey = numTriesTag
+	reasonTag, err := tag.NewKey("reason")
+	if err != nil {
+		return nil, err
+	}
+	r.reasonKey = reasonTag
 	// Create view to see our measurements.
 	err = view.Register(
 		&view.View{
@@ -127,6 +169,30 @@ func NewStatsReporter() (*Reporter, error) {
 			Aggregation: defaultLatencyDistribution,
 			TagKeys:     []tag.Key{r.namespaceTagKey, r.serviceTagKey, r.configTagKey, r.revisionTagKey, r.responseCodeClassKey, r.responseCodeKey},
 		},
+		&view.View{
+			Description: "The number of requests that are rejected by the Activator throttler",
+			Measure:     requestRejectedCountM,
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{r.namespaceTagKey, r.serviceTagKey, r.configTagKey, r.revisionTagKey, r.reasonKey},
+		},
+		&view.View{
+			Description: "The number of requests waiting in the Activator throttler",
+			Measure:     queueDepthM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{r.namespaceTagKey, r.serviceTagKey, r.configTagKey, r.revisionTagKey},
+		},
+		&view.View{
+			Description: "The time requests waited in the Activator throttler in millisecond",
+			Measure:     queueTimeInMsecM,
+			Aggregation: defaultLatencyDistribution,
+			TagKeys:     []tag.Key{r.namespaceTagKey, r.serviceTagKey, r.configTagKey, r.revisionTagKey},
+		},
+		&view.View{
+			Description: "Concurrent streams, e.g. WebSocket connections, that are routed to Activator",
+			Measure:     activeStreamsM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{r.namespaceTagKey, r.serviceTagKey, r.configTagKey, r.revisionTagKey},
+		},
 	)
 	if err != nil {
 		return nil, err
@@ -212,6 +278,92 @@ func (r *Reporter) ReportResponseTime(ns, service, config, rev string, responseC
 	return nil
 }
 
+// ReportRequestRejected captures the count of requests rejected for the reason with value v.
+func (r *Reporter) ReportRequestRejected(ns, service, config, rev, reason string, v int64) error {
+	if !r.initialized {
+		return errors.New("StatsReporter is not initialized yet")
+	}
+
+	// Note that service names can be an empty string, so it needs a special treatment.
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(r.namespaceTagKey, ns),
+		tag.Insert(r.serviceTagKey, valueOrUnknown(service)),
+		tag.Insert(r.configTagKey, config),
+		tag.Insert(r.revisionTagKey, rev),
+		tag.Insert(r.reasonKey, reason))
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, requestRejectedCountM.M(v))
+	return nil
+}
+
+// ReportQueueDepth captures the number of requests waiting for capacity with value v.
+func (r *Reporter) ReportQueueDepth(ns, service, config, rev string, v int64) error {
+	if !r.initialized {
+		return errors.New("StatsReporter is not initialized yet")
+	}
+
+	// Note that service names can be an empty string, so it needs a special treatment.
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(r.namespaceTagKey, ns),
+		tag.Insert(r.serviceTagKey, valueOrUnknown(service)),
+		tag.Insert(r.configTagKey, config),
+		tag.Insert(r.revisionTagKey, rev))
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, queueDepthM.M(v))
+	return nil
+}
+
+// ReportQueueTime captures the time a request waited for capacity
+func (r *Reporter) ReportQueueTime(ns, service, config, rev string, d time.Duration) error {
+	if !r.initialized {
+		return errors.New("StatsReporter is not initialized yet")
+	}
+
+	// Note that service names can be an empty string, so it needs a special treatment.
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(r.namespaceTagKey, ns),
+		tag.Insert(r.serviceTagKey, valueOrUnknown(service)),
+		tag.Insert(r.configTagKey, config),
+		tag.Insert(r.revisionTagKey, rev))
+	if err != nil {
+		return err
+	}
+
+	// convert time.Duration in nanoseconds to milliseconds
+	metrics.Record(ctx, queueTimeInMsecM.M(float64(d/time.Millisecond)))
+	return nil
+}
+
+// ReportActiveStreams captures the number of active streams with value v.
+func (r *Reporter) ReportActiveStreams(ns, service, config, rev string, v int64) error {
+	if !r.initialized {
+		return errors.New("StatsReporter is not initialized yet")
+	}
+
+	// Note that service names can be an empty string, so it needs a special treatment.
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(r.namespaceTagKey, ns),
+		tag.Insert(r.serviceTagKey, valueOrUnknown(service)),
+		tag.Insert(r.configTagKey, config),
+		tag.Insert(r.revisionTagKey, rev))
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, activeStreamsM.M(v))
+	return nil
+}
+
 // responseCodeClass converts response code to a string of response code class.
 // e.g. The response code class is "5xx" for response code 503.
 func responseCodeClass(responseCode int) string {
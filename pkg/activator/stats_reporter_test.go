@@ -30,7 +30,8 @@ import (
 // Since golang executes test iterations within the same process, the stats reporter
 // returns an error if the metric is already registered and the test panics.
 func unregister() {
-	metricstest.Unregister("request_count", "request_latencies", "request_concurrency")
+	metricstest.Unregister("request_count", "request_latencies", "request_concurrency", "request_rejected_count",
+		"request_queue_depth", "request_queue_latencies", "active_streams")
 }
 
 func TestActivatorReporter(t *testing.T) {
@@ -98,6 +99,47 @@ func TestActivatorReporter(t *testing.T) {
 		return r.ReportResponseTime("testns", "testsvc", "testconfig", "testrev", http.StatusOK, 9100*time.Millisecond)
 	})
 	metricstest.CheckDistributionData(t, "request_latencies", wantTags3, 2, 1100.0, 9100.0)
+
+	// test ReportRequestRejected
+	wantTags4 := map[string]string{
+		metricskey.LabelNamespaceName:     "testns",
+		metricskey.LabelServiceName:       "testsvc",
+		metricskey.LabelConfigurationName: "testconfig",
+		metricskey.LabelRevisionName:      "testrev",
+		"reason":                          RejectReasonKeyQueueFull,
+	}
+	expectSuccess(t, func() error {
+		return r.ReportRequestRejected("testns", "testsvc", "testconfig", "testrev", RejectReasonKeyQueueFull, 1)
+	})
+	expectSuccess(t, func() error {
+		return r.ReportRequestRejected("testns", "testsvc", "testconfig", "testrev", RejectReasonKeyQueueFull, 2)
+	})
+	metricstest.CheckSumData(t, "request_rejected_count", wantTags4, 3)
+
+	// test ReportQueueDepth
+	expectSuccess(t, func() error {
+		return r.ReportQueueDepth("testns", "testsvc", "testconfig", "testrev", 7)
+	})
+	metricstest.CheckLastValueData(t, "request_queue_depth", wantTags1, 7)
+	expectSuccess(t, func() error {
+		return r.ReportQueueDepth("testns", "testsvc", "testconfig", "testrev", 0)
+	})
+	metricstest.CheckLastValueData(t, "request_queue_depth", wantTags1, 0)
+
+	// test ReportQueueTime
+	expectSuccess(t, func() error {
+		return r.ReportQueueTime("testns", "testsvc", "testconfig", "testrev", 2100*time.Millisecond)
+	})
+	expectSuccess(t, func() error {
+		return r.ReportQueueTime("testns", "testsvc", "testconfig", "testrev", 100*time.Millisecond)
+	})
+	metricstest.CheckDistributionData(t, "request_queue_latencies", wantTags1, 2, 100.0, 2100.0)
+
+	// test ReportActiveStreams
+	expectSuccess(t, func() error {
+		return r.ReportActiveStreams("testns", "testsvc", "testconfig", "testrev", 3)
+	})
+	metricstest.CheckLastValueData(t, "active_streams", wantTags1, 3)
 }
 
 func TestActivatorReporterEmptyServiceName(t *testing.T) {
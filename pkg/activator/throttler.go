@@ -21,6 +21,7 @@ import (
 	"errors"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -41,8 +42,19 @@ import (
 	"k8s.io/client-go/tools/cache"
 )
 
-// ErrActivatorOverload indicates that throttler has no free slots to buffer the request.
-var ErrActivatorOverload = errors.New("activator overload")
+var (
+	// ErrActivatorOverload indicates that throttler has no free slots to buffer the request.
+	ErrActivatorOverload = errors.New("activator overload")
+	// ErrKeyOverload indicates that throttler has no free slots to buffer the request
+	// for the request's fair queuing key.
+	ErrKeyOverload = errors.New("activator overload for the request's key")
+	// ErrRequestShed indicates that throttler shed the low priority request, since
+	// it waited for capacity longer than its wait budget.
+	ErrRequestShed = errors.New("activator shed low priority request")
+	// ErrColdStart indicates that the revision is scaling from zero and the
+	// request asked not to wait for it.
+	ErrColdStart = errors.New("revision is scaling from zero")
+)
 
 // Throttler keeps the mapping of Revisions to Breakers
 // and allows updating max concurrency dynamically of respective Breakers.
@@ -60,14 +72,17 @@ type Throttler struct {
 	revisionLister  servinglisters.RevisionLister
 	sksLister       netlisters.ServerlessServiceLister
 
+	coldStarts *coldStarts
+
 	numActivatorsMux sync.RWMutex
 	numActivators    int
 }
 
 type breaker interface {
 	Capacity() int
-	Maybe(ctx context.Context, thunk func()) bool
+	Try(ctx context.Context, thunk func()) error
 	UpdateConcurrency(int) error
+	Waiting() int
 }
 
 // NewThrottler creates a new Throttler.
@@ -85,6 +100,7 @@ func NewThrottler(
 		endpointsLister: endpointsInformer.Lister(),
 		revisionLister:  revisionLister,
 		sksLister:       sksLister,
+		coldStarts:      newColdStarts(),
 	}
 
 	// Update/create the breaker in the throttler when the number of endpoints changes.
@@ -125,6 +141,7 @@ func (t *Throttler) Remove(rev RevisionID) {
 	t.breakersMux.Lock()
 	defer t.breakersMux.Unlock()
 	delete(t.breakers, rev)
+	t.coldStarts.remove(rev)
 }
 
 // UpdateCapacity updates the max concurrency of the Breaker corresponding to a revision.
@@ -137,28 +154,115 @@ func (t *Throttler) UpdateCapacity(rev RevisionID, size int) error {
 	if err != nil {
 		return err
 	}
-	return t.updateCapacity(breaker, int(revision.Spec.ContainerConcurrency), size, t.activatorCount())
+	if err := t.updateCapacity(breaker, int(revision.Spec.ContainerConcurrency), size, t.activatorCount()); err != nil {
+		return err
+	}
+	if size > 0 {
+		t.coldStarts.finish(rev)
+	}
+	return nil
 }
 
 // Try potentially registers a new breaker in our bookkeeping
 // and executes the `function` on the Breaker.
 // It returns an error if either breaker doesn't have enough capacity,
 // or breaker's registration didn't succeed, e.g. getting endpoints or update capacity failed.
+// If the breakers queue fairly, the key is taken from the context, see queue.WithFairQueueKey,
+// and so is the priority class if they admit by priority, see queue.WithPriority.
 func (t *Throttler) Try(ctx context.Context, rev RevisionID, function func()) error {
-	breaker, existed, err := t.getOrCreateBreaker(rev)
+	breaker, err := t.breaker(rev)
 	if err != nil {
 		return err
 	}
+	if breaker.Capacity() == 0 {
+		// Observe the cold start, to estimate the next ones.
+		t.coldStarts.start(rev)
+	}
+	switch err := breaker.Try(ctx, function); err {
+	case nil:
+		return nil
+	case queue.ErrKeyQueueFull:
+		return ErrKeyOverload
+	case queue.ErrWaitBudgetExceeded:
+		return ErrRequestShed
+	default:
+		return ErrActivatorOverload
+	}
+}
+
+// ColdStart returns whether the revision has no capacity, since it's scaling
+// from zero, and if so, an estimate of how much longer that takes. The
+// estimate is based on the revision's previous cold starts and is 0 if it
+// wasn't observed scaling from zero before.
+func (t *Throttler) ColdStart(rev RevisionID) (bool, time.Duration, error) {
+	breaker, err := t.breaker(rev)
+	if err != nil {
+		return false, 0, err
+	}
+	if breaker.Capacity() > 0 {
+		return false, 0, nil
+	}
+	return true, t.coldStarts.start(rev), nil
+}
+
+// breaker returns the breaker of the revision, creating it with the latest
+// endpoints state if needed.
+func (t *Throttler) breaker(rev RevisionID) (breaker, error) {
+	breaker, existed, err := t.getOrCreateBreaker(rev)
+	if err != nil {
+		return nil, err
+	}
 	if !existed {
 		// Need to fetch the latest endpoints state, in case we missed the update.
 		if err := t.forceUpdateCapacity(rev, breaker, t.activatorCount()); err != nil {
-			return err
+			return nil, err
 		}
 	}
-	if !breaker.Maybe(ctx, function) {
-		return ErrActivatorOverload
+	return breaker, nil
+}
+
+// ReportQueueDepth reports the number of requests waiting for every revision
+// the throttler has a breaker for on every tick of reportCh, until stopCh is
+// closed. Once the breaker of a revision is removed, its queue depth is
+// reported as 0 a last time, so that the gauge doesn't keep its last value.
+func (t *Throttler) ReportQueueDepth(reporter StatsReporter, reportCh <-chan time.Time, stopCh <-chan struct{}) {
+	// reported are the service and configuration names the revisions were
+	// last reported with, which outlive the revisions themselves.
+	type names struct{ service, config string }
+	reported := make(map[RevisionID]names)
+	for {
+		select {
+		case <-reportCh:
+			t.breakersMux.RLock()
+			depths := make(map[RevisionID]int, len(t.breakers))
+			for revID, breaker := range t.breakers {
+				depths[revID] = breaker.Waiting()
+			}
+			t.breakersMux.RUnlock()
+
+			for revID, n := range reported {
+				if _, ok := depths[revID]; !ok {
+					reporter.ReportQueueDepth(revID.Namespace, n.service, n.config, revID.Name, 0)
+					delete(reported, revID)
+				}
+			}
+			for revID, depth := range depths {
+				revision, err := t.revisionLister.Revisions(revID.Namespace).Get(revID.Name)
+				if err != nil {
+					t.logger.With(zap.String(logkey.Key, revID.String())).Errorw("Error while getting revision", zap.Error(err))
+					continue
+				}
+				n := names{
+					service: revision.Labels[serving.ServiceLabelKey],
+					config:  revision.Labels[serving.ConfigurationLabelKey],
+				}
+				reporter.ReportQueueDepth(revID.Namespace, n.service, n.config, revID.Name, int64(depth))
+				reported[revID] = n
+			}
+		case <-stopCh:
+			return
+		}
 	}
-	return nil
 }
 
 func (t *Throttler) activatorCount() int {
@@ -224,9 +328,17 @@ func (t *Throttler) getOrCreateBreaker(revID RevisionID) (breaker, bool, error)
 		return nil, false, err
 	}
 	if revision.Spec.ContainerConcurrency == 0 {
-		breaker = &infiniteBreaker{
+		ib := &infiniteBreaker{
 			broadcast: make(chan struct{}),
 		}
+		if t.breakerParams.FairQueue != nil && t.breakerParams.FairQueue.KeyQueueDepth > 0 {
+			ib.keyQueueDepth = t.breakerParams.FairQueue.KeyQueueDepth
+			ib.keyWaiting = make(map[string]int)
+		}
+		if t.breakerParams.Priority != nil {
+			ib.lowPriorityWaitBudget = t.breakerParams.Priority.LowPriorityWaitBudget
+		}
+		breaker = ib
 	} else {
 		breaker = queue.NewBreaker(t.breakerParams)
 	}
@@ -259,7 +371,13 @@ func (t *Throttler) forceUpdateCapacity(rev RevisionID, breaker breaker, activat
 		return err
 	}
 
-	return t.updateCapacity(breaker, int(revision.Spec.ContainerConcurrency), size, activatorCount)
+	if err := t.updateCapacity(breaker, int(revision.Spec.ContainerConcurrency), size, activatorCount); err != nil {
+		return err
+	}
+	if size > 0 {
+		t.coldStarts.finish(rev)
+	}
+	return nil
 }
 
 // updateAllBreakerCapacity updates the capacity of all breakers.
@@ -315,6 +433,9 @@ func (t *Throttler) endpointsDeleted(obj interface{}) {
 // (i.e. infinity).
 // The infiniteBreaker will, though, block the requests when
 // downstream capacity is 0.
+// As the capacity shows up for all the waiting requests at once, there is no
+// order to admit them in fairly, but the number of requests waiting per fair
+// queuing key is limited like in the queue.Breaker.
 type infiniteBreaker struct {
 	// mu guards `broadcast` channel.
 	mu sync.RWMutex
@@ -332,12 +453,34 @@ type infiniteBreaker struct {
 	// immediately or wait for capacity to appear.
 	// `concurrency` should only be manipulated by `sync/atomic` methods.
 	concurrency int32
+
+	// waiting is the number of requests waiting for downstream capacity.
+	// `waiting` should only be manipulated by `sync/atomic` methods.
+	waiting int32
+
+	// lowPriorityWaitBudget is how long low priority requests wait for
+	// downstream capacity to show up before they are shed. 0 means they
+	// wait as long as their context permits.
+	lowPriorityWaitBudget time.Duration
+
+	// keyQueueDepth limits the number of requests waiting per fair queuing
+	// key. 0 means no limit.
+	keyQueueDepth int
+	// keyMux guards keyWaiting, the number of requests waiting per fair
+	// queuing key. Keys are dropped once no request of theirs waits.
+	keyMux     sync.Mutex
+	keyWaiting map[string]int
 }
 
 func (ib *infiniteBreaker) Capacity() int {
 	return int(atomic.LoadInt32(&ib.concurrency))
 }
 
+// Waiting returns the number of requests waiting for downstream capacity.
+func (ib *infiniteBreaker) Waiting() int {
+	return int(atomic.LoadInt32(&ib.waiting))
+}
+
 func zeroOrOne(x int) int32 {
 	if x == 0 {
 		return 0
@@ -365,12 +508,53 @@ func (ib *infiniteBreaker) UpdateConcurrency(cc int) error {
 	return nil
 }
 
+// Try executes thunk like Maybe and returns why thunk was not executed:
+// queue.ErrKeyQueueFull, queue.ErrWaitBudgetExceeded if a low priority
+// request waited too long, or the error of the context.
+func (ib *infiniteBreaker) Try(ctx context.Context, thunk func()) error {
+	waitCtx := ctx
+	if ib.lowPriorityWaitBudget > 0 && queue.PriorityFrom(ctx) == queue.PriorityLow {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, ib.lowPriorityWaitBudget)
+		defer cancel()
+	}
+	switch err := ib.wait(waitCtx); {
+	case err == nil:
+		thunk()
+		return nil
+	case err == queue.ErrKeyQueueFull:
+		return err
+	case ctx.Err() == nil:
+		return queue.ErrWaitBudgetExceeded
+	default:
+		return ctx.Err()
+	}
+}
+
 func (ib *infiniteBreaker) Maybe(ctx context.Context, thunk func()) bool {
+	if ib.wait(ctx) != nil {
+		return false
+	}
+	thunk()
+	return true
+}
+
+// wait blocks until there is downstream capacity. It returns
+// queue.ErrKeyQueueFull if too many requests of the request's fair queuing
+// key wait already, or the error of the context.
+func (ib *infiniteBreaker) wait(ctx context.Context) error {
 	has := ib.Capacity()
 	// We're scaled to serve.
 	if has > 0 {
-		thunk()
-		return true
+		return nil
+	}
+
+	if ib.keyQueueDepth > 0 {
+		key := queue.FairQueueKeyFrom(ctx)
+		if !ib.enqueueKey(key) {
+			return queue.ErrKeyQueueFull
+		}
+		defer ib.dequeueKey(key)
 	}
 
 	// Make sure we lock to get the channel, to avoid
@@ -379,12 +563,33 @@ func (ib *infiniteBreaker) Maybe(ctx context.Context, thunk func()) bool {
 	ib.mu.RLock()
 	ch = ib.broadcast
 	ib.mu.RUnlock()
+	atomic.AddInt32(&ib.waiting, 1)
+	defer atomic.AddInt32(&ib.waiting, -1)
 	select {
 	case <-ch:
 		// Scaled up.
-		thunk()
-		return true
+		return nil
 	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueueKey counts a request of key as waiting, unless keyQueueDepth of
+// them wait already.
+func (ib *infiniteBreaker) enqueueKey(key string) bool {
+	ib.keyMux.Lock()
+	defer ib.keyMux.Unlock()
+	if ib.keyWaiting[key] >= ib.keyQueueDepth {
 		return false
 	}
+	ib.keyWaiting[key]++
+	return true
+}
+
+func (ib *infiniteBreaker) dequeueKey(key string) {
+	ib.keyMux.Lock()
+	defer ib.keyMux.Unlock()
+	if ib.keyWaiting[key]--; ib.keyWaiting[key] == 0 {
+		delete(ib.keyWaiting, key)
+	}
 }
@@ -320,6 +320,153 @@ func TestThrottlerTryOverload(t *testing.T) {
 	}
 }
 
+func TestThrottlerTryKeyOverload(t *testing.T) {
+	params := queue.BreakerParams{
+		QueueDepth:      10,
+		MaxConcurrency:  1,
+		InitialCapacity: 1,
+		FairQueue:       &queue.FairQueueParams{KeyQueueDepth: 1},
+	}
+	th := NewThrottler(params,
+		endpointsInformer(testNamespace, testRevision, 1),
+		sksLister(testNamespace, testRevision),
+		revisionLister(testNamespace, testRevision, 1),
+		TestLogger(t))
+
+	ctx := queue.WithFairQueueKey(context.Background(), "tenant")
+	doneCh := make(chan struct{})
+	errCh := make(chan error)
+
+	// One request is in flight, one waits and the third overflows the queue of the key.
+	for i := 0; i < 3; i++ {
+		go func() {
+			if err := th.Try(ctx, revID, func() {
+				doneCh <- struct{}{}
+			}); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	if err := <-errCh; err != ErrKeyOverload {
+		t.Errorf("error = %v, want: %v", err, ErrKeyOverload)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-doneCh:
+		case err := <-errCh:
+			t.Errorf("Only one request should fail, got: %v", err)
+		}
+	}
+}
+
+// queueDepthReporter records the reported queue depths.
+type queueDepthReporter struct {
+	StatsReporter
+	depths chan int64
+}
+
+func (r *queueDepthReporter) ReportQueueDepth(ns, service, config, rev string, v int64) error {
+	r.depths <- v
+	return nil
+}
+
+func TestThrottlerReportQueueDepth(t *testing.T) {
+	defer ClearAll()
+	throttler := getThrottler(
+		defaultMaxConcurrency,
+		revisionLister(testNamespace, testRevision, 10),
+		endpointsInformer(testNamespace, testRevision, 0),
+		sksLister(testNamespace, testRevision),
+		TestLogger(t),
+		0)
+
+	reporter := &queueDepthReporter{depths: make(chan int64)}
+	reportCh := make(chan time.Time)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go throttler.ReportQueueDepth(reporter, reportCh, stopCh)
+
+	// The request waits, since the revision has no capacity.
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- throttler.Try(ctx, revID, func() {})
+	}()
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		breaker, _, _ := throttler.getOrCreateBreaker(revID)
+		return breaker.Waiting() == 1, nil
+	}); err != nil {
+		t.Fatalf("The request never started waiting: %v", err)
+	}
+	reportCh <- time.Now()
+	if got := <-reporter.depths; got != 1 {
+		t.Errorf("Reported queue depth = %d, want 1", got)
+	}
+
+	cancel()
+	if err := <-errCh; err != ErrActivatorOverload {
+		t.Errorf("Try() = %v, want %v", err, ErrActivatorOverload)
+	}
+	reportCh <- time.Now()
+	if got := <-reporter.depths; got != 0 {
+		t.Errorf("Reported queue depth = %d, want 0", got)
+	}
+
+	// Once the breaker is removed, 0 is reported a last time.
+	throttler.Remove(revID)
+	reportCh <- time.Now()
+	if got := <-reporter.depths; got != 0 {
+		t.Errorf("Reported queue depth = %d, want 0", got)
+	}
+	reportCh <- time.Now()
+	select {
+	case got := <-reporter.depths:
+		t.Errorf("Reported queue depth %d after the removal", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestThrottlerColdStart(t *testing.T) {
+	throttler := getThrottler(
+		defaultMaxConcurrency,
+		revisionLister(testNamespace, testRevision, 10),
+		endpointsInformer(testNamespace, testRevision, 0),
+		sksLister(testNamespace, testRevision),
+		TestLogger(t),
+		0)
+	now := time.Now()
+	throttler.coldStarts.now = func() time.Time { return now }
+
+	checkColdStart := func(wantColdStart bool, wantRemaining time.Duration) {
+		t.Helper()
+		coldStart, remaining, err := throttler.ColdStart(revID)
+		if err != nil {
+			t.Fatalf("ColdStart() = %v", err)
+		}
+		if coldStart != wantColdStart || remaining != wantRemaining {
+			t.Errorf("ColdStart() = (%v, %v), want: (%v, %v)", coldStart, remaining, wantColdStart, wantRemaining)
+		}
+	}
+
+	// The first cold start has no estimate.
+	checkColdStart(true, 0)
+	now = now.Add(10 * time.Second)
+	if err := throttler.UpdateCapacity(revID, 1); err != nil {
+		t.Fatalf("UpdateCapacity() = %v", err)
+	}
+	checkColdStart(false, 0)
+
+	// The next ones are estimated to take as long.
+	if err := throttler.UpdateCapacity(revID, 0); err != nil {
+		t.Fatalf("UpdateCapacity() = %v", err)
+	}
+	now = now.Add(time.Minute)
+	checkColdStart(true, 10*time.Second)
+	now = now.Add(4 * time.Second)
+	checkColdStart(true, 6*time.Second)
+}
+
 func TestThrottlerRemove(t *testing.T) {
 	throttler := getThrottler(
 		defaultMaxConcurrency,
@@ -486,6 +633,44 @@ func breakerCount(t *Throttler) int {
 	return len(t.breakers)
 }
 
+func TestInfiniteBreakerKeyQueueDepth(t *testing.T) {
+	b := &infiniteBreaker{
+		broadcast:     make(chan struct{}),
+		keyQueueDepth: 1,
+		keyWaiting:    make(map[string]int),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error)
+	go func() {
+		errCh <- b.Try(queue.WithFairQueueKey(ctx, "a"), func() {})
+	}()
+	if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		return b.Waiting() == 1, nil
+	}); err != nil {
+		t.Fatal("The first request never waited")
+	}
+
+	// The queue of key "a" is full, the one of key "b" isn't.
+	if err := b.Try(queue.WithFairQueueKey(ctx, "a"), func() {}); err != queue.ErrKeyQueueFull {
+		t.Errorf("Try() = %v, want %v", err, queue.ErrKeyQueueFull)
+	}
+	go func() {
+		errCh <- b.Try(queue.WithFairQueueKey(ctx, "b"), func() {})
+	}()
+
+	b.UpdateConcurrency(1)
+	for i := 0; i < 2; i++ {
+		if err := <-errCh; err != nil {
+			t.Errorf("Try() = %v, want nil", err)
+		}
+	}
+	if len(b.keyWaiting) != 0 {
+		t.Errorf("keyWaiting = %v, want empty", b.keyWaiting)
+	}
+}
+
 func endpointsSubset(hostsPerSubset, subsets int) []corev1.EndpointSubset {
 	resp := []corev1.EndpointSubset{}
 	if hostsPerSubset > 0 {
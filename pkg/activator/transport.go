@@ -0,0 +1,46 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"net/http"
+
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/network"
+)
+
+// QueueProxyScheme returns the URL scheme the queue-proxies of the revision
+// are reached with, https if they serve TLS and http otherwise.
+func QueueProxyScheme(rev *v1alpha1.Revision) string {
+	if rev.Annotations[serving.QueueProxyTLSSecretAnnotationKey] != "" {
+		return "https"
+	}
+	return "http"
+}
+
+// NewQueueProxyTransport returns a RoundTripper for the requests to the
+// queue-proxies. The https requests are sent with the secure transport, all
+// others with the plain one.
+func NewQueueProxyTransport(plain, secure http.RoundTripper) http.RoundTripper {
+	return network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Scheme == "https" {
+			return secure.RoundTrip(r)
+		}
+		return plain.RoundTrip(r)
+	})
+}
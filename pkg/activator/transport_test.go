@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"net/http"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/network"
+)
+
+func TestQueueProxyScheme(t *testing.T) {
+	rev := &v1alpha1.Revision{}
+	if got, want := QueueProxyScheme(rev), "http"; got != want {
+		t.Errorf("QueueProxyScheme() = %q, want: %q", got, want)
+	}
+
+	rev.ObjectMeta = metav1.ObjectMeta{
+		Annotations: map[string]string{
+			serving.QueueProxyTLSSecretAnnotationKey: "queue-proxy-certs",
+		},
+	}
+	if got, want := QueueProxyScheme(rev), "https"; got != want {
+		t.Errorf("QueueProxyScheme() = %q, want: %q", got, want)
+	}
+}
+
+func TestQueueProxyTransport(t *testing.T) {
+	var got string
+	rt := func(name string) http.RoundTripper {
+		return network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			got = name + " " + r.URL.String()
+			return nil, nil
+		})
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{{
+		name: "cleartext",
+		url:  "http://10.0.0.1:8012/path",
+		want: "plain http://10.0.0.1:8012/path",
+	}, {
+		name: "tls",
+		url:  "https://10.0.0.1:8012/path",
+		want: "secure https://10.0.0.1:8012/path",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := NewQueueProxyTransport(rt("plain"), rt("secure"))
+			req, err := http.NewRequest(http.MethodGet, test.url, nil)
+			if err != nil {
+				t.Fatalf("NewRequest() = %v", err)
+			}
+			transport.RoundTrip(req)
+			if got != test.want {
+				t.Errorf("RoundTrip() sent %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"knative.dev/pkg/apis"
 )
 
@@ -41,7 +43,8 @@ func ValidateAnnotations(anns map[string]string) *apis.FieldError {
 	if len(anns) == 0 {
 		return nil
 	}
-	return validateMinMaxScale(anns).Also(validateFloats(anns)).Also(validateWindows(anns))
+	return validateMinMaxScale(anns).Also(validateFloats(anns)).Also(validateWindows(anns)).
+		Also(validateScaleSchedule(anns)).Also(validateCustomMetric(anns))
 }
 
 func validateFloats(annotations map[string]string) *apis.FieldError {
@@ -82,6 +85,12 @@ func validateFloats(annotations map[string]string) *apis.FieldError {
 			errs = errs.Also(apis.ErrInvalidValue(v, TargetBurstCapacityKey))
 		}
 	}
+
+	if v, ok := annotations[MaxScaleDownRateAnnotationKey]; ok {
+		if fv, err := strconv.ParseFloat(v, 64); err != nil || fv <= MaxScaleDownRateMin {
+			errs = errs.Also(apis.ErrInvalidValue(v, MaxScaleDownRateAnnotationKey))
+		}
+	}
 	return errs
 }
 
@@ -115,3 +124,51 @@ func validateMinMaxScale(annotations map[string]string) *apis.FieldError {
 	}
 	return errs
 }
+
+func validateScaleSchedule(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[ScaleScheduleAnnotationKey]
+	if !ok {
+		return nil
+	}
+	windows, err := ParseScaleSchedule(v)
+	if err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("invalid scale schedule: %v", err),
+			Paths:   []string{ScaleScheduleAnnotationKey},
+		}
+	}
+	// Windows which only set minScale are still bound by the maxScale annotation.
+	max, _ := getIntGE0(annotations, MaxScaleAnnotationKey)
+	var errs *apis.FieldError
+	for i, w := range windows {
+		if w.MaxScale == 0 && max != 0 && int64(w.MinScale) > max {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("window %d: minScale=%d is greater than maxScale=%d", i, w.MinScale, max),
+				Paths:   []string{ScaleScheduleAnnotationKey, MaxScaleAnnotationKey},
+			})
+		}
+	}
+	return errs
+}
+
+// CustomMetricName returns the name of the custom metric the given metric
+// annotation value refers to, if any, see CustomMetricPrefix.
+func CustomMetricName(metric string) (string, bool) {
+	if !strings.HasPrefix(metric, CustomMetricPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(metric, CustomMetricPrefix), true
+}
+
+// IsValidGaugeName returns whether name is a valid name of the Prometheus
+// gauge a custom metric is scraped from.
+func IsValidGaugeName(name string) bool {
+	return model.IsValidMetricName(model.LabelValue(name))
+}
+
+func validateCustomMetric(annotations map[string]string) *apis.FieldError {
+	if v, ok := annotations[CustomMetricPathAnnotationKey]; ok && !strings.HasPrefix(v, "/") {
+		return apis.ErrInvalidValue(v, CustomMetricPathAnnotationKey)
+	}
+	return nil
+}
@@ -134,6 +134,17 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 		name:        "TBC invalid",
 		annotations: map[string]string{TargetBurstCapacityKey: "qarashen"},
 		expectErr:   "invalid value: qarashen: autoscaling.knative.dev/targetBurstCapacity",
+	}, {
+		name:        "max scale down rate 2",
+		annotations: map[string]string{MaxScaleDownRateAnnotationKey: "2"},
+	}, {
+		name:        "max scale down rate 1",
+		annotations: map[string]string{MaxScaleDownRateAnnotationKey: "1"},
+		expectErr:   "invalid value: 1: autoscaling.knative.dev/maxScaleDownRate",
+	}, {
+		name:        "max scale down rate invalid",
+		annotations: map[string]string{MaxScaleDownRateAnnotationKey: "fast"},
+		expectErr:   "invalid value: fast: autoscaling.knative.dev/maxScaleDownRate",
 	}, {
 		name:        "TU too small",
 		annotations: map[string]string{TargetUtilizationPercentageKey: "0"},
@@ -158,6 +169,37 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 		name:        "window too long",
 		annotations: map[string]string{WindowAnnotationKey: "365h"},
 		expectErr:   "expected 6s <= 365h <= 1h0m0s: autoscaling.knative.dev/window",
+	}, {
+		name:        "scale schedule okay",
+		annotations: map[string]string{ScaleScheduleAnnotationKey: `[{"schedule": "0 8 * * 1-5", "duration": "10h", "minScale": 20}]`},
+	}, {
+		name:        "scale schedule not json",
+		annotations: map[string]string{ScaleScheduleAnnotationKey: "weekdays"},
+		expectErr:   "invalid scale schedule: invalid character 'w' looking for beginning of value: autoscaling.knative.dev/scaleSchedule",
+	}, {
+		name:        "scale schedule bad cron",
+		annotations: map[string]string{ScaleScheduleAnnotationKey: `[{"schedule": "0 25 * * *", "duration": "1h", "minScale": 2}]`},
+		expectErr:   `invalid scale schedule: window 0: invalid hour in schedule "0 25 * * *": "25" is out of the range [0, 23]: autoscaling.knative.dev/scaleSchedule`,
+	}, {
+		name: "scale schedule min above maxScale",
+		annotations: map[string]string{
+			MaxScaleAnnotationKey:      "10",
+			ScaleScheduleAnnotationKey: `[{"schedule": "0 8 * * *", "duration": "1h", "minScale": 20}]`,
+		},
+		expectErr: "window 0: minScale=20 is greater than maxScale=10: autoscaling.knative.dev/maxScale, autoscaling.knative.dev/scaleSchedule",
+	}, {
+		name: "scale schedule overrides maxScale",
+		annotations: map[string]string{
+			MaxScaleAnnotationKey:      "10",
+			ScaleScheduleAnnotationKey: `[{"schedule": "0 8 * * *", "duration": "1h", "minScale": 20, "maxScale": 30}]`,
+		},
+	}, {
+		name:        "custom metric path okay",
+		annotations: map[string]string{CustomMetricPathAnnotationKey: "/stats/prometheus"},
+	}, {
+		name:        "custom metric relative path",
+		annotations: map[string]string{CustomMetricPathAnnotationKey: "metrics"},
+		expectErr:   "invalid value: metrics: autoscaling.knative.dev/customMetricPath",
 	}, {
 		name: "all together now fail",
 		annotations: map[string]string{
@@ -50,6 +50,56 @@ const (
 	Concurrency = "concurrency"
 	// CPU is the amount of the requested cpu actually being consumed by the Pod.
 	CPU = "cpu"
+	// Memory is the amount of the requested memory actually being consumed by the Pod.
+	// Only the hpa.autoscaling.knative.dev class autoscaler supports memory,
+	// and only if the user containers request memory. The queue-proxy then
+	// requests memory too.
+	Memory = "memory"
+	// CustomMetricPrefix prefixes the name of a custom metric. The target is
+	// the average value per Pod. For example,
+	//   autoscaling.knative.dev/metric: custom/queue_depth
+	//   autoscaling.knative.dev/target: "10"   # target 10 queued items per pod
+	// The kpa.autoscaling.knative.dev class autoscaler scales on the
+	// Prometheus gauge of that name, which the queue-proxy scrapes from the
+	// user container's port, summing the values of all its series. The
+	// hpa.autoscaling.knative.dev class autoscaler scales on the per-pod
+	// metric of that name served by the Kubernetes custom metrics API.
+	CustomMetricPrefix = "custom/"
+	// ExternalMetricsAPIPrefix prefixes the name of a metric served by the
+	// Kubernetes external metrics API, usually one of a system outside of the
+	// cluster. The target is the average value per Pod. For example,
+	//   autoscaling.knative.dev/metric: external/queue_messages_ready
+	//   autoscaling.knative.dev/target: "30"
+	// Only the hpa.autoscaling.knative.dev class autoscaler supports external metrics API metrics.
+	ExternalMetricsAPIPrefix = "external/"
+	// RPS is the requests per second reaching the Pod.
+	RPS = "rps"
+
+	// CustomMetricPathAnnotationKey is the annotation to specify the HTTP path
+	// the user container serves its Prometheus metrics on, when the
+	// kpa.autoscaling.knative.dev class autoscaler scales on a custom metric.
+	// For example,
+	//   autoscaling.knative.dev/customMetricPath: /stats/prometheus
+	CustomMetricPathAnnotationKey = GroupName + "/customMetricPath"
+	// CustomMetricPathDefault is the path the custom metric is scraped from
+	// when the CustomMetricPathAnnotationKey annotation is not present.
+	CustomMetricPathDefault = "/metrics"
+
+	// AlgorithmAnnotationKey is the annotation to specify the algorithm the
+	// PodAutoscaler should use to compute the desired scale. For example,
+	//   autoscaling.knative.dev/algorithm: pid
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the algorithm annotation.
+	AlgorithmAnnotationKey = GroupName + "/algorithm"
+	// StablePanicAlgorithm is the default KPA algorithm. It averages the metric
+	// over the stable window and switches to the panic window during bursts.
+	StablePanicAlgorithm = "stable-panic"
+	// PIDAlgorithm drives the pod count with a proportional-integral-derivative
+	// controller on the difference between the observed and the target value.
+	PIDAlgorithm = "pid"
+	// PredictiveAlgorithm forecasts the metric with double exponential
+	// smoothing and provisions for the forecasted value.
+	PredictiveAlgorithm = "predictive"
 
 	// TargetAnnotationKey is the annotation to specify what metric value the
 	// PodAutoscaler should attempt to maintain. For example,
@@ -150,6 +200,33 @@ const (
 	// but bounding from above.
 	PanicThresholdPercentageMax = 1000.0
 
+	// MaxScaleDownRateAnnotationKey is the annotation to specify the
+	// maximum ratio of observed pods versus desired pods the autoscaler
+	// may recommend in a single decision. For example, with
+	//   autoscaling.knative.dev/maxScaleDownRate: "2.0"
+	// a revision running 200 pods will not be scaled below 100 pods at once.
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the maxScaleDownRate annotation.
+	MaxScaleDownRateAnnotationKey = GroupName + "/maxScaleDownRate"
+	// MaxScaleDownRateMin is the minimum allowable max scale down rate.
+	// A rate of 1 would prevent the revision from ever scaling down.
+	MaxScaleDownRateMin = 1.0
+
+	// ScaleScheduleAnnotationKey is the annotation to specify recurring time
+	// windows which override the minScale and maxScale annotations. The value
+	// is a JSON list of windows, each starting at a cron schedule in UTC and
+	// lasting for the given duration. For example, to keep at least 20 pods
+	// on weekdays from 08:00 to 18:00 UTC,
+	//   autoscaling.knative.dev/scaleSchedule: |
+	//     [{"schedule": "0 8 * * 1-5", "duration": "10h", "minScale": 20}]
+	// When several windows are active, the first one in the list applies.
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the scaleSchedule annotation.
+	ScaleScheduleAnnotationKey = GroupName + "/scaleSchedule"
+	// ScaleWindowDurationMax is the maximum duration of a scheduled scale
+	// window. A week covers every recurring weekly pattern.
+	ScaleWindowDurationMax = 7 * 24 * time.Hour
+
 	// KPALabelKey is the label key attached to a K8s Service to hint to the KPA
 	// which services/endpoints should trigger reconciles.
 	KPALabelKey = GroupName + "/kpa"
@@ -0,0 +1,209 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScaleWindow is a recurring time window during which the scale bounds
+// of a PodAutoscaler are overridden.
+type ScaleWindow struct {
+	// Schedule is a cron expression in UTC with the five fields
+	// "minute hour day-of-month month day-of-week", at which the window starts.
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window lasts after each start.
+	Duration metav1.Duration `json:"duration"`
+
+	// MinScale overrides the minScale annotation while the window is active.
+	// +optional
+	MinScale int32 `json:"minScale,omitempty"`
+
+	// MaxScale overrides the maxScale annotation while the window is active.
+	// +optional
+	MaxScale int32 `json:"maxScale,omitempty"`
+
+	cron *cronSchedule
+}
+
+// ParseScaleSchedule parses the value of the ScaleScheduleAnnotationKey
+// annotation, which is a JSON list of ScaleWindows.
+func ParseScaleSchedule(s string) ([]ScaleWindow, error) {
+	var windows []ScaleWindow
+	if err := json.Unmarshal([]byte(s), &windows); err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		return nil, errors.New("schedule must contain at least one window")
+	}
+	for i := range windows {
+		w := &windows[i]
+		cron, err := parseCron(w.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %v", i, err)
+		}
+		w.cron = cron
+		if d := w.Duration.Duration; d < time.Minute || d > ScaleWindowDurationMax {
+			return nil, fmt.Errorf("window %d: duration %v must be between %v and %v",
+				i, d, time.Minute, ScaleWindowDurationMax)
+		}
+		if w.MinScale < 0 || w.MaxScale < 0 {
+			return nil, fmt.Errorf("window %d: minScale and maxScale must not be negative", i)
+		}
+		if w.MinScale == 0 && w.MaxScale == 0 {
+			return nil, fmt.Errorf("window %d: either minScale or maxScale must be set", i)
+		}
+		if w.MaxScale != 0 && w.MaxScale < w.MinScale {
+			return nil, fmt.Errorf("window %d: maxScale=%d is less than minScale=%d", i, w.MaxScale, w.MinScale)
+		}
+	}
+	return windows, nil
+}
+
+// ActiveUntil returns the end of the occurrence of the window that is active
+// at the given time, or false if the window is not active.
+func (w *ScaleWindow) ActiveUntil(now time.Time) (time.Time, bool) {
+	if w.cron == nil {
+		return time.Time{}, false
+	}
+	now = now.UTC()
+	for start := now.Truncate(time.Minute); now.Sub(start) < w.Duration.Duration; start = start.Add(-time.Minute) {
+		if w.cron.matches(start) {
+			return start.Add(w.Duration.Duration), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NextStart returns the first start of the window after the given time,
+// or false if the window does not start within the horizon.
+func (w *ScaleWindow) NextStart(now time.Time, horizon time.Duration) (time.Time, bool) {
+	if w.cron == nil {
+		return time.Time{}, false
+	}
+	now = now.UTC()
+	for start := now.Truncate(time.Minute).Add(time.Minute); start.Sub(now) <= horizon; start = start.Add(time.Minute) {
+		if w.cron.matches(start) {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// cronSchedule is a parsed five field cron expression. Each field is a bit set
+// of the values it matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// Like in cron, when both day fields are restricted a day matching
+	// either of them matches.
+	domStar, dowStar bool
+}
+
+func (c *cronSchedule) matches(t time.Time) bool {
+	if c.minute&(1<<uint(t.Minute())) == 0 || c.hour&(1<<uint(t.Hour())) == 0 ||
+		c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func parseCron(s string) (*cronSchedule, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q must have 5 fields, got %d", s, len(fields))
+	}
+	var (
+		c   cronSchedule
+		err error
+	)
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid minute in schedule %q: %v", s, err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid hour in schedule %q: %v", s, err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid day of month in schedule %q: %v", s, err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid month in schedule %q: %v", s, err)
+	}
+	// Both 0 and 7 are Sunday.
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid day of week in schedule %q: %v", s, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domStar = strings.HasPrefix(fields[2], "*")
+	c.dowStar = strings.HasPrefix(fields[4], "*")
+	return &c, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges ("1-5")
+// and steps ("*/15", "0-30/10") into a bit set.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		i := strings.Index(part, "/")
+		if i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rng = part[:i]
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value in %q", part)
+			}
+			switch {
+			case len(bounds) == 2:
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value in %q", part)
+				}
+			case i < 0:
+				// A single value, "lo/step" steps from lo up to max instead.
+				hi = lo
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is out of the range [%d, %d]", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
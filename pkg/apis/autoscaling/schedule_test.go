@@ -0,0 +1,198 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScaleSchedule(t *testing.T) {
+	cases := []struct {
+		name      string
+		schedule  string
+		expectErr string
+	}{{
+		name:     "weekdays",
+		schedule: `[{"schedule": "0 8 * * 1-5", "duration": "10h", "minScale": 20}]`,
+	}, {
+		name:     "lists and steps",
+		schedule: `[{"schedule": "*/15 0,12 1-7 */2 *", "duration": "5m", "maxScale": 3}]`,
+	}, {
+		name:      "empty",
+		schedule:  `[]`,
+		expectErr: "at least one window",
+	}, {
+		name:      "too few fields",
+		schedule:  `[{"schedule": "0 8 * *", "duration": "1h", "minScale": 1}]`,
+		expectErr: "must have 5 fields",
+	}, {
+		name:      "bad step",
+		schedule:  `[{"schedule": "*/0 * * * *", "duration": "1h", "minScale": 1}]`,
+		expectErr: "invalid step",
+	}, {
+		name:      "bad range",
+		schedule:  `[{"schedule": "0 8 * * 5-1", "duration": "1h", "minScale": 1}]`,
+		expectErr: "out of the range",
+	}, {
+		name:      "duration too short",
+		schedule:  `[{"schedule": "0 8 * * *", "duration": "30s", "minScale": 1}]`,
+		expectErr: "duration 30s must be between",
+	}, {
+		name:      "duration too long",
+		schedule:  `[{"schedule": "0 8 * * *", "duration": "200h", "minScale": 1}]`,
+		expectErr: "duration 200h0m0s must be between",
+	}, {
+		name:      "no bounds",
+		schedule:  `[{"schedule": "0 8 * * *", "duration": "1h"}]`,
+		expectErr: "either minScale or maxScale must be set",
+	}, {
+		name:      "negative bound",
+		schedule:  `[{"schedule": "0 8 * * *", "duration": "1h", "minScale": -1}]`,
+		expectErr: "must not be negative",
+	}, {
+		name:      "max below min",
+		schedule:  `[{"schedule": "0 8 * * *", "duration": "1h", "minScale": 5, "maxScale": 2}]`,
+		expectErr: "maxScale=2 is less than minScale=5",
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseScaleSchedule(c.schedule)
+			if c.expectErr == "" {
+				if err != nil {
+					t.Errorf("ParseScaleSchedule() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.expectErr) {
+				t.Errorf("ParseScaleSchedule() = %v, want error containing %q", err, c.expectErr)
+			}
+		})
+	}
+}
+
+func TestScaleWindowActiveUntil(t *testing.T) {
+	windows, err := ParseScaleSchedule(`[{"schedule": "0 8 * * 1-5", "duration": "10h", "minScale": 20}]`)
+	if err != nil {
+		t.Fatalf("ParseScaleSchedule() = %v", err)
+	}
+	w := &windows[0]
+
+	// 2019-09-02 is a Monday.
+	monday := time.Date(2019, 9, 2, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name    string
+		now     time.Time
+		wantEnd time.Time
+		wantOk  bool
+	}{{
+		name: "before the window",
+		now:  monday.Add(7*time.Hour + 59*time.Minute),
+	}, {
+		name:    "window start",
+		now:     monday.Add(8 * time.Hour),
+		wantEnd: monday.Add(18 * time.Hour),
+		wantOk:  true,
+	}, {
+		name:    "within the window",
+		now:     monday.Add(17*time.Hour + 59*time.Minute + 59*time.Second),
+		wantEnd: monday.Add(18 * time.Hour),
+		wantOk:  true,
+	}, {
+		name: "window end",
+		now:  monday.Add(18 * time.Hour),
+	}, {
+		name: "sunday",
+		now:  monday.Add(-24*time.Hour + 10*time.Hour),
+	}, {
+		name:    "other time zone",
+		now:     monday.Add(9 * time.Hour).In(time.FixedZone("UTC+5", 5*60*60)),
+		wantEnd: monday.Add(18 * time.Hour),
+		wantOk:  true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			end, ok := w.ActiveUntil(c.now)
+			if ok != c.wantOk || !end.Equal(c.wantEnd) {
+				t.Errorf("ActiveUntil() = (%v, %v), want: (%v, %v)", end, ok, c.wantEnd, c.wantOk)
+			}
+		})
+	}
+}
+
+func TestScaleWindowNextStart(t *testing.T) {
+	windows, err := ParseScaleSchedule(`[{"schedule": "30 8 * * 0", "duration": "1h", "minScale": 2}]`)
+	if err != nil {
+		t.Fatalf("ParseScaleSchedule() = %v", err)
+	}
+	w := &windows[0]
+
+	// 2019-09-01 is a Sunday.
+	sunday := time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)
+	if got, ok := w.NextStart(sunday, 24*time.Hour); !ok || !got.Equal(sunday.Add(8*time.Hour+30*time.Minute)) {
+		t.Errorf("NextStart() = (%v, %v), want: (%v, true)", got, ok, sunday.Add(8*time.Hour+30*time.Minute))
+	}
+	if got, ok := w.NextStart(sunday.Add(9*time.Hour), 24*time.Hour); ok {
+		t.Errorf("NextStart() = (%v, true), want no start within a day", got)
+	}
+}
+
+func TestCronDayMatching(t *testing.T) {
+	// 2019-09-01 is a Sunday.
+	sunday := time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		schedule string
+		time     time.Time
+		want     bool
+	}{{
+		name:     "7 is sunday",
+		schedule: "0 0 * * 7",
+		time:     sunday,
+		want:     true,
+	}, {
+		name:     "restricted day of month and week match either",
+		schedule: "0 0 15 * 0",
+		time:     sunday,
+		want:     true,
+	}, {
+		name:     "restricted day of month only",
+		schedule: "0 0 15 * *",
+		time:     sunday,
+		want:     false,
+	}, {
+		name:     "minute step from offset",
+		schedule: "5/20 * * * *",
+		time:     sunday.Add(45 * time.Minute),
+		want:     true,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cron, err := parseCron(c.schedule)
+			if err != nil {
+				t.Fatalf("parseCron() = %v", err)
+			}
+			if got := cron.matches(c.time); got != c.want {
+				t.Errorf("matches() = %v, want: %v", got, c.want)
+			}
+		})
+	}
+}
@@ -54,6 +54,18 @@ func (pa *PodAutoscaler) Metric() string {
 	return defaultMetric(pa.Class())
 }
 
+// Algorithm returns the scaling algorithm annotation value, or the
+// default algorithm for the KPA class if the annotation is not present.
+func (pa *PodAutoscaler) Algorithm() string {
+	if a, ok := pa.Annotations[autoscaling.AlgorithmAnnotationKey]; ok {
+		return a
+	}
+	if pa.Class() == autoscaling.KPA {
+		return autoscaling.StablePanicAlgorithm
+	}
+	return ""
+}
+
 func (pa *PodAutoscaler) annotationInt32(key string) int32 {
 	if s, ok := pa.Annotations[key]; ok {
 		// no error check: relying on validation
@@ -125,6 +137,24 @@ func (pa *PodAutoscaler) PanicThresholdPercentage() (percentage float64, ok bool
 	return pa.annotationFloat64(autoscaling.PanicThresholdPercentageAnnotationKey)
 }
 
+// MaxScaleDownRate returns the max scale down rate annotation value or false if not present.
+func (pa *PodAutoscaler) MaxScaleDownRate() (rate float64, ok bool) {
+	// The value is validated in the webhook.
+	return pa.annotationFloat64(autoscaling.MaxScaleDownRateAnnotationKey)
+}
+
+// ScaleSchedule returns the scheduled scale windows of the PA, or nil if the
+// annotation is not present.
+func (pa *PodAutoscaler) ScaleSchedule() []autoscaling.ScaleWindow {
+	if s, ok := pa.Annotations[autoscaling.ScaleScheduleAnnotationKey]; ok {
+		// The value is validated in the webhook.
+		if windows, err := autoscaling.ParseScaleSchedule(s); err == nil {
+			return windows
+		}
+	}
+	return nil
+}
+
 // IsReady looks at the conditions and if the Status has a condition
 // PodAutoscalerConditionReady returns true if ConditionStatus is True
 func (pas *PodAutoscalerStatus) IsReady() bool {
@@ -830,6 +830,105 @@ func TestPanicThresholdPercentage(t *testing.T) {
 	}
 }
 
+func TestAlgorithm(t *testing.T) {
+	cases := []struct {
+		name string
+		pa   *PodAutoscaler
+		want string
+	}{{
+		name: "kpa class, annotation set",
+		pa: pa(map[string]string{
+			autoscaling.ClassAnnotationKey:     autoscaling.KPA,
+			autoscaling.AlgorithmAnnotationKey: autoscaling.PIDAlgorithm,
+		}),
+		want: autoscaling.PIDAlgorithm,
+	}, {
+		name: "kpa class",
+		pa: pa(map[string]string{
+			autoscaling.ClassAnnotationKey: autoscaling.KPA,
+		}),
+		want: autoscaling.StablePanicAlgorithm,
+	}, {
+		name: "hpa class",
+		pa: pa(map[string]string{
+			autoscaling.ClassAnnotationKey: autoscaling.HPA,
+		}),
+		want: "",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, want := tc.pa.Algorithm(), tc.want; got != want {
+				t.Errorf("Algorithm() = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMaxScaleDownRate(t *testing.T) {
+	cases := []struct {
+		name     string
+		pa       *PodAutoscaler
+		wantRate float64
+		wantOk   bool
+	}{{
+		name:     "not present",
+		pa:       pa(map[string]string{}),
+		wantRate: 0.0,
+		wantOk:   false,
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.MaxScaleDownRateAnnotationKey: "2.5",
+		}),
+		wantRate: 2.5,
+		wantOk:   true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotRate, gotOk := tc.pa.MaxScaleDownRate()
+			if gotRate != tc.wantRate {
+				t.Errorf("%q expected rate: %v got: %v", tc.name, tc.wantRate, gotRate)
+			}
+			if gotOk != tc.wantOk {
+				t.Errorf("%q expected ok: %v got %v", tc.name, tc.wantOk, gotOk)
+			}
+		})
+	}
+}
+
+func TestScaleSchedule(t *testing.T) {
+	cases := []struct {
+		name        string
+		pa          *PodAutoscaler
+		wantWindows int
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "invalid",
+		pa: pa(map[string]string{
+			autoscaling.ScaleScheduleAnnotationKey: "weekdays",
+		}),
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.ScaleScheduleAnnotationKey: `[{"schedule": "0 8 * * 1-5", "duration": "10h", "minScale": 20},` +
+				`{"schedule": "0 20 * * *", "duration": "2h", "maxScale": 3}]`,
+		}),
+		wantWindows: 2,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, want := len(tc.pa.ScaleSchedule()), tc.wantWindows; got != want {
+				t.Errorf("len(ScaleSchedule()) = %d, want: %d", got, want)
+			}
+		})
+	}
+}
+
 func pa(annotations map[string]string) *PodAutoscaler {
 	p := &PodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
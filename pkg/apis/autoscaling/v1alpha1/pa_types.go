@@ -114,6 +114,28 @@ type PodAutoscalerStatus struct {
 	// MetricsServiceName is the K8s Service name that provides revision metrics.
 	// The service is managed by the PA object.
 	MetricsServiceName string `json:"metricsServiceName"`
+
+	// ScaleWindow is the scheduled scale window currently overriding
+	// the scale bounds of the PA, if any.
+	// +optional
+	ScaleWindow *ScaleWindowStatus `json:"scaleWindow,omitempty"`
+}
+
+// ScaleWindowStatus describes the active scheduled scale window of a PodAutoscaler.
+type ScaleWindowStatus struct {
+	// Schedule is the cron schedule at which the window started.
+	Schedule string `json:"schedule"`
+
+	// MinScale is the minimum scale enforced during the window.
+	// +optional
+	MinScale int32 `json:"minScale,omitempty"`
+
+	// MaxScale is the maximum scale enforced during the window.
+	// +optional
+	MaxScale int32 `json:"maxScale,omitempty"`
+
+	// EndTime is when the current occurrence of the window ends.
+	EndTime metav1.Time `json:"endTime"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/equality"
 	"knative.dev/pkg/apis"
@@ -29,6 +30,7 @@ import (
 func (pa *PodAutoscaler) Validate(ctx context.Context) *apis.FieldError {
 	errs := serving.ValidateObjectMetadata(pa.GetObjectMeta()).ViaField("metadata")
 	errs = errs.Also(pa.validateMetric())
+	errs = errs.Also(pa.validateAlgorithm())
 	return errs.Also(pa.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
 }
 
@@ -52,13 +54,24 @@ func (pa *PodAutoscaler) validateMetric() *apis.FieldError {
 		switch pa.Class() {
 		case autoscaling.KPA:
 			switch metric {
-			case autoscaling.Concurrency:
+			case autoscaling.Concurrency, autoscaling.RPS:
 				return nil
 			}
+			if name, ok := autoscaling.CustomMetricName(metric); ok {
+				return pa.validateCustomMetric(metric, name)
+			}
 		case autoscaling.HPA:
 			switch metric {
 			case autoscaling.CPU, autoscaling.Concurrency:
 				return nil
+			case autoscaling.Memory:
+				return pa.validateTargetPresent()
+			}
+			if name, ok := hpaMetricsAPIName(metric); ok {
+				if name == "" {
+					return apis.ErrInvalidValue(metric, "annotations[autoscaling.knative.dev/metric]")
+				}
+				return pa.validateTargetPresent()
 			}
 			// TODO: implement OPS autoscaling.
 		default:
@@ -73,3 +86,56 @@ func (pa *PodAutoscaler) validateMetric() *apis.FieldError {
 	}
 	return nil
 }
+
+// validateCustomMetric checks that the custom metric names a Prometheus gauge
+// and has a target, since there is no default value to aim for.
+func (pa *PodAutoscaler) validateCustomMetric(metric, name string) *apis.FieldError {
+	var errs *apis.FieldError
+	if !autoscaling.IsValidGaugeName(name) {
+		errs = apis.ErrInvalidValue(metric, "annotations[autoscaling.knative.dev/metric]")
+	}
+	return errs.Also(pa.validateTargetPresent())
+}
+
+// validateTargetPresent checks that the target annotation is present, for the
+// metrics which have no default value to aim for.
+func (pa *PodAutoscaler) validateTargetPresent() *apis.FieldError {
+	if _, ok := pa.Annotations[autoscaling.TargetAnnotationKey]; !ok {
+		return apis.ErrMissingField("annotations[autoscaling.knative.dev/target]")
+	}
+	return nil
+}
+
+// hpaMetricsAPIName returns the name of the custom or external metrics API
+// metric the given metric annotation value refers to, if any.
+func hpaMetricsAPIName(metric string) (string, bool) {
+	for _, prefix := range []string{autoscaling.CustomMetricPrefix, autoscaling.ExternalMetricsAPIPrefix} {
+		if strings.HasPrefix(metric, prefix) {
+			return strings.TrimPrefix(metric, prefix), true
+		}
+	}
+	return "", false
+}
+
+func (pa *PodAutoscaler) validateAlgorithm() *apis.FieldError {
+	algorithm, ok := pa.Annotations[autoscaling.AlgorithmAnnotationKey]
+	if !ok {
+		return nil
+	}
+	switch pa.Class() {
+	case autoscaling.KPA:
+		switch algorithm {
+		case autoscaling.StablePanicAlgorithm, autoscaling.PIDAlgorithm, autoscaling.PredictiveAlgorithm:
+			return nil
+		}
+		return apis.ErrInvalidValue(algorithm, "annotations[autoscaling.knative.dev/algorithm]")
+	case autoscaling.HPA:
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Unsupported algorithm %q for PodAutoscaler class %q",
+				algorithm, pa.Class()),
+			Paths: []string{"annotations[autoscaling.knative.dev/algorithm]"},
+		}
+	}
+	// Leave other classes of PodAutoscaler alone.
+	return nil
+}
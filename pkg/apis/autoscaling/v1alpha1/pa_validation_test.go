@@ -231,6 +231,349 @@ func TestPodAutoscalerValidation(t *testing.T) {
 			},
 		},
 		want: apis.ErrOutOfBoundsValue("FOO", 1, math.MaxInt32, autoscaling.MinScaleAnnotationKey).ViaField("metadata", "annotations"),
+	}, {
+		name: "kpa class, rps metric",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.KPA,
+					autoscaling.MetricAnnotationKey: autoscaling.RPS,
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: nil,
+	}, {
+		name: "kpa class, custom metric",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.KPA,
+					autoscaling.MetricAnnotationKey: "custom/queue_depth",
+					autoscaling.TargetAnnotationKey: "10",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: nil,
+	}, {
+		name: "kpa class, invalid custom metric without target",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.KPA,
+					autoscaling.MetricAnnotationKey: "custom/queue-depth",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: apis.ErrInvalidValue("custom/queue-depth", "annotations[autoscaling.knative.dev/metric]").Also(
+			apis.ErrMissingField("annotations[autoscaling.knative.dev/target]")),
+	}, {
+		name: "kpa class, custom metric without name",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.KPA,
+					autoscaling.MetricAnnotationKey: "custom",
+					autoscaling.TargetAnnotationKey: "10",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: &apis.FieldError{
+			Message: `Unsupported metric "custom" for PodAutoscaler class "kpa.autoscaling.knative.dev"`,
+			Paths:   []string{"annotations[autoscaling.knative.dev/metric]"},
+		},
+	}, {
+		name: "kpa class, cpu metric",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.KPA,
+					autoscaling.MetricAnnotationKey: autoscaling.CPU,
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: &apis.FieldError{
+			Message: `Unsupported metric "cpu" for PodAutoscaler class "kpa.autoscaling.knative.dev"`,
+			Paths:   []string{"annotations[autoscaling.knative.dev/metric]"},
+		},
+	}, {
+		name: "kpa class, pid algorithm",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:     autoscaling.KPA,
+					autoscaling.AlgorithmAnnotationKey: autoscaling.PIDAlgorithm,
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: nil,
+	}, {
+		name: "kpa class, empty algorithm",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:     autoscaling.KPA,
+					autoscaling.AlgorithmAnnotationKey: "",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: apis.ErrInvalidValue("", "annotations[autoscaling.knative.dev/algorithm]"),
+	}, {
+		name: "kpa class, unknown algorithm",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:     autoscaling.KPA,
+					autoscaling.AlgorithmAnnotationKey: "fuzzy",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: apis.ErrInvalidValue("fuzzy", "annotations[autoscaling.knative.dev/algorithm]"),
+	}, {
+		name: "hpa class, memory metric",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: autoscaling.Memory,
+					autoscaling.TargetAnnotationKey: "75",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: nil,
+	}, {
+		name: "hpa class, memory metric without target",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: autoscaling.Memory,
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: apis.ErrMissingField("annotations[autoscaling.knative.dev/target]"),
+	}, {
+		name: "hpa class, custom metrics API metric",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: "custom/http_requests",
+					autoscaling.TargetAnnotationKey: "10",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: nil,
+	}, {
+		name: "hpa class, external metrics API metric",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: "external/queue_messages_ready",
+					autoscaling.TargetAnnotationKey: "30",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: nil,
+	}, {
+		name: "hpa class, external metrics API metric without name",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: "external/",
+					autoscaling.TargetAnnotationKey: "30",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: apis.ErrInvalidValue("external/", "annotations[autoscaling.knative.dev/metric]"),
+	}, {
+		name: "hpa class, custom metric without name",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: "custom",
+					autoscaling.TargetAnnotationKey: "10",
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: &apis.FieldError{
+			Message: `Unsupported metric "custom" for PodAutoscaler class "hpa.autoscaling.knative.dev"`,
+			Paths:   []string{"annotations[autoscaling.knative.dev/metric]"},
+		},
+	}, {
+		name: "kpa class, memory metric",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.KPA,
+					autoscaling.MetricAnnotationKey: autoscaling.Memory,
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: &apis.FieldError{
+			Message: `Unsupported metric "memory" for PodAutoscaler class "kpa.autoscaling.knative.dev"`,
+			Paths:   []string{"annotations[autoscaling.knative.dev/metric]"},
+		},
+	}, {
+		name: "hpa class, predictive algorithm",
+		r: &PodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "valid",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:     autoscaling.HPA,
+					autoscaling.AlgorithmAnnotationKey: autoscaling.PredictiveAlgorithm,
+				},
+			},
+			Spec: PodAutoscalerSpec{
+				ScaleTargetRef: corev1.ObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "bar",
+				},
+				ProtocolType: net.ProtocolHTTP1,
+			},
+		},
+		want: &apis.FieldError{
+			Message: `Unsupported algorithm "predictive" for PodAutoscaler class "hpa.autoscaling.knative.dev"`,
+			Paths:   []string{"annotations[autoscaling.knative.dev/algorithm]"},
+		},
 	}, {
 		name: "empty spec",
 		r: &PodAutoscaler{
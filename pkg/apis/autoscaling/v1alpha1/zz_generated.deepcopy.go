@@ -200,6 +200,11 @@ func (in *PodAutoscalerSpec) DeepCopy() *PodAutoscalerSpec {
 func (in *PodAutoscalerStatus) DeepCopyInto(out *PodAutoscalerStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.ScaleWindow != nil {
+		in, out := &in.ScaleWindow, &out.ScaleWindow
+		*out = new(ScaleWindowStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -316,3 +321,20 @@ func (in *PodScalableStatus) DeepCopy() *PodScalableStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleWindowStatus) DeepCopyInto(out *ScaleWindowStatus) {
+	*out = *in
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleWindowStatus.
+func (in *ScaleWindowStatus) DeepCopy() *ScaleWindowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleWindowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
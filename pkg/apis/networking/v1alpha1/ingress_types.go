@@ -208,6 +208,27 @@ type HTTPIngressPath struct {
 	// +optional
 	Path string `json:"path,omitempty"`
 
+	// Headers are the request headers that must have the given values for
+	// a request to match the path.
+	//
+	// NOTE: This differs from K8s Ingress which doesn't allow header matching.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Cookies are the cookies that must have the given values for a request
+	// to match the path.
+	//
+	// NOTE: This differs from K8s Ingress which doesn't allow cookie matching.
+	// +optional
+	Cookies map[string]string `json:"cookies,omitempty"`
+
+	// QueryParameters are the query parameters that must have the given
+	// values for a request to match the path.
+	//
+	// NOTE: This differs from K8s Ingress which doesn't allow query matching.
+	// +optional
+	QueryParameters map[string]string `json:"queryParameters,omitempty"`
+
 	// Splits defines the referenced service endpoints to which the traffic
 	// will be forwarded to.
 	Splits []IngressBackendSplit `json:"splits"`
@@ -230,6 +251,13 @@ type HTTPIngressPath struct {
 	// NOTE: This differs from K8s Ingress which doesn't allow retry settings.
 	// +optional
 	Retries *HTTPRetry `json:"retries,omitempty"`
+
+	// Mirror specifies the backend a copy of the requests is sent to. The
+	// responses of the mirror backend are discarded.
+	//
+	// NOTE: This differs from K8s Ingress which doesn't allow mirroring.
+	// +optional
+	Mirror *IngressBackendMirror `json:"mirror,omitempty"`
 }
 
 // IngressBackendSplit describes all endpoints for a given service and port.
@@ -251,6 +279,17 @@ type IngressBackendSplit struct {
 	AppendHeaders map[string]string `json:"appendHeaders,omitempty"`
 }
 
+// IngressBackendMirror describes the backend the requests are mirrored to.
+type IngressBackendMirror struct {
+	// Specifies the backend receiving the mirrored traffic.
+	IngressBackend `json:",inline"`
+
+	// Specifies the percentage of the requests that are mirrored, a number
+	// between 0 and 100. If unspecified, we default to 100.
+	// +optional
+	Percent int `json:"percent,omitempty"`
+}
+
 // IngressBackend describes all endpoints for a given service and port.
 type IngressBackend struct {
 	// Specifies the namespace of the referenced service.
@@ -110,9 +110,22 @@ func (h HTTPIngressPath) Validate(ctx context.Context) *apis.FieldError {
 	if h.Retries != nil {
 		all = all.Also(h.Retries.Validate(ctx).ViaField("retries"))
 	}
+	if h.Mirror != nil {
+		all = all.Also(h.Mirror.Validate(ctx).ViaField("mirror"))
+	}
 	return all
 }
 
+// Validate inspects and validates IngressBackendMirror object.
+func (m *IngressBackendMirror) Validate(ctx context.Context) *apis.FieldError {
+	var all *apis.FieldError
+	// Percent must be between 0 and 100.
+	if m.Percent < 0 || m.Percent > 100 {
+		all = all.Also(apis.ErrInvalidValue(m.Percent, "percent"))
+	}
+	return all.Also(m.IngressBackend.Validate(ctx))
+}
+
 // Validate inspects and validates HTTPIngressPath object.
 func (s IngressBackendSplit) Validate(ctx context.Context) *apis.FieldError {
 	// Must not be empty.
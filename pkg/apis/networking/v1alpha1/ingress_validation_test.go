@@ -51,6 +51,14 @@ func TestIngressSpecValidation(t *testing.T) {
 						Retries: &HTTPRetry{
 							Attempts: 3,
 						},
+						Mirror: &IngressBackendMirror{
+							IngressBackend: IngressBackend{
+								ServiceName:      "revision-001",
+								ServiceNamespace: "default",
+								ServicePort:      intstr.FromInt(8080),
+							},
+							Percent: 10,
+						},
 					}},
 				},
 			}},
@@ -270,6 +278,59 @@ func TestIngressSpecValidation(t *testing.T) {
 			}},
 		},
 		want: apis.ErrInvalidValue(-1, "rules[0].http.paths[0].retries.attempts"),
+	}, {
+		name: "wrong-mirror-percent",
+		is: &IngressSpec{
+			Rules: []IngressRule{{
+				Hosts: []string{"example.com"},
+				HTTP: &HTTPIngressRuleValue{
+					Paths: []HTTPIngressPath{{
+						Splits: []IngressBackendSplit{{
+							IngressBackend: IngressBackend{
+								ServiceName:      "revision-000",
+								ServiceNamespace: "default",
+								ServicePort:      intstr.FromInt(8080),
+							},
+						}},
+						Mirror: &IngressBackendMirror{
+							IngressBackend: IngressBackend{
+								ServiceName:      "revision-001",
+								ServiceNamespace: "default",
+								ServicePort:      intstr.FromInt(8080),
+							},
+							Percent: 101,
+						},
+					}},
+				},
+			}},
+		},
+		want: apis.ErrInvalidValue(101, "rules[0].http.paths[0].mirror.percent"),
+	}, {
+		name: "missing-mirror-service-name",
+		is: &IngressSpec{
+			Rules: []IngressRule{{
+				Hosts: []string{"example.com"},
+				HTTP: &HTTPIngressRuleValue{
+					Paths: []HTTPIngressPath{{
+						Splits: []IngressBackendSplit{{
+							IngressBackend: IngressBackend{
+								ServiceName:      "revision-000",
+								ServiceNamespace: "default",
+								ServicePort:      intstr.FromInt(8080),
+							},
+						}},
+						Mirror: &IngressBackendMirror{
+							IngressBackend: IngressBackend{
+								ServiceNamespace: "default",
+								ServicePort:      intstr.FromInt(8080),
+							},
+							Percent: 10,
+						},
+					}},
+				},
+			}},
+		},
+		want: apis.ErrMissingField("rules[0].http.paths[0].mirror.serviceName"),
 	}, {
 		name: "empty-tls",
 		is: &IngressSpec{
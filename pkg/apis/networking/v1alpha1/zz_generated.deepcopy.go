@@ -222,6 +222,27 @@ func (in *HTTP01Challenge) DeepCopy() *HTTP01Challenge {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPIngressPath) DeepCopyInto(out *HTTPIngressPath) {
 	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Cookies != nil {
+		in, out := &in.Cookies, &out.Cookies
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.QueryParameters != nil {
+		in, out := &in.QueryParameters, &out.QueryParameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Splits != nil {
 		in, out := &in.Splits, &out.Splits
 		*out = make([]IngressBackendSplit, len(*in))
@@ -246,6 +267,11 @@ func (in *HTTPIngressPath) DeepCopyInto(out *HTTPIngressPath) {
 		*out = new(HTTPRetry)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(IngressBackendMirror)
+		**out = **in
+	}
 	return
 }
 
@@ -348,6 +374,23 @@ func (in *IngressBackend) DeepCopy() *IngressBackend {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressBackendMirror) DeepCopyInto(out *IngressBackendMirror) {
+	*out = *in
+	out.IngressBackend = in.IngressBackend
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressBackendMirror.
+func (in *IngressBackendMirror) DeepCopy() *IngressBackendMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressBackendMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressBackendSplit) DeepCopyInto(out *IngressBackendSplit) {
 	*out = *in
@@ -313,6 +313,23 @@ func HTTPGetActionMask(in *corev1.HTTPGetAction) *corev1.HTTPGetAction {
 	return out
 }
 
+// SidecarHTTPGetActionMask performs a _shallow_ copy of the Kubernetes HTTPGetAction object
+// of a probe of a sidecar container to a new Kubernetes HTTPGetAction object bringing over
+// only the fields allowed in the Knative API. Unlike the probes of the serving container,
+// which target the user port, the probes of sidecars name their port. This does not
+// validate the contents or the bounds of the provided fields.
+func SidecarHTTPGetActionMask(in *corev1.HTTPGetAction) *corev1.HTTPGetAction {
+	out := HTTPGetActionMask(in)
+	if out == nil {
+		return nil
+	}
+
+	// Allowed fields
+	out.Port = in.Port
+
+	return out
+}
+
 // TCPSocketActionMask performs a _shallow_ copy of the Kubernetes TCPSocketAction object to a new
 // Kubernetes TCPSocketAction object bringing over only the fields allowed in the Knative API. This
 // does not validate the contents or the bounds of the provided fields.
@@ -328,6 +345,23 @@ func TCPSocketActionMask(in *corev1.TCPSocketAction) *corev1.TCPSocketAction {
 	return out
 }
 
+// SidecarTCPSocketActionMask performs a _shallow_ copy of the Kubernetes TCPSocketAction
+// object of a probe of a sidecar container to a new Kubernetes TCPSocketAction object
+// bringing over only the fields allowed in the Knative API. Unlike the probes of the serving
+// container, which target the user port, the probes of sidecars name their port. This does
+// not validate the contents or the bounds of the provided fields.
+func SidecarTCPSocketActionMask(in *corev1.TCPSocketAction) *corev1.TCPSocketAction {
+	out := TCPSocketActionMask(in)
+	if out == nil {
+		return nil
+	}
+
+	// Allowed fields
+	out.Port = in.Port
+
+	return out
+}
+
 // ContainerPortMask performs a _shallow_ copy of the Kubernetes ContainerPort object to a new
 // Kubernetes ContainerPort object bringing over only the fields allowed in the Knative API. This
 // does not validate the contents or the bounds of the provided fields.
@@ -25,9 +25,11 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/networking"
 )
 
@@ -239,7 +241,7 @@ func ValidatePodSpec(ps corev1.PodSpec) *apis.FieldError {
 		errs = errs.Also(ValidateContainer(ps.Containers[0], volumes).
 			ViaFieldIndex("containers", 0))
 	default:
-		errs = errs.Also(apis.ErrMultipleOneOf("containers"))
+		errs = errs.Also(validateContainers(ps.Containers, volumes))
 	}
 	if ps.ServiceAccountName != "" {
 		for range validation.IsDNS1123Subdomain(ps.ServiceAccountName) {
@@ -249,10 +251,80 @@ func ValidatePodSpec(ps corev1.PodSpec) *apis.FieldError {
 	return errs
 }
 
+// ServingContainerIndex returns the index of the container that serves the
+// requests of a Revision. That is the only container, or the one setting a
+// port when the Revision has sidecars. It defaults to the first container.
+func ServingContainerIndex(containers []corev1.Container) int {
+	if len(containers) > 1 {
+		for i := range containers {
+			if len(containers[i].Ports) > 0 {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// validateContainers validates the containers of a Revision with sidecars.
+// Exactly one of them, the serving container, sets a port.
+func validateContainers(containers []corev1.Container, volumes sets.String) *apis.FieldError {
+	var errs *apis.FieldError
+	var ports []string
+	names := sets.NewString()
+	mounted := sets.NewString()
+	for i, container := range containers {
+		serving := len(container.Ports) > 0
+		if serving {
+			ports = append(ports, fmt.Sprintf("containers[%d].ports", i))
+		}
+		errs = errs.Also(validateContainer(container, volumes, serving).ViaFieldIndex("containers", i))
+
+		if names.Has(container.Name) {
+			errs = errs.Also(apis.ErrInvalidValue(
+				fmt.Sprintf("%q must be unique", container.Name), "name").ViaFieldIndex("containers", i))
+		}
+		names.Insert(container.Name)
+		for _, vm := range container.VolumeMounts {
+			mounted.Insert(vm.Name)
+		}
+	}
+
+	switch len(ports) {
+	case 0:
+		errs = errs.Also(&apis.FieldError{
+			Message: "no container sets a port",
+			Paths:   []string{"containers"},
+			Details: "The container serving the requests must set a port when there are multiple containers",
+		})
+	case 1:
+	default:
+		errs = errs.Also(apis.ErrMultipleOneOf(ports...))
+	}
+	return errs.Also(validateVolumesMounted(volumes, mounted).ViaField("containers"))
+}
+
+// ValidateContainer validates the serving container of a Revision without
+// sidecars.
 func ValidateContainer(container corev1.Container, volumes sets.String) *apis.FieldError {
 	if equality.Semantic.DeepEqual(container, corev1.Container{}) {
 		return apis.ErrMissingField(apis.CurrentField)
 	}
+	errs := validateContainer(container, volumes, true)
+	mounted := sets.NewString()
+	for _, vm := range container.VolumeMounts {
+		mounted.Insert(vm.Name)
+	}
+	return errs.Also(validateVolumesMounted(volumes, mounted).ViaField("volumeMounts"))
+}
+
+// validateContainer validates a container of a Revision. The readiness probe
+// of the serving container is run by the queue-proxy and its probes target
+// the user port, the probes of sidecars are left to the kubelet and name the
+// port they target.
+func validateContainer(container corev1.Container, volumes sets.String, serving bool) *apis.FieldError {
+	if equality.Semantic.DeepEqual(container, corev1.Container{}) {
+		return apis.ErrMissingField(apis.CurrentField)
+	}
 
 	errs := apis.CheckDisallowedFields(container, *ContainerMask(&container))
 
@@ -279,11 +351,15 @@ func ValidateContainer(container corev1.Container, volumes sets.String) *apis.Fi
 		errs = errs.Also(fe)
 	}
 	// Liveness Probes
-	errs = errs.Also(validateProbe(container.LivenessProbe).ViaField("livenessProbe"))
+	errs = errs.Also(validateProbe(container.LivenessProbe, serving).ViaField("livenessProbe"))
 	// Ports
 	errs = errs.Also(validateContainerPorts(container.Ports).ViaField("ports"))
 	// Readiness Probes
-	errs = errs.Also(validateReadinessProbe(container.ReadinessProbe).ViaField("readinessProbe"))
+	if serving {
+		errs = errs.Also(validateReadinessProbe(container.ReadinessProbe).ViaField("readinessProbe"))
+	} else {
+		errs = errs.Also(validateProbe(container.ReadinessProbe, false).ViaField("readinessProbe"))
+	}
 	// Resources
 	errs = errs.Also(validateResources(&container.Resources).ViaField("resources"))
 	// SecurityContext
@@ -300,6 +376,29 @@ func ValidateContainer(container corev1.Container, volumes sets.String) *apis.Fi
 	return errs
 }
 
+// ValidateMemoryRequests validates that the containers request memory if the
+// annotations have the HPA scale on memory, as it targets a share of the
+// requested memory.
+func ValidateMemoryRequests(annotations map[string]string, containers []corev1.Container) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, c := range containers {
+		errs = errs.Also(ValidateMemoryRequest(annotations, c).ViaFieldIndex("containers", i))
+	}
+	return errs
+}
+
+// ValidateMemoryRequest is ValidateMemoryRequests for a single container.
+func ValidateMemoryRequest(annotations map[string]string, container corev1.Container) *apis.FieldError {
+	if annotations[autoscaling.ClassAnnotationKey] != autoscaling.HPA ||
+		annotations[autoscaling.MetricAnnotationKey] != autoscaling.Memory {
+		return nil
+	}
+	if _, ok := container.Resources.Requests[corev1.ResourceMemory]; !ok {
+		return apis.ErrMissingField("resources.requests.memory")
+	}
+	return nil
+}
+
 func validateResources(resources *corev1.ResourceRequirements) *apis.FieldError {
 	if resources == nil {
 		return nil
@@ -324,9 +423,7 @@ func validateSecurityContext(sc *corev1.SecurityContext) *apis.FieldError {
 
 func validateVolumeMounts(mounts []corev1.VolumeMount, volumes sets.String) *apis.FieldError {
 	var errs *apis.FieldError
-	// Check that volume mounts match names in "volumes", that "volumes" has 100%
-	// coverage, and the field restrictions.
-	seenName := sets.NewString()
+	// Check that volume mounts match names in "volumes" and the field restrictions.
 	seenMountPath := sets.NewString()
 	for i, vm := range mounts {
 		errs = errs.Also(apis.CheckDisallowedFields(vm, *VolumeMountMask(&vm)).ViaIndex(i))
@@ -337,7 +434,6 @@ func validateVolumeMounts(mounts []corev1.VolumeMount, volumes sets.String) *api
 				Paths:   []string{"name"},
 			}).ViaIndex(i))
 		}
-		seenName.Insert(vm.Name)
 
 		if vm.MountPath == "" {
 			errs = errs.Also(apis.ErrMissingField("mountPath").ViaIndex(i))
@@ -359,14 +455,18 @@ func validateVolumeMounts(mounts []corev1.VolumeMount, volumes sets.String) *api
 		}
 
 	}
+	return errs
+}
 
-	if missing := volumes.Difference(seenName); missing.Len() > 0 {
-		errs = errs.Also(&apis.FieldError{
+// validateVolumesMounted checks that "volumes" has 100% coverage.
+func validateVolumesMounted(volumes, mounted sets.String) *apis.FieldError {
+	if missing := volumes.Difference(mounted); missing.Len() > 0 {
+		return &apis.FieldError{
 			Message: fmt.Sprintf("volumes not mounted: %v", missing.List()),
 			Paths:   []string{apis.CurrentField},
-		})
+		}
 	}
-	return errs
+	return nil
 }
 
 func validateContainerPorts(ports []corev1.ContainerPort) *apis.FieldError {
@@ -426,7 +526,7 @@ func validateReadinessProbe(p *corev1.Probe) *apis.FieldError {
 		return nil
 	}
 
-	errs := validateProbe(p)
+	errs := validateProbe(p, true)
 
 	if p.PeriodSeconds < 0 {
 		errs = errs.Also(apis.ErrOutOfBoundsValue(p.PeriodSeconds, 0, math.MaxInt32, "periodSeconds"))
@@ -458,7 +558,9 @@ func validateReadinessProbe(p *corev1.Probe) *apis.FieldError {
 	return errs
 }
 
-func validateProbe(p *corev1.Probe) *apis.FieldError {
+// validateProbe validates a probe of a container. The probes of the serving
+// container target the user port, so only the probes of sidecars set a port.
+func validateProbe(p *corev1.Probe, serving bool) *apis.FieldError {
 	if p == nil {
 		return nil
 	}
@@ -471,11 +573,21 @@ func validateProbe(p *corev1.Probe) *apis.FieldError {
 
 	if h.HTTPGet != nil {
 		handlers = append(handlers, "httpGet")
-		errs = errs.Also(apis.CheckDisallowedFields(*h.HTTPGet, *HTTPGetActionMask(h.HTTPGet))).ViaField("httpGet")
+		mask := HTTPGetActionMask(h.HTTPGet)
+		if !serving {
+			mask = SidecarHTTPGetActionMask(h.HTTPGet)
+			errs = errs.Also(validateProbePort(h.HTTPGet.Port).ViaField("httpGet"))
+		}
+		errs = errs.Also(apis.CheckDisallowedFields(*h.HTTPGet, *mask).ViaField("httpGet"))
 	}
 	if h.TCPSocket != nil {
 		handlers = append(handlers, "tcpSocket")
-		errs = errs.Also(apis.CheckDisallowedFields(*h.TCPSocket, *TCPSocketActionMask(h.TCPSocket))).ViaField("tcpSocket")
+		mask := TCPSocketActionMask(h.TCPSocket)
+		if !serving {
+			mask = SidecarTCPSocketActionMask(h.TCPSocket)
+			errs = errs.Also(validateProbePort(h.TCPSocket.Port).ViaField("tcpSocket"))
+		}
+		errs = errs.Also(apis.CheckDisallowedFields(*h.TCPSocket, *mask).ViaField("tcpSocket"))
 	}
 	if h.Exec != nil {
 		handlers = append(handlers, "exec")
@@ -490,6 +602,20 @@ func validateProbe(p *corev1.Probe) *apis.FieldError {
 	return errs
 }
 
+// validateProbePort validates the port a probe of a sidecar targets, which
+// the kubelet requires.
+func validateProbePort(port intstr.IntOrString) *apis.FieldError {
+	switch {
+	case port.Type == intstr.String && port.StrVal == "":
+		return apis.ErrMissingField("port")
+	case port.Type == intstr.Int && port.IntVal == 0:
+		return apis.ErrMissingField("port")
+	case port.Type == intstr.Int && (port.IntVal < 1 || port.IntVal > 65535):
+		return apis.ErrOutOfBoundsValue(port.IntVal, 1, 65535, "port")
+	}
+	return nil
+}
+
 func ValidateNamespacedObjectReference(p *corev1.ObjectReference) *apis.FieldError {
 	if p == nil {
 		return nil
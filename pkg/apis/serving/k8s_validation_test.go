@@ -150,15 +150,192 @@ func TestPodSpecValidation(t *testing.T) {
 		},
 		want: apis.ErrMissingField("containers"),
 	}, {
-		name: "too many containers",
+		name: "no serving container",
 		ps: corev1.PodSpec{
 			Containers: []corev1.Container{{
+				Name:  "busybox",
 				Image: "busybox",
 			}, {
+				Name:  "helloworld",
 				Image: "helloworld",
 			}},
 		},
-		want: apis.ErrMultipleOneOf("containers"),
+		want: &apis.FieldError{
+			Message: "no container sets a port",
+			Paths:   []string{"containers"},
+			Details: "The container serving the requests must set a port when there are multiple containers",
+		},
+	}, {
+		name: "sidecar",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "sidecar",
+				Image: "busybox",
+				ReadinessProbe: &corev1.Probe{
+					PeriodSeconds: 10,
+					Handler: corev1.Handler{
+						Exec: &corev1.ExecAction{
+							Command: []string{"true"},
+						},
+					},
+				},
+				VolumeMounts: []corev1.VolumeMount{{
+					MountPath: "/mount/path",
+					Name:      "the-name",
+					ReadOnly:  true,
+				}},
+			}, {
+				Name:  "serving",
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "the-name",
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: "foo",
+					},
+				},
+			}},
+		},
+		want: nil,
+	}, {
+		name: "sidecar probes with ports",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "sidecar",
+				Image: "busybox",
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{
+							Path: "/ready",
+							Port: intstr.FromInt(9090),
+						},
+					},
+				},
+				LivenessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						TCPSocket: &corev1.TCPSocketAction{
+							Port: intstr.FromString("admin"),
+						},
+					},
+				},
+			}, {
+				Name:  "serving",
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+			}},
+		},
+		want: nil,
+	}, {
+		name: "sidecar probe without port",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "sidecar",
+				Image: "busybox",
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{
+							Path: "/ready",
+						},
+					},
+				},
+			}, {
+				Name:  "serving",
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+			}},
+		},
+		want: apis.ErrMissingField("containers[0].readinessProbe.httpGet.port"),
+	}, {
+		name: "serving container probe with port",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "sidecar",
+				Image: "busybox",
+			}, {
+				Name:  "serving",
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+				ReadinessProbe: &corev1.Probe{
+					SuccessThreshold: 1,
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{
+							Path: "/ready",
+							Port: intstr.FromInt(8888),
+						},
+					},
+				},
+			}},
+		},
+		want: apis.ErrDisallowedFields("containers[1].readinessProbe.httpGet.port"),
+	}, {
+		name: "multiple serving containers",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "busybox",
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+			}, {
+				Name:  "helloworld",
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 9999,
+				}},
+			}},
+		},
+		want: apis.ErrMultipleOneOf("containers[0].ports", "containers[1].ports"),
+	}, {
+		name: "duplicate container names",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "busybox",
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+			}, {
+				Name:  "busybox",
+				Image: "helloworld",
+			}},
+		},
+		want: apis.ErrInvalidValue(`"busybox" must be unique`, "name").ViaFieldIndex("containers", 1),
+	}, {
+		name: "volume not mounted by any container",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "busybox",
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+			}, {
+				Name:  "helloworld",
+				Image: "helloworld",
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "the-name",
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: "foo",
+					},
+				},
+			}},
+		},
+		want: &apis.FieldError{
+			Message: "volumes not mounted: [the-name]",
+			Paths:   []string{"containers"},
+		},
 	}, {
 		name: "extra field",
 		ps: corev1.PodSpec{
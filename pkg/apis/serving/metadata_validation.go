@@ -17,6 +17,10 @@ limitations under the License.
 package serving
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/autoscaling"
@@ -26,5 +30,76 @@ import (
 // resources is correct.
 func ValidateObjectMetadata(meta metav1.Object) *apis.FieldError {
 	return apis.ValidateObjectMetadata(meta).
-		Also(autoscaling.ValidateAnnotations(meta.GetAnnotations()).ViaField("annotations"))
+		Also(autoscaling.ValidateAnnotations(meta.GetAnnotations()).ViaField("annotations")).
+		Also(validateRequestPriority(meta.GetAnnotations()).ViaField("annotations")).
+		Also(validateColdStartPolicy(meta.GetAnnotations()).ViaField("annotations")).
+		Also(validateDrain(meta.GetAnnotations()).ViaField("annotations")).
+		Also(validateRequestLimits(meta.GetAnnotations()).ViaField("annotations")).
+		Also(validateRequestTimeouts(meta.GetAnnotations()).ViaField("annotations"))
+}
+
+func validateRequestPriority(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[RequestPriorityAnnotationKey]
+	if !ok {
+		return nil
+	}
+	switch v {
+	case RequestPriorityHigh, RequestPriorityNormal, RequestPriorityLow:
+		return nil
+	}
+	return apis.ErrInvalidValue(v, RequestPriorityAnnotationKey)
+}
+
+func validateColdStartPolicy(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[ColdStartPolicyAnnotationKey]
+	if !ok {
+		return nil
+	}
+	switch v {
+	case ColdStartPolicyQueue, ColdStartPolicyFailFast:
+		return nil
+	}
+	return apis.ErrInvalidValue(v, ColdStartPolicyAnnotationKey)
+}
+
+func validateDrain(annotations map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	if v, ok := annotations[PreStopDelayAnnotationKey]; ok {
+		if d, err := time.ParseDuration(v); err != nil || d < 0 {
+			errs = errs.Also(apis.ErrInvalidValue(v, PreStopDelayAnnotationKey))
+		}
+	}
+	if v, ok := annotations[DrainTimeoutAnnotationKey]; ok {
+		if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+			errs = errs.Also(apis.ErrInvalidValue(v, DrainTimeoutAnnotationKey))
+		}
+	}
+	if v, ok := annotations[DrainNotifyPathAnnotationKey]; ok && !strings.HasPrefix(v, "/") {
+		errs = errs.Also(apis.ErrInvalidValue(v, DrainNotifyPathAnnotationKey))
+	}
+	return errs
+}
+
+func validateRequestLimits(annotations map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	for _, key := range []string{MaxRequestBodyBytesAnnotationKey, MaxRequestHeaderBytesAnnotationKey} {
+		if v, ok := annotations[key]; ok {
+			if n, err := strconv.ParseInt(v, 10, 64); err != nil || n <= 0 {
+				errs = errs.Also(apis.ErrInvalidValue(v, key))
+			}
+		}
+	}
+	return errs
+}
+
+func validateRequestTimeouts(annotations map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	for _, key := range []string{MaxRequestDurationAnnotationKey, ResponseIdleTimeoutAnnotationKey} {
+		if v, ok := annotations[key]; ok {
+			if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+				errs = errs.Also(apis.ErrInvalidValue(v, key))
+			}
+		}
+	}
+	return errs
 }
@@ -87,6 +87,113 @@ func TestValidateObjectMetadata(t *testing.T) {
 			Message: "not a DNS 1035 label prefix: [must be no more than 63 characters]",
 			Paths:   []string{"generateName"},
 		},
+	}, {
+		name: "valid request priority",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				RequestPriorityAnnotationKey: RequestPriorityLow,
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid request priority",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				RequestPriorityAnnotationKey: "urgent",
+			},
+		},
+		expectErr: (&apis.FieldError{}).Also(
+			apis.ErrInvalidValue("urgent", "annotations."+RequestPriorityAnnotationKey)),
+	}, {
+		name: "valid cold start policy",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				ColdStartPolicyAnnotationKey: ColdStartPolicyFailFast,
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid cold start policy",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				ColdStartPolicyAnnotationKey: "wait",
+			},
+		},
+		expectErr: (&apis.FieldError{}).Also(
+			apis.ErrInvalidValue("wait", "annotations."+ColdStartPolicyAnnotationKey)),
+	}, {
+		name: "valid drain",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PreStopDelayAnnotationKey:    "0s",
+				DrainTimeoutAnnotationKey:    "5m",
+				DrainNotifyPathAnnotationKey: "/drain",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid drain",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PreStopDelayAnnotationKey:    "-1s",
+				DrainTimeoutAnnotationKey:    "0s",
+				DrainNotifyPathAnnotationKey: "drain",
+			},
+		},
+		expectErr: (&apis.FieldError{}).Also((&apis.FieldError{}).Also(
+			apis.ErrInvalidValue("-1s", "annotations."+PreStopDelayAnnotationKey),
+			apis.ErrInvalidValue("0s", "annotations."+DrainTimeoutAnnotationKey),
+			apis.ErrInvalidValue("drain", "annotations."+DrainNotifyPathAnnotationKey))),
+	}, {
+		name: "valid request limits",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				MaxRequestBodyBytesAnnotationKey:   "1048576",
+				MaxRequestHeaderBytesAnnotationKey: "8192",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid request limits",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				MaxRequestBodyBytesAnnotationKey:   "1Mi",
+				MaxRequestHeaderBytesAnnotationKey: "0",
+			},
+		},
+		expectErr: (&apis.FieldError{}).Also((&apis.FieldError{}).Also(
+			apis.ErrInvalidValue("1Mi", "annotations."+MaxRequestBodyBytesAnnotationKey),
+			apis.ErrInvalidValue("0", "annotations."+MaxRequestHeaderBytesAnnotationKey))),
+	}, {
+		name: "valid request timeouts",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				MaxRequestDurationAnnotationKey:  "1h",
+				ResponseIdleTimeoutAnnotationKey: "30s",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid request timeouts",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				MaxRequestDurationAnnotationKey:  "forever",
+				ResponseIdleTimeoutAnnotationKey: "-1s",
+			},
+		},
+		expectErr: (&apis.FieldError{}).Also((&apis.FieldError{}).Also(
+			apis.ErrInvalidValue("forever", "annotations."+MaxRequestDurationAnnotationKey),
+			apis.ErrInvalidValue("-1s", "annotations."+ResponseIdleTimeoutAnnotationKey))),
 	}, {
 		name:       "missing name and generateName",
 		objectMeta: &metav1.ObjectMeta{},
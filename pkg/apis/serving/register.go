@@ -66,4 +66,84 @@ const (
 	// QueueSideCarResourcePercentageAnnotation is the percentage of user container resources to be used for queue-proxy
 	// It has to be in [0.1,100]
 	QueueSideCarResourcePercentageAnnotation = "queue.sidecar." + GroupName + "/resourcePercentage"
+
+	// RequestPriorityAnnotationKey is the annotation key of a Route to set the
+	// priority class of the requests it routes. The requests waiting for
+	// capacity are admitted by their priority class where request priority
+	// is enabled.
+	RequestPriorityAnnotationKey = GroupName + "/requestPriority"
+
+	// ColdStartPolicyAnnotationKey is the annotation key of a Revision to set
+	// how the activator handles the requests that arrive while the revision
+	// is scaling from zero.
+	ColdStartPolicyAnnotationKey = GroupName + "/coldStartPolicy"
+
+	// PreStopDelayAnnotationKey is the annotation key of a Revision to set
+	// how long its pods keep accepting requests once they're asked to
+	// terminate, giving the network time to stop routing requests to them.
+	PreStopDelayAnnotationKey = GroupName + "/preStopDelay"
+
+	// DrainTimeoutAnnotationKey is the annotation key of a Revision to set
+	// how long the requests in flight may take to complete after the
+	// pre-stop delay before they're cut. It defaults to the revision's
+	// timeout.
+	DrainTimeoutAnnotationKey = GroupName + "/drainTimeout"
+
+	// DrainNotifyPathAnnotationKey is the annotation key of a Revision to
+	// name a path on the port of its container that is sent a POST request
+	// once a pod begins to drain.
+	DrainNotifyPathAnnotationKey = GroupName + "/drainNotifyPath"
+
+	// MaxRequestBodyBytesAnnotationKey is the annotation key of a Revision
+	// to limit the size of the request bodies, in bytes, its pods accept.
+	// Larger requests are answered with 413 Request Entity Too Large.
+	MaxRequestBodyBytesAnnotationKey = GroupName + "/maxRequestBodyBytes"
+
+	// MaxRequestHeaderBytesAnnotationKey is the annotation key of a Revision
+	// to limit the size of the request headers, in bytes, its pods accept.
+	// Requests with larger headers are answered with 431 Request Header
+	// Fields Too Large.
+	MaxRequestHeaderBytesAnnotationKey = GroupName + "/maxRequestHeaderBytes"
+
+	// MaxRequestDurationAnnotationKey is the annotation key of a Revision to
+	// limit how long the responses of its pods may take in total. Requests
+	// running out of time before the response started are answered with
+	// 504 Gateway Timeout, responses running out of time are aborted.
+	MaxRequestDurationAnnotationKey = GroupName + "/maxRequestDuration"
+
+	// ResponseIdleTimeoutAnnotationKey is the annotation key of a Revision to
+	// limit how long the responses of its pods may stall between two
+	// writes. Stalled responses are aborted.
+	ResponseIdleTimeoutAnnotationKey = GroupName + "/responseIdleTimeout"
+
+	// QueueProxyTLSSecretAnnotationKey is the annotation key of a Revision
+	// naming the Secret its queue-proxies serve TLS with. The Configuration
+	// reconciler sets it on the revisions it creates while the
+	// queueProxyTLSSecret of config-network is set, so that changing that
+	// key leaves the revisions that exist already as they are. Revisions
+	// serving TLS are only reached through the activator.
+	QueueProxyTLSSecretAnnotationKey = GroupName + "/queueProxyTLSSecret"
+)
+
+// The priority classes of requests.
+const (
+	// RequestPriorityHigh is for requests admitted before all others, e.g.
+	// health checks.
+	RequestPriorityHigh = "high"
+	// RequestPriorityNormal is the priority class of requests by default.
+	RequestPriorityNormal = "normal"
+	// RequestPriorityLow is for requests that can be shed if they wait for
+	// capacity too long, e.g. batch callbacks.
+	RequestPriorityLow = "low"
+)
+
+// The cold start policies.
+const (
+	// ColdStartPolicyQueue queues the requests until the revision scaled from
+	// zero. It's the default.
+	ColdStartPolicyQueue = "queue"
+	// ColdStartPolicyFailFast rejects the requests with 503 right away, with
+	// a Retry-After estimate if the revision was seen scaling from zero
+	// before. It's for callers with short deadlines, e.g. webhooks.
+	ColdStartPolicyFailFast = "fail-fast"
 )
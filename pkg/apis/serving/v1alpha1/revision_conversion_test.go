@@ -92,6 +92,43 @@ func TestRevisionConversion(t *testing.T) {
 				LogURL:      "http://logger.io",
 			},
 		},
+	}, {
+		name: "good roundtrip w/ sidecar",
+		in: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "asdf",
+				Namespace:  "blah",
+				Generation: 1,
+			},
+			Spec: RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					PodSpec: corev1.PodSpec{
+						ServiceAccountName: "robocop",
+						Containers: []corev1.Container{{
+							Image: "busybox",
+							Ports: []corev1.ContainerPort{{
+								ContainerPort: 8888,
+							}},
+						}, {
+							Image: "helloworld",
+						}},
+					},
+					TimeoutSeconds:       ptr.Int64(18),
+					ContainerConcurrency: 53,
+				},
+			},
+			Status: RevisionStatus{
+				Status: duckv1beta1.Status{
+					ObservedGeneration: 1,
+					Conditions: duckv1beta1.Conditions{{
+						Type:   "Ready",
+						Status: "True",
+					}},
+				},
+				ServiceName: "foo-bar",
+				LogURL:      "http://logger.io",
+			},
+		},
 	}, {
 		name:     "bad roundtrip w/ build ref",
 		badField: "buildRef",
@@ -178,6 +215,9 @@ func TestRevisionConversion(t *testing.T) {
 		// A variant of the test that uses `container:`,
 		// but end up with what we have above anyways.
 		t.Run(test.name+" (deprecated)", func(t *testing.T) {
+			if len(test.in.Spec.Containers) > 1 {
+				t.Skip("`container:` can not express sidecars")
+			}
 			start := toDeprecated(test.in)
 			beta := &v1beta1.Revision{}
 			if err := start.ConvertUp(context.Background(), beta); err != nil {
@@ -210,41 +250,6 @@ func TestRevisionConversionError(t *testing.T) {
 		in   *Revision
 		want *apis.FieldError
 	}{{
-		name: "multiple containers in podspec",
-		in: &Revision{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "asdf",
-				Namespace:  "blah",
-				Generation: 1,
-			},
-			Spec: RevisionSpec{
-				RevisionSpec: v1beta1.RevisionSpec{
-					PodSpec: corev1.PodSpec{
-						ServiceAccountName: "robocop",
-						Containers: []corev1.Container{{
-							Image: "busybox",
-						}, {
-							Image: "helloworld",
-						}},
-					},
-					TimeoutSeconds:       ptr.Int64(18),
-					ContainerConcurrency: 53,
-				},
-			},
-			Status: RevisionStatus{
-				Status: duckv1beta1.Status{
-					ObservedGeneration: 1,
-					Conditions: duckv1beta1.Conditions{{
-						Type:   "Ready",
-						Status: "True",
-					}},
-				},
-				ServiceName: "foo-bar",
-				LogURL:      "http://logger.io",
-			},
-		},
-		want: apis.ErrMultipleOneOf("containers"),
-	}, {
 		name: "no containers in podspec",
 		in: &Revision{
 			ObjectMeta: metav1.ObjectMeta{
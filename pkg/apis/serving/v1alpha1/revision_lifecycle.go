@@ -66,14 +66,15 @@ func (r *Revision) GetGroupVersionKind() schema.GroupVersionKind {
 }
 
 // GetContainer returns a pointer to the relevant corev1.Container field.
-// It is never nil and should be exactly the specified container as guaranteed
+// It is never nil and should be exactly the specified container, or the
+// container serving the requests if there are sidecars, as guaranteed
 // by validation.
 func (rs *RevisionSpec) GetContainer() *corev1.Container {
 	if rs.DeprecatedContainer != nil {
 		return rs.DeprecatedContainer
 	}
 	if len(rs.Containers) > 0 {
-		return &rs.Containers[0]
+		return &rs.Containers[serving.ServingContainerIndex(rs.Containers)]
 	}
 	// Should be unreachable post-validation, but here to ease testing.
 	return &corev1.Container{}
@@ -191,6 +191,12 @@ type RevisionStatus struct {
 	// may be empty if the image comes from a registry listed to skip resolution.
 	// +optional
 	ImageDigest string `json:"imageDigest,omitempty"`
+
+	// ContainerStatuses holds the resolved digests of the images of all the
+	// containers of a Revision with sidecars, like ImageDigest does for the
+	// serving container.
+	// +optional
+	ContainerStatuses []v1beta1.ContainerStatus `json:"containerStatuses,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -64,6 +64,11 @@ func (r *Revision) Validate(ctx context.Context) *apis.FieldError {
 func (rt *RevisionTemplateSpec) Validate(ctx context.Context) *apis.FieldError {
 	errs := rt.Spec.Validate(ctx).ViaField("spec")
 	errs = errs.Also(autoscaling.ValidateAnnotations(rt.GetAnnotations()).ViaField("metadata.annotations"))
+	if c := rt.Spec.DeprecatedContainer; c != nil {
+		errs = errs.Also(serving.ValidateMemoryRequest(rt.GetAnnotations(), *c).ViaField("spec.container"))
+	} else {
+		errs = errs.Also(serving.ValidateMemoryRequests(rt.GetAnnotations(), rt.Spec.Containers).ViaField("spec"))
+	}
 
 	// If the DeprecatedRevisionTemplate has a name specified, then check that
 	// it follows the requirements on the name.
@@ -45,6 +45,7 @@ func (source *RouteSpec) ConvertUp(ctx context.Context, sink *v1beta1.RouteSpec)
 			return err
 		}
 	}
+	sink.Mirror = source.Mirror.DeepCopy()
 	return nil
 }
 
@@ -104,6 +105,7 @@ func (sink *RouteSpec) ConvertDown(ctx context.Context, source v1beta1.RouteSpec
 	for i := range source.Traffic {
 		sink.Traffic[i].ConvertDown(ctx, source.Traffic[i])
 	}
+	sink.Mirror = source.Mirror.DeepCopy()
 }
 
 // ConvertDown helps implement apis.Convertible
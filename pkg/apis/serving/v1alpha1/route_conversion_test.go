@@ -25,6 +25,7 @@ import (
 
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/ptr"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 )
 
@@ -97,6 +98,10 @@ func TestRouteConversion(t *testing.T) {
 						Percent:      100,
 					},
 				}},
+				Mirror: &v1beta1.TrafficMirror{
+					RevisionName: "foo-00003",
+					Percent:      ptr.Int64(10),
+				},
 			},
 			Status: RouteStatus{
 				Status: duckv1beta1.Status{
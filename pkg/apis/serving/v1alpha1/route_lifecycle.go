@@ -152,6 +152,56 @@ func (rs *RouteStatus) MarkCertificateNotOwned(name string) {
 	})
 }
 
+// MarkMirrorActive notes that the requests to the Route are mirrored to the
+// revision.
+func (rs *RouteStatus) MarkMirrorActive(name string) {
+	routeCondSet.Manage(rs).SetCondition(apis.Condition{
+		Type:     RouteConditionMirrorActive,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "MirrorActive",
+		Message:  fmt.Sprintf("Requests are mirrored to Revision %q.", name),
+	})
+}
+
+// MarkMirrorNotRoutable notes that the requests to the Route are not
+// mirrored since the revision is missing or not ready.
+func (rs *RouteStatus) MarkMirrorNotRoutable(msg string) {
+	routeCondSet.Manage(rs).SetCondition(apis.Condition{
+		Type:     RouteConditionMirrorActive,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "MirrorNotRoutable",
+		Message:  msg,
+	})
+}
+
+// MarkMirrorInactive notes that the requests to the Route are not mirrored
+// since the revision is scaled to zero. Mirrored requests don't scale it
+// from zero, so it has to keep a minimum scale of at least 1 to receive them.
+func (rs *RouteStatus) MarkMirrorInactive(name string) {
+	routeCondSet.Manage(rs).SetCondition(apis.Condition{
+		Type:     RouteConditionMirrorActive,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "MirrorInactive",
+		Message: fmt.Sprintf("Revision %q is scaled to zero and mirrored requests don't scale it up, "+
+			"set its minScale to at least 1.", name),
+	})
+}
+
+// MarkMirrorNotConfigured removes the RouteConditionMirrorActive condition
+// of a Route without a mirror.
+func (rs *RouteStatus) MarkMirrorNotConfigured() {
+	conds := make(duckv1beta1.Conditions, 0, len(rs.Conditions))
+	for _, c := range rs.Conditions {
+		if c.Type != RouteConditionMirrorActive {
+			conds = append(conds, c)
+		}
+	}
+	rs.Conditions = conds
+}
+
 // PropagateIngressStatus update RouteConditionIngressReady condition
 // in RouteStatus according to IngressStatus.
 func (rs *RouteStatus) PropagateIngressStatus(cs v1alpha1.IngressStatus) {
@@ -376,6 +376,30 @@ func TestRouteNotOwnCertificate(t *testing.T) {
 	apitesting.CheckConditionFailed(r.duck(), RouteConditionCertificateProvisioned, t)
 }
 
+func TestMirrorActive(t *testing.T) {
+	r := &RouteStatus{}
+	r.InitializeConditions()
+	r.MarkTrafficAssigned()
+	r.MarkIngressNotConfigured()
+
+	r.MarkMirrorInactive("rev")
+	apitesting.CheckConditionFailed(r.duck(), RouteConditionMirrorActive, t)
+	r.MarkMirrorNotRoutable("Revision \"rev\" is not yet ready.")
+	apitesting.CheckConditionFailed(r.duck(), RouteConditionMirrorActive, t)
+	r.MarkMirrorActive("rev")
+	apitesting.CheckConditionSucceeded(r.duck(), RouteConditionMirrorActive, t)
+
+	// The mirror doesn't affect the readiness of the Route.
+	r.MarkMirrorInactive("rev")
+	apitesting.CheckConditionOngoing(r.duck(), RouteConditionReady, t)
+
+	r.MarkMirrorNotConfigured()
+	if c := r.GetCondition(RouteConditionMirrorActive); c != nil {
+		t.Errorf("GetCondition(%s) = %v, want nil", RouteConditionMirrorActive, c)
+	}
+	apitesting.CheckConditionSucceeded(r.duck(), RouteConditionAllTrafficAssigned, t)
+}
+
 func TestIngressNotConfigured(t *testing.T) {
 	r := &RouteStatus{}
 	r.InitializeConditions()
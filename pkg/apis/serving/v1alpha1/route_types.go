@@ -91,6 +91,11 @@ type RouteSpec struct {
 	// Traffic specifies how to distribute traffic over a collection of Knative Serving Revisions and Configurations.
 	// +optional
 	Traffic []TrafficTarget `json:"traffic,omitempty"`
+
+	// Mirror sends a copy of the requests to the URL of the Route to a
+	// revision, whose responses are discarded.
+	// +optional
+	Mirror *v1beta1.TrafficMirror `json:"mirror,omitempty"`
 }
 
 const (
@@ -110,6 +115,11 @@ const (
 	// RouteConditionCertificateProvisioned is set to False when the
 	// Knative Certificates fail to be provisioned for the Route.
 	RouteConditionCertificateProvisioned apis.ConditionType = "CertificateProvisioned"
+
+	// RouteConditionMirrorActive is set to False while the requests to the
+	// Route are not mirrored, because the mirror revision is not routable or
+	// scaled to zero. It's only set while the Route has a mirror.
+	RouteConditionMirrorActive apis.ConditionType = "MirrorActive"
 )
 
 // RouteStatusFields holds all of the non-duckv1beta1.Status status fields of a Route.
@@ -91,5 +91,9 @@ func (rs *RouteSpec) Validate(ctx context.Context) *apis.FieldError {
 			Paths:   []string{"traffic"},
 		})
 	}
+	if rs.Mirror != nil {
+		// Delegate to the v1beta1 validation.
+		errs = errs.Also(rs.Mirror.Validate(ctx).ViaField("mirror"))
+	}
 	return errs
 }
@@ -18,10 +18,12 @@ package v1beta1
 
 import (
 	"context"
+	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
 
 	"knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
 )
 
 // SetDefaults implements apis.Defaultable
@@ -44,9 +46,14 @@ func (rs *RevisionSpec) SetDefaults(ctx context.Context) {
 		rs.TimeoutSeconds = &ts
 	}
 
+	servingIdx := serving.ServingContainerIndex(rs.PodSpec.Containers)
 	for idx := range rs.PodSpec.Containers {
 		if rs.PodSpec.Containers[idx].Name == "" {
 			rs.PodSpec.Containers[idx].Name = cfg.Defaults.UserContainerName(ctx)
+			// Sidecars are told apart by their index.
+			if idx != servingIdx {
+				rs.PodSpec.Containers[idx].Name += "-" + strconv.Itoa(idx)
+			}
 		}
 
 		if rs.PodSpec.Containers[idx].Resources.Requests == nil {
@@ -76,17 +83,21 @@ func (rs *RevisionSpec) SetDefaults(ctx context.Context) {
 				rs.PodSpec.Containers[idx].Resources.Limits[corev1.ResourceMemory] = *rsrc
 			}
 		}
-		if rs.PodSpec.Containers[idx].ReadinessProbe == nil {
-			rs.PodSpec.Containers[idx].ReadinessProbe = &corev1.Probe{}
-		}
-		if rs.PodSpec.Containers[idx].ReadinessProbe.TCPSocket == nil &&
-			rs.PodSpec.Containers[idx].ReadinessProbe.HTTPGet == nil &&
-			rs.PodSpec.Containers[idx].ReadinessProbe.Exec == nil {
-			rs.PodSpec.Containers[idx].ReadinessProbe.TCPSocket = &corev1.TCPSocketAction{}
-		}
+		// Only the readiness of the serving container is probed by the
+		// queue-proxy, sidecars keep their probes as they are.
+		if idx == servingIdx {
+			if rs.PodSpec.Containers[idx].ReadinessProbe == nil {
+				rs.PodSpec.Containers[idx].ReadinessProbe = &corev1.Probe{}
+			}
+			if rs.PodSpec.Containers[idx].ReadinessProbe.TCPSocket == nil &&
+				rs.PodSpec.Containers[idx].ReadinessProbe.HTTPGet == nil &&
+				rs.PodSpec.Containers[idx].ReadinessProbe.Exec == nil {
+				rs.PodSpec.Containers[idx].ReadinessProbe.TCPSocket = &corev1.TCPSocketAction{}
+			}
 
-		if rs.PodSpec.Containers[idx].ReadinessProbe.SuccessThreshold == 0 {
-			rs.PodSpec.Containers[idx].ReadinessProbe.SuccessThreshold = 1
+			if rs.PodSpec.Containers[idx].ReadinessProbe.SuccessThreshold == 0 {
+				rs.PodSpec.Containers[idx].ReadinessProbe.SuccessThreshold = 1
+			}
 		}
 
 		vms := rs.PodSpec.Containers[idx].VolumeMounts
@@ -236,7 +236,42 @@ func TestRevisionDefaulting(t *testing.T) {
 						Resources:      defaultResources,
 						ReadinessProbe: defaultProbe,
 					}, {
-						Name:           "helloworld",
+						Name:      "helloworld",
+						Resources: defaultResources,
+					}},
+				},
+			},
+		},
+	}, {
+		name: "unnamed sidecar",
+		in: &Revision{
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "sidecar",
+					}, {
+						Image: "busybox",
+						Ports: []corev1.ContainerPort{{
+							ContainerPort: 8888,
+						}},
+					}},
+				},
+			},
+		},
+		want: &Revision{
+			Spec: RevisionSpec{
+				TimeoutSeconds: ptr.Int64(config.DefaultRevisionTimeoutSeconds),
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:      config.DefaultUserContainerName + "-0",
+						Image:     "sidecar",
+						Resources: defaultResources,
+					}, {
+						Name:  config.DefaultUserContainerName,
+						Image: "busybox",
+						Ports: []corev1.ContainerPort{{
+							ContainerPort: 8888,
+						}},
 						Resources:      defaultResources,
 						ReadinessProbe: defaultProbe,
 					}},
@@ -79,6 +79,9 @@ const (
 
 // RevisionSpec holds the desired state of the Revision (from the client).
 type RevisionSpec struct {
+	// PodSpec holds the containers of the Revision. When there are several
+	// containers, exactly one of them declares a port and serves the
+	// requests, the others are sidecars whose probes name their port.
 	corev1.PodSpec `json:",inline"`
 
 	// ContainerConcurrency specifies the maximum allowed in-flight (concurrent)
@@ -121,6 +124,24 @@ type RevisionStatus struct {
 	// may be empty if the image comes from a registry listed to skip resolution.
 	// +optional
 	ImageDigest string `json:"imageDigest,omitempty"`
+
+	// ContainerStatuses holds the resolved digests of the images of all the
+	// containers of a Revision with sidecars, like ImageDigest does for the
+	// serving container.
+	// +optional
+	ContainerStatuses []ContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+// ContainerStatus holds the information of a container of a Revision.
+type ContainerStatus struct {
+	// Name is the name of the container.
+	Name string `json:"name,omitempty"`
+
+	// ImageDigest holds the resolved digest for the image of the container.
+	// It may be empty if the image comes from a registry listed to skip
+	// resolution.
+	// +optional
+	ImageDigest string `json:"imageDigest,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -60,6 +60,7 @@ func (r *Revision) Validate(ctx context.Context) *apis.FieldError {
 func (rts *RevisionTemplateSpec) Validate(ctx context.Context) *apis.FieldError {
 	errs := rts.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec")
 	errs = errs.Also(autoscaling.ValidateAnnotations(rts.GetAnnotations()).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateMemoryRequests(rts.GetAnnotations(), rts.Spec.Containers).ViaField("spec"))
 
 	// If the RevisionTemplateSpec has a name specified, then check that
 	// it follows the requirements on the name.
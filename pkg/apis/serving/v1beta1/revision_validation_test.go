@@ -373,17 +373,23 @@ func TestRevisionSpecValidation(t *testing.T) {
 		},
 		want: apis.ErrMissingField("containers"),
 	}, {
-		name: "too many containers",
+		name: "no serving container",
 		rs: &RevisionSpec{
 			PodSpec: corev1.PodSpec{
 				Containers: []corev1.Container{{
+					Name:  "busybox",
 					Image: "busybox",
 				}, {
+					Name:  "helloworld",
 					Image: "helloworld",
 				}},
 			},
 		},
-		want: apis.ErrMultipleOneOf("containers"),
+		want: &apis.FieldError{
+			Message: "no container sets a port",
+			Paths:   []string{"containers"},
+			Details: "The container serving the requests must set a port when there are multiple containers",
+		},
 	}, {
 		name: "exceed max timeout",
 		rs: &RevisionSpec{
@@ -781,6 +787,47 @@ func TestRevisionTemplateSpecValidation(t *testing.T) {
 			},
 		},
 		want: nil,
+	}, {
+		name: "hpa memory target with memory request",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: autoscaling.Memory,
+				},
+			},
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "helloworld",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceMemory: resource.MustParse("128Mi"),
+							},
+						},
+					}},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "hpa memory target without memory request",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: autoscaling.Memory,
+				},
+			},
+			Spec: RevisionSpec{
+				PodSpec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Image: "helloworld",
+					}},
+				},
+			},
+		},
+		want: apis.ErrMissingField("spec.containers[0].resources.requests.memory"),
 	}, {
 		name: "invalid metadata.annotations for scale",
 		rts: &RevisionTemplateSpec{
@@ -99,6 +99,41 @@ type TrafficTarget struct {
 	// a hostname, but may not contain anything else (e.g. basic auth, url path, etc.)
 	// +optional
 	URL *apis.URL `json:"url,omitempty"`
+
+	// Match routes the requests to the URL of the Route that match any of
+	// the rules to this target, instead of splitting them by percent. It is
+	// only allowed on targets with a Tag.
+	// +optional
+	Match []TrafficMatch `json:"match,omitempty"`
+}
+
+const (
+	// MaxMatchCookies is the most cookies a TrafficMatch can match, as the
+	// expression to match them in any order grows with the factorial of
+	// their number.
+	MaxMatchCookies = 3
+
+	// MaxMatchQueryParameters is the most query parameters a TrafficMatch
+	// can match, for the same reason as MaxMatchCookies.
+	MaxMatchQueryParameters = 3
+)
+
+// TrafficMatch is a rule that a request matches when all of its conditions
+// hold. The values are matched exactly.
+type TrafficMatch struct {
+	// Headers are the request headers that must have the given values.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Cookies are the cookies that must have the given values, at most
+	// MaxMatchCookies of them.
+	// +optional
+	Cookies map[string]string `json:"cookies,omitempty"`
+
+	// QueryParameters are the query parameters that must have the given
+	// values, at most MaxMatchQueryParameters of them.
+	// +optional
+	QueryParameters map[string]string `json:"queryParameters,omitempty"`
 }
 
 // RouteSpec holds the desired state of the Route (from the client).
@@ -107,6 +142,24 @@ type RouteSpec struct {
 	// revisions and configurations.
 	// +optional
 	Traffic []TrafficTarget `json:"traffic,omitempty"`
+
+	// Mirror sends a copy of the requests to the URL of the Route to a
+	// revision, whose responses are discarded.
+	// +optional
+	Mirror *TrafficMirror `json:"mirror,omitempty"`
+}
+
+// TrafficMirror names the revision a copy of the traffic is sent to. The
+// mirror is dropped while the revision is missing or not ready, the traffic
+// of the Route is not affected.
+type TrafficMirror struct {
+	// RevisionName is the name of the revision the traffic is mirrored to.
+	RevisionName string `json:"revisionName"`
+
+	// Percent is the percentage of the requests that are mirrored, a number
+	// between 1 and 100. All of them are mirrored if unset.
+	// +optional
+	Percent *int64 `json:"percent,omitempty"`
 }
 
 const (
@@ -73,7 +73,23 @@ func validateTrafficList(ctx context.Context, traffic []TrafficTarget) *apis.Fie
 
 // Validate implements apis.Validatable
 func (rs *RouteSpec) Validate(ctx context.Context) *apis.FieldError {
-	return validateTrafficList(ctx, rs.Traffic).ViaField("traffic")
+	errs := validateTrafficList(ctx, rs.Traffic).ViaField("traffic")
+	if rs.Mirror != nil {
+		errs = errs.Also(rs.Mirror.Validate(ctx).ViaField("mirror"))
+	}
+	return errs
+}
+
+// Validate verifies that TrafficMirror is properly configured.
+func (tm *TrafficMirror) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if tm.RevisionName == "" {
+		errs = errs.Also(apis.ErrMissingField("revisionName"))
+	}
+	if tm.Percent != nil && (*tm.Percent < 1 || *tm.Percent > 100) {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*tm.Percent, 1, 100, "percent"))
+	}
+	return errs
 }
 
 // Validate verifies that TrafficTarget is properly configured.
@@ -81,9 +97,48 @@ func (tt *TrafficTarget) Validate(ctx context.Context) *apis.FieldError {
 	errs := tt.validateLatestRevision(ctx)
 	errs = tt.validateRevisionAndConfiguration(ctx, errs)
 	errs = tt.validateTrafficPercentage(errs)
+	errs = tt.validateMatch(errs)
 	return tt.validateUrl(ctx, errs)
 }
 
+func (tt *TrafficTarget) validateMatch(errs *apis.FieldError) *apis.FieldError {
+	if len(tt.Match) == 0 {
+		return errs
+	}
+	// Only the requests for the Route's URL are matched, they need a
+	// target they can be told apart from.
+	if tt.Tag == "" {
+		return errs.Also(apis.ErrDisallowedFields("match"))
+	}
+	for i, m := range tt.Match {
+		if len(m.Headers) == 0 && len(m.Cookies) == 0 && len(m.QueryParameters) == 0 {
+			errs = errs.Also(apis.ErrMissingOneOf("headers", "cookies", "queryParameters").ViaFieldIndex("match", i))
+		}
+		for name := range m.Headers {
+			if el := validation.IsHTTPHeaderName(name); len(el) > 0 {
+				errs = errs.Also(apis.ErrInvalidKeyName(name, "headers", el...).ViaFieldIndex("match", i))
+			}
+		}
+		if len(m.Cookies) > MaxMatchCookies {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(len(m.Cookies), 0, MaxMatchCookies, "cookies").ViaFieldIndex("match", i))
+		}
+		for name := range m.Cookies {
+			if name == "" {
+				errs = errs.Also(apis.ErrInvalidKeyName(name, "cookies").ViaFieldIndex("match", i))
+			}
+		}
+		if len(m.QueryParameters) > MaxMatchQueryParameters {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(len(m.QueryParameters), 0, MaxMatchQueryParameters, "queryParameters").ViaFieldIndex("match", i))
+		}
+		for name := range m.QueryParameters {
+			if name == "" {
+				errs = errs.Also(apis.ErrInvalidKeyName(name, "queryParameters").ViaFieldIndex("match", i))
+			}
+		}
+	}
+	return errs
+}
+
 func (tt *TrafficTarget) validateRevisionAndConfiguration(ctx context.Context, errs *apis.FieldError) *apis.FieldError {
 	// We only validate the sense of latestRevision in the context of a Spec,
 	// and only when it is specified.
@@ -65,6 +65,73 @@ func TestTrafficTargetValidation(t *testing.T) {
 		},
 		wc:   apis.WithinStatus,
 		want: nil,
+	}, {
+		name: "valid with match",
+		tt: &TrafficTarget{
+			Tag:          "canary",
+			RevisionName: "bar",
+			Match: []TrafficMatch{{
+				Headers: map[string]string{"X-Canary": "true"},
+			}, {
+				Cookies:         map[string]string{"canary": "always"},
+				QueryParameters: map[string]string{"canary": "true"},
+			}},
+		},
+		wc:   apis.WithinSpec,
+		want: nil,
+	}, {
+		name: "invalid match with too many cookies",
+		tt: &TrafficTarget{
+			Tag:          "canary",
+			RevisionName: "bar",
+			Match: []TrafficMatch{{
+				Cookies: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"},
+			}},
+		},
+		wc:   apis.WithinSpec,
+		want: apis.ErrOutOfBoundsValue(4, 0, MaxMatchCookies, "match[0].cookies"),
+	}, {
+		name: "invalid match with too many query parameters",
+		tt: &TrafficTarget{
+			Tag:          "canary",
+			RevisionName: "bar",
+			Match: []TrafficMatch{{
+				QueryParameters: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"},
+			}},
+		},
+		wc:   apis.WithinSpec,
+		want: apis.ErrOutOfBoundsValue(4, 0, MaxMatchQueryParameters, "match[0].queryParameters"),
+	}, {
+		name: "invalid match without tag",
+		tt: &TrafficTarget{
+			RevisionName: "bar",
+			Percent:      12,
+			Match: []TrafficMatch{{
+				Headers: map[string]string{"X-Canary": "true"},
+			}},
+		},
+		wc:   apis.WithinSpec,
+		want: apis.ErrDisallowedFields("match"),
+	}, {
+		name: "invalid empty match",
+		tt: &TrafficTarget{
+			Tag:          "canary",
+			RevisionName: "bar",
+			Match:        []TrafficMatch{{}},
+		},
+		wc:   apis.WithinSpec,
+		want: apis.ErrMissingOneOf("match[0].headers", "match[0].cookies", "match[0].queryParameters"),
+	}, {
+		name: "invalid match header name",
+		tt: &TrafficTarget{
+			Tag:          "canary",
+			RevisionName: "bar",
+			Match: []TrafficMatch{{
+				Headers: map[string]string{"X Canary": "true"},
+			}},
+		},
+		wc:   apis.WithinSpec,
+		want: apis.ErrInvalidKeyName("X Canary", "match[0].headers", "a valid HTTP header must consist of alphanumeric characters or '-' (e.g. 'X-Header-Name', regex used for validation is '[-A-Za-z0-9]+')"),
 	}, {
 		name: "invalid with revisionName and name (status)",
 		tt: &TrafficTarget{
@@ -314,6 +381,60 @@ func TestRouteValidation(t *testing.T) {
 			},
 		},
 		want: nil,
+	}, {
+		name: "valid mirror",
+		r: &Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: RouteSpec{
+				Traffic: []TrafficTarget{{
+					RevisionName: "foo",
+					Percent:      100,
+				}},
+				Mirror: &TrafficMirror{
+					RevisionName: "bar",
+					Percent:      ptr.Int64(10),
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid mirror",
+		r: &Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: RouteSpec{
+				Traffic: []TrafficTarget{{
+					RevisionName: "foo",
+					Percent:      100,
+				}},
+				Mirror: &TrafficMirror{
+					Percent: ptr.Int64(101),
+				},
+			},
+		},
+		want: apis.ErrMissingField("spec.mirror.revisionName").Also(
+			apis.ErrOutOfBoundsValue(101, 1, 100, "spec.mirror.percent")),
+	}, {
+		name: "mirror of no requests",
+		r: &Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: RouteSpec{
+				Traffic: []TrafficTarget{{
+					RevisionName: "foo",
+					Percent:      100,
+				}},
+				Mirror: &TrafficMirror{
+					RevisionName: "bar",
+					Percent:      ptr.Int64(0),
+				},
+			},
+		},
+		want: apis.ErrOutOfBoundsValue(0, 1, 100, "spec.mirror.percent"),
 	}, {
 		name: "valid split",
 		r: &Route{
@@ -206,6 +206,40 @@ func TestTimedFloat64Buckets_RemoveOlderThan(t *testing.T) {
 	}
 }
 
+func TestTimedFloat64Buckets_SnapshotRestore(t *testing.T) {
+	granularity := 2 * time.Second
+	now := time.Now().Truncate(granularity)
+
+	buckets := NewTimedFloat64Buckets(granularity)
+	buckets.Record(now, "pod1", 1.0)
+	buckets.Record(now, "pod1", 3.0)
+	buckets.Record(now, "pod2", 5.0)
+	buckets.Record(now.Add(-10*time.Second), "pod1", 7.0)
+	buckets.Record(now.Add(-time.Minute), "pod1", 100.0)
+
+	snapshot := buckets.Snapshot()
+	if got, want := len(snapshot), 3; got != want {
+		t.Fatalf("len(Snapshot()) = %d, want: %d", got, want)
+	}
+
+	restored := NewTimedFloat64Buckets(granularity)
+	// Values recorded before the restore are merged with the restored ones.
+	restored.Record(now, "pod1", 8.0)
+	restored.Restore(snapshot, now.Add(-30*time.Second))
+
+	got := make(map[time.Time]float64)
+	for time, bucket := range restored.buckets {
+		got[time] = bucket.Sum()
+	}
+	want := map[time.Time]float64{
+		now:                        9.0, // (1 + 3 + 8) / 3 + 5
+		now.Add(-10 * time.Second): 7.0,
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("Unexpected values (-want +got): %v", cmp.Diff(want, got))
+	}
+}
+
 func TestFloat64Bucket(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,224 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/resources"
+)
+
+// AlgorithmFactory creates a UniScaler implementing a scaling algorithm
+// for the given revision.
+type AlgorithmFactory func(
+	namespace string,
+	revision string,
+	metricClient MetricClient,
+	podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec,
+	reporter StatsReporter) (UniScaler, error)
+
+// AlgorithmRegistry maps scaling algorithm names to the factories creating them.
+type AlgorithmRegistry struct {
+	mux       sync.RWMutex
+	factories map[string]AlgorithmFactory
+}
+
+// NewAlgorithmRegistry creates an empty AlgorithmRegistry.
+func NewAlgorithmRegistry() *AlgorithmRegistry {
+	return &AlgorithmRegistry{
+		factories: make(map[string]AlgorithmFactory),
+	}
+}
+
+// NewDefaultAlgorithmRegistry creates an AlgorithmRegistry holding the
+// scaling algorithms shipped with the autoscaler.
+func NewDefaultAlgorithmRegistry() *AlgorithmRegistry {
+	r := NewAlgorithmRegistry()
+	r.Register(autoscaling.StablePanicAlgorithm, newStablePanicScaler)
+	r.Register(autoscaling.PIDAlgorithm, newPIDScaler)
+	r.Register(autoscaling.PredictiveAlgorithm, newPredictiveScaler)
+	return r
+}
+
+// Register adds the factory under the given name, replacing any factory
+// previously registered under the same name.
+func (r *AlgorithmRegistry) Register(name string, factory AlgorithmFactory) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.factories[name] = factory
+}
+
+// Get returns the factory registered under the given name. An empty name
+// resolves to the default stable/panic algorithm.
+func (r *AlgorithmRegistry) Get(name string) (AlgorithmFactory, error) {
+	if name == "" {
+		name = autoscaling.StablePanicAlgorithm
+	}
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scaling algorithm %q", name)
+	}
+	return factory, nil
+}
+
+func newStablePanicScaler(namespace, revision string, metricClient MetricClient, podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec, reporter StatsReporter) (UniScaler, error) {
+	a, err := New(namespace, revision, metricClient, podCounter, deciderSpec, reporter)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// windowScaler holds what the scaling algorithms beyond stable/panic have in
+// common: the revision they scale, its current DeciderSpec and the observation
+// of the stable and panic window values of its scaling metric.
+type windowScaler struct {
+	namespace    string
+	revision     string
+	metricClient MetricClient
+	podCounter   resources.ReadyPodCounter
+	reporter     StatsReporter
+
+	// specMux guards the current DeciderSpec.
+	specMux     sync.RWMutex
+	deciderSpec DeciderSpec
+}
+
+// windowObservation is what a windowScaler observed at a tick.
+type windowObservation struct {
+	spec DeciderSpec
+	// readyPodCount is the number of ready pods, and readyPodsCount the
+	// same but at least 1 to scale from.
+	readyPodCount  int
+	readyPodsCount float64
+	metricName     string
+	stableValue    float64
+	panicValue     float64
+}
+
+func newWindowScaler(namespace, revision string, metricClient MetricClient, podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec, reporter StatsReporter) (*windowScaler, error) {
+	if podCounter == nil {
+		return nil, errors.New("'podCounter' must not be nil")
+	}
+	if reporter == nil {
+		return nil, errors.New("stats reporter must not be nil")
+	}
+	// These algorithms never panic.
+	reporter.ReportPanic(0)
+	return &windowScaler{
+		namespace:    namespace,
+		revision:     revision,
+		metricClient: metricClient,
+		podCounter:   podCounter,
+		deciderSpec:  deciderSpec,
+		reporter:     reporter,
+	}, nil
+}
+
+// Update reconfigures the UniScaler according to the DeciderSpec.
+func (w *windowScaler) Update(deciderSpec DeciderSpec) error {
+	w.specMux.Lock()
+	defer w.specMux.Unlock()
+
+	w.deciderSpec = deciderSpec
+	return nil
+}
+
+func (w *windowScaler) currentSpec() DeciderSpec {
+	w.specMux.RLock()
+	defer w.specMux.RUnlock()
+	return w.deciderSpec
+}
+
+// observe returns the ready pod count and the window values of the scaling
+// metric, or false if there is nothing to scale on.
+func (w *windowScaler) observe(ctx context.Context, now time.Time) (windowObservation, bool) {
+	logger := logging.FromContext(ctx)
+
+	o := windowObservation{spec: w.currentSpec()}
+	readyPodCount, err := w.podCounter.ReadyCount()
+	// If the error is NotFound, then presume 0.
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Errorw("Failed to get Endpoints via K8S Lister", zap.Error(err))
+		return o, false
+	}
+	o.readyPodCount = readyPodCount
+	// Use 1 if there are zero current pods.
+	o.readyPodsCount = math.Max(1, float64(readyPodCount))
+
+	metricKey := types.NamespacedName{Namespace: w.namespace, Name: w.revision}
+	o.metricName, o.stableValue, o.panicValue, err = observedMetricValues(w.metricClient, o.spec, metricKey, now)
+	if err != nil {
+		if err == ErrNoData {
+			logger.Debug("No data to scale on yet")
+		} else {
+			logger.Errorw("Failed to obtain metrics", zap.Error(err))
+		}
+		return o, false
+	}
+	reportMetricValues(w.reporter, o.spec, o.stableValue, o.panicValue)
+	return o, true
+}
+
+// result clamps the desired pod count the algorithm computed from the
+// observation and reports the ScaleResult.
+func (w *windowScaler) result(o windowObservation, desired float64) (sRes ScaleResult) {
+	sRes.DesiredPodCount, sRes.ScaleUpClamped, sRes.ScaleDownClamped = clampScale(desired, o.readyPodsCount, o.spec)
+	sRes.ExcessBurstCapacity = excessBurstCapacity(o.readyPodCount, o.stableValue, o.spec)
+	w.reporter.ReportExcessBurstCapacity(float64(sRes.ExcessBurstCapacity))
+	w.reporter.ReportDesiredPodCount(int64(sRes.DesiredPodCount))
+	sRes.ScalingMetric = o.metricName
+	sRes.ObservedStableValue = o.stableValue
+	sRes.ObservedPanicValue = o.panicValue
+	sRes.ReadyPodCount = o.readyPodCount
+	return sRes
+}
+
+// scaleDownLimit returns the least pod count the rate allows scaling down to.
+// Rates of 1 or less, e.g. the zero value of an unset rate, don't limit
+// scaling down.
+func scaleDownLimit(readyPodsCount, maxScaleDownRate float64) float64 {
+	if maxScaleDownRate <= 1 {
+		return 0
+	}
+	return math.Floor(readyPodsCount / maxScaleDownRate)
+}
+
+// clampScale keeps the desired pod count in the [maxScaleDown, maxScaleUp] range
+// permitted by the rate limits of the spec and reports whether it was clamped.
+func clampScale(desired, readyPodsCount float64, spec DeciderSpec) (count int32, upClamped, downClamped bool) {
+	maxScaleUp := spec.MaxScaleUpRate * readyPodsCount
+	maxScaleDown := scaleDownLimit(readyPodsCount, spec.MaxScaleDownRate)
+	return int32(math.Min(math.Max(desired, maxScaleDown), maxScaleUp)),
+		desired > maxScaleUp, desired < maxScaleDown
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"testing"
+	"time"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/resources"
+)
+
+func TestDefaultAlgorithmRegistry(t *testing.T) {
+	r := NewDefaultAlgorithmRegistry()
+	for _, name := range []string{"", autoscaling.StablePanicAlgorithm, autoscaling.PIDAlgorithm, autoscaling.PredictiveAlgorithm} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := r.Get(name); err != nil {
+				t.Errorf("Get(%q) = %v", name, err)
+			}
+		})
+	}
+	if _, err := r.Get("magic"); err == nil {
+		t.Error("Get(magic) = nil, wanted an error")
+	}
+}
+
+func TestAlgorithmRegistryRegister(t *testing.T) {
+	r := NewAlgorithmRegistry()
+	if _, err := r.Get(""); err == nil {
+		t.Error("Get() on an empty registry = nil, wanted an error")
+	}
+
+	want := &fakeUniScaler{}
+	r.Register("fake", func(string, string, MetricClient, resources.ReadyPodCounter, DeciderSpec, StatsReporter) (UniScaler, error) {
+		return want, nil
+	})
+	factory, err := r.Get("fake")
+	if err != nil {
+		t.Fatalf("Get(fake) = %v", err)
+	}
+	if got, _ := factory(testNamespace, testRevision, nil, nil, DeciderSpec{}, nil); got != want {
+		t.Errorf("factory() = %v, want: %v", got, want)
+	}
+}
+
+func TestAlgorithmsNilArguments(t *testing.T) {
+	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, testService)
+	for _, name := range []string{autoscaling.PIDAlgorithm, autoscaling.PredictiveAlgorithm} {
+		t.Run(name, func(t *testing.T) {
+			factory, _ := NewDefaultAlgorithmRegistry().Get(name)
+			if _, err := factory(testNamespace, testRevision, &testMetricClient{}, nil, DeciderSpec{}, &mockReporter{}); err == nil {
+				t.Error("Expected error when podCounter is nil, but got none")
+			}
+			if _, err := factory(testNamespace, testRevision, &testMetricClient{}, podCounter, DeciderSpec{}, nil); err == nil {
+				t.Error("Expected error when reporter is nil, but got none")
+			}
+		})
+	}
+}
+
+func TestClampScale(t *testing.T) {
+	spec := DeciderSpec{MaxScaleUpRate: 10, MaxScaleDownRate: 2}
+	tests := []struct {
+		name     string
+		desired  float64
+		ready    float64
+		spec     *DeciderSpec
+		want     int32
+		wantUp   bool
+		wantDown bool
+	}{{
+		name:    "within limits",
+		desired: 15,
+		ready:   10,
+		want:    15,
+	}, {
+		name:    "clamped up",
+		desired: 150,
+		ready:   10,
+		want:    100,
+		wantUp:  true,
+	}, {
+		name:     "clamped down",
+		desired:  1,
+		ready:    10,
+		want:     5,
+		wantDown: true,
+	}, {
+		name:    "scale to zero from one pod",
+		desired: 0,
+		ready:   1,
+		want:    0,
+	}, {
+		name:    "no scale down rate",
+		desired: 1,
+		ready:   10,
+		spec:    &DeciderSpec{MaxScaleUpRate: 10},
+		want:    1,
+	}, {
+		name:    "scale down rate below one",
+		desired: 1,
+		ready:   10,
+		spec:    &DeciderSpec{MaxScaleUpRate: 10, MaxScaleDownRate: 0.5},
+		want:    1,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := spec
+			if test.spec != nil {
+				spec = *test.spec
+			}
+			got, up, down := clampScale(test.desired, test.ready, spec)
+			if got != test.want || up != test.wantUp || down != test.wantDown {
+				t.Errorf("clampScale() = (%d, %v, %v), want: (%d, %v, %v)",
+					got, up, down, test.want, test.wantUp, test.wantDown)
+			}
+		})
+	}
+}
+
+// newTestAlgorithm creates the named algorithm with the same spec as newTestAutoscaler.
+func newTestAlgorithm(t *testing.T, name string, targetValue float64, metrics MetricClient) UniScaler {
+	t.Helper()
+	deciderSpec := DeciderSpec{
+		Algorithm:           name,
+		ScalingMetric:       autoscaling.Concurrency,
+		TargetValue:         targetValue,
+		TotalValue:          targetValue / targetUtilization,
+		TargetBurstCapacity: 0,
+		PanicThreshold:      2 * targetValue,
+		MaxScaleUpRate:      10,
+		MaxScaleDownRate:    10,
+		StableWindow:        stableWindow,
+		ServiceName:         testService,
+	}
+	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, testService)
+	endpoints(1)
+	factory, err := NewDefaultAlgorithmRegistry().Get(name)
+	if err != nil {
+		t.Fatalf("Get(%q) = %v", name, err)
+	}
+	us, err := factory(testNamespace, testRevision, metrics, podCounter, deciderSpec, &mockReporter{})
+	if err != nil {
+		t.Fatalf("Error creating test %s scaler: %v", name, err)
+	}
+	return us
+}
+
+func expectAlgorithmScale(t *testing.T, us UniScaler, now time.Time, expectScale int32, expectOk bool) ScaleResult {
+	t.Helper()
+	sRes, ok := us.Scale(TestContextWithLogger(t), now)
+	if ok != expectOk {
+		t.Errorf("Unexpected autoscale decision. Expected %v. Got %v.", expectOk, ok)
+	}
+	if got, want := sRes.DesiredPodCount, expectScale; got != want {
+		t.Errorf("Scale %d, want: %d", got, want)
+	}
+	return sRes
+}
@@ -28,6 +28,7 @@ import (
 
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/resources"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -110,9 +111,9 @@ func (a *Autoscaler) Update(deciderSpec DeciderSpec) error {
 }
 
 // Scale calculates the desired scale based on current statistics given the current time.
-// desiredPodCount is the calculated pod count the autoscaler would like to set.
-// validScale signifies whether the desiredPodCount should be applied or not.
-func (a *Autoscaler) Scale(ctx context.Context, now time.Time) (desiredPodCount int32, excessBC int32, validScale bool) {
+// The returned ScaleResult holds the calculated pod count the autoscaler would like to set.
+// validScale signifies whether the ScaleResult should be applied or not.
+func (a *Autoscaler) Scale(ctx context.Context, now time.Time) (sRes ScaleResult, validScale bool) {
 	logger := logging.FromContext(ctx)
 
 	spec := a.currentSpec()
@@ -120,43 +121,45 @@ func (a *Autoscaler) Scale(ctx context.Context, now time.Time) (desiredPodCount
 	// If the error is NotFound, then presume 0.
 	if err != nil && !apierrors.IsNotFound(err) {
 		logger.Errorw("Failed to get Endpoints via K8S Lister", zap.Error(err))
-		return 0, 0, false
+		return ScaleResult{}, false
 	}
 	// Use 1 if there are zero current pods.
 	readyPodsCount := math.Max(1, float64(originalReadyPodsCount))
 
 	metricKey := types.NamespacedName{Namespace: a.namespace, Name: a.revision}
-	observedStableConcurrency, observedPanicConcurrency, err := a.metricClient.StableAndPanicConcurrency(metricKey, now)
+	metricName, observedStableValue, observedPanicValue, err := observedMetricValues(a.metricClient, spec, metricKey, now)
 	if err != nil {
 		if err == ErrNoData {
 			logger.Debug("No data to scale on yet")
 		} else {
 			logger.Errorw("Failed to obtain metrics", zap.Error(err))
 		}
-		return 0, 0, false
+		return ScaleResult{}, false
 	}
 
+	// We want to keep the desired pod count in the [maxScaleDown, maxScaleUp] range.
 	maxScaleUp := spec.MaxScaleUpRate * readyPodsCount
-	desiredStablePodCount := int32(math.Min(math.Ceil(observedStableConcurrency/spec.TargetConcurrency), maxScaleUp))
-	desiredPanicPodCount := int32(math.Min(math.Ceil(observedPanicConcurrency/spec.TargetConcurrency), maxScaleUp))
+	maxScaleDown := scaleDownLimit(readyPodsCount, spec.MaxScaleDownRate)
+	dspc := math.Ceil(observedStableValue / spec.TargetValue)
+	dppc := math.Ceil(observedPanicValue / spec.TargetValue)
+	desiredStablePodCount := int32(math.Min(math.Max(dspc, maxScaleDown), maxScaleUp))
+	desiredPanicPodCount := int32(math.Min(math.Max(dppc, maxScaleDown), maxScaleUp))
 
-	a.reporter.ReportStableRequestConcurrency(observedStableConcurrency)
-	a.reporter.ReportPanicRequestConcurrency(observedPanicConcurrency)
-	a.reporter.ReportTargetRequestConcurrency(spec.TargetConcurrency)
+	reportMetricValues(a.reporter, spec, observedStableValue, observedPanicValue)
 
-	logger.Debugw(fmt.Sprintf("Observed average %0.3f concurrency, targeting %0.3f.",
-		observedStableConcurrency, spec.TargetConcurrency),
-		zap.String("concurrency", "stable"))
-	logger.Debugw(fmt.Sprintf("Observed average %0.3f concurrency, targeting %0.3f.",
-		observedPanicConcurrency, spec.TargetConcurrency),
-		zap.String("concurrency", "panic"))
+	logger.Debugw(fmt.Sprintf("Observed average %0.3f %s, targeting %0.3f.",
+		observedStableValue, metricName, spec.TargetValue),
+		zap.String("mode", "stable"))
+	logger.Debugw(fmt.Sprintf("Observed average %0.3f %s, targeting %0.3f.",
+		observedPanicValue, metricName, spec.TargetValue),
+		zap.String("mode", "panic"))
 
-	isOverPanicThreshold := observedPanicConcurrency/readyPodsCount >= spec.PanicThreshold
+	isOverPanicThreshold := observedPanicValue/readyPodsCount >= spec.PanicThreshold
 
 	a.stateMux.Lock()
 	defer a.stateMux.Unlock()
 	if a.panicTime == nil && isOverPanicThreshold {
-		// Begin panicking when we cross the concurrency threshold in the panic window.
+		// Begin panicking when we cross the threshold in the panic window.
 		logger.Info("PANICKING")
 		a.panicTime = &now
 		a.reporter.ReportPanic(1)
@@ -168,6 +171,7 @@ func (a *Autoscaler) Scale(ctx context.Context, now time.Time) (desiredPodCount
 		a.reporter.ReportPanic(0)
 	}
 
+	var desiredPodCount int32
 	if a.panicTime != nil {
 		logger.Debug("Operating in panic mode.")
 		// We do not scale down while in panic mode. Only increases will be applied.
@@ -175,33 +179,34 @@ func (a *Autoscaler) Scale(ctx context.Context, now time.Time) (desiredPodCount
 			logger.Infof("Increasing pods from %v to %v.", originalReadyPodsCount, desiredPanicPodCount)
 			a.panicTime = &now
 			a.maxPanicPods = desiredPanicPodCount
+			sRes.ScaleUpClamped = dppc > maxScaleUp
 		}
 		desiredPodCount = a.maxPanicPods
 	} else {
 		logger.Debug("Operating in stable mode.")
 		desiredPodCount = desiredStablePodCount
+		sRes.ScaleUpClamped = dspc > maxScaleUp
+		sRes.ScaleDownClamped = dspc < maxScaleDown
 	}
-
-	// Compute the excess burst capacity based on stable concurrency for now, since we don't want to
-	// be making knee-jerk decisions about Activator in the request path. Negative EBC means
-	// that the deployment does not have enough capacity to serve the desired burst off hand.
-	// EBC = TotCapacity - Cur#ReqInFlight - TargetBurstCapacity
-	excessBC = int32(-1)
-	switch {
-	case a.deciderSpec.TargetBurstCapacity == 0:
-		excessBC = 0
-	case a.deciderSpec.TargetBurstCapacity >= 0:
-		excessBC = int32(math.Floor(float64(originalReadyPodsCount)*a.deciderSpec.TotalConcurrency - observedStableConcurrency -
-			a.deciderSpec.TargetBurstCapacity))
-		logger.Debugf("PodCount=%v TotalConc=%v ObservedStableConc=%v TargetBC=%v ExcessBC=%v",
-			originalReadyPodsCount,
-			a.deciderSpec.TotalConcurrency,
-			observedStableConcurrency, a.deciderSpec.TargetBurstCapacity, excessBC)
+	if sRes.ScaleUpClamped || sRes.ScaleDownClamped {
+		logger.Debugf("Desired pod count was clamped to %d by the scale rate limits [%v, %v].",
+			desiredPodCount, maxScaleDown, maxScaleUp)
 	}
+
+	excessBC := excessBurstCapacity(originalReadyPodsCount, observedStableValue, spec)
+	logger.Debugf("PodCount=%v TotalValue=%v ObservedStableValue=%v TargetBC=%v ExcessBC=%v",
+		originalReadyPodsCount, spec.TotalValue, observedStableValue, spec.TargetBurstCapacity, excessBC)
 	a.reporter.ReportExcessBurstCapacity(float64(excessBC))
 
 	a.reporter.ReportDesiredPodCount(int64(desiredPodCount))
-	return desiredPodCount, excessBC, true
+	sRes.DesiredPodCount = desiredPodCount
+	sRes.ExcessBurstCapacity = excessBC
+	sRes.ScalingMetric = metricName
+	sRes.ObservedStableValue = observedStableValue
+	sRes.ObservedPanicValue = observedPanicValue
+	sRes.ReadyPodCount = originalReadyPodsCount
+	sRes.InPanicMode = a.panicTime != nil
+	return sRes, true
 }
 
 func (a *Autoscaler) currentSpec() DeciderSpec {
@@ -209,3 +214,60 @@ func (a *Autoscaler) currentSpec() DeciderSpec {
 	defer a.specMux.RUnlock()
 	return a.deciderSpec
 }
+
+// observedMetricValues returns the name of the scaling metric of the spec alongside
+// its stable and panic window averages observed for the given key.
+func observedMetricValues(metricClient MetricClient, spec DeciderSpec, key types.NamespacedName,
+	now time.Time) (metricName string, stableValue, panicValue float64, err error) {
+	switch {
+	case spec.ScalingMetric == autoscaling.RPS:
+		stableValue, panicValue, err = metricClient.StableAndPanicRPS(key, now)
+		return autoscaling.RPS, stableValue, panicValue, err
+	case isCustomMetric(spec.ScalingMetric):
+		stableValue, panicValue, err = metricClient.StableAndPanicCustomMetric(key, now)
+		return spec.ScalingMetric, stableValue, panicValue, err
+	default:
+		// Concurrency is used by default.
+		stableValue, panicValue, err = metricClient.StableAndPanicConcurrency(key, now)
+		return autoscaling.Concurrency, stableValue, panicValue, err
+	}
+}
+
+// reportMetricValues reports the observed values and the target of the spec's scaling metric.
+func reportMetricValues(reporter StatsReporter, spec DeciderSpec, stableValue, panicValue float64) {
+	switch {
+	case spec.ScalingMetric == autoscaling.RPS:
+		reporter.ReportStableRPS(stableValue)
+		reporter.ReportPanicRPS(panicValue)
+		reporter.ReportTargetRPS(spec.TargetValue)
+	case isCustomMetric(spec.ScalingMetric):
+		reporter.ReportStableCustomMetric(stableValue)
+		reporter.ReportPanicCustomMetric(panicValue)
+		reporter.ReportTargetCustomMetric(spec.TargetValue)
+	default:
+		reporter.ReportStableRequestConcurrency(stableValue)
+		reporter.ReportPanicRequestConcurrency(panicValue)
+		reporter.ReportTargetRequestConcurrency(spec.TargetValue)
+	}
+}
+
+func isCustomMetric(metric string) bool {
+	_, ok := autoscaling.CustomMetricName(metric)
+	return ok
+}
+
+// excessBurstCapacity computes the excess burst capacity based on stable value for now,
+// since we don't want to be making knee-jerk decisions about Activator in the request path.
+// Negative EBC means that the deployment does not have enough capacity to serve the desired
+// burst off hand.
+// EBC = TotCapacity - Cur#ReqInFlight - TargetBurstCapacity
+func excessBurstCapacity(readyPodsCount int, observedStableValue float64, spec DeciderSpec) int32 {
+	switch {
+	case spec.TargetBurstCapacity == 0:
+		return 0
+	case spec.TargetBurstCapacity >= 0:
+		return int32(math.Floor(float64(readyPodsCount)*spec.TotalValue - observedStableValue -
+			spec.TargetBurstCapacity))
+	}
+	return -1
+}
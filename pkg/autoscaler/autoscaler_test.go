@@ -29,6 +29,7 @@ import (
 	kubeinformers "k8s.io/client-go/informers"
 	fakeK8s "k8s.io/client-go/kubernetes/fake"
 	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/resources"
 )
 
@@ -43,7 +44,7 @@ var (
 )
 
 func TestNewErrorWhenGivenNilReadyPodCounter(t *testing.T) {
-	_, err := New(testNamespace, testRevision, &testMetricClient{}, nil, DeciderSpec{TargetConcurrency: 10, ServiceName: testService}, &mockReporter{})
+	_, err := New(testNamespace, testRevision, &testMetricClient{}, nil, DeciderSpec{TargetValue: 10, ServiceName: testService}, &mockReporter{})
 	if err == nil {
 		t.Error("Expected error when ReadyPodCounter interface is nil, but got none.")
 	}
@@ -55,7 +56,7 @@ func TestNewErrorWhenGivenNilStatsReporter(t *testing.T) {
 	podCounter := resources.NewScopedEndpointsCounter(kubeInformer.Core().V1().Endpoints().Lister(), testNamespace, testService)
 
 	_, err := New(testNamespace, testRevision, &testMetricClient{}, podCounter,
-		DeciderSpec{TargetConcurrency: 10, ServiceName: testService}, reporter)
+		DeciderSpec{TargetValue: 10, ServiceName: testService}, reporter)
 	if err == nil {
 		t.Error("Expected error when EndpointsInformer interface is nil, but got none.")
 	}
@@ -139,12 +140,52 @@ func TestAutoscalerStableModeNoTrafficScaleToZero(t *testing.T) {
 	a.expectScale(t, time.Now(), 0, expectedEBC(10, 75, 0, 1), true)
 }
 
+func TestAutoscalerStableModeIncreaseWithRPS(t *testing.T) {
+	metrics := &testMetricClient{stableRPS: 50.0, stableConcurrency: 1000}
+	a := newTestAutoscalerWithScalingMetric(t, 10, 101, metrics, autoscaling.RPS)
+	a.expectScale(t, time.Now(), 5, expectedEBC(10, 101, 50, 1), true)
+
+	metrics.stableRPS = 100
+	a.expectScale(t, time.Now(), 10, expectedEBC(10, 101, 100, 1), true)
+}
+
+func TestAutoscalerPanicModeWithRPS(t *testing.T) {
+	metrics := &testMetricClient{stableRPS: 50, panicRPS: 100}
+	a := newTestAutoscalerWithScalingMetric(t, 10, 84, metrics, autoscaling.RPS)
+
+	// PanicRPS takes precedence.
+	a.expectScale(t, time.Now(), 10, expectedEBC(10, 84, 50, 1), true)
+}
+
+func TestAutoscalerStableModeIncreaseWithCustomMetric(t *testing.T) {
+	metrics := &testMetricClient{stableCustom: 50.0, panicCustom: 50.0, stableConcurrency: 1000}
+	a := newTestAutoscalerWithScalingMetric(t, 10, 101, metrics, "custom/queue_depth")
+	a.expectScale(t, time.Now(), 5, expectedEBC(10, 101, 50, 1), true)
+
+	metrics.stableCustom, metrics.panicCustom = 100, 100
+	a.expectScale(t, time.Now(), 10, expectedEBC(10, 101, 100, 1), true)
+}
+
 func TestAutoscalerPanicModeDoublePodCount(t *testing.T) {
 	metrics := &testMetricClient{stableConcurrency: 50, panicConcurrency: 100}
 	a := newTestAutoscaler(t, 10, 84, metrics)
 
 	// PanicConcurrency takes precedence.
-	a.expectScale(t, time.Now(), 10, expectedEBC(10, 84, 50, 1), true)
+	sRes := a.expectScale(t, time.Now(), 10, expectedEBC(10, 84, 50, 1), true)
+
+	// The observations the decision is based on are reported alongside.
+	want := ScaleResult{
+		DesiredPodCount:     10,
+		ExcessBurstCapacity: expectedEBC(10, 84, 50, 1),
+		ScalingMetric:       autoscaling.Concurrency,
+		ObservedStableValue: 50,
+		ObservedPanicValue:  100,
+		ReadyPodCount:       1,
+		InPanicMode:         true,
+	}
+	if sRes != want {
+		t.Errorf("Scale() = %#v, want: %#v", sRes, want)
+	}
 }
 
 // QPS is increasing exponentially. Each scaling event bring concurrency
@@ -194,13 +235,73 @@ func TestAutoscalerRateLimitScaleUp(t *testing.T) {
 	a := newTestAutoscaler(t, 10, 61, metrics)
 
 	// Need 100 pods but only scale x10
-	a.expectScale(t, time.Now(), 10, expectedEBC(10, 61, 1000, 1), true)
+	if sRes := a.expectScale(t, time.Now(), 10, expectedEBC(10, 61, 1000, 1), true); !sRes.ScaleUpClamped {
+		t.Error("ScaleUpClamped = false, want: true")
+	}
 
 	endpoints(10)
 	// Scale x10 again
 	a.expectScale(t, time.Now(), 100, expectedEBC(10, 61, 1000, 10), true)
 }
 
+func TestAutoscalerRateLimitScaleDown(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 1}
+	a := newTestAutoscaler(t, 10, 61, metrics)
+	endpoints(100)
+
+	// Need 1 pod but only scale /10.
+	if sRes := a.expectScale(t, time.Now(), 10, expectedEBC(10, 61, 1, 100), true); !sRes.ScaleDownClamped {
+		t.Error("ScaleDownClamped = false, want: true")
+	}
+
+	endpoints(10)
+	// Scale /10 again.
+	if sRes := a.expectScale(t, time.Now(), 1, expectedEBC(10, 61, 1, 10), true); sRes.ScaleDownClamped {
+		t.Error("ScaleDownClamped = true, want: false")
+	}
+}
+
+func TestAutoscalerNoMaxScaleDownRate(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 10}
+	a := newTestAutoscaler(t, 10, 61, metrics)
+	endpoints(200)
+
+	a.Update(DeciderSpec{
+		TargetValue:         10,
+		TotalValue:          10 / targetUtilization,
+		TargetBurstCapacity: 61,
+		PanicThreshold:      20,
+		MaxScaleUpRate:      10,
+		StableWindow:        stableWindow,
+		ServiceName:         testService,
+	})
+	// An unset rate doesn't limit scaling down.
+	if sRes := a.expectScale(t, time.Now(), 1, expectedEBC(10, 61, 10, 200), true); sRes.ScaleDownClamped {
+		t.Error("ScaleDownClamped = true, want: false")
+	}
+}
+
+func TestAutoscalerUpdateMaxScaleDownRate(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 10}
+	a := newTestAutoscaler(t, 10, 61, metrics)
+	endpoints(200)
+
+	a.Update(DeciderSpec{
+		TargetValue:         10,
+		TotalValue:          10 / targetUtilization,
+		TargetBurstCapacity: 61,
+		PanicThreshold:      20,
+		MaxScaleUpRate:      10,
+		MaxScaleDownRate:    2,
+		StableWindow:        stableWindow,
+		ServiceName:         testService,
+	})
+	// Need 1 pod, but only halve the revision.
+	if sRes := a.expectScale(t, time.Now(), 100, expectedEBC(10, 61, 10, 200), true); !sRes.ScaleDownClamped {
+		t.Error("ScaleDownClamped = false, want: true")
+	}
+}
+
 func eraseEndpoints() {
 	ep, _ := kubeClient.CoreV1().Endpoints(testNamespace).Get(testService, metav1.GetOptions{})
 	kubeClient.CoreV1().Endpoints(testNamespace).Delete(testService, nil)
@@ -229,11 +330,12 @@ func TestAutoscalerUpdateTarget(t *testing.T) {
 
 	endpoints(10)
 	a.Update(DeciderSpec{
-		TargetConcurrency:   1,
-		TotalConcurrency:    1 / targetUtilization,
+		TargetValue:         1,
+		TotalValue:          1 / targetUtilization,
 		TargetBurstCapacity: 71,
 		PanicThreshold:      2,
 		MaxScaleUpRate:      10,
+		MaxScaleDownRate:    10,
 		StableWindow:        stableWindow,
 		ServiceName:         testService,
 	})
@@ -272,6 +374,36 @@ func (r *mockReporter) ReportTargetRequestConcurrency(v float64) error {
 	return nil
 }
 
+// ReportStableRPS of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportStableRPS(v float64) error {
+	return nil
+}
+
+// ReportPanicRPS of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportPanicRPS(v float64) error {
+	return nil
+}
+
+// ReportTargetRPS of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportTargetRPS(v float64) error {
+	return nil
+}
+
+// ReportStableCustomMetric of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportStableCustomMetric(v float64) error {
+	return nil
+}
+
+// ReportPanicCustomMetric of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportPanicCustomMetric(v float64) error {
+	return nil
+}
+
+// ReportTargetCustomMetric of a mockReporter does nothing and return nil for error.
+func (r *mockReporter) ReportTargetCustomMetric(v float64) error {
+	return nil
+}
+
 // ReportPanic of a mockReporter does nothing and return nil for error.
 func (r *mockReporter) ReportPanic(v int64) error {
 	return nil
@@ -283,13 +415,20 @@ func (r *mockReporter) ReportExcessBurstCapacity(v float64) error {
 }
 
 func newTestAutoscaler(t *testing.T, targetConcurrency, targetBurstCapacity float64, metrics MetricClient) *Autoscaler {
+	t.Helper()
+	return newTestAutoscalerWithScalingMetric(t, targetConcurrency, targetBurstCapacity, metrics, autoscaling.Concurrency)
+}
+
+func newTestAutoscalerWithScalingMetric(t *testing.T, targetValue, targetBurstCapacity float64, metrics MetricClient, metric string) *Autoscaler {
 	t.Helper()
 	deciderSpec := DeciderSpec{
-		TargetConcurrency:   targetConcurrency,
-		TotalConcurrency:    targetConcurrency / targetUtilization, // For UTs presume 75% utilization
+		ScalingMetric:       metric,
+		TargetValue:         targetValue,
+		TotalValue:          targetValue / targetUtilization, // For UTs presume 75% utilization
 		TargetBurstCapacity: targetBurstCapacity,
-		PanicThreshold:      2 * targetConcurrency,
+		PanicThreshold:      2 * targetValue,
 		MaxScaleUpRate:      10.0,
+		MaxScaleDownRate:    10,
 		StableWindow:        stableWindow,
 		ServiceName:         testService,
 	}
@@ -305,23 +444,28 @@ func newTestAutoscaler(t *testing.T, targetConcurrency, targetBurstCapacity floa
 	return a
 }
 
-func (a *Autoscaler) expectScale(t *testing.T, now time.Time, expectScale, expectEBC int32, expectOk bool) {
+func (a *Autoscaler) expectScale(t *testing.T, now time.Time, expectScale, expectEBC int32, expectOk bool) ScaleResult {
 	t.Helper()
-	scale, ebc, ok := a.Scale(TestContextWithLogger(t), now)
+	sRes, ok := a.Scale(TestContextWithLogger(t), now)
 	if ok != expectOk {
 		t.Errorf("Unexpected autoscale decision. Expected %v. Got %v.", expectOk, ok)
 	}
-	if got, want := scale, expectScale; got != want {
+	if got, want := sRes.DesiredPodCount, expectScale; got != want {
 		t.Errorf("Scale %d, want: %d", got, want)
 	}
-	if got, want := ebc, expectEBC; got != want {
+	if got, want := sRes.ExcessBurstCapacity, expectEBC; got != want {
 		t.Errorf("ExcessBurstCapacity = %d, want: %d", got, want)
 	}
+	return sRes
 }
 
 type testMetricClient struct {
 	stableConcurrency float64
 	panicConcurrency  float64
+	stableRPS         float64
+	panicRPS          float64
+	stableCustom      float64
+	panicCustom       float64
 	err               error
 }
 
@@ -329,6 +473,14 @@ func (t *testMetricClient) StableAndPanicConcurrency(key types.NamespacedName, n
 	return t.stableConcurrency, t.panicConcurrency, t.err
 }
 
+func (t *testMetricClient) StableAndPanicRPS(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return t.stableRPS, t.panicRPS, t.err
+}
+
+func (t *testMetricClient) StableAndPanicCustomMetric(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return t.stableCustom, t.panicCustom, t.err
+}
+
 func endpoints(count int) {
 	epAddresses := make([]corev1.EndpointAddress, count)
 	for i := 0; i < count; i++ {
@@ -352,11 +504,12 @@ func endpoints(count int) {
 func TestStartInPanicMode(t *testing.T) {
 	metrics := &testMetricClient{}
 	deciderSpec := DeciderSpec{
-		TargetConcurrency:   100,
-		TotalConcurrency:    120,
+		TargetValue:         100,
+		TotalValue:          120,
 		TargetBurstCapacity: 11,
 		PanicThreshold:      220,
 		MaxScaleUpRate:      10.0,
+		MaxScaleDownRate:    10,
 		StableWindow:        stableWindow,
 		ServiceName:         testService,
 	}
@@ -394,11 +547,12 @@ func TestNewFail(t *testing.T) {
 	eraseEndpoints()
 	metrics := &testMetricClient{}
 	deciderSpec := DeciderSpec{
-		TargetConcurrency:   100,
-		TotalConcurrency:    120,
+		TargetValue:         100,
+		TotalValue:          120,
 		TargetBurstCapacity: 11,
 		PanicThreshold:      220,
 		MaxScaleUpRate:      10.0,
+		MaxScaleDownRate:    10,
 		StableWindow:        stableWindow,
 		ServiceName:         testService,
 	}
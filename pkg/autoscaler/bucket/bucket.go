@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Set is a fixed set of buckets the revision keyspace is split into.
+type Set struct {
+	names []string
+}
+
+// NewSet creates a Set of the given number of buckets.
+func NewSet(total uint32) *Set {
+	names := make([]string, total)
+	for i := range names {
+		names[i] = Name(uint32(i), total)
+	}
+	return &Set{names: names}
+}
+
+// Name returns the name of the bucket with the given ordinal in a Set of the
+// given number of buckets. It doubles as the name of the Lease of the bucket.
+func Name(ordinal, total uint32) string {
+	return fmt.Sprintf("autoscaler-bucket-%02d-of-%02d", ordinal, total)
+}
+
+// Names returns the names of all buckets of the Set.
+func (s *Set) Names() []string {
+	return s.names
+}
+
+// Bucket returns the name of the bucket the key belongs to.
+func (s *Set) Bucket(key types.NamespacedName) string {
+	h := fnv.New32a()
+	h.Write([]byte(key.String()))
+	return s.names[h.Sum32()%uint32(len(s.names))]
+}
+
+// Ownership tells which buckets this autoscaler replica leads and which
+// replicas lead the others.
+type Ownership interface {
+	// Bucket returns the name of the bucket the key belongs to.
+	Bucket(key types.NamespacedName) string
+	// IsLeader returns whether this replica leads the bucket.
+	IsLeader(bucket string) bool
+	// Holder returns the identity, i.e. the pod IP, of the replica leading
+	// the bucket or false if no leader is known.
+	Holder(bucket string) (string, bool)
+	// Watch registers a function to call when this replica starts or stops
+	// leading a bucket.
+	Watch(func())
+}
+
+// Owns returns whether this replica leads the bucket of the key.
+func Owns(o Ownership, key types.NamespacedName) bool {
+	return o.IsLeader(o.Bucket(key))
+}
+
+// singleOwnership is the Ownership of an autoscaler running without leader
+// election, which owns the whole keyspace.
+type singleOwnership struct{}
+
+// Bucket implements Ownership.
+func (singleOwnership) Bucket(types.NamespacedName) string { return Name(0, 1) }
+
+// IsLeader implements Ownership.
+func (singleOwnership) IsLeader(string) bool { return true }
+
+// Holder implements Ownership.
+func (singleOwnership) Holder(string) (string, bool) { return "", false }
+
+// Watch implements Ownership.
+func (singleOwnership) Watch(func()) {}
+
+type ownershipKey struct{}
+
+// WithOwnership attaches the Ownership to the context.
+func WithOwnership(ctx context.Context, o Ownership) context.Context {
+	return context.WithValue(ctx, ownershipKey{}, o)
+}
+
+// OwnershipFromContext returns the Ownership attached to the context, or one
+// owning the whole keyspace if there is none.
+func OwnershipFromContext(ctx context.Context) Ownership {
+	if o, ok := ctx.Value(ownershipKey{}).(Ownership); ok {
+		return o
+	}
+	return singleOwnership{}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestSet(t *testing.T) {
+	set := NewSet(3)
+	want := []string{
+		"autoscaler-bucket-00-of-03",
+		"autoscaler-bucket-01-of-03",
+		"autoscaler-bucket-02-of-03",
+	}
+	if got := set.Names(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	key := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	if got, want := set.Bucket(key), set.Bucket(key); got != want {
+		t.Errorf("Bucket() = %s, then %s, want stable buckets", got, want)
+	}
+
+	used := sets.NewString()
+	for i := 0; i < 100; i++ {
+		b := set.Bucket(types.NamespacedName{Namespace: "ns", Name: fmt.Sprintf("rev-%d", i)})
+		if !sets.NewString(want...).Has(b) {
+			t.Fatalf("Bucket() = %s, not in %v", b, want)
+		}
+		used.Insert(b)
+	}
+	if got, want := used.Len(), 3; got != want {
+		t.Errorf("Keys spread over %d buckets, want %d", got, want)
+	}
+
+	if got, want := NewSet(1).Bucket(key), Name(0, 1); got != want {
+		t.Errorf("Bucket() = %s, want %s", got, want)
+	}
+}
+
+func TestOwnershipFromContext(t *testing.T) {
+	key := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	if o := OwnershipFromContext(context.Background()); !Owns(o, key) {
+		t.Error("Without leader election the whole keyspace must be owned")
+	}
+
+	o := &fakeOwnership{}
+	if got := OwnershipFromContext(WithOwnership(context.Background(), o)); got != o {
+		t.Errorf("OwnershipFromContext() = %v, want %v", got, o)
+	}
+	if Owns(o, key) {
+		t.Error("Owns() = true, want false")
+	}
+	o.leading = true
+	if !Owns(o, key) {
+		t.Error("Owns() = false, want true")
+	}
+}
+
+type fakeOwnership struct {
+	leading bool
+	holder  string
+}
+
+func (o *fakeOwnership) Bucket(key types.NamespacedName) string { return key.String() }
+func (o *fakeOwnership) IsLeader(string) bool                   { return o.leading }
+func (o *fakeOwnership) Holder(string) (string, bool)           { return o.holder, o.holder != "" }
+func (o *fakeOwnership) Watch(func())                           {}
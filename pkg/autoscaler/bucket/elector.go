@@ -0,0 +1,427 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bucket
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationclient "k8s.io/client-go/kubernetes/typed/coordination/v1beta1"
+
+	"knative.dev/pkg/ptr"
+	"knative.dev/pkg/system"
+)
+
+const (
+	// LeaseDuration is how long other replicas wait after they last saw a
+	// lease renewed before they take it over.
+	LeaseDuration = 15 * time.Second
+	// RenewDeadline is how long the leader keeps leading while failing to
+	// renew its lease. It must be shorter than LeaseDuration so that a leader
+	// steps down before another replica takes over.
+	RenewDeadline = 10 * time.Second
+	// RetryPeriod is how often the leases are acquired or renewed.
+	RetryPeriod = 2 * time.Second
+
+	// memberLabelKey labels the member Leases, which announce the replicas
+	// taking part in the election.
+	memberLabelKey = "autoscaling.knative.dev/bucket-member"
+	// memberLeasePrefix prefixes the names of the member Leases.
+	memberLeasePrefix = "autoscaler-member-"
+)
+
+// Elector elects this replica as the leader of buckets of a Set. Each bucket
+// has a Lease, held by the replica leading the bucket. Besides, every replica
+// keeps a member Lease of its own, so that the replicas know how many of them
+// share the buckets. A replica leads at most its share of the buckets, i.e.
+// ceil(buckets/replicas), and gives up the buckets beyond it for newly started
+// replicas to take over. The buckets of a lost replica are taken over once
+// their leases expire.
+type Elector struct {
+	*Set
+	leases   coordinationclient.LeaseInterface
+	identity string
+	clock    system.Clock
+	logger   *zap.SugaredLogger
+
+	// mux guards the records, the members and the watchers.
+	mux      sync.RWMutex
+	records  map[string]*leaseRecord
+	members  map[string]*leaseRecord
+	watchers []func()
+}
+
+// leaseRecord is what the Elector knows about the lease of a bucket.
+type leaseRecord struct {
+	holder string
+	// renewTime is the last renew time seen in the lease and observedTime the
+	// local time it was seen at. Expiry is judged with the local clock only,
+	// so that clock skew between the replicas does not matter.
+	renewTime    time.Time
+	observedTime time.Time
+	duration     time.Duration
+
+	leading bool
+	// renewed is when this replica last renewed the lease.
+	renewed time.Time
+}
+
+var _ Ownership = (*Elector)(nil)
+
+// NewElector creates an Elector for the buckets of the set, keeping their
+// leases with the given client. The identity, typically the pod IP, must be
+// unique among the replicas and is where other replicas forward statistics to.
+func NewElector(set *Set, leases coordinationclient.LeaseInterface, identity string, logger *zap.SugaredLogger) *Elector {
+	return &Elector{
+		Set:      set,
+		leases:   leases,
+		identity: identity,
+		clock:    system.RealClock{},
+		logger:   logger.Named("bucket-elector").With(zap.String("identity", identity)),
+		records:  make(map[string]*leaseRecord, len(set.Names())),
+		members:  make(map[string]*leaseRecord),
+	}
+}
+
+// memberLeaseName returns the name of the member Lease of the replica with the
+// given identity. Colons of IPv6 addresses are not allowed in names.
+func memberLeaseName(identity string) string {
+	return memberLeasePrefix + strings.Replace(identity, ":", "-", -1)
+}
+
+// Run acquires and renews the leases of the buckets until the stopCh is
+// closed. It then releases the leases this replica holds so that other
+// replicas take over without waiting for the leases to expire.
+func (e *Elector) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(RetryPeriod)
+	defer ticker.Stop()
+	for {
+		e.tryAcquireOrRenewAll()
+		select {
+		case <-stopCh:
+			e.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsLeader implements Ownership.
+func (e *Elector) IsLeader(bucket string) bool {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	r, ok := e.records[bucket]
+	return ok && r.leading
+}
+
+// Holder implements Ownership.
+func (e *Elector) Holder(bucket string) (string, bool) {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	r, ok := e.records[bucket]
+	if !ok || r.holder == "" || r.expired(e.clock.Now()) {
+		return "", false
+	}
+	return r.holder, true
+}
+
+// Watch implements Ownership.
+func (e *Elector) Watch(fn func()) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.watchers = append(e.watchers, fn)
+}
+
+func (e *Elector) tryAcquireOrRenewAll() {
+	now := e.clock.Now()
+	e.renewMembership(now)
+	quota := e.quota(now)
+
+	changed, held := false, 0
+	for _, bucket := range e.Names() {
+		if e.IsLeader(bucket) && held >= quota {
+			// Give up the buckets beyond this replica's share.
+			if e.releaseBucket(bucket) {
+				changed = true
+			}
+			continue
+		}
+		if e.tryAcquireOrRenew(bucket, held < quota) {
+			changed = true
+		}
+		if e.IsLeader(bucket) {
+			held++
+		}
+	}
+	if changed {
+		e.notify()
+	}
+}
+
+// quota returns how many buckets this replica may lead, i.e. its share of the
+// buckets among the live members.
+func (e *Elector) quota(now time.Time) int {
+	members := e.liveMembers(now)
+	return (len(e.Names()) + members - 1) / members
+}
+
+// renewMembership creates or renews the member Lease of this replica.
+func (e *Elector) renewMembership(now time.Time) {
+	name := memberLeaseName(e.identity)
+	lease, err := e.leases.Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = e.leases.Create(&coordinationv1beta1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{memberLabelKey: "true"},
+			},
+			Spec: coordinationv1beta1.LeaseSpec{
+				HolderIdentity:       ptr.String(e.identity),
+				LeaseDurationSeconds: ptr.Int32(int32(LeaseDuration.Seconds())),
+				AcquireTime:          &metav1.MicroTime{Time: now},
+				RenewTime:            &metav1.MicroTime{Time: now},
+			},
+		})
+	} else if err == nil {
+		lease = lease.DeepCopy()
+		lease.Spec.HolderIdentity = ptr.String(e.identity)
+		lease.Spec.LeaseDurationSeconds = ptr.Int32(int32(LeaseDuration.Seconds()))
+		lease.Spec.RenewTime = &metav1.MicroTime{Time: now}
+		_, err = e.leases.Update(lease)
+	}
+	if err != nil {
+		e.logger.Warnw("Failed to renew member lease", zap.Error(err))
+	}
+}
+
+// liveMembers returns the number of replicas whose member Lease has not
+// expired, counting this replica in any case.
+func (e *Elector) liveMembers(now time.Time) int {
+	list, err := e.leases.List(metav1.ListOptions{LabelSelector: memberLabelKey})
+
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if err != nil {
+		e.logger.Warnw("Failed to list member leases", zap.Error(err))
+	} else {
+		seen := make(map[string]bool, len(list.Items))
+		for i := range list.Items {
+			lease := &list.Items[i]
+			seen[lease.Name] = true
+			r, ok := e.members[lease.Name]
+			if !ok {
+				r = &leaseRecord{}
+				e.members[lease.Name] = r
+			}
+			r.update(lease, now)
+		}
+		for name := range e.members {
+			if !seen[name] {
+				delete(e.members, name)
+			}
+		}
+	}
+
+	self := memberLeaseName(e.identity)
+	live := 1
+	for name, r := range e.members {
+		if name != self && !r.expired(now) {
+			live++
+		}
+	}
+	return live
+}
+
+// tryAcquireOrRenew renews the lease of the bucket if this replica holds it,
+// or acquires it if acquire is set and no other replica holds it. It returns
+// whether this replica started or stopped leading.
+func (e *Elector) tryAcquireOrRenew(bucket string, acquire bool) bool {
+	now := e.clock.Now()
+	lease, err := e.leases.Get(bucket, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if !acquire {
+			return e.observe(bucket, nil, nil, now)
+		}
+		lease, err = e.leases.Create(&coordinationv1beta1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: bucket},
+			Spec: coordinationv1beta1.LeaseSpec{
+				HolderIdentity:       ptr.String(e.identity),
+				LeaseDurationSeconds: ptr.Int32(int32(LeaseDuration.Seconds())),
+				AcquireTime:          &metav1.MicroTime{Time: now},
+				RenewTime:            &metav1.MicroTime{Time: now},
+				LeaseTransitions:     ptr.Int32(0),
+			},
+		})
+		return e.observe(bucket, lease, err, now)
+	} else if err != nil {
+		return e.observe(bucket, nil, err, now)
+	}
+
+	if holder := holderOf(lease); holder != e.identity && (!acquire || holder != "" && !e.expired(bucket, lease, now)) {
+		return e.observe(bucket, lease, nil, now)
+	}
+
+	lease = lease.DeepCopy()
+	if holderOf(lease) != e.identity {
+		lease.Spec.HolderIdentity = ptr.String(e.identity)
+		lease.Spec.AcquireTime = &metav1.MicroTime{Time: now}
+		transitions := int32(0)
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions
+		}
+		lease.Spec.LeaseTransitions = ptr.Int32(transitions + 1)
+	}
+	lease.Spec.LeaseDurationSeconds = ptr.Int32(int32(LeaseDuration.Seconds()))
+	lease.Spec.RenewTime = &metav1.MicroTime{Time: now}
+	// A concurrent update by another replica fails with a conflict.
+	lease, err = e.leases.Update(lease)
+	return e.observe(bucket, lease, err, now)
+}
+
+// expired returns whether the lease has not been renewed for its duration.
+func (e *Elector) expired(bucket string, lease *coordinationv1beta1.Lease, now time.Time) bool {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+	r, ok := e.records[bucket]
+	if !ok || r.holder != holderOf(lease) || !r.renewTime.Equal(renewTimeOf(lease)) {
+		// The lease changed since we last saw it, so it was just renewed.
+		return false
+	}
+	return r.expired(now)
+}
+
+// observe records the outcome of an attempt to acquire or renew the lease of
+// the bucket and returns whether this replica started or stopped leading.
+func (e *Elector) observe(bucket string, lease *coordinationv1beta1.Lease, err error, now time.Time) bool {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	r, ok := e.records[bucket]
+	if !ok {
+		r = &leaseRecord{}
+		e.records[bucket] = r
+	}
+	wasLeading := r.leading
+
+	if err != nil {
+		e.logger.Warnw("Failed to acquire or renew lease", zap.String("bucket", bucket), zap.Error(err))
+		// The error may be transient, so keep leading until the renew deadline.
+		if r.leading && now.Sub(r.renewed) >= RenewDeadline {
+			r.leading = false
+		}
+	} else if lease == nil {
+		// The lease does not exist and this replica is not to acquire it.
+		r.holder, r.leading = "", false
+	} else {
+		r.update(lease, now)
+		r.leading = r.holder == e.identity
+		if r.leading {
+			r.renewed = now
+		}
+	}
+
+	if r.leading != wasLeading {
+		if r.leading {
+			e.logger.Infof("Started leading bucket %s", bucket)
+		} else {
+			e.logger.Infof("Stopped leading bucket %s", bucket)
+		}
+		return true
+	}
+	return false
+}
+
+// release gives up the leases held by this replica and its membership.
+func (e *Elector) release() {
+	for _, bucket := range e.Names() {
+		if e.IsLeader(bucket) {
+			e.releaseBucket(bucket)
+		}
+	}
+	if err := e.leases.Delete(memberLeaseName(e.identity), &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		e.logger.Warnw("Failed to delete member lease", zap.Error(err))
+	}
+}
+
+// releaseBucket gives up the lease of the bucket so that another replica takes
+// over without waiting for the lease to expire. It returns whether this
+// replica stopped leading.
+func (e *Elector) releaseBucket(bucket string) bool {
+	lease, err := e.leases.Get(bucket, metav1.GetOptions{})
+	if err == nil && holderOf(lease) == e.identity {
+		lease = lease.DeepCopy()
+		lease.Spec.HolderIdentity = ptr.String("")
+		lease.Spec.LeaseDurationSeconds = ptr.Int32(1)
+		_, err = e.leases.Update(lease)
+	}
+	if err != nil {
+		e.logger.Warnw("Failed to release lease", zap.String("bucket", bucket), zap.Error(err))
+	}
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	r := e.records[bucket]
+	r.holder = ""
+	if !r.leading {
+		return false
+	}
+	r.leading = false
+	e.logger.Infof("Stopped leading bucket %s", bucket)
+	return true
+}
+
+func (e *Elector) notify() {
+	e.mux.RLock()
+	watchers := make([]func(), len(e.watchers))
+	copy(watchers, e.watchers)
+	e.mux.RUnlock()
+	for _, fn := range watchers {
+		fn()
+	}
+}
+
+// update records the holder and the renew time of the lease, if they changed.
+func (r *leaseRecord) update(lease *coordinationv1beta1.Lease, now time.Time) {
+	if holder, renewTime := holderOf(lease), renewTimeOf(lease); holder != r.holder || !renewTime.Equal(r.renewTime) {
+		r.holder, r.renewTime, r.observedTime = holder, renewTime, now
+	}
+	r.duration = LeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		r.duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+}
+
+func (r *leaseRecord) expired(now time.Time) bool {
+	return now.Sub(r.observedTime) > r.duration
+}
+
+func holderOf(lease *coordinationv1beta1.Lease) string {
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+func renewTimeOf(lease *coordinationv1beta1.Lease) time.Time {
+	if lease.Spec.RenewTime == nil {
+		return time.Time{}
+	}
+	return lease.Spec.RenewTime.Time
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bucket
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+const testNamespace = "knative-serving"
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestElector(t *testing.T, client *fakek8s.Clientset, set *Set, identity string, clock *fakeClock) *Elector {
+	e := NewElector(set, client.CoordinationV1beta1().Leases(testNamespace), identity, TestLogger(t))
+	e.clock = clock
+	return e
+}
+
+func TestElectorAcquireAndTakeOver(t *testing.T) {
+	client := fakek8s.NewSimpleClientset()
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	set := NewSet(2)
+	a := newTestElector(t, client, set, "10.0.0.1", clock)
+	b := newTestElector(t, client, set, "10.0.0.2", clock)
+
+	var aChanges, bChanges int
+	a.Watch(func() { aChanges++ })
+	b.Watch(func() { bChanges++ })
+
+	a.tryAcquireOrRenewAll()
+	b.tryAcquireOrRenewAll()
+	for _, bucket := range set.Names() {
+		if !a.IsLeader(bucket) {
+			t.Errorf("a.IsLeader(%s) = false, want true", bucket)
+		}
+		if b.IsLeader(bucket) {
+			t.Errorf("b.IsLeader(%s) = true, want false", bucket)
+		}
+		if got, ok := b.Holder(bucket); !ok || got != "10.0.0.1" {
+			t.Errorf("b.Holder(%s) = %q, %v, want 10.0.0.1", bucket, got, ok)
+		}
+		lease, err := client.CoordinationV1beta1().Leases(testNamespace).Get(bucket, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Get(%s) = %v", bucket, err)
+		}
+		if got, want := holderOf(lease), "10.0.0.1"; got != want {
+			t.Errorf("Lease holder = %q, want %q", got, want)
+		}
+	}
+	if aChanges != 1 || bChanges != 0 {
+		t.Errorf("Watch calls = %d, %d, want 1, 0", aChanges, bChanges)
+	}
+
+	// a sees b joining and gives up the bucket beyond its share for b.
+	a.tryAcquireOrRenewAll()
+	b.tryAcquireOrRenewAll()
+	assertBalanced(t, set, a, b)
+	if aChanges != 2 || bChanges != 1 {
+		t.Errorf("Watch calls = %d, %d, want 2, 1", aChanges, bChanges)
+	}
+
+	// a keeps renewing, so b never takes over.
+	for i := 0; i < 10; i++ {
+		clock.now = clock.now.Add(RetryPeriod)
+		a.tryAcquireOrRenewAll()
+		b.tryAcquireOrRenewAll()
+	}
+	if b.IsLeader(set.Names()[0]) {
+		t.Error("b took over a renewed lease")
+	}
+	assertBalanced(t, set, a, b)
+
+	// a is lost, b takes over once the leases expire.
+	clock.now = clock.now.Add(LeaseDuration / 2)
+	b.tryAcquireOrRenewAll()
+	if b.IsLeader(set.Names()[0]) {
+		t.Error("b took over an unexpired lease")
+	}
+	clock.now = clock.now.Add(LeaseDuration)
+	b.tryAcquireOrRenewAll()
+	for _, bucket := range set.Names() {
+		if !b.IsLeader(bucket) {
+			t.Errorf("b.IsLeader(%s) = false, want true", bucket)
+		}
+		lease, err := client.CoordinationV1beta1().Leases(testNamespace).Get(bucket, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Get(%s) = %v", bucket, err)
+		}
+		if got, want := *lease.Spec.LeaseTransitions, int32(1); got != want {
+			t.Errorf("LeaseTransitions = %d, want %d", got, want)
+		}
+	}
+	if bChanges != 2 {
+		t.Errorf("b Watch calls = %d, want 2", bChanges)
+	}
+
+	// a comes back and steps down.
+	a.tryAcquireOrRenewAll()
+	for _, bucket := range set.Names() {
+		if a.IsLeader(bucket) {
+			t.Errorf("a.IsLeader(%s) = true, want false", bucket)
+		}
+	}
+	if aChanges != 3 {
+		t.Errorf("a Watch calls = %d, want 3", aChanges)
+	}
+
+	// b sees a again and hands a bucket back.
+	b.tryAcquireOrRenewAll()
+	a.tryAcquireOrRenewAll()
+	assertBalanced(t, set, a, b)
+}
+
+// assertBalanced asserts that every bucket is led by exactly one of the
+// electors, and that every elector leads the same number of buckets.
+func assertBalanced(t *testing.T, set *Set, electors ...*Elector) {
+	t.Helper()
+	led := make([]int, len(electors))
+	for _, bucket := range set.Names() {
+		leaders := 0
+		for i, e := range electors {
+			if e.IsLeader(bucket) {
+				leaders++
+				led[i]++
+			}
+		}
+		if leaders != 1 {
+			t.Errorf("Bucket %s has %d leaders, want 1", bucket, leaders)
+		}
+	}
+	for i := range led {
+		if led[i] != led[0] {
+			t.Errorf("Buckets led = %v, want an equal share", led)
+			return
+		}
+	}
+}
+
+func TestElectorQuota(t *testing.T) {
+	client := fakek8s.NewSimpleClientset()
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	set := NewSet(6)
+	electors := []*Elector{
+		newTestElector(t, client, set, "10.0.0.1", clock),
+		newTestElector(t, client, set, "10.0.0.2", clock),
+		newTestElector(t, client, set, "fd00::3", clock),
+	}
+	// All replicas announce their membership before electing.
+	for _, e := range electors {
+		e.renewMembership(clock.now)
+	}
+	for i := 0; i < 2; i++ {
+		for _, e := range electors {
+			e.tryAcquireOrRenewAll()
+		}
+	}
+	assertBalanced(t, set, electors...)
+
+	// A replica leaving hands its buckets to the others.
+	electors[2].release()
+	if _, err := client.CoordinationV1beta1().Leases(testNamespace).Get(memberLeaseName("fd00::3"), metav1.GetOptions{}); err == nil {
+		t.Error("The member lease was not deleted on release")
+	}
+	for _, e := range electors[:2] {
+		e.tryAcquireOrRenewAll()
+	}
+	assertBalanced(t, set, electors[:2]...)
+}
+
+func TestElectorRenewDeadline(t *testing.T) {
+	client := fakek8s.NewSimpleClientset()
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	set := NewSet(1)
+	bucket := set.Names()[0]
+	e := newTestElector(t, client, set, "10.0.0.1", clock)
+
+	e.tryAcquireOrRenewAll()
+	if !e.IsLeader(bucket) {
+		t.Fatal("IsLeader() = false, want true")
+	}
+
+	client.PrependReactor("*", "leases", func(clientgotesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("api server unavailable")
+	})
+	clock.now = clock.now.Add(RenewDeadline / 2)
+	e.tryAcquireOrRenewAll()
+	if !e.IsLeader(bucket) {
+		t.Error("IsLeader() = false before the renew deadline, want true")
+	}
+	clock.now = clock.now.Add(RenewDeadline / 2)
+	e.tryAcquireOrRenewAll()
+	if e.IsLeader(bucket) {
+		t.Error("IsLeader() = true after the renew deadline, want false")
+	}
+}
+
+func TestElectorRelease(t *testing.T) {
+	client := fakek8s.NewSimpleClientset()
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	set := NewSet(1)
+	bucket := set.Names()[0]
+	a := newTestElector(t, client, set, "10.0.0.1", clock)
+	b := newTestElector(t, client, set, "10.0.0.2", clock)
+
+	stopCh := make(chan struct{})
+	close(stopCh)
+	// Acquires the lease and releases it right away.
+	a.Run(stopCh)
+	if a.IsLeader(bucket) {
+		t.Error("IsLeader() = true after release, want false")
+	}
+
+	b.tryAcquireOrRenewAll()
+	if !b.IsLeader(bucket) {
+		t.Error("b did not take over a released lease")
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bucket
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/websocket"
+	"knative.dev/serving/pkg/autoscaler"
+)
+
+// statSender sends stat messages to another replica.
+type statSender interface {
+	Send(interface{}) error
+	Shutdown() error
+}
+
+// Forwarder passes the stat messages of the buckets led by this replica on
+// to be processed locally and forwards all others to the replica leading
+// their bucket.
+type Forwarder struct {
+	ownership Ownership
+	port      int
+	accept    func(*autoscaler.StatMessage)
+	logger    *zap.SugaredLogger
+
+	// newSender creates a statSender for the given target.
+	newSender func(target string, logger *zap.SugaredLogger) statSender
+
+	// mux guards the senders.
+	mux sync.Mutex
+	// senders holds the connection to the leader of each bucket.
+	senders map[string]*forwardSender
+}
+
+type forwardSender struct {
+	holder string
+	sender statSender
+}
+
+// NewForwarder creates a Forwarder which calls accept for the stat messages
+// of the buckets this replica leads and forwards all others to the stat
+// server of the replica leading their bucket, listening on the given port.
+func NewForwarder(ownership Ownership, port int, accept func(*autoscaler.StatMessage), logger *zap.SugaredLogger) *Forwarder {
+	return &Forwarder{
+		ownership: ownership,
+		port:      port,
+		accept:    accept,
+		logger:    logger.Named("stat-forwarder"),
+		newSender: func(target string, logger *zap.SugaredLogger) statSender {
+			return websocket.NewDurableSendingConnection(target, logger)
+		},
+		senders: make(map[string]*forwardSender),
+	}
+}
+
+// Process accepts or forwards the stat message.
+func (f *Forwarder) Process(sm *autoscaler.StatMessage) {
+	bucket := f.ownership.Bucket(sm.Key)
+	if f.ownership.IsLeader(bucket) {
+		f.accept(sm)
+		return
+	}
+
+	holder, ok := f.ownership.Holder(bucket)
+	if !ok {
+		// Dropping is fine, the leader will scrape the stats soon enough.
+		f.logger.Debugf("Dropping stat message for %v, bucket %s has no leader", sm.Key, bucket)
+		return
+	}
+	if err := f.sender(bucket, holder).Send(sm); err != nil {
+		f.logger.Warnw(fmt.Sprintf("Failed to forward stat message for %v to %s", sm.Key, holder), zap.Error(err))
+	}
+}
+
+// sender returns the connection to the holder of the bucket, replacing the
+// connection to a previous holder.
+func (f *Forwarder) sender(bucket, holder string) statSender {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if s, ok := f.senders[bucket]; ok {
+		if s.holder == holder {
+			return s.sender
+		}
+		go s.sender.Shutdown()
+	}
+	target := fmt.Sprintf("ws://%s:%d", holder, f.port)
+	f.logger.Infof("Forwarding stat messages of bucket %s to %s", bucket, target)
+	s := &forwardSender{holder: holder, sender: f.newSender(target, f.logger)}
+	f.senders[bucket] = s
+	return s.sender
+}
+
+// Shutdown closes the connections to the other replicas.
+func (f *Forwarder) Shutdown() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for bucket, s := range f.senders {
+		s.sender.Shutdown()
+		delete(f.senders, bucket)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bucket
+
+import (
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/autoscaler"
+)
+
+type fakeSender struct {
+	mux      sync.Mutex
+	target   string
+	sent     []interface{}
+	shutdown bool
+}
+
+func (s *fakeSender) Send(msg interface{}) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func (s *fakeSender) Shutdown() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.shutdown = true
+	return nil
+}
+
+func (s *fakeSender) isShutdown() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.shutdown
+}
+
+func TestForwarder(t *testing.T) {
+	ownership := &fakeOwnership{leading: true}
+	var accepted []*autoscaler.StatMessage
+	f := NewForwarder(ownership, 8080, func(sm *autoscaler.StatMessage) {
+		accepted = append(accepted, sm)
+	}, TestLogger(t))
+	senders := map[string]*fakeSender{}
+	f.newSender = func(target string, _ *zap.SugaredLogger) statSender {
+		s := &fakeSender{target: target}
+		senders[target] = s
+		return s
+	}
+
+	sm := &autoscaler.StatMessage{
+		Key:  types.NamespacedName{Namespace: "ns", Name: "rev"},
+		Stat: autoscaler.Stat{PodName: "pod", AverageConcurrentRequests: 1},
+	}
+
+	// Stats of a bucket led by this replica are accepted.
+	f.Process(sm)
+	if len(accepted) != 1 || accepted[0] != sm {
+		t.Errorf("Accepted = %v, want %v", accepted, sm)
+	}
+
+	// Stats of a bucket without leader are dropped.
+	ownership.leading = false
+	f.Process(sm)
+	if len(accepted) != 1 || len(senders) != 0 {
+		t.Errorf("Accepted = %v, senders = %v, want the stat message dropped", accepted, senders)
+	}
+
+	// Stats of a bucket led by another replica are forwarded.
+	ownership.holder = "10.0.0.1"
+	f.Process(sm)
+	f.Process(sm)
+	first, ok := senders["ws://10.0.0.1:8080"]
+	if !ok {
+		t.Fatalf("Senders = %v, want one to ws://10.0.0.1:8080", senders)
+	}
+	if got, want := len(first.sent), 2; got != want {
+		t.Errorf("Forwarded %d stat messages, want %d", got, want)
+	}
+
+	// The connection follows the leader of the bucket.
+	ownership.holder = "10.0.0.2"
+	f.Process(sm)
+	second, ok := senders["ws://10.0.0.2:8080"]
+	if !ok {
+		t.Fatalf("Senders = %v, want one to ws://10.0.0.2:8080", senders)
+	}
+	if got, want := len(second.sent), 1; got != want {
+		t.Errorf("Forwarded %d stat messages, want %d", got, want)
+	}
+
+	f.Shutdown()
+	if !second.isShutdown() {
+		t.Error("Shutdown() did not close the connection")
+	}
+	if len(accepted) != 1 {
+		t.Errorf("Accepted = %v, want only the first stat message", accepted)
+	}
+}
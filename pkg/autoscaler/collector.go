@@ -68,6 +68,13 @@ type Stat struct {
 
 	// Part of RequestCount, for requests going through a proxy.
 	ProxiedRequestCount float64
+
+	// Value of the custom metric the user container reports, if declared.
+	CustomMetric float64
+
+	// Average number of streams, e.g. WebSocket connections or server-sent
+	// events, currently being handled by this pod.
+	AverageConcurrentStreams float64
 }
 
 // StatMessage wraps a Stat with identifying information so it can be routed
@@ -93,6 +100,14 @@ type MetricClient interface {
 	// StableAndPanicConcurrency returns both the stable and the panic concurrency
 	// for the given replica as of the given time.
 	StableAndPanicConcurrency(key types.NamespacedName, now time.Time) (float64, float64, error)
+
+	// StableAndPanicRPS returns both the stable and the panic RPS
+	// for the given replica as of the given time.
+	StableAndPanicRPS(key types.NamespacedName, now time.Time) (float64, float64, error)
+
+	// StableAndPanicCustomMetric returns both the stable and the panic value
+	// of the custom metric for the given replica as of the given time.
+	StableAndPanicCustomMetric(key types.NamespacedName, now time.Time) (float64, float64, error)
 }
 
 // MetricCollector manages collection of metrics for many entities.
@@ -103,6 +118,10 @@ type MetricCollector struct {
 
 	collections      map[types.NamespacedName]*collection
 	collectionsMutex sync.RWMutex
+
+	// restored holds the snapshots loaded from a SnapshotStore until the
+	// collections they belong to are created. Guarded by the collectionsMutex.
+	restored map[types.NamespacedName]*CollectionSnapshot
 }
 
 var _ Collector = (*MetricCollector)(nil)
@@ -148,7 +167,13 @@ func (c *MetricCollector) CreateOrUpdate(metric *av1alpha1.Metric) error {
 		return nil
 	}
 
-	c.collections[key] = newCollection(metric, scraper, c.logger)
+	collection = newCollection(metric, scraper, c.logger)
+	if snapshot, ok := c.restored[key]; ok {
+		c.logger.Debugf("Restoring metric buckets of %s", key)
+		collection.restore(snapshot, time.Now())
+		delete(c.restored, key)
+	}
+	c.collections[key] = collection
 	return nil
 }
 
@@ -191,14 +216,143 @@ func (c *MetricCollector) StableAndPanicConcurrency(key types.NamespacedName, no
 	return collection.stableAndPanicConcurrency(now)
 }
 
+// StableAndPanicRPS returns both the stable and the panic RPS.
+// It may truncate metric buckets as a side-effect.
+func (c *MetricCollector) StableAndPanicRPS(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return 0, 0, ErrNotScraping
+	}
+
+	return collection.stableAndPanicRPS(now)
+}
+
+// StableAndPanicCustomMetric returns both the stable and the panic value of
+// the custom metric. It may truncate metric buckets as a side-effect.
+func (c *MetricCollector) StableAndPanicCustomMetric(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return 0, 0, ErrNotScraping
+	}
+
+	return collection.stableAndPanicCustomMetric(now)
+}
+
+// Snapshot returns a copy of the metric buckets of all collections.
+func (c *MetricCollector) Snapshot(now time.Time) *CollectorSnapshot {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	snapshot := &CollectorSnapshot{
+		Time:        now,
+		Collections: make([]CollectionSnapshot, 0, len(c.collections)),
+	}
+	for key, collection := range c.collections {
+		snapshot.Collections = append(snapshot.Collections, CollectionSnapshot{
+			Namespace:   key.Namespace,
+			Name:        key.Name,
+			Concurrency: collection.concurrencyBuckets.Snapshot(),
+			RPS:         collection.rpsBuckets.Snapshot(),
+			Custom:      collection.customBuckets.Snapshot(),
+		})
+	}
+	return snapshot
+}
+
+// SnapshotOwner assigns the collections to the buckets their snapshots are
+// saved in and tells which buckets this replica saves. It is implemented by
+// bucket.Ownership.
+type SnapshotOwner interface {
+	// Bucket returns the name of the bucket the key belongs to.
+	Bucket(key types.NamespacedName) string
+	// IsLeader returns whether this replica leads the bucket.
+	IsLeader(bucket string) bool
+}
+
+// Restore loads the last snapshots of the given buckets from the store. The
+// buckets are restored into the collections as they get created, skipping the
+// buckets which fell out of the collection's stable window in the meantime.
+// If a collection is found in several snapshots, the newest one wins.
+func (c *MetricCollector) Restore(store SnapshotStore, buckets []string) error {
+	restored := make(map[types.NamespacedName]*CollectionSnapshot)
+	taken := make(map[types.NamespacedName]time.Time)
+	for _, b := range buckets {
+		snapshot, err := store.Load(b)
+		if err != nil {
+			return err
+		}
+		if snapshot == nil {
+			continue
+		}
+		for i := range snapshot.Collections {
+			cs := &snapshot.Collections[i]
+			key := types.NamespacedName{Namespace: cs.Namespace, Name: cs.Name}
+			if t, ok := taken[key]; ok && t.After(snapshot.Time) {
+				continue
+			}
+			restored[key] = cs
+			taken[key] = snapshot.Time
+		}
+		c.logger.Infof("Loaded metric snapshot of bucket %s with %d collections taken at %v",
+			b, len(snapshot.Collections), snapshot.Time)
+	}
+
+	c.collectionsMutex.Lock()
+	defer c.collectionsMutex.Unlock()
+	c.restored = restored
+	return nil
+}
+
+// PersistSnapshots saves a snapshot of every bucket led by this replica to the
+// store every interval, and a last one once the stopCh is closed.
+func (c *MetricCollector) PersistSnapshots(store SnapshotStore, owner SnapshotOwner, buckets []string,
+	interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			c.saveSnapshots(store, owner, buckets, time.Now())
+			return
+		case now := <-ticker.C:
+			c.saveSnapshots(store, owner, buckets, now)
+		}
+	}
+}
+
+func (c *MetricCollector) saveSnapshots(store SnapshotStore, owner SnapshotOwner, buckets []string, now time.Time) {
+	snapshot := c.Snapshot(now)
+	byBucket := make(map[string][]CollectionSnapshot, len(buckets))
+	for _, cs := range snapshot.Collections {
+		b := owner.Bucket(types.NamespacedName{Namespace: cs.Namespace, Name: cs.Name})
+		byBucket[b] = append(byBucket[b], cs)
+	}
+	for _, b := range buckets {
+		if !owner.IsLeader(b) {
+			continue
+		}
+		if err := store.Save(b, &CollectorSnapshot{Time: now, Collections: byBucket[b]}); err != nil {
+			c.logger.Errorw("Failed to save metric snapshot of bucket "+b, zap.Error(err))
+		}
+	}
+}
+
 // collection represents the collection of metrics for one specific entity.
 type collection struct {
 	metricMutex sync.RWMutex
 	metric      *av1alpha1.Metric
 
-	scraperMutex sync.RWMutex
-	scraper      StatsScraper
-	buckets      *aggregation.TimedFloat64Buckets
+	scraperMutex       sync.RWMutex
+	scraper            StatsScraper
+	concurrencyBuckets *aggregation.TimedFloat64Buckets
+	rpsBuckets         *aggregation.TimedFloat64Buckets
+	customBuckets      *aggregation.TimedFloat64Buckets
 
 	grp    sync.WaitGroup
 	stopCh chan struct{}
@@ -220,9 +374,11 @@ func (c *collection) getScraper() StatsScraper {
 // collect stats every scrapeTickInterval.
 func newCollection(metric *av1alpha1.Metric, scraper StatsScraper, logger *zap.SugaredLogger) *collection {
 	c := &collection{
-		metric:  metric,
-		buckets: aggregation.NewTimedFloat64Buckets(BucketSize),
-		scraper: scraper,
+		metric:             metric,
+		concurrencyBuckets: aggregation.NewTimedFloat64Buckets(BucketSize),
+		rpsBuckets:         aggregation.NewTimedFloat64Buckets(BucketSize),
+		customBuckets:      aggregation.NewTimedFloat64Buckets(BucketSize),
+		scraper:            scraper,
 
 		stopCh: make(chan struct{}),
 	}
@@ -271,24 +427,46 @@ func (c *collection) currentMetric() *av1alpha1.Metric {
 // record adds a stat to the current collection.
 func (c *collection) record(stat Stat) {
 	// Proxied requests have been counted at the activator. Subtract
-	// AverageProxiedConcurrentRequests to avoid double counting.
-	c.buckets.Record(*stat.Time, stat.PodName, stat.AverageConcurrentRequests-stat.AverageProxiedConcurrentRequests)
+	// AverageProxiedConcurrentRequests and ProxiedRequestCount to avoid
+	// double counting.
+	c.concurrencyBuckets.Record(*stat.Time, stat.PodName, stat.AverageConcurrentRequests-stat.AverageProxiedConcurrentRequests)
+	c.rpsBuckets.Record(*stat.Time, stat.PodName, stat.RequestCount-stat.ProxiedRequestCount)
+	// The custom metric is reported by the pods only, so there is
+	// nothing to subtract.
+	c.customBuckets.Record(*stat.Time, stat.PodName, stat.CustomMetric)
 }
 
 // stableAndPanicConcurrency calculates both stable and panic concurrency based on the
 // current stats.
 func (c *collection) stableAndPanicConcurrency(now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(c.concurrencyBuckets, now)
+}
+
+// stableAndPanicRPS calculates both stable and panic RPS based on the
+// current stats.
+func (c *collection) stableAndPanicRPS(now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(c.rpsBuckets, now)
+}
+
+// stableAndPanicCustomMetric calculates both stable and panic value of the
+// custom metric based on the current stats.
+func (c *collection) stableAndPanicCustomMetric(now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(c.customBuckets, now)
+}
+
+// stableAndPanic calculates both stable and panic averages of the given buckets.
+func (c *collection) stableAndPanic(buckets *aggregation.TimedFloat64Buckets, now time.Time) (float64, float64, error) {
 	spec := c.currentMetric().Spec
 
-	c.buckets.RemoveOlderThan(now.Add(-spec.StableWindow))
+	buckets.RemoveOlderThan(now.Add(-spec.StableWindow))
 
-	if c.buckets.IsEmpty() {
+	if buckets.IsEmpty() {
 		return 0, 0, ErrNoData
 	}
 
 	panicAverage := aggregation.Average{}
 	stableAverage := aggregation.Average{}
-	c.buckets.ForEachBucket(
+	buckets.ForEachBucket(
 		aggregation.YoungerThan(now.Add(-spec.PanicWindow), panicAverage.Accumulate),
 		stableAverage.Accumulate, // No need to add a YoungerThan condition as we already deleted all outdated stats above.
 	)
@@ -296,6 +474,15 @@ func (c *collection) stableAndPanicConcurrency(now time.Time) (float64, float64,
 	return stableAverage.Value(), panicAverage.Value(), nil
 }
 
+// restore adds the buckets of the snapshot which are still within the
+// stable window to the collection.
+func (c *collection) restore(snapshot *CollectionSnapshot, now time.Time) {
+	notOlderThan := now.Add(-c.currentMetric().Spec.StableWindow)
+	c.concurrencyBuckets.Restore(snapshot.Concurrency, notOlderThan)
+	c.rpsBuckets.Restore(snapshot.RPS, notOlderThan)
+	c.customBuckets.Restore(snapshot.Custom, notOlderThan)
+}
+
 // close stops collecting metrics, stops the scraper.
 func (c *collection) close() {
 	close(c.stopCh)
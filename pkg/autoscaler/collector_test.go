@@ -18,6 +18,7 @@ package autoscaler
 
 import (
 	"errors"
+	"os"
 	"testing"
 	"time"
 
@@ -173,6 +174,9 @@ func TestMetricCollectorRecord(t *testing.T) {
 		PodName:                          "testPod",
 		AverageConcurrentRequests:        want + 10,
 		AverageProxiedConcurrentRequests: 10, // this should be subtracted from the above.
+		RequestCount:                     want + 20,
+		ProxiedRequestCount:              20, // this should be subtracted from the above.
+		CustomMetric:                     want,
 	}
 	scraper := &testScraper{
 		s: func() (*StatMessage, error) {
@@ -189,13 +193,95 @@ func TestMetricCollectorRecord(t *testing.T) {
 		t.Error("StableAndPanicConcurrency() = nil, wanted an error")
 	}
 
-	// After adding a stat the concurrencies are calculated correctly.
+	if _, _, err := coll.StableAndPanicRPS(metricKey, now); err == nil {
+		t.Error("StableAndPanicRPS() = nil, wanted an error")
+	}
+
+	if _, _, err := coll.StableAndPanicCustomMetric(metricKey, now); err == nil {
+		t.Error("StableAndPanicCustomMetric() = nil, wanted an error")
+	}
+
+	// After adding a stat the concurrencies and RPS are calculated correctly.
 	coll.Record(metricKey, stat)
-	if stable, panic, err := coll.StableAndPanicConcurrency(metricKey, now); stable != panic && stable != want && err != nil {
+	if stable, panic, err := coll.StableAndPanicConcurrency(metricKey, now); stable != panic || stable != want || err != nil {
 		t.Errorf("StableAndPanicConcurrency() = %v, %v, %v; want %v, %v, nil", stable, panic, err, want, want)
 	}
+	if stable, panic, err := coll.StableAndPanicRPS(metricKey, now); stable != panic || stable != want || err != nil {
+		t.Errorf("StableAndPanicRPS() = %v, %v, %v; want %v, %v, nil", stable, panic, err, want, want)
+	}
+	if stable, panic, err := coll.StableAndPanicCustomMetric(metricKey, now); stable != panic || stable != want || err != nil {
+		t.Errorf("StableAndPanicCustomMetric() = %v, %v, %v; want %v, %v, nil", stable, panic, err, want, want)
+	}
 }
 
+func TestMetricCollectorSnapshotRestore(t *testing.T) {
+	defer ClearAll()
+	logger := TestLogger(t)
+
+	now := time.Now()
+	stale := now.Add(-2 * defaultMetric.Spec.StableWindow)
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+	factory := scraperFactory(scraper, nil)
+
+	coll := NewMetricCollector(factory, logger)
+	coll.CreateOrUpdate(defaultMetric)
+	coll.Record(metricKey, Stat{Time: &now, PodName: "testPod", AverageConcurrentRequests: 10, RequestCount: 20, CustomMetric: 30})
+	coll.Record(metricKey, Stat{Time: &stale, PodName: "testPod", AverageConcurrentRequests: 100, RequestCount: 200})
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	store := NewFileSnapshotStore(dir)
+	owner := testSnapshotOwner{bucket: "bucket-0", leads: "bucket-0"}
+	coll.saveSnapshots(store, owner, []string{"bucket-0", "bucket-1"}, now)
+	coll.Delete(defaultNamespace, defaultName)
+
+	// Only the bucket led by this replica was saved.
+	if snapshot, err := store.Load("bucket-1"); snapshot != nil || err != nil {
+		t.Errorf("Load(bucket-1) = %v, %v; want nil, nil", snapshot, err)
+	}
+
+	restored := NewMetricCollector(factory, logger)
+	if err := restored.Restore(store, []string{"bucket-0", "bucket-1"}); err != nil {
+		t.Fatalf("Restore() = %v", err)
+	}
+	// Nothing is restored until the collection is created.
+	if _, _, err := restored.StableAndPanicConcurrency(metricKey, now); err != ErrNotScraping {
+		t.Errorf("StableAndPanicConcurrency() = %v, want: %v", err, ErrNotScraping)
+	}
+
+	restored.CreateOrUpdate(defaultMetric)
+	defer restored.Delete(defaultNamespace, defaultName)
+	snapshot := restored.Snapshot(now)
+	if got, want := len(snapshot.Collections), 1; got != want {
+		t.Fatalf("len(Collections) = %d, want: %d", got, want)
+	}
+	// The stale buckets were skipped.
+	if got, want := len(snapshot.Collections[0].Concurrency), 1; got != want {
+		t.Errorf("len(Concurrency) = %d, want: %d", got, want)
+	}
+	if stable, panic, err := restored.StableAndPanicConcurrency(metricKey, now); stable != 10 || panic != 10 || err != nil {
+		t.Errorf("StableAndPanicConcurrency() = %v, %v, %v; want 10, 10, nil", stable, panic, err)
+	}
+	if stable, panic, err := restored.StableAndPanicRPS(metricKey, now); stable != 20 || panic != 20 || err != nil {
+		t.Errorf("StableAndPanicRPS() = %v, %v, %v; want 20, 20, nil", stable, panic, err)
+	}
+	if stable, panic, err := restored.StableAndPanicCustomMetric(metricKey, now); stable != 30 || panic != 30 || err != nil {
+		t.Errorf("StableAndPanicCustomMetric() = %v, %v, %v; want 30, 30, nil", stable, panic, err)
+	}
+}
+
+type testSnapshotOwner struct {
+	bucket, leads string
+}
+
+func (o testSnapshotOwner) Bucket(types.NamespacedName) string { return o.bucket }
+func (o testSnapshotOwner) IsLeader(b string) bool             { return b == o.leads }
+
 func scraperFactory(scraper StatsScraper, err error) StatsScraperFactory {
 	return func(*av1alpha1.Metric) (StatsScraper, error) {
 		return scraper, err
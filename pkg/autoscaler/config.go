@@ -44,8 +44,13 @@ type Config struct {
 	// NB: most of our computations are in floats, so this is float to avoid casting.
 	TargetBurstCapacity float64
 
+	// Target requests per second knobs for the rps scaling metric.
+	RPSTargetFraction float64
+	RPSTargetDefault  float64
+
 	// General autoscaler algorithm configuration.
 	MaxScaleUpRate           float64
+	MaxScaleDownRate         float64
 	StableWindow             time.Duration
 	PanicWindowPercentage    float64
 	PanicThresholdPercentage float64
@@ -87,6 +92,10 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		key:          "max-scale-up-rate",
 		field:        &lc.MaxScaleUpRate,
 		defaultValue: 1000.0,
+	}, {
+		key:          "max-scale-down-rate",
+		field:        &lc.MaxScaleDownRate,
+		defaultValue: 2.0,
 	}, {
 		key:   "container-concurrency-target-percentage",
 		field: &lc.ContainerConcurrencyTargetFraction,
@@ -96,6 +105,14 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		key:          "container-concurrency-target-default",
 		field:        &lc.ContainerConcurrencyTargetDefault,
 		defaultValue: 100.0,
+	}, {
+		key:          "requests-per-second-target-percentage",
+		field:        &lc.RPSTargetFraction,
+		defaultValue: 0.7,
+	}, {
+		key:          "requests-per-second-target-default",
+		field:        &lc.RPSTargetDefault,
+		defaultValue: 200.0,
 	}, {
 		key:          "target-burst-capacity",
 		field:        &lc.TargetBurstCapacity,
@@ -125,6 +142,9 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 	if lc.ContainerConcurrencyTargetFraction > 1.0 {
 		lc.ContainerConcurrencyTargetFraction /= 100.0
 	}
+	if lc.RPSTargetFraction > 1.0 {
+		lc.RPSTargetFraction /= 100.0
+	}
 
 	// Process Duration fields
 	for _, dur := range []struct {
@@ -176,6 +196,18 @@ func validate(lc *Config) (*Config, error) {
 		return nil, fmt.Errorf("container-concurrency-target-percentage and container-concurrency-target-default yield target concurrency of %f, can't be less than 1", x)
 	}
 
+	if lc.MaxScaleDownRate <= 1.0 {
+		return nil, fmt.Errorf("max-scale-down-rate = %v, must be greater than 1.0", lc.MaxScaleDownRate)
+	}
+
+	if lc.RPSTargetFraction <= 0 || lc.RPSTargetFraction > 1 {
+		return nil, fmt.Errorf("requests-per-second-target-percentage = %f is outside of valid range of (0, 100]", lc.RPSTargetFraction)
+	}
+
+	if x := lc.RPSTargetFraction * lc.RPSTargetDefault; x < 1.0 {
+		return nil, fmt.Errorf("requests-per-second-target-percentage and requests-per-second-target-default yield target rps of %f, can't be less than 1", x)
+	}
+
 	// We can't permit stable window be less than our aggregation window for correctness.
 	if lc.StableWindow < autoscaling.WindowMin {
 		return nil, fmt.Errorf("stable-window = %v, must be at least %v", lc.StableWindow, BucketSize)
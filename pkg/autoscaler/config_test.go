@@ -30,8 +30,11 @@ var defaultConfig = Config{
 	EnableScaleToZero:                  true,
 	ContainerConcurrencyTargetFraction: 0.7,
 	ContainerConcurrencyTargetDefault:  100.0,
+	RPSTargetFraction:                  0.7,
+	RPSTargetDefault:                   200.0,
 	TargetBurstCapacity:                0,
 	MaxScaleUpRate:                     1000.0,
+	MaxScaleDownRate:                   2.0,
 	StableWindow:                       time.Minute,
 	PanicWindow:                        6 * time.Second,
 	ScaleToZeroGracePeriod:             30 * time.Second,
@@ -80,6 +83,17 @@ func TestNewConfig(t *testing.T) {
 			c.ContainerConcurrencyTargetFraction = 0.55
 			return &c
 		}(defaultConfig),
+	}, {
+		name: "rps target percentage as percent",
+		input: map[string]string{
+			"requests-per-second-target-percentage": "80",
+			"requests-per-second-target-default":    "10",
+		},
+		want: func(c Config) *Config {
+			c.RPSTargetFraction = 0.8
+			c.RPSTargetDefault = 10
+			return &c
+		}(defaultConfig),
 	}, {
 		name: "with -1 tbc",
 		input: map[string]string{
@@ -138,6 +152,21 @@ func TestNewConfig(t *testing.T) {
 			c.ScaleToZeroGracePeriod = 33 * time.Second
 			return &c
 		}(defaultConfig),
+	}, {
+		name: "with max scale down rate",
+		input: map[string]string{
+			"max-scale-down-rate": "3.5",
+		},
+		want: func(c Config) *Config {
+			c.MaxScaleDownRate = 3.5
+			return &c
+		}(defaultConfig),
+	}, {
+		name: "max scale down rate too small",
+		input: map[string]string{
+			"max-scale-down-rate": "1.0",
+		},
+		wantErr: true,
 	}, {
 		name: "malformed float",
 		input: map[string]string{
@@ -175,6 +204,19 @@ func TestNewConfig(t *testing.T) {
 			"container-concurrency-target-default":    "2",
 		},
 		wantErr: true,
+	}, {
+		name: "invalid rps target %, too big",
+		input: map[string]string{
+			"requests-per-second-target-percentage": "142.4",
+		},
+		wantErr: true,
+	}, {
+		name: "rps target less than 1",
+		input: map[string]string{
+			"requests-per-second-target-percentage": "30.0",
+			"requests-per-second-target-default":    "2",
+		},
+		wantErr: true,
 	}, {
 		name: "stable window too small",
 		input: map[string]string{
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// DecisionsPath is the path the DecisionsHandler serves the decisions of a
+// revision at, followed by "<namespace>/<revision>".
+const DecisionsPath = "/debug/decisions/"
+
+// DecisionsResponse is the JSON body returned by the DecisionsHandler.
+type DecisionsResponse struct {
+	Namespace string     `json:"namespace"`
+	Name      string     `json:"name"`
+	Decisions []Decision `json:"decisions"`
+}
+
+// DecisionsHandler returns a read-only handler serving the recent decisions
+// the MultiScaler made for a revision as JSON.
+func DecisionsHandler(m *MultiScaler, logger *zap.SugaredLogger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, DecisionsPath), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			http.Error(w, "expected path "+DecisionsPath+"<namespace>/<revision>", http.StatusBadRequest)
+			return
+		}
+		namespace, name := parts[0], parts[1]
+
+		decisions, err := m.Decisions(namespace, name)
+		if apierrors.IsNotFound(err) {
+			// With leader election, another replica may be deciding for the revision.
+			http.Error(w, "no decider for "+namespace+"/"+name+" on this autoscaler", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(DecisionsResponse{
+			Namespace: namespace,
+			Name:      name,
+			Decisions: decisions,
+		}); err != nil {
+			logger.Errorw("Failed to write decisions", zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestDecisionsHandler(t *testing.T) {
+	ms := NewMultiScaler(make(chan struct{}), nil, TestLogger(t))
+	runner := &scalerRunner{}
+	runner.recordDecision(Decision{DesiredScale: 1, ObservedStableValue: 5})
+	runner.recordDecision(Decision{DesiredScale: 2, ObservedPanicValue: 20, InPanicMode: true})
+	ms.scalers[types.NamespacedName{Namespace: testNamespace, Name: testRevision}] = runner
+	handler := DecisionsHandler(ms, TestLogger(t))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+	}{{
+		name:     "decisions",
+		method:   http.MethodGet,
+		path:     DecisionsPath + testNamespace + "/" + testRevision,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "read only",
+		method:   http.MethodPost,
+		path:     DecisionsPath + testNamespace + "/" + testRevision,
+		wantCode: http.StatusMethodNotAllowed,
+	}, {
+		name:     "missing revision",
+		method:   http.MethodGet,
+		path:     DecisionsPath + testNamespace,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "unknown revision",
+		method:   http.MethodGet,
+		path:     DecisionsPath + testNamespace + "/unknown",
+		wantCode: http.StatusNotFound,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+			if got, want := rec.Code, test.wantCode; got != want {
+				t.Errorf("StatusCode = %d, want: %d", got, want)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DecisionsPath+testNamespace+"/"+testRevision, nil))
+	var resp DecisionsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if resp.Namespace != testNamespace || resp.Name != testRevision {
+		t.Errorf("Response for %s/%s, want: %s/%s", resp.Namespace, resp.Name, testNamespace, testRevision)
+	}
+	if got, want := len(resp.Decisions), 2; got != want {
+		t.Fatalf("len(Decisions) = %d, want: %d", got, want)
+	}
+	if got := resp.Decisions[1]; got.DesiredScale != 2 || !got.InPanicMode || got.ObservedPanicValue != 20 {
+		t.Errorf("Latest decision = %#v, want the panicking one", got)
+	}
+}
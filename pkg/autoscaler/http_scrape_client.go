@@ -84,6 +84,15 @@ func extractData(body io.Reader) (*Stat, error) {
 			}
 		}
 	}
+	// The custom metric is only exported by queue-proxies scraping the
+	// user container, so it is optional.
+	if pm := prometheusMetric(metricFamilies, "queue_custom_metric"); pm != nil {
+		stat.CustomMetric = *pm.Gauge.Value
+	}
+	// Older queue-proxies don't export the streams.
+	if pm := prometheusMetric(metricFamilies, "queue_average_concurrent_streams"); pm != nil {
+		stat.AverageConcurrentStreams = *pm.Gauge.Value
+	}
 	return &stat, nil
 }
 
@@ -44,6 +44,10 @@ queue_average_proxied_concurrent_requests{destination_namespace="test-namespace"
 	testProxiedQPSContext = `# HELP queue_proxied_operations_per_second Number of proxied requests received since last Stat
 # TYPE queue_proxied_operations_per_second gauge
 queue_proxied_operations_per_second{destination_namespace="test-namespace",destination_revision="test-revision",destination_pod="test-revision-1234"} 4
+`
+	testCustomMetricContext = `# HELP queue_custom_metric Value of the custom metric reported by the user container
+# TYPE queue_custom_metric gauge
+queue_custom_metric{destination_namespace="test-namespace",destination_revision="test-revision",destination_pod="test-revision-1234"} 7
 `
 	testFullContext = testAverageConcurrencyContext + testQPSContext + testAverageProxiedConcurrenyContext + testProxiedQPSContext
 )
@@ -99,6 +103,26 @@ func TestHTTPScrapeClient_Scrape_HappyCase(t *testing.T) {
 	if stat.PodName != "test-revision-1234" {
 		t.Errorf("stat.PodName = %s, want test-revision-1234", stat.PodName)
 	}
+	// The custom metric is optional.
+	if stat.CustomMetric != 0 {
+		t.Errorf("stat.CustomMetric = %v, want 0", stat.CustomMetric)
+	}
+}
+
+func TestHTTPScrapeClient_Scrape_CustomMetric(t *testing.T) {
+	hClient := newTestHTTPClient(getHTTPResponse(http.StatusOK, testFullContext+testCustomMetricContext), nil)
+	sClient, err := newHTTPScrapeClient(hClient)
+	if err != nil {
+		t.Fatalf("newHTTPScrapeClient = %v, want no error", err)
+	}
+
+	stat, err := sClient.Scrape(testURL)
+	if err != nil {
+		t.Fatalf("scrapeViaURL = %v, want no error", err)
+	}
+	if stat.CustomMetric != 7 {
+		t.Errorf("stat.CustomMetric = %v, want 7", stat.CustomMetric)
+	}
 }
 
 func TestHTTPScrapeClient_Scrape_ErrorCases(t *testing.T) {
@@ -117,3 +117,11 @@ func (s staticConcurrency) StableAndPanicConcurrency(key types.NamespacedName, n
 	}
 	return 0.0, 0.0, errors.New("doesn't exist")
 }
+
+func (s staticConcurrency) StableAndPanicRPS(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return 0.0, 0.0, nil
+}
+
+func (s staticConcurrency) StableAndPanicCustomMetric(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return 0.0, 0.0, nil
+}
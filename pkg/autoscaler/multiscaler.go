@@ -41,13 +41,19 @@ type Decider struct {
 
 // DeciderSpec is the parameters in which the Revision should scaled.
 type DeciderSpec struct {
-	TickInterval   time.Duration
-	MaxScaleUpRate float64
-	// The concurrency per pod that we target to maintain.
-	// TargetConcurrency <= TotalConcurency.
-	TargetConcurrency float64
-	// The total concurrency that a pod can maintain.
-	TotalConcurrency float64
+	TickInterval     time.Duration
+	MaxScaleUpRate   float64
+	MaxScaleDownRate float64
+	// The name of the scaling algorithm used to compute the desired scale.
+	// An empty name selects the default stable/panic algorithm.
+	Algorithm string
+	// The metric used for scaling, i.e. concurrency or rps.
+	ScalingMetric string
+	// The value of scaling metric per pod that we target to maintain.
+	// TargetValue <= TotalValue.
+	TargetValue float64
+	// The total value of scaling metric that a pod can maintain.
+	TotalValue float64
 	// The burst capacity that user wants to maintain without queing at the POD level.
 	// Note, that queueing still might happen due to the non-ideal load balancing.
 	TargetBurstCapacity float64
@@ -70,14 +76,65 @@ type DeciderStatus struct {
 	// If this number is negative: Activator will be threaded in
 	// the request path by the PodAutoscaler controller.
 	ExcessBurstCapacity int32
+
+	// ScaleUpClamped is true if DesiredScale was limited by
+	// the MaxScaleUpRate.
+	ScaleUpClamped bool
+
+	// ScaleDownClamped is true if DesiredScale was limited by
+	// the MaxScaleDownRate.
+	ScaleDownClamped bool
+}
+
+// ScaleResult holds the scale result of the UniScaler evaluation cycle.
+type ScaleResult struct {
+	// DesiredPodCount is the number of pods the UniScaler suggests for the revision.
+	DesiredPodCount int32
+	// ExcessBurstCapacity is the computed headroom of the revision taking into
+	// account the target burst capacity.
+	ExcessBurstCapacity int32
+	// ScaleUpClamped is true if DesiredPodCount was limited by the MaxScaleUpRate.
+	ScaleUpClamped bool
+	// ScaleDownClamped is true if DesiredPodCount was limited by the MaxScaleDownRate.
+	ScaleDownClamped bool
+
+	// The observations the evaluation was based on.
+	// ScalingMetric is the metric the UniScaler scaled on.
+	ScalingMetric string
+	// ObservedStableValue is the average of the scaling metric over the stable window.
+	ObservedStableValue float64
+	// ObservedPanicValue is the average of the scaling metric over the panic window.
+	ObservedPanicValue float64
+	// ReadyPodCount is the number of ready pods of the revision.
+	ReadyPodCount int
+	// InPanicMode is true if the UniScaler was in panic mode.
+	InPanicMode bool
+}
+
+// Decision is a scale recommendation of a Decider alongside the observations
+// it is based on, kept for debugging.
+type Decision struct {
+	Time                time.Time `json:"time"`
+	ScalingMetric       string    `json:"scalingMetric"`
+	ObservedStableValue float64   `json:"observedStableValue"`
+	ObservedPanicValue  float64   `json:"observedPanicValue"`
+	ReadyPodCount       int       `json:"readyPodCount"`
+	InPanicMode         bool      `json:"inPanicMode"`
+	ExcessBurstCapacity int32     `json:"excessBurstCapacity"`
+	DesiredScale        int32     `json:"desiredScale"`
+	ScaleUpClamped      bool      `json:"scaleUpClamped"`
+	ScaleDownClamped    bool      `json:"scaleDownClamped"`
 }
 
+// decisionHistoryLength is the number of recent decisions kept per Decider.
+const decisionHistoryLength = 100
+
 // UniScaler records statistics for a particular Decider and proposes the scale for the Decider's target based on those statistics.
 type UniScaler interface {
 	// Scale either proposes a number of replicas and available excess burst capacity,
 	// or skips proposing. The proposal is requested at the given time.
 	// The returned boolean is true if and only if a proposal was returned.
-	Scale(context.Context, time.Time) (int32, int32, bool)
+	Scale(context.Context, time.Time) (ScaleResult, bool)
 
 	// Update reconfigures the UniScaler according to the DeciderSpec.
 	Update(DeciderSpec) error
@@ -95,6 +152,12 @@ type scalerRunner struct {
 	// mux guards access to metric
 	mux     sync.RWMutex
 	decider Decider
+
+	// historyMux guards the history, so that recording the decisions of a
+	// tick doesn't contend with updates of the decider.
+	historyMux sync.RWMutex
+	// history holds the recent decisions, oldest first.
+	history []Decision
 }
 
 func (sr *scalerRunner) getLatestScale() int32 {
@@ -107,23 +170,37 @@ func sameSign(a, b int32) bool {
 	return (a&math.MinInt32)^(b&math.MinInt32) == 0
 }
 
-func (sr *scalerRunner) updateLatestScale(proposed, ebc int32) bool {
+func (sr *scalerRunner) updateLatestScale(sRes ScaleResult) bool {
 	ret := false
 	sr.mux.Lock()
 	defer sr.mux.Unlock()
-	if sr.decider.Status.DesiredScale != proposed {
-		sr.decider.Status.DesiredScale = proposed
+	if sr.decider.Status.DesiredScale != sRes.DesiredPodCount {
+		sr.decider.Status.DesiredScale = sRes.DesiredPodCount
 		ret = true
 	}
 
 	// If sign has changed -- then we have to update KPA
-	ret = ret || !sameSign(sr.decider.Status.ExcessBurstCapacity, ebc)
+	ret = ret || !sameSign(sr.decider.Status.ExcessBurstCapacity, sRes.ExcessBurstCapacity)
 
 	// Update with the latest calculation anyway.
-	sr.decider.Status.ExcessBurstCapacity = ebc
+	sr.decider.Status.ExcessBurstCapacity = sRes.ExcessBurstCapacity
+	sr.decider.Status.ScaleUpClamped = sRes.ScaleUpClamped
+	sr.decider.Status.ScaleDownClamped = sRes.ScaleDownClamped
 	return ret
 }
 
+// recordDecision appends the decision to the history, dropping the oldest
+// decision once the history is full.
+func (sr *scalerRunner) recordDecision(d Decision) {
+	sr.historyMux.Lock()
+	defer sr.historyMux.Unlock()
+	if len(sr.history) == decisionHistoryLength {
+		copy(sr.history, sr.history[1:])
+		sr.history = sr.history[:len(sr.history)-1]
+	}
+	sr.history = append(sr.history, d)
+}
+
 // MultiScaler maintains a collection of Uniscalers.
 type MultiScaler struct {
 	scalers       map[types.NamespacedName]*scalerRunner
@@ -166,6 +243,23 @@ func (m *MultiScaler) Get(ctx context.Context, namespace, name string) (*Decider
 	return (&scaler.decider).DeepCopy(), nil
 }
 
+// Decisions returns the recent decisions of the Decider, oldest first.
+func (m *MultiScaler) Decisions(namespace, name string) ([]Decision, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	m.scalersMutex.RLock()
+	defer m.scalersMutex.RUnlock()
+	scaler, exists := m.scalers[key]
+	if !exists {
+		// This GroupResource is a lie, but unfortunately this interface requires one.
+		return nil, errors.NewNotFound(av1alpha1.Resource("Deciders"), key.String())
+	}
+	scaler.historyMux.RLock()
+	defer scaler.historyMux.RUnlock()
+	decisions := make([]Decision, len(scaler.history))
+	copy(decisions, scaler.history)
+	return decisions, nil
+}
+
 // Create instantiates the desired Decider.
 func (m *MultiScaler) Create(ctx context.Context, decider *Decider) (*Decider, error) {
 	m.scalersMutex.Lock()
@@ -192,12 +286,32 @@ func (m *MultiScaler) Update(ctx context.Context, decider *Decider) (*Decider, e
 	defer m.scalersMutex.Unlock()
 	if scaler, exists := m.scalers[key]; exists {
 		scaler.mux.Lock()
-		defer scaler.mux.Unlock()
 		oldDeciderSpec := scaler.decider.Spec
+		var uniScaler UniScaler
+		if oldDeciderSpec.Algorithm != decider.Spec.Algorithm {
+			// A different algorithm needs a new UniScaler.
+			var err error
+			if uniScaler, err = m.uniScalerFactory(decider); err != nil {
+				scaler.mux.Unlock()
+				return nil, err
+			}
+		}
 		scaler.decider = *decider
-		scaler.scaler.Update(decider.Spec)
-		if oldDeciderSpec.TickInterval != decider.Spec.TickInterval {
+		scaler.mux.Unlock()
+
+		// The ticker is stopped without holding the lock of the scaler, as
+		// a running tick takes it to record its result.
+		switch {
+		case uniScaler != nil:
+			// The new UniScaler must be swapped in while the ticker is stopped.
+			m.stopRunner(scaler)
+			scaler.scaler = uniScaler
+			m.runScalerTicker(ctx, scaler)
+		case oldDeciderSpec.TickInterval != decider.Spec.TickInterval:
+			scaler.scaler.Update(decider.Spec)
 			m.updateRunner(ctx, scaler)
+		default:
+			scaler.scaler.Update(decider.Spec)
 		}
 		return decider, nil
 	}
@@ -241,10 +355,19 @@ func (m *MultiScaler) Inform(event string) bool {
 }
 
 func (m *MultiScaler) updateRunner(ctx context.Context, runner *scalerRunner) {
-	runner.stopCh <- struct{}{}
+	m.stopRunner(runner)
 	m.runScalerTicker(ctx, runner)
 }
 
+// stopRunner stops the ticker of the runner, unless all tickers were stopped
+// already. The caller must not hold the lock of the runner.
+func (m *MultiScaler) stopRunner(runner *scalerRunner) {
+	select {
+	case runner.stopCh <- struct{}{}:
+	case <-m.scalersStopCh:
+	}
+}
+
 func (m *MultiScaler) runScalerTicker(ctx context.Context, runner *scalerRunner) {
 	metricKey := types.NamespacedName{Namespace: runner.decider.Namespace, Name: runner.decider.Name}
 	ticker := time.NewTicker(runner.decider.Spec.TickInterval)
@@ -285,19 +408,32 @@ func (m *MultiScaler) createScaler(ctx context.Context, decider *Decider) (*scal
 
 func (m *MultiScaler) tickScaler(ctx context.Context, scaler UniScaler, runner *scalerRunner, metricKey types.NamespacedName) {
 	logger := logging.FromContext(ctx)
-	desiredScale, excessBC, scaled := scaler.Scale(ctx, time.Now())
+	now := time.Now()
+	sRes, scaled := scaler.Scale(ctx, now)
 
 	if !scaled {
 		return
 	}
+	runner.recordDecision(Decision{
+		Time:                now,
+		ScalingMetric:       sRes.ScalingMetric,
+		ObservedStableValue: sRes.ObservedStableValue,
+		ObservedPanicValue:  sRes.ObservedPanicValue,
+		ReadyPodCount:       sRes.ReadyPodCount,
+		InPanicMode:         sRes.InPanicMode,
+		ExcessBurstCapacity: sRes.ExcessBurstCapacity,
+		DesiredScale:        sRes.DesiredPodCount,
+		ScaleUpClamped:      sRes.ScaleUpClamped,
+		ScaleDownClamped:    sRes.ScaleDownClamped,
+	})
 
 	// Cannot scale negative (nor we can compute burst capacity).
-	if desiredScale < 0 {
-		logger.Errorf("Cannot scale: desiredScale %d < 0.", desiredScale)
+	if sRes.DesiredPodCount < 0 {
+		logger.Errorf("Cannot scale: desiredScale %d < 0.", sRes.DesiredPodCount)
 		return
 	}
 
-	if runner.updateLatestScale(desiredScale, excessBC) {
+	if runner.updateLatestScale(sRes) {
 		m.Inform(metricKey.String())
 	}
 }
@@ -126,6 +126,75 @@ func TestMultiScalerScaling(t *testing.T) {
 	}
 }
 
+func TestScalerRunnerUpdateLatestScaleClamped(t *testing.T) {
+	sr := &scalerRunner{}
+	if !sr.updateLatestScale(ScaleResult{DesiredPodCount: 100, ScaleDownClamped: true}) {
+		t.Error("updateLatestScale() = false, want: true")
+	}
+	if got, want := sr.decider.Status, (DeciderStatus{DesiredScale: 100, ScaleDownClamped: true}); got != want {
+		t.Errorf("Status = %#v, want: %#v", got, want)
+	}
+
+	// Only the clamping changed, no need to inform the watcher.
+	if sr.updateLatestScale(ScaleResult{DesiredPodCount: 100}) {
+		t.Error("updateLatestScale() = true, want: false")
+	}
+	if sr.decider.Status.ScaleDownClamped {
+		t.Error("ScaleDownClamped = true, want: false")
+	}
+}
+
+func TestScalerRunnerRecordDecision(t *testing.T) {
+	sr := &scalerRunner{}
+	for i := 0; i < decisionHistoryLength+10; i++ {
+		sr.recordDecision(Decision{DesiredScale: int32(i)})
+	}
+	if got, want := len(sr.history), decisionHistoryLength; got != want {
+		t.Fatalf("len(history) = %d, want: %d", got, want)
+	}
+	// The oldest decisions were dropped.
+	if got, want := sr.history[0].DesiredScale, int32(10); got != want {
+		t.Errorf("Oldest DesiredScale = %d, want: %d", got, want)
+	}
+	if got, want := sr.history[decisionHistoryLength-1].DesiredScale, int32(decisionHistoryLength+9); got != want {
+		t.Errorf("Latest DesiredScale = %d, want: %d", got, want)
+	}
+}
+
+func TestMultiScalerDecisions(t *testing.T) {
+	ctx := context.Background()
+	ms, stopCh, statCh, uniScaler := createMultiScaler(t)
+	defer close(stopCh)
+	defer close(statCh)
+
+	decider := newDecider()
+	uniScaler.setScaleResult(3, 7, true)
+
+	if d, err := ms.Decisions(decider.Namespace, decider.Name); !apierrors.IsNotFound(err) {
+		t.Errorf("Decisions() = (%v, %v), want not found error", d, err)
+	}
+
+	errCh := make(chan error)
+	ms.Watch(watchFunc(ctx, ms, decider, 3, errCh))
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := verifyTick(errCh); err != nil {
+		t.Fatal(err)
+	}
+
+	decisions, err := ms.Decisions(decider.Namespace, decider.Name)
+	if err != nil {
+		t.Fatalf("Decisions() = %v", err)
+	}
+	if len(decisions) == 0 {
+		t.Fatal("Decisions() = [], want at least one")
+	}
+	if got := decisions[0]; got.DesiredScale != 3 || got.ExcessBurstCapacity != 7 || got.Time.IsZero() {
+		t.Errorf("Decision = %#v, want DesiredScale 3 and ExcessBurstCapacity 7 at a time", got)
+	}
+}
+
 func TestMultiScalerOnlyCapacityChange(t *testing.T) {
 	ctx := context.Background()
 	ms, stopCh, statCh, uniScaler := createMultiScaler(t)
@@ -270,7 +339,7 @@ func TestMultiScalerScaleFromZero(t *testing.T) {
 	metricKey := types.NamespacedName{Namespace: decider.Namespace, Name: decider.Name}
 	if scaler, exists := ms.scalers[metricKey]; !exists {
 		t.Errorf("Failed to get scaler for metric %s", metricKey)
-	} else if !scaler.updateLatestScale(0, 10) {
+	} else if !scaler.updateLatestScale(ScaleResult{DesiredPodCount: 0, ExcessBurstCapacity: 10}) {
 		t.Error("Failed to set scale for metric to 0")
 	}
 
@@ -339,7 +408,7 @@ func TestMultiScalerUpdate(t *testing.T) {
 	defer close(statCh)
 
 	decider := newDecider()
-	decider.Spec.TargetConcurrency = 1.0
+	decider.Spec.TargetValue = 1.0
 	uniScaler.setScaleResult(0, 100, true)
 
 	// Create the decider and verify the Spec
@@ -351,12 +420,12 @@ func TestMultiScalerUpdate(t *testing.T) {
 	if err != nil {
 		t.Errorf("Get() = %v", err)
 	}
-	if got, want := m.Spec.TargetConcurrency, 1.0; got != want {
+	if got, want := m.Spec.TargetValue, 1.0; got != want {
 		t.Errorf("Got target concurrency %v. Wanted %v", got, want)
 	}
 
 	// Update the target and verify the Spec
-	decider.Spec.TargetConcurrency = 10.0
+	decider.Spec.TargetValue = 10.0
 	if _, err = ms.Update(ctx, decider); err != nil {
 		t.Errorf("Update() = %v", err)
 	}
@@ -364,11 +433,118 @@ func TestMultiScalerUpdate(t *testing.T) {
 	if err != nil {
 		t.Errorf("Get() = %v", err)
 	}
-	if got, want := m.Spec.TargetConcurrency, 10.0; got != want {
+	if got, want := m.Spec.TargetValue, 10.0; got != want {
 		t.Errorf("Got target concurrency %v. Wanted %v", got, want)
 	}
 }
 
+func TestMultiScalerUpdateAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	scalers := map[string]*fakeUniScaler{
+		"":    {},
+		"pid": {},
+	}
+	factory := func(decider *Decider) (UniScaler, error) {
+		us, ok := scalers[decider.Spec.Algorithm]
+		if !ok {
+			return nil, fmt.Errorf("unknown scaling algorithm %q", decider.Spec.Algorithm)
+		}
+		return us, nil
+	}
+	ms := NewMultiScaler(stopCh, factory, TestLogger(t))
+
+	decider := newDecider()
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+
+	decider.Spec.Algorithm = "magic"
+	if _, err := ms.Update(ctx, decider); err == nil {
+		t.Error("Update() with an unknown algorithm = nil, wanted an error")
+	}
+
+	decider.Spec.Algorithm = "pid"
+	if _, err := ms.Update(ctx, decider); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	if err := wait.PollImmediate(tickInterval, tickTimeout, func() (bool, error) {
+		return scalers["pid"].getScaleCount() >= 1, nil
+	}); err != nil {
+		t.Fatal("The new algorithm was never asked to scale")
+	}
+	// The replaced UniScaler must not be asked to scale anymore.
+	count := scalers[""].getScaleCount()
+	time.Sleep(5 * tickInterval)
+	if got := scalers[""].getScaleCount(); got != count {
+		t.Errorf("Replaced UniScaler scale count = %d, want: %d", got, count)
+	}
+}
+
+func TestMultiScalerUpdateWhileTicking(t *testing.T) {
+	ctx := context.Background()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	blocking := &blockingUniScaler{
+		scaling: make(chan struct{}, 1),
+		proceed: make(chan struct{}),
+	}
+	factory := func(decider *Decider) (UniScaler, error) {
+		if decider.Spec.Algorithm == "pid" {
+			return &fakeUniScaler{}, nil
+		}
+		return blocking, nil
+	}
+	ms := NewMultiScaler(stopCh, factory, TestLogger(t))
+
+	decider := newDecider()
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	// Wait for a tick to be running, and let it finish only while the
+	// update tries to stop the ticker.
+	<-blocking.scaling
+	updated := make(chan error)
+	go func() {
+		decider.Spec.Algorithm = "pid"
+		_, err := ms.Update(ctx, decider)
+		updated <- err
+	}()
+	time.Sleep(tickInterval)
+	close(blocking.proceed)
+
+	select {
+	case err := <-updated:
+		if err != nil {
+			t.Fatalf("Update() = %v", err)
+		}
+	case <-time.After(tickTimeout):
+		t.Fatal("Update() deadlocked with a running tick")
+	}
+}
+
+// blockingUniScaler is a UniScaler whose ticks block until proceed is closed.
+type blockingUniScaler struct {
+	scaling chan struct{}
+	proceed chan struct{}
+}
+
+func (u *blockingUniScaler) Scale(context.Context, time.Time) (ScaleResult, bool) {
+	select {
+	case u.scaling <- struct{}{}:
+	default:
+	}
+	<-u.proceed
+	return ScaleResult{DesiredPodCount: 1}, true
+}
+
+func (u *blockingUniScaler) Update(DeciderSpec) error {
+	return nil
+}
+
 func createMultiScaler(t *testing.T) (*MultiScaler, chan<- struct{}, chan *StatMessage, *fakeUniScaler) {
 	logger := TestLogger(t)
 	uniscaler := &fakeUniScaler{}
@@ -392,11 +568,11 @@ func (u *fakeUniScaler) fakeUniScalerFactory(*Decider) (UniScaler, error) {
 	return u, nil
 }
 
-func (u *fakeUniScaler) Scale(context.Context, time.Time) (int32, int32, bool) {
+func (u *fakeUniScaler) Scale(context.Context, time.Time) (ScaleResult, bool) {
 	u.mutex.Lock()
 	defer u.mutex.Unlock()
 	u.scaleCount++
-	return u.replicas, u.surplus, u.scaled
+	return ScaleResult{DesiredPodCount: u.replicas, ExcessBurstCapacity: u.surplus}, u.scaled
 }
 
 func (u *fakeUniScaler) getScaleCount() int {
@@ -425,8 +601,8 @@ func newDecider() *Decider {
 			Name:      testRevision,
 		},
 		Spec: DeciderSpec{
-			TickInterval:      tickInterval,
-			TargetConcurrency: 1,
+			TickInterval: tickInterval,
+			TargetValue:  1,
 		},
 		Status: DeciderStatus{},
 	}
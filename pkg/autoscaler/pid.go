@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/resources"
+)
+
+const (
+	// pidProportionalGain is the share of the current error, in pods,
+	// corrected by a single decision.
+	pidProportionalGain = 0.7
+	// pidIntegralGain is the share of the accumulated error, in pod-seconds,
+	// added to the decision. It removes a persistent offset from the target.
+	pidIntegralGain = 0.05
+	// pidDerivativeGain weighs the rate of change of the error, in pods per
+	// second, so that the controller reacts faster to a changing load.
+	pidDerivativeGain = 0.2
+)
+
+// pidScaler computes the desired scale with a proportional-integral-derivative
+// controller on the difference between the number of pods the observed stable
+// value requires and the number of ready pods.
+type pidScaler struct {
+	*windowScaler
+
+	// Controller state. Carries over multiple Scale calls. Guarded
+	// by the stateMux.
+	stateMux  sync.Mutex
+	integral  float64
+	prevError float64
+	prevTime  time.Time
+}
+
+func newPIDScaler(namespace, revision string, metricClient MetricClient, podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec, reporter StatsReporter) (UniScaler, error) {
+	w, err := newWindowScaler(namespace, revision, metricClient, podCounter, deciderSpec, reporter)
+	if err != nil {
+		return nil, err
+	}
+	return &pidScaler{windowScaler: w}, nil
+}
+
+// Scale calculates the desired scale based on current statistics given the current time.
+func (p *pidScaler) Scale(ctx context.Context, now time.Time) (ScaleResult, bool) {
+	logger := logging.FromContext(ctx)
+
+	o, ok := p.observe(ctx, now)
+	if !ok {
+		return ScaleResult{}, false
+	}
+	logger.Debugw(fmt.Sprintf("Observed average %0.3f %s, targeting %0.3f.",
+		o.stableValue, o.metricName, o.spec.TargetValue),
+		zap.String("mode", "pid"))
+
+	p.stateMux.Lock()
+	defer p.stateMux.Unlock()
+
+	var desired float64
+	if o.stableValue > 0 {
+		readyPodsCount := o.readyPodsCount
+		pidError := o.stableValue/o.spec.TargetValue - readyPodsCount
+
+		var elapsed float64
+		if !p.prevTime.IsZero() {
+			elapsed = now.Sub(p.prevTime).Seconds()
+		}
+		// Drop the accumulated error once the error changes sign to
+		// avoid overshooting, and bound it so that the integral term never
+		// accounts for more than the current pod count.
+		if pidError*p.prevError < 0 {
+			p.integral = 0
+		}
+		maxIntegral := readyPodsCount / pidIntegralGain
+		p.integral = math.Min(math.Max(p.integral+pidError*elapsed, -maxIntegral), maxIntegral)
+		var derivative float64
+		if elapsed > 0 {
+			derivative = (pidError - p.prevError) / elapsed
+		}
+		p.prevError, p.prevTime = pidError, now
+
+		desired = math.Round(readyPodsCount + pidProportionalGain*pidError +
+			pidIntegralGain*p.integral + pidDerivativeGain*derivative)
+		// Keep serving the observed traffic.
+		desired = math.Max(desired, 1)
+		logger.Debugf("PID error=%v integral=%v derivative=%v desired=%v",
+			pidError, p.integral, derivative, desired)
+	} else {
+		// No traffic over the stable window, start afresh once it comes back.
+		p.integral, p.prevError, p.prevTime = 0, 0, time.Time{}
+	}
+	return p.result(o, desired), true
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"testing"
+	"time"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/apis/autoscaling"
+)
+
+func TestPIDNoData(t *testing.T) {
+	metrics := &testMetricClient{err: ErrNoData}
+	p := newTestAlgorithm(t, autoscaling.PIDAlgorithm, 10, metrics)
+	expectAlgorithmScale(t, p, time.Now(), 0, false)
+}
+
+func TestPIDSteadyState(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 50}
+	p := newTestAlgorithm(t, autoscaling.PIDAlgorithm, 10, metrics)
+	endpoints(5)
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		expectAlgorithmScale(t, p, now, 5, true)
+		now = now.Add(2 * time.Second)
+	}
+}
+
+func TestPIDConvergesUp(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 100}
+	p := newTestAlgorithm(t, autoscaling.PIDAlgorithm, 10, metrics)
+	endpoints(2)
+
+	now := time.Now()
+	// The proportional term only corrects part of the error at once.
+	sRes := expectAlgorithmScale(t, p, now, 8, true)
+	for i := 0; i < 10; i++ {
+		endpoints(int(sRes.DesiredPodCount))
+		now = now.Add(2 * time.Second)
+		sRes, _ = p.Scale(TestContextWithLogger(t), now)
+	}
+	if got, want := sRes.DesiredPodCount, int32(10); got != want {
+		t.Errorf("Scale after convergence = %d, want: %d", got, want)
+	}
+}
+
+func TestPIDScaleToZero(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 5}
+	p := newTestAlgorithm(t, autoscaling.PIDAlgorithm, 10, metrics)
+	now := time.Now()
+
+	// Low traffic keeps one pod around.
+	expectAlgorithmScale(t, p, now, 1, true)
+
+	metrics.stableConcurrency = 0
+	expectAlgorithmScale(t, p, now.Add(2*time.Second), 0, true)
+}
+
+func TestPIDRateLimit(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 1000}
+	p := newTestAlgorithm(t, autoscaling.PIDAlgorithm, 1, metrics)
+
+	if sRes := expectAlgorithmScale(t, p, time.Now(), 10, true); !sRes.ScaleUpClamped {
+		t.Error("ScaleUpClamped = false, want: true")
+	}
+}
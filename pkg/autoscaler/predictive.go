@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/resources"
+)
+
+const (
+	// predictiveLevelSmoothing is the weight of a new observation in the
+	// smoothed level of the metric.
+	predictiveLevelSmoothing = 0.5
+	// predictiveTrendSmoothing is the weight of a new level change in the
+	// smoothed trend of the metric.
+	predictiveTrendSmoothing = 0.3
+	// predictiveHorizon is how far ahead the metric is forecasted. It
+	// approximates the time a new pod needs to become ready.
+	predictiveHorizon = 10 * time.Second
+)
+
+// predictiveScaler forecasts the panic window value of the scaling metric with
+// double exponential smoothing (Holt's linear method) and provisions pods for
+// the forecasted value ahead of time. It never provisions less than the stable
+// window value requires, so scaling down is as conservative as in stable mode.
+type predictiveScaler struct {
+	*windowScaler
+
+	// Smoothing state. Carries over multiple Scale calls. Guarded
+	// by the stateMux.
+	stateMux sync.Mutex
+	level    float64
+	// trend is the smoothed change of the level per second.
+	trend    float64
+	prevTime time.Time
+}
+
+func newPredictiveScaler(namespace, revision string, metricClient MetricClient, podCounter resources.ReadyPodCounter,
+	deciderSpec DeciderSpec, reporter StatsReporter) (UniScaler, error) {
+	w, err := newWindowScaler(namespace, revision, metricClient, podCounter, deciderSpec, reporter)
+	if err != nil {
+		return nil, err
+	}
+	return &predictiveScaler{windowScaler: w}, nil
+}
+
+// Scale calculates the desired scale based on current statistics given the current time.
+func (p *predictiveScaler) Scale(ctx context.Context, now time.Time) (ScaleResult, bool) {
+	logger := logging.FromContext(ctx)
+
+	o, ok := p.observe(ctx, now)
+	if !ok {
+		return ScaleResult{}, false
+	}
+
+	p.stateMux.Lock()
+	defer p.stateMux.Unlock()
+
+	var desired float64
+	if o.stableValue > 0 {
+		forecast := p.forecast(o.panicValue, now)
+		logger.Debugw(fmt.Sprintf("Observed average %0.3f %s, forecasted %0.3f, targeting %0.3f.",
+			o.panicValue, o.metricName, forecast, o.spec.TargetValue),
+			zap.String("mode", "predictive"))
+		desired = math.Ceil(math.Max(forecast, o.stableValue) / o.spec.TargetValue)
+	} else {
+		// No traffic over the stable window, start afresh once it comes back.
+		p.level, p.trend, p.prevTime = 0, 0, time.Time{}
+	}
+	return p.result(o, desired), true
+}
+
+// forecast folds the observation into the smoothed level and trend and returns
+// the value expected predictiveHorizon from now. It must be called with the
+// stateMux held.
+func (p *predictiveScaler) forecast(observed float64, now time.Time) float64 {
+	if p.prevTime.IsZero() {
+		p.level, p.trend, p.prevTime = observed, 0, now
+		return observed
+	}
+	elapsed := now.Sub(p.prevTime).Seconds()
+	if elapsed <= 0 {
+		return math.Max(0, p.level+p.trend*predictiveHorizon.Seconds())
+	}
+	prevLevel := p.level
+	p.level = predictiveLevelSmoothing*observed + (1-predictiveLevelSmoothing)*(prevLevel+p.trend*elapsed)
+	p.trend = predictiveTrendSmoothing*(p.level-prevLevel)/elapsed + (1-predictiveTrendSmoothing)*p.trend
+	p.prevTime = now
+	return math.Max(0, p.level+p.trend*predictiveHorizon.Seconds())
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"testing"
+	"time"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/apis/autoscaling"
+)
+
+func TestPredictiveNoData(t *testing.T) {
+	metrics := &testMetricClient{err: ErrNoData}
+	p := newTestAlgorithm(t, autoscaling.PredictiveAlgorithm, 10, metrics)
+	expectAlgorithmScale(t, p, time.Now(), 0, false)
+}
+
+func TestPredictiveSteadyState(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 50, panicConcurrency: 50}
+	p := newTestAlgorithm(t, autoscaling.PredictiveAlgorithm, 10, metrics)
+	endpoints(5)
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		expectAlgorithmScale(t, p, now, 5, true)
+		now = now.Add(2 * time.Second)
+	}
+}
+
+func TestPredictiveScalesAheadOfRisingLoad(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 10, panicConcurrency: 10}
+	p := newTestAlgorithm(t, autoscaling.PredictiveAlgorithm, 10, metrics)
+	endpoints(1)
+
+	now := time.Now()
+	expectAlgorithmScale(t, p, now, 1, true)
+
+	// The load grows by 10 every 2 seconds.
+	var sRes ScaleResult
+	for i := 1; i <= 5; i++ {
+		metrics.panicConcurrency = float64(10 + 10*i)
+		now = now.Add(2 * time.Second)
+		sRes, _ = p.Scale(TestContextWithLogger(t), now)
+		endpoints(int(sRes.DesiredPodCount))
+	}
+	// The observed load is 60, so the forecast must provision for more.
+	if got := sRes.DesiredPodCount; got <= 6 {
+		t.Errorf("Scale = %d, want more than 6", got)
+	}
+}
+
+func TestPredictiveNeverBelowStable(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 50, panicConcurrency: 50}
+	p := newTestAlgorithm(t, autoscaling.PredictiveAlgorithm, 10, metrics)
+	endpoints(5)
+
+	now := time.Now()
+	expectAlgorithmScale(t, p, now, 5, true)
+	// A sudden drop in the panic window is not followed until the stable window drops.
+	metrics.panicConcurrency = 0
+	expectAlgorithmScale(t, p, now.Add(2*time.Second), 5, true)
+}
+
+func TestPredictiveScaleToZero(t *testing.T) {
+	metrics := &testMetricClient{stableConcurrency: 5, panicConcurrency: 5}
+	p := newTestAlgorithm(t, autoscaling.PredictiveAlgorithm, 10, metrics)
+	now := time.Now()
+	expectAlgorithmScale(t, p, now, 1, true)
+
+	metrics.stableConcurrency, metrics.panicConcurrency = 0, 0
+	expectAlgorithmScale(t, p, now.Add(2*time.Second), 0, true)
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replay runs the autoscaler offline on a recorded trace of stats,
+// to compare the effects of autoscaler configurations.
+package replay
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler"
+	kparesources "knative.dev/serving/pkg/reconciler/autoscaling/kpa/resources"
+	aresources "knative.dev/serving/pkg/reconciler/autoscaling/resources"
+)
+
+// Options configure a replay.
+type Options struct {
+	// Config is the autoscaler configuration to replay the trace with.
+	Config *autoscaler.Config
+	// PodAutoscaler is the PA of the revision, carrying its container
+	// concurrency and autoscaling annotations.
+	PodAutoscaler *v1alpha1.PodAutoscaler
+	// InitialPods is the number of ready pods at the start of the trace.
+	InitialPods int
+	// PodStartupDelay is how long a new pod takes to become ready.
+	PodStartupDelay time.Duration
+}
+
+// Point is the state of the revision at a tick of the autoscaler.
+type Point struct {
+	// Offset is the time since the start of the trace in seconds.
+	Offset              float64 `json:"offset"`
+	ReadyPods           int     `json:"readyPods"`
+	DesiredPods         int32   `json:"desiredPods"`
+	ObservedStableValue float64 `json:"observedStableValue"`
+	ObservedPanicValue  float64 `json:"observedPanicValue"`
+	InPanicMode         bool    `json:"inPanicMode"`
+}
+
+// Interval is a period of time, in seconds since the start of the trace.
+type Interval struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Result is the outcome of a replay.
+type Result struct {
+	// ScalingMetric is the metric the observed values are of.
+	ScalingMetric string `json:"scalingMetric"`
+	// Points holds the state of the revision at every tick.
+	Points []Point `json:"points"`
+	// PanicIntervals holds the periods the autoscaler was in panic mode.
+	// A period lasting until the end of the trace ends at the last tick.
+	PanicIntervals []Interval `json:"panicIntervals"`
+}
+
+// Run replays the trace, which must be sorted by time, through the metric
+// collector and the scaling algorithm of the revision on a fake clock ticking
+// every tick-interval of the configuration from the first to the last stat.
+// Like the KPA, it keeps the desired scale within the scale bounds of the PA
+// and only scales to zero once the desired scale was zero for the scale to
+// zero grace period, if scale to zero is enabled.
+func Run(ctx context.Context, trace []autoscaler.Stat, opts Options) (*Result, error) {
+	if len(trace) == 0 {
+		return nil, errors.New("the trace is empty")
+	}
+	if opts.Config == nil || opts.PodAutoscaler == nil {
+		return nil, errors.New("a Config and a PodAutoscaler are required")
+	}
+	if opts.Config.TickInterval <= 0 {
+		return nil, fmt.Errorf("tick-interval must be positive, was %v", opts.Config.TickInterval)
+	}
+	logger := logging.FromContext(ctx)
+	pa := opts.PodAutoscaler
+
+	metric := aresources.MakeMetric(ctx, pa, pa.Name, opts.Config)
+	decider := kparesources.MakeDecider(ctx, pa, opts.Config, pa.Name)
+
+	// The stats are recorded from the trace only, so the scraper never returns any.
+	collector := autoscaler.NewMetricCollector(func(*v1alpha1.Metric) (autoscaler.StatsScraper, error) {
+		return nopScraper{}, nil
+	}, logger)
+	if err := collector.CreateOrUpdate(metric); err != nil {
+		return nil, err
+	}
+	defer collector.Delete(metric.Namespace, metric.Name)
+
+	newAlgorithm, err := autoscaler.NewDefaultAlgorithmRegistry().Get(decider.Spec.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	pods := &simulatedPods{ready: opts.InitialPods, startupDelay: opts.PodStartupDelay}
+	scaler, err := newAlgorithm(pa.Namespace, pa.Name, collector, pods, decider.Spec, nopReporter{})
+	if err != nil {
+		return nil, err
+	}
+
+	key := types.NamespacedName{Namespace: metric.Namespace, Name: metric.Name}
+	minScale, maxScale := pa.ScaleBounds()
+	start, end := *trace[0].Time, *trace[len(trace)-1].Time
+	result := &Result{ScalingMetric: decider.Spec.ScalingMetric}
+	desired := int32(opts.InitialPods)
+	var zeroSince *time.Time
+	var panicStart *float64
+
+	next := 0
+	for now := start; !now.After(end); now = now.Add(opts.Config.TickInterval) {
+		for ; next < len(trace) && !trace[next].Time.After(now); next++ {
+			collector.Record(key, trace[next])
+		}
+		pods.advance(now)
+		offset := now.Sub(start).Seconds()
+
+		point := Point{Offset: offset}
+		if sRes, ok := scaler.Scale(ctx, now); ok && sRes.DesiredPodCount >= 0 {
+			desired = applyBounds(minScale, maxScale, sRes.DesiredPodCount)
+			point.ObservedStableValue = sRes.ObservedStableValue
+			point.ObservedPanicValue = sRes.ObservedPanicValue
+			point.InPanicMode = sRes.InPanicMode
+		}
+
+		if desired == 0 {
+			if zeroSince == nil {
+				// now is the loop variable and advances with the loop.
+				t := now
+				zeroSince = &t
+			}
+			if !opts.Config.EnableScaleToZero || now.Sub(*zeroSince) < opts.Config.ScaleToZeroGracePeriod {
+				desired = 1
+			}
+		} else {
+			zeroSince = nil
+		}
+		pods.scale(int(desired), now)
+
+		point.ReadyPods, point.DesiredPods = pods.ready, desired
+		result.Points = append(result.Points, point)
+
+		if point.InPanicMode && panicStart == nil {
+			panicStart = &offset
+		} else if !point.InPanicMode && panicStart != nil {
+			result.PanicIntervals = append(result.PanicIntervals, Interval{Start: *panicStart, End: offset})
+			panicStart = nil
+		}
+	}
+	if panicStart != nil {
+		result.PanicIntervals = append(result.PanicIntervals,
+			Interval{Start: *panicStart, End: result.Points[len(result.Points)-1].Offset})
+	}
+	return result, nil
+}
+
+// applyBounds keeps x within the scale bounds, where a max of 0 is unbounded.
+func applyBounds(min, max, x int32) int32 {
+	if x < min {
+		return min
+	}
+	if max != 0 && x > max {
+		return max
+	}
+	return x
+}
+
+// simulatedPods is the deployment of the revision. Pods become ready after
+// the startup delay and are removed immediately.
+type simulatedPods struct {
+	ready        int
+	startupDelay time.Duration
+	// starting holds the times the starting pods become ready at, in order.
+	starting []time.Time
+}
+
+// ReadyCount implements resources.ReadyPodCounter.
+func (p *simulatedPods) ReadyCount() (int, error) {
+	return p.ready, nil
+}
+
+// advance marks the pods which finished starting up by now as ready.
+func (p *simulatedPods) advance(now time.Time) {
+	for len(p.starting) > 0 && !p.starting[0].After(now) {
+		p.starting = p.starting[1:]
+		p.ready++
+	}
+}
+
+// scale starts or removes pods, removing starting pods before ready ones.
+func (p *simulatedPods) scale(desired int, now time.Time) {
+	for total := p.ready + len(p.starting); total < desired; total++ {
+		p.starting = append(p.starting, now.Add(p.startupDelay))
+	}
+	for total := p.ready + len(p.starting); total > desired; total-- {
+		if len(p.starting) > 0 {
+			p.starting = p.starting[:len(p.starting)-1]
+		} else {
+			p.ready--
+		}
+	}
+	p.advance(now)
+}
+
+type nopScraper struct{}
+
+// Scrape implements autoscaler.StatsScraper.
+func (nopScraper) Scrape() (*autoscaler.StatMessage, error) {
+	return nil, nil
+}
+
+type nopReporter struct{}
+
+func (nopReporter) ReportDesiredPodCount(v int64) error            { return nil }
+func (nopReporter) ReportRequestedPodCount(v int64) error          { return nil }
+func (nopReporter) ReportActualPodCount(v int64) error             { return nil }
+func (nopReporter) ReportStableRequestConcurrency(v float64) error { return nil }
+func (nopReporter) ReportPanicRequestConcurrency(v float64) error  { return nil }
+func (nopReporter) ReportTargetRequestConcurrency(v float64) error { return nil }
+func (nopReporter) ReportStableRPS(v float64) error                { return nil }
+func (nopReporter) ReportPanicRPS(v float64) error                 { return nil }
+func (nopReporter) ReportTargetRPS(v float64) error                { return nil }
+func (nopReporter) ReportStableCustomMetric(v float64) error       { return nil }
+func (nopReporter) ReportPanicCustomMetric(v float64) error        { return nil }
+func (nopReporter) ReportTargetCustomMetric(v float64) error       { return nil }
+func (nopReporter) ReportExcessBurstCapacity(v float64) error      { return nil }
+func (nopReporter) ReportPanic(v int64) error                      { return nil }
+
+// WriteCSV writes the points as CSV, followed by an empty line and the panic
+// intervals as CSV.
+func (r *Result) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"offset", "readyPods", "desiredPods", "observedStableValue", "observedPanicValue", "inPanicMode"})
+	for _, p := range r.Points {
+		cw.Write([]string{
+			formatFloat(p.Offset),
+			strconv.Itoa(p.ReadyPods),
+			strconv.Itoa(int(p.DesiredPods)),
+			formatFloat(p.ObservedStableValue),
+			formatFloat(p.ObservedPanicValue),
+			strconv.FormatBool(p.InPanicMode),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	cw.Write([]string{"panicStart", "panicEnd"})
+	for _, i := range r.PanicIntervals {
+		cw.Write([]string{formatFloat(i.Start), formatFloat(i.End)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the result as indented JSON.
+func (r *Result) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
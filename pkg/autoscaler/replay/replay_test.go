@@ -0,0 +1,246 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler"
+)
+
+func testConfig(t *testing.T, data map[string]string) *autoscaler.Config {
+	t.Helper()
+	config, err := autoscaler.NewConfigFromMap(data)
+	if err != nil {
+		t.Fatalf("NewConfigFromMap() = %v", err)
+	}
+	return config
+}
+
+func testPA(annotations map[string]string) *v1alpha1.PodAutoscaler {
+	return &v1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "replay",
+			Name:        "replay",
+			Annotations: annotations,
+		},
+		Spec: v1alpha1.PodAutoscalerSpec{
+			ContainerConcurrency: 10,
+		},
+	}
+}
+
+// burstTrace is a single pod under a concurrency of 5, which bursts to 100
+// between 10s and 20s.
+func burstTrace() []autoscaler.Stat {
+	var trace []autoscaler.Stat
+	for secs := 0; secs <= 120; secs += 2 {
+		concurrency := 5.0
+		if secs >= 10 && secs < 20 {
+			concurrency = 100
+		}
+		trace = append(trace, stat(float64(secs), "pod-1", concurrency, concurrency))
+	}
+	return trace
+}
+
+func TestRun(t *testing.T) {
+	result, err := Run(TestContextWithLogger(t), burstTrace(), Options{
+		Config:          testConfig(t, map[string]string{}),
+		PodAutoscaler:   testPA(nil),
+		InitialPods:     1,
+		PodStartupDelay: 4 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	if got, want := result.ScalingMetric, autoscaling.Concurrency; got != want {
+		t.Errorf("ScalingMetric = %q, want %q", got, want)
+	}
+	// One point every default tick interval of 2s.
+	if got, want := len(result.Points), 61; got != want {
+		t.Fatalf("len(Points) = %d, want %d", got, want)
+	}
+	if got, want := result.Points[1].Offset, 2.0; got != want {
+		t.Errorf("Points[1].Offset = %v, want %v", got, want)
+	}
+
+	maxPods := 0
+	for _, p := range result.Points {
+		if p.ReadyPods > maxPods {
+			maxPods = p.ReadyPods
+		}
+		if p.ReadyPods > int(p.DesiredPods) {
+			t.Errorf("At %vs ReadyPods = %d > DesiredPods = %d", p.Offset, p.ReadyPods, p.DesiredPods)
+		}
+	}
+	// A concurrency of 100 with a target of 7 per pod needs 15 pods.
+	if got, want := maxPods, 15; got != want {
+		t.Errorf("Max ReadyPods = %d, want %d", got, want)
+	}
+	// New pods take 4s to become ready.
+	if p := result.Points[5]; p.Offset != 10 || p.DesiredPods <= 1 || p.ReadyPods != 1 {
+		t.Errorf("Points[5] = %#v, want more desired than the 1 ready pod at 10s", p)
+	}
+
+	if got, want := len(result.PanicIntervals), 1; got != want {
+		t.Fatalf("PanicIntervals = %v, want %d interval", result.PanicIntervals, want)
+	}
+	if got := result.PanicIntervals[0]; got.Start != 10 || got.End <= 20 {
+		t.Errorf("PanicIntervals[0] = %#v, want to start at 10s and end after the burst", got)
+	}
+}
+
+func TestRunCompareConfigs(t *testing.T) {
+	run := func(stableWindow string) *Result {
+		result, err := Run(TestContextWithLogger(t), burstTrace(), Options{
+			Config:        testConfig(t, map[string]string{"stable-window": stableWindow, "panic-window-percentage": "50"}),
+			PodAutoscaler: testPA(nil),
+			InitialPods:   1,
+		})
+		if err != nil {
+			t.Fatalf("Run() = %v", err)
+		}
+		return result
+	}
+	long, short := run("60s"), run("10s")
+	if long.PanicIntervals[0].End <= short.PanicIntervals[0].End {
+		t.Errorf("Panic with a 60s stable window ends at %vs, want after the end with a 10s window at %vs",
+			long.PanicIntervals[0].End, short.PanicIntervals[0].End)
+	}
+}
+
+func TestRunScaleBounds(t *testing.T) {
+	result, err := Run(TestContextWithLogger(t), burstTrace(), Options{
+		Config: testConfig(t, map[string]string{}),
+		PodAutoscaler: testPA(map[string]string{
+			autoscaling.MinScaleAnnotationKey: "3",
+			autoscaling.MaxScaleAnnotationKey: "5",
+		}),
+		InitialPods: 3,
+	})
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	for _, p := range result.Points {
+		if p.DesiredPods < 3 || p.DesiredPods > 5 {
+			t.Errorf("At %vs DesiredPods = %d, want within [3, 5]", p.Offset, p.DesiredPods)
+		}
+	}
+}
+
+func TestRunScaleToZero(t *testing.T) {
+	// The pod keeps reporting once the traffic stops.
+	trace := []autoscaler.Stat{stat(0, "pod-1", 1, 1)}
+	for secs := 2; secs <= 120; secs += 2 {
+		trace = append(trace, stat(float64(secs), "pod-1", 0, 0))
+	}
+	for _, enabled := range []string{"true", "false"} {
+		t.Run("enable-scale-to-zero="+enabled, func(t *testing.T) {
+			result, err := Run(TestContextWithLogger(t), trace, Options{
+				Config: testConfig(t, map[string]string{
+					"enable-scale-to-zero":       enabled,
+					"stable-window":              "10s",
+					"panic-window-percentage":    "50",
+					"scale-to-zero-grace-period": "30s",
+				}),
+				PodAutoscaler: testPA(nil),
+				InitialPods:   1,
+			})
+			if err != nil {
+				t.Fatalf("Run() = %v", err)
+			}
+			var zeroAt float64 = -1
+			for _, p := range result.Points {
+				if p.DesiredPods == 0 {
+					zeroAt = p.Offset
+					break
+				}
+			}
+			switch {
+			case enabled == "false" && zeroAt >= 0:
+				t.Errorf("Scaled to zero at %vs, want never", zeroAt)
+			// The traffic leaves the 10s stable window, then the 30s grace period passes.
+			case enabled == "true" && zeroAt < 40:
+				t.Errorf("Scaled to zero at %vs, want after the stable window and grace period", zeroAt)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if _, err := Run(TestContextWithLogger(t), nil, Options{
+		Config:        testConfig(t, map[string]string{}),
+		PodAutoscaler: testPA(nil),
+	}); err == nil {
+		t.Error("Run() = nil, want an error for an empty trace")
+	}
+	if _, err := Run(TestContextWithLogger(t), burstTrace(), Options{}); err == nil {
+		t.Error("Run() = nil, want an error without Config and PodAutoscaler")
+	}
+	if _, err := Run(TestContextWithLogger(t), burstTrace(), Options{
+		Config:        testConfig(t, map[string]string{}),
+		PodAutoscaler: testPA(map[string]string{autoscaling.AlgorithmAnnotationKey: "unknown"}),
+	}); err == nil {
+		t.Error("Run() = nil, want an error for an unknown algorithm")
+	}
+}
+
+func TestResultWrite(t *testing.T) {
+	result := &Result{
+		ScalingMetric: autoscaling.Concurrency,
+		Points: []Point{
+			{Offset: 0, ReadyPods: 1, DesiredPods: 2, ObservedStableValue: 10, ObservedPanicValue: 20, InPanicMode: true},
+			{Offset: 2, ReadyPods: 2, DesiredPods: 2, ObservedStableValue: 10.5, ObservedPanicValue: 5},
+		},
+		PanicIntervals: []Interval{{Start: 0, End: 2}},
+	}
+
+	var buf bytes.Buffer
+	if err := result.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() = %v", err)
+	}
+	want := `offset,readyPods,desiredPods,observedStableValue,observedPanicValue,inPanicMode
+0,1,2,10,20,true
+2,2,2,10.5,5,false
+
+panicStart,panicEnd
+0,2
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := result.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() = %v", err)
+	}
+	for _, want := range []string{`"scalingMetric": "concurrency"`, `"inPanicMode": true`, `"end": 2`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteJSON() = %s, want it to contain %s", buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"knative.dev/serving/pkg/autoscaler"
+)
+
+// The columns of a CSV trace, respectively the keys of a JSONL trace.
+const (
+	timeKey                             = "time"
+	podNameKey                          = "podName"
+	averageConcurrentRequestsKey        = "averageConcurrentRequests"
+	averageProxiedConcurrentRequestsKey = "averageProxiedConcurrentRequests"
+	requestCountKey                     = "requestCount"
+	proxiedRequestCountKey              = "proxiedRequestCount"
+	customMetricKey                     = "customMetric"
+)
+
+// ReadCSV reads a trace of autoscaler.Stats from CSV. The first row is a
+// header naming the columns "time" and "podName" and, optionally,
+// "averageConcurrentRequests", "averageProxiedConcurrentRequests",
+// "requestCount", "proxiedRequestCount" and "customMetric". The times are either RFC 3339
+// timestamps or offsets in seconds. The stats are returned sorted by time.
+func ReadCSV(r io.Reader) ([]autoscaler.Stat, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{timeKey, podNameKey} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	stats := make([]autoscaler.Stat, 0, len(records)-1)
+	for i, record := range records[1:] {
+		row := i + 2
+		t, err := parseTime(record[columns[timeKey]])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		stat := autoscaler.Stat{
+			Time:    &t,
+			PodName: record[columns[podNameKey]],
+		}
+		for key, value := range map[string]*float64{
+			averageConcurrentRequestsKey:        &stat.AverageConcurrentRequests,
+			averageProxiedConcurrentRequestsKey: &stat.AverageProxiedConcurrentRequests,
+			requestCountKey:                     &stat.RequestCount,
+			proxiedRequestCountKey:              &stat.ProxiedRequestCount,
+			customMetricKey:                     &stat.CustomMetric,
+		} {
+			i, ok := columns[key]
+			if !ok || strings.TrimSpace(record[i]) == "" {
+				continue
+			}
+			if *value, err = strconv.ParseFloat(strings.TrimSpace(record[i]), 64); err != nil {
+				return nil, fmt.Errorf("row %d: invalid %s: %v", row, key, err)
+			}
+		}
+		stats = append(stats, stat)
+	}
+	sortByTime(stats)
+	return stats, nil
+}
+
+// jsonStat is a line of a JSONL trace.
+type jsonStat struct {
+	Time                             json.RawMessage `json:"time"`
+	PodName                          string          `json:"podName"`
+	AverageConcurrentRequests        float64         `json:"averageConcurrentRequests"`
+	AverageProxiedConcurrentRequests float64         `json:"averageProxiedConcurrentRequests"`
+	RequestCount                     float64         `json:"requestCount"`
+	ProxiedRequestCount              float64         `json:"proxiedRequestCount"`
+	CustomMetric                     float64         `json:"customMetric"`
+}
+
+// ReadJSONL reads a trace of autoscaler.Stats from JSON lines, one object per
+// line with the same keys as the columns of a CSV trace. The times are either
+// RFC 3339 timestamp strings or offsets in seconds. Empty lines are skipped.
+// The stats are returned sorted by time.
+func ReadJSONL(r io.Reader) ([]autoscaler.Stat, error) {
+	var stats []autoscaler.Stat
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var js jsonStat
+		if err := json.Unmarshal(scanner.Bytes(), &js); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if len(js.Time) == 0 {
+			return nil, fmt.Errorf("line %d: missing %s", line, timeKey)
+		}
+		raw := string(js.Time)
+		if unquoted, err := strconv.Unquote(raw); err == nil {
+			raw = unquoted
+		}
+		t, err := parseTime(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		stats = append(stats, autoscaler.Stat{
+			Time:                             &t,
+			PodName:                          js.PodName,
+			AverageConcurrentRequests:        js.AverageConcurrentRequests,
+			AverageProxiedConcurrentRequests: js.AverageProxiedConcurrentRequests,
+			RequestCount:                     js.RequestCount,
+			ProxiedRequestCount:              js.ProxiedRequestCount,
+			CustomMetric:                     js.CustomMetric,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortByTime(stats)
+	return stats, nil
+}
+
+// parseTime parses an RFC 3339 timestamp or an offset in seconds, which is
+// relative to the Unix epoch.
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, 0).Add(time.Duration(secs * float64(time.Second))).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q, want an RFC 3339 timestamp or seconds", timeKey, s)
+	}
+	return t, nil
+}
+
+func sortByTime(stats []autoscaler.Stat) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Time.Before(*stats[j].Time)
+	})
+}
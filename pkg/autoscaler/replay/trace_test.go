@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"knative.dev/serving/pkg/autoscaler"
+)
+
+func stat(secs float64, pod string, concurrency, requests float64) autoscaler.Stat {
+	t := time.Unix(0, 0).Add(time.Duration(secs * float64(time.Second))).UTC()
+	return autoscaler.Stat{
+		Time:                      &t,
+		PodName:                   pod,
+		AverageConcurrentRequests: concurrency,
+		RequestCount:              requests,
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	proxied := stat(1.5, "pod-2", 4, 0)
+	proxied.AverageProxiedConcurrentRequests = 1
+	proxied.ProxiedRequestCount = 2
+	proxied.CustomMetric = 3
+
+	tests := []struct {
+		name    string
+		in      string
+		want    []autoscaler.Stat
+		wantErr bool
+	}{{
+		name: "sorted by time",
+		in: `time,podName,averageConcurrentRequests,requestCount
+2,pod-1,3,5
+1,pod-1,1,2
+`,
+		want: []autoscaler.Stat{stat(1, "pod-1", 1, 2), stat(2, "pod-1", 3, 5)},
+	}, {
+		name: "any column order and optional columns",
+		in: `requestCount,proxiedRequestCount,podName,time,averageConcurrentRequests,averageProxiedConcurrentRequests,customMetric
+,2,pod-2,1.5,4,1,3
+`,
+		want: []autoscaler.Stat{proxied},
+	}, {
+		name: "timestamps",
+		in: `time,podName
+1970-01-01T00:00:01Z,pod-1
+`,
+		want: []autoscaler.Stat{stat(1, "pod-1", 0, 0)},
+	}, {
+		name:    "missing time column",
+		in:      "podName\npod-1\n",
+		wantErr: true,
+	}, {
+		name:    "invalid time",
+		in:      "time,podName\nyesterday,pod-1\n",
+		wantErr: true,
+	}, {
+		name:    "invalid value",
+		in:      "time,podName,requestCount\n1,pod-1,many\n",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ReadCSV(strings.NewReader(test.in))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ReadCSV() = %v, wantErr %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ReadCSV() (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func TestReadJSONL(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []autoscaler.Stat
+		wantErr bool
+	}{{
+		name: "seconds and timestamps",
+		in: `{"time": 2, "podName": "pod-1", "averageConcurrentRequests": 3, "requestCount": 5}
+
+{"time": "1970-01-01T00:00:01Z", "podName": "pod-1", "averageConcurrentRequests": 1, "requestCount": 2}
+`,
+		want: []autoscaler.Stat{stat(1, "pod-1", 1, 2), stat(2, "pod-1", 3, 5)},
+	}, {
+		name:    "missing time",
+		in:      `{"podName": "pod-1"}`,
+		wantErr: true,
+	}, {
+		name:    "invalid json",
+		in:      `{"time": 1,`,
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ReadJSONL(strings.NewReader(test.in))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ReadJSONL() = %v, wantErr %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ReadJSONL() (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"knative.dev/serving/pkg/autoscaler/aggregation"
+)
+
+const (
+	// snapshotConfigMapKey is the key of the ConfigMap data holding the snapshot.
+	snapshotConfigMapKey = "snapshot"
+	// maxSnapshotBytes is the maximal size of a snapshot kept in a ConfigMap,
+	// which is limited to 1MiB including its metadata.
+	maxSnapshotBytes = 1000 * 1000
+)
+
+// CollectionSnapshot is a serializable copy of the metric buckets of a single collection.
+type CollectionSnapshot struct {
+	Namespace   string                       `json:"namespace"`
+	Name        string                       `json:"name"`
+	Concurrency []aggregation.BucketSnapshot `json:"concurrency,omitempty"`
+	RPS         []aggregation.BucketSnapshot `json:"rps,omitempty"`
+	Custom      []aggregation.BucketSnapshot `json:"custom,omitempty"`
+}
+
+// CollectorSnapshot is a serializable copy of the metric buckets of a MetricCollector.
+type CollectorSnapshot struct {
+	// Time is when the snapshot was taken.
+	Time        time.Time            `json:"time"`
+	Collections []CollectionSnapshot `json:"collections"`
+}
+
+// SnapshotStore persists CollectorSnapshots across autoscaler restarts. The
+// snapshots are saved per bucket of revisions, so that the autoscaler replicas
+// leading different buckets don't overwrite each other's snapshots.
+type SnapshotStore interface {
+	// Save persists the snapshot of the bucket, replacing the previously
+	// saved one.
+	Save(bucket string, snapshot *CollectorSnapshot) error
+	// Load returns the last saved snapshot of the bucket, or nil if there
+	// is none.
+	Load(bucket string) (*CollectorSnapshot, error)
+}
+
+// configMapSnapshotStore keeps the snapshot of every bucket in a ConfigMap
+// of its own.
+type configMapSnapshotStore struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+	name       string
+}
+
+var _ SnapshotStore = (*configMapSnapshotStore)(nil)
+
+// NewConfigMapSnapshotStore creates a SnapshotStore keeping the snapshots in
+// the ConfigMaps named after the given name and the bucket, which are created
+// if they do not exist yet. As ConfigMaps are limited to 1MiB of data, the
+// collections which don't fit are dropped from a snapshot.
+func NewConfigMapSnapshotStore(kubeClient kubernetes.Interface, namespace, name string) SnapshotStore {
+	return &configMapSnapshotStore{
+		kubeClient: kubeClient,
+		namespace:  namespace,
+		name:       name,
+	}
+}
+
+func (s *configMapSnapshotStore) configMapName(bucket string) string {
+	return s.name + "-" + bucket
+}
+
+// Save implements SnapshotStore.
+func (s *configMapSnapshotStore) Save(bucket string, snapshot *CollectorSnapshot) error {
+	data, dropped, err := marshalCapped(snapshot, maxSnapshotBytes)
+	if err != nil {
+		return err
+	}
+	if err := s.save(s.configMapName(bucket), data); err != nil {
+		return err
+	}
+	if dropped > 0 {
+		return fmt.Errorf("dropped %d of %d collections from the snapshot of bucket %s to fit into a ConfigMap",
+			dropped, len(snapshot.Collections), bucket)
+	}
+	return nil
+}
+
+func (s *configMapSnapshotStore) save(name string, data []byte) error {
+	cm, err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = s.kubeClient.CoreV1().ConfigMaps(s.namespace).Create(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      name,
+			},
+			Data: map[string]string{snapshotConfigMapKey: string(data)},
+		})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	cm = cm.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = make(map[string]string, 1)
+	}
+	cm.Data[snapshotConfigMapKey] = string(data)
+	_, err = s.kubeClient.CoreV1().ConfigMaps(s.namespace).Update(cm)
+	return err
+}
+
+// Load implements SnapshotStore.
+func (s *configMapSnapshotStore) Load(bucket string) (*CollectorSnapshot, error) {
+	cm, err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Get(s.configMapName(bucket), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	data, ok := cm.Data[snapshotConfigMapKey]
+	if !ok {
+		return nil, nil
+	}
+	snapshot := &CollectorSnapshot{}
+	if err := json.Unmarshal([]byte(data), snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// marshalCapped marshals the snapshot, dropping collections until it fits into
+// maxBytes. It returns the number of collections dropped.
+func marshalCapped(snapshot *CollectorSnapshot, maxBytes int) ([]byte, int, error) {
+	capped := *snapshot
+	for {
+		data, err := json.Marshal(&capped)
+		if err != nil || len(data) <= maxBytes || len(capped.Collections) == 0 {
+			return data, len(snapshot.Collections) - len(capped.Collections), err
+		}
+		// Drop collections in proportion to the excess, but at least one.
+		keep := len(capped.Collections) * maxBytes / len(data)
+		if keep >= len(capped.Collections) {
+			keep = len(capped.Collections) - 1
+		}
+		capped.Collections = capped.Collections[:keep]
+	}
+}
+
+// fileSnapshotStore keeps the snapshot of every bucket in a file of its own in
+// a local directory.
+type fileSnapshotStore struct {
+	dir string
+}
+
+var _ SnapshotStore = (*fileSnapshotStore)(nil)
+
+// NewFileSnapshotStore creates a SnapshotStore keeping the snapshots in the
+// files named after the buckets in the given directory.
+func NewFileSnapshotStore(dir string) SnapshotStore {
+	return &fileSnapshotStore{dir: dir}
+}
+
+func (s *fileSnapshotStore) path(bucket string) string {
+	return filepath.Join(s.dir, bucket+".json")
+}
+
+// Save implements SnapshotStore.
+func (s *fileSnapshotStore) Save(bucket string, snapshot *CollectorSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that a crash never leaves
+	// a partially written snapshot behind.
+	tmp, err := ioutil.TempFile(s.dir, bucket+".json.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(bucket))
+}
+
+// Load implements SnapshotStore.
+func (s *fileSnapshotStore) Load(bucket string) (*CollectorSnapshot, error) {
+	data, err := ioutil.ReadFile(s.path(bucket))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	snapshot := &CollectorSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
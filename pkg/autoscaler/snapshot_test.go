@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+
+	"knative.dev/serving/pkg/autoscaler/aggregation"
+)
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	return dir
+}
+
+func testSnapshot(now time.Time) *CollectorSnapshot {
+	return &CollectorSnapshot{
+		Time: now,
+		Collections: []CollectionSnapshot{{
+			Namespace: testNamespace,
+			Name:      testRevision,
+			Concurrency: []aggregation.BucketSnapshot{{
+				Time:   now,
+				Values: map[string]aggregation.ValueSnapshot{"pod": {Sum: 3, Count: 2}},
+			}},
+		}},
+	}
+}
+
+func TestSnapshotStores(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	stores := map[string]SnapshotStore{
+		"file":      NewFileSnapshotStore(dir),
+		"configmap": NewConfigMapSnapshotStore(fakek8s.NewSimpleClientset(), testNamespace, "autoscaler-snapshot"),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			// Nothing was saved yet.
+			if got, err := store.Load("bucket-0"); got != nil || err != nil {
+				t.Fatalf("Load() = %v, %v; want nil, nil", got, err)
+			}
+
+			// Roundtrip and overwrite the snapshot.
+			now := time.Now().UTC().Truncate(time.Second)
+			for _, want := range []*CollectorSnapshot{testSnapshot(now.Add(-time.Minute)), testSnapshot(now)} {
+				if err := store.Save("bucket-0", want); err != nil {
+					t.Fatalf("Save() = %v", err)
+				}
+				got, err := store.Load("bucket-0")
+				if err != nil {
+					t.Fatalf("Load() = %v", err)
+				}
+				if !cmp.Equal(want, got) {
+					t.Errorf("Load() (-want, +got): %s", cmp.Diff(want, got))
+				}
+			}
+
+			// The buckets don't overwrite each other.
+			other := testSnapshot(now.Add(time.Minute))
+			if err := store.Save("bucket-1", other); err != nil {
+				t.Fatalf("Save() = %v", err)
+			}
+			if got, err := store.Load("bucket-0"); err != nil || !got.Time.Equal(now) {
+				t.Errorf("Load(bucket-0) = %v, %v; want the snapshot taken at %v", got, err, now)
+			}
+			if got, err := store.Load("bucket-1"); err != nil || !cmp.Equal(other, got) {
+				t.Errorf("Load(bucket-1) = %v, %v; want %v", got, err, other)
+			}
+		})
+	}
+}
+
+func TestFileSnapshotStoreCorrupted(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "bucket-0.json")
+	if err := ioutil.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if _, err := NewFileSnapshotStore(dir).Load("bucket-0"); err == nil {
+		t.Error("Load() = nil, wanted an error")
+	}
+}
+
+func TestConfigMapSnapshotStoreCapped(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	snapshot := &CollectorSnapshot{Time: now}
+	for i := 0; i < 100; i++ {
+		cs := testSnapshot(now).Collections[0]
+		cs.Name = fmt.Sprintf("%s-%d", testRevision, i)
+		snapshot.Collections = append(snapshot.Collections, cs)
+	}
+
+	data, dropped, err := marshalCapped(snapshot, 1000)
+	if err != nil {
+		t.Fatalf("marshalCapped() = %v", err)
+	}
+	if len(data) > 1000 {
+		t.Errorf("len(data) = %d, want at most 1000", len(data))
+	}
+	if dropped == 0 || dropped == len(snapshot.Collections) {
+		t.Errorf("dropped = %d, want some but not all collections dropped", dropped)
+	}
+
+	// Snapshots beyond the ConfigMap limit are saved capped, with an error.
+	for len(snapshot.Collections) < 10000 {
+		snapshot.Collections = append(snapshot.Collections, snapshot.Collections...)
+	}
+	store := NewConfigMapSnapshotStore(fakek8s.NewSimpleClientset(), testNamespace, "autoscaler-snapshot")
+	if err := store.Save("bucket-0", snapshot); err == nil {
+		t.Error("Save() = nil, wanted an error about the dropped collections")
+	}
+	got, err := store.Load("bucket-0")
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if len(got.Collections) == 0 || len(got.Collections) >= len(snapshot.Collections) {
+		t.Errorf("len(Collections) = %d, want between 0 and %d", len(got.Collections), len(snapshot.Collections))
+	}
+}
@@ -57,6 +57,30 @@ var (
 		"target_concurrency_per_pod",
 		"The desired number of concurrent requests for each pod",
 		stats.UnitDimensionless)
+	stableRPSM = stats.Float64(
+		"stable_requests_per_second",
+		"Average requests-per-second per observed pod over the stable window",
+		stats.UnitDimensionless)
+	panicRPSM = stats.Float64(
+		"panic_requests_per_second",
+		"Average requests-per-second per observed pod over the panic window",
+		stats.UnitDimensionless)
+	targetRPSM = stats.Float64(
+		"target_requests_per_second",
+		"The desired requests-per-second for each pod",
+		stats.UnitDimensionless)
+	stableCustomMetricM = stats.Float64(
+		"stable_custom_metric",
+		"Average value of the custom metric per observed pod over the stable window",
+		stats.UnitDimensionless)
+	panicCustomMetricM = stats.Float64(
+		"panic_custom_metric",
+		"Average value of the custom metric per observed pod over the panic window",
+		stats.UnitDimensionless)
+	targetCustomMetricM = stats.Float64(
+		"target_custom_metric_per_pod",
+		"The desired value of the custom metric for each pod",
+		stats.UnitDimensionless)
 	panicM = stats.Int64(
 		"panic_mode",
 		"1 if autoscaler is in panic mode, 0 otherwise",
@@ -141,6 +165,42 @@ func register() {
 			Aggregation: view.LastValue(),
 			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
 		},
+		&view.View{
+			Description: "Average requests-per-second over the stable window",
+			Measure:     stableRPSM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
+		},
+		&view.View{
+			Description: "Average requests-per-second over the panic window",
+			Measure:     panicRPSM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
+		},
+		&view.View{
+			Description: "The desired requests-per-second for each pod",
+			Measure:     targetRPSM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
+		},
+		&view.View{
+			Description: "Average value of the custom metric over the stable window",
+			Measure:     stableCustomMetricM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
+		},
+		&view.View{
+			Description: "Average value of the custom metric over the panic window",
+			Measure:     panicCustomMetricM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
+		},
+		&view.View{
+			Description: "The desired value of the custom metric for each pod",
+			Measure:     targetCustomMetricM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
+		},
 		&view.View{
 			Description: "1 if autoscaler is in panic mode, 0 otherwise",
 			Measure:     panicM,
@@ -161,6 +221,12 @@ type StatsReporter interface {
 	ReportStableRequestConcurrency(v float64) error
 	ReportPanicRequestConcurrency(v float64) error
 	ReportTargetRequestConcurrency(v float64) error
+	ReportStableRPS(v float64) error
+	ReportPanicRPS(v float64) error
+	ReportTargetRPS(v float64) error
+	ReportStableCustomMetric(v float64) error
+	ReportPanicCustomMetric(v float64) error
+	ReportTargetCustomMetric(v float64) error
 	ReportExcessBurstCapacity(v float64) error
 	ReportPanic(v int64) error
 }
@@ -235,6 +301,36 @@ func (r *Reporter) ReportTargetRequestConcurrency(v float64) error {
 	return r.report(targetRequestConcurrencyM.M(v))
 }
 
+// ReportStableRPS captures value v for stable RPS measure.
+func (r *Reporter) ReportStableRPS(v float64) error {
+	return r.report(stableRPSM.M(v))
+}
+
+// ReportPanicRPS captures value v for panic RPS measure.
+func (r *Reporter) ReportPanicRPS(v float64) error {
+	return r.report(panicRPSM.M(v))
+}
+
+// ReportTargetRPS captures value v for target requests-per-second-per-pod measure.
+func (r *Reporter) ReportTargetRPS(v float64) error {
+	return r.report(targetRPSM.M(v))
+}
+
+// ReportStableCustomMetric captures value v for stable custom metric measure.
+func (r *Reporter) ReportStableCustomMetric(v float64) error {
+	return r.report(stableCustomMetricM.M(v))
+}
+
+// ReportPanicCustomMetric captures value v for panic custom metric measure.
+func (r *Reporter) ReportPanicCustomMetric(v float64) error {
+	return r.report(panicCustomMetricM.M(v))
+}
+
+// ReportTargetCustomMetric captures value v for target custom-metric-per-pod measure.
+func (r *Reporter) ReportTargetCustomMetric(v float64) error {
+	return r.report(targetCustomMetricM.M(v))
+}
+
 // ReportPanic captures value v for panic mode measure.
 func (r *Reporter) ReportPanic(v int64) error {
 	return r.report(panicM.M(v))
@@ -63,6 +63,12 @@ func TestReporterReport(t *testing.T) {
 	expectSuccess(t, "ReportPanicRequestConcurrency", func() error { return r.ReportPanicRequestConcurrency(3) })
 	expectSuccess(t, "ReportTargetRequestConcurrency", func() error { return r.ReportTargetRequestConcurrency(0.9) })
 	expectSuccess(t, "ReportExcessBurstCapacity", func() error { return r.ReportExcessBurstCapacity(19.84) })
+	expectSuccess(t, "ReportStableRPS", func() error { return r.ReportStableRPS(4) })
+	expectSuccess(t, "ReportPanicRPS", func() error { return r.ReportPanicRPS(5) })
+	expectSuccess(t, "ReportTargetRPS", func() error { return r.ReportTargetRPS(6) })
+	expectSuccess(t, "ReportStableCustomMetric", func() error { return r.ReportStableCustomMetric(7) })
+	expectSuccess(t, "ReportPanicCustomMetric", func() error { return r.ReportPanicCustomMetric(8) })
+	expectSuccess(t, "ReportTargetCustomMetric", func() error { return r.ReportTargetCustomMetric(9) })
 	metricstest.CheckLastValueData(t, "desired_pods", wantTags, 10)
 	metricstest.CheckLastValueData(t, "requested_pods", wantTags, 7)
 	metricstest.CheckLastValueData(t, "actual_pods", wantTags, 5)
@@ -71,6 +77,12 @@ func TestReporterReport(t *testing.T) {
 	metricstest.CheckLastValueData(t, "excess_burst_capacity", wantTags, 19.84)
 	metricstest.CheckLastValueData(t, "panic_request_concurrency", wantTags, 3)
 	metricstest.CheckLastValueData(t, "target_concurrency_per_pod", wantTags, 0.9)
+	metricstest.CheckLastValueData(t, "stable_requests_per_second", wantTags, 4)
+	metricstest.CheckLastValueData(t, "panic_requests_per_second", wantTags, 5)
+	metricstest.CheckLastValueData(t, "target_requests_per_second", wantTags, 6)
+	metricstest.CheckLastValueData(t, "stable_custom_metric", wantTags, 7)
+	metricstest.CheckLastValueData(t, "panic_custom_metric", wantTags, 8)
+	metricstest.CheckLastValueData(t, "target_custom_metric_per_pod", wantTags, 9)
 
 	// All the stats are gauges - record multiple entries for one stat - last one should stick
 	expectSuccess(t, "ReportDesiredPodCount", func() error { return r.ReportDesiredPodCount(1) })
@@ -129,6 +141,12 @@ func resetMetrics() {
 		panicRequestConcurrencyM.Name(),
 		excessBurstCapacityM.Name(),
 		targetRequestConcurrencyM.Name(),
+		stableRPSM.Name(),
+		panicRPSM.Name(),
+		targetRPSM.Name(),
+		stableCustomMetricM.Name(),
+		panicCustomMetricM.Name(),
+		targetCustomMetricM.Name(),
 		panicM.Name())
 	register()
 }
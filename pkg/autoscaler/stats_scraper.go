@@ -171,6 +171,8 @@ func (s *ServiceScraper) Scrape() (*StatMessage, error) {
 		avgProxiedConcurrency float64
 		reqCount              float64
 		proxiedReqCount       float64
+		customMetric          float64
+		avgStreams            float64
 		successCount          float64
 	)
 
@@ -180,6 +182,8 @@ func (s *ServiceScraper) Scrape() (*StatMessage, error) {
 		avgProxiedConcurrency += stat.AverageProxiedConcurrentRequests
 		reqCount += stat.RequestCount
 		proxiedReqCount += stat.ProxiedRequestCount
+		customMetric += stat.CustomMetric
+		avgStreams += stat.AverageConcurrentStreams
 	}
 
 	frpc := float64(readyPodsCount)
@@ -187,6 +191,8 @@ func (s *ServiceScraper) Scrape() (*StatMessage, error) {
 	avgProxiedConcurrency = avgProxiedConcurrency / successCount
 	reqCount = reqCount / successCount
 	proxiedReqCount = proxiedReqCount / successCount
+	customMetric = customMetric / successCount
+	avgStreams = avgStreams / successCount
 	now := time.Now()
 
 	// Assumption: A particular pod can stand for other pods, i.e. other pods
@@ -203,6 +209,8 @@ func (s *ServiceScraper) Scrape() (*StatMessage, error) {
 		AverageProxiedConcurrentRequests: avgProxiedConcurrency * frpc,
 		RequestCount:                     reqCount * frpc,
 		ProxiedRequestCount:              proxiedReqCount * frpc,
+		CustomMetric:                     customMetric * frpc,
+		AverageConcurrentStreams:         avgStreams * frpc,
 	}
 
 	return &StatMessage{
@@ -45,18 +45,21 @@ var (
 			AverageProxiedConcurrentRequests: 2.0,
 			RequestCount:                     5,
 			ProxiedRequestCount:              4,
+			CustomMetric:                     1,
 		}, {
 			PodName:                          "pod-2",
 			AverageConcurrentRequests:        5.0,
 			AverageProxiedConcurrentRequests: 4.0,
 			RequestCount:                     7,
 			ProxiedRequestCount:              6,
+			CustomMetric:                     2,
 		}, {
 			PodName:                          "pod-3",
 			AverageConcurrentRequests:        3.0,
 			AverageProxiedConcurrentRequests: 2.0,
 			RequestCount:                     5,
 			ProxiedRequestCount:              4,
+			CustomMetric:                     3,
 		},
 	}
 )
@@ -172,6 +175,10 @@ func TestScrapeReportStatWhenAllCallsSucceed(t *testing.T) {
 	if got.Stat.ProxiedRequestCount != 14 {
 		t.Errorf("StatMessage.Stat.ProxiedCount=%v, want %v", got.Stat.ProxiedRequestCount, 12)
 	}
+	// ((1 + 2 + 3) / 3.0) * 3 = 6
+	if got.Stat.CustomMetric != 6 {
+		t.Errorf("StatMessage.Stat.CustomMetric=%v, want %v", got.Stat.CustomMetric, 6)
+	}
 }
 
 func TestScrapeReportErrorCannotFindEnoughPods(t *testing.T) {
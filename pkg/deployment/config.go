@@ -18,7 +18,10 @@ package deployment
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -31,6 +34,10 @@ const (
 	// QueueSidecarImageKey is the config map key for queue sidecar image
 	QueueSidecarImageKey           = "queueSidecarImage"
 	registriesSkippingTagResolving = "registriesSkippingTagResolving"
+	enableRequestPriorityKey       = "enableRequestPriority"
+	lowPriorityWaitBudgetKey       = "lowPriorityWaitBudget"
+	streamIdleTimeoutKey           = "streamIdleTimeout"
+	excludeStreamsKey              = "excludeStreamsFromConcurrency"
 )
 
 // NewConfigFromMap creates a DeploymentConfig from the supplied Map
@@ -48,6 +55,44 @@ func NewConfigFromMap(configMap map[string]string) (*Config, error) {
 	} else {
 		nc.RegistriesSkippingTagResolving = sets.NewString(strings.Split(registries, ",")...)
 	}
+
+	if raw, ok := configMap[enableRequestPriorityKey]; ok {
+		enable, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", enableRequestPriorityKey, err)
+		}
+		nc.EnableRequestPriority = enable
+	}
+
+	if raw, ok := configMap[lowPriorityWaitBudgetKey]; ok {
+		budget, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", lowPriorityWaitBudgetKey, err)
+		}
+		if budget < 0 {
+			return nil, fmt.Errorf("%s = %v, must be at least 0", lowPriorityWaitBudgetKey, budget)
+		}
+		nc.LowPriorityWaitBudget = budget
+	}
+
+	if raw, ok := configMap[streamIdleTimeoutKey]; ok {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", streamIdleTimeoutKey, err)
+		}
+		if timeout < 0 {
+			return nil, fmt.Errorf("%s = %v, must be at least 0", streamIdleTimeoutKey, timeout)
+		}
+		nc.StreamIdleTimeout = timeout
+	}
+
+	if raw, ok := configMap[excludeStreamsKey]; ok {
+		exclude, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", excludeStreamsKey, err)
+		}
+		nc.ExcludeStreamsFromConcurrency = exclude
+	}
 	return nc, nil
 }
 
@@ -64,4 +109,22 @@ type Config struct {
 
 	// Repositories for which tag to digest resolving should be skipped
 	RegistriesSkippingTagResolving sets.String
+
+	// EnableRequestPriority makes the queue sidecar admit the requests
+	// waiting for capacity by their priority class.
+	EnableRequestPriority bool
+
+	// LowPriorityWaitBudget is how long low priority requests wait for
+	// capacity in the queue sidecar before they are shed. 0 means they wait
+	// until they time out. It only applies with EnableRequestPriority.
+	LowPriorityWaitBudget time.Duration
+
+	// StreamIdleTimeout is how long WebSocket connections and server-sent
+	// events may idle before the queue sidecar closes them. 0 means they're
+	// never closed for being idle.
+	StreamIdleTimeout time.Duration
+
+	// ExcludeStreamsFromConcurrency keeps the requests that turned into
+	// streams out of the concurrency reported for autoscaling.
+	ExcludeStreamsFromConcurrency bool
 }
@@ -18,6 +18,7 @@ package deployment
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
@@ -82,6 +83,116 @@ func TestControllerConfiguration(t *testing.T) {
 				registriesSkippingTagResolving: "ko.local,ko.dev",
 			},
 		},
+	}, {
+		name:    "controller configuration with request priority",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			EnableRequestPriority:          true,
+			LowPriorityWaitBudget:          5 * time.Second,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:     noSidecarImage,
+				enableRequestPriorityKey: "true",
+				lowPriorityWaitBudgetKey: "5s",
+			},
+		},
+	}, {
+		name:           "controller configuration with bad request priority enablement",
+		wantErr:        true,
+		wantController: (*Config)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:     noSidecarImage,
+				enableRequestPriorityKey: "maybe",
+			},
+		},
+	}, {
+		name:           "controller configuration with bad low priority wait budget",
+		wantErr:        true,
+		wantController: (*Config)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:     noSidecarImage,
+				lowPriorityWaitBudgetKey: "soon",
+			},
+		},
+	}, {
+		name:           "controller configuration with negative low priority wait budget",
+		wantErr:        true,
+		wantController: (*Config)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:     noSidecarImage,
+				lowPriorityWaitBudgetKey: "-1s",
+			},
+		},
+	}, {
+		name:    "controller configuration with streams",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			StreamIdleTimeout:              time.Minute,
+			ExcludeStreamsFromConcurrency:  true,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey: noSidecarImage,
+				streamIdleTimeoutKey: "1m",
+				excludeStreamsKey:    "true",
+			},
+		},
+	}, {
+		name:           "controller configuration with negative stream idle timeout",
+		wantErr:        true,
+		wantController: (*Config)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey: noSidecarImage,
+				streamIdleTimeoutKey: "-1m",
+			},
+		},
+	}, {
+		name:           "controller configuration with bad stream exclusion",
+		wantErr:        true,
+		wantController: (*Config)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey: noSidecarImage,
+				excludeStreamsKey:    "maybe",
+			},
+		},
 	}, {
 		name:           "controller with no side car image",
 		wantErr:        true,
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// grpcContentType is the content type of gRPC requests and responses.
+	// It may carry a suffix like "+proto".
+	grpcContentType = "application/grpc"
+
+	// GRPCStatusHeaderName is the name of the trailer carrying the status
+	// code of a gRPC response.
+	GRPCStatusHeaderName = "Grpc-Status"
+	// GRPCMessageHeaderName is the name of the trailer carrying the status
+	// message of a gRPC response.
+	GRPCMessageHeaderName = "Grpc-Message"
+)
+
+// IsGRPC returns whether the request is a gRPC request.
+func IsGRPC(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), grpcContentType)
+}
+
+// Error replies to the request with the message and HTTP code, like
+// http.Error. gRPC requests are answered with the corresponding gRPC status
+// instead, see GRPCCode, so that gRPC clients can tell why they failed.
+func Error(w http.ResponseWriter, r *http.Request, msg string, code int) {
+	if IsGRPC(r) {
+		GRPCError(w, GRPCCode(code), msg)
+		return
+	}
+	http.Error(w, msg, code)
+}
+
+// GRPCError replies to a gRPC request with the status code and message. The
+// response is Trailers-Only, i.e. the status is sent in the headers and the
+// response has no body.
+func GRPCError(w http.ResponseWriter, code codes.Code, msg string) {
+	h := w.Header()
+	h.Set("Content-Type", grpcContentType)
+	h.Set(GRPCStatusHeaderName, strconv.Itoa(int(code)))
+	h.Set(GRPCMessageHeaderName, encodeGRPCMessage(msg))
+	w.WriteHeader(http.StatusOK)
+}
+
+// GRPCCode maps the HTTP code to a gRPC status code, the way gRPC clients map
+// the HTTP codes of responses that aren't gRPC responses, see
+// https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md.
+// Timeouts however map to DeadlineExceeded, rather than Unavailable.
+func GRPCCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// encodeGRPCMessage percent-encodes the message as the gRPC protocol requires.
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		code        int
+		wantCode    int
+		wantBody    string
+		wantStatus  string
+		wantMessage string
+	}{{
+		name:     "http",
+		code:     http.StatusServiceUnavailable,
+		wantCode: http.StatusServiceUnavailable,
+		wantBody: "no capacity: 100%\n",
+	}, {
+		name:        "grpc",
+		contentType: "application/grpc",
+		code:        http.StatusServiceUnavailable,
+		wantCode:    http.StatusOK,
+		wantStatus:  strconv.Itoa(int(codes.Unavailable)),
+		wantMessage: "no capacity: 100%25",
+	}, {
+		name:        "grpc with suffix",
+		contentType: "application/grpc+proto",
+		code:        http.StatusGatewayTimeout,
+		wantCode:    http.StatusOK,
+		wantStatus:  strconv.Itoa(int(codes.DeadlineExceeded)),
+		wantMessage: "no capacity: 100%25",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			resp := httptest.NewRecorder()
+
+			Error(resp, req, "no capacity: 100%", test.code)
+
+			if resp.Code != test.wantCode {
+				t.Errorf("Code = %d, want: %d", resp.Code, test.wantCode)
+			}
+			if got := resp.Body.String(); got != test.wantBody {
+				t.Errorf("Body = %q, want: %q", got, test.wantBody)
+			}
+			if got := resp.Header().Get(GRPCStatusHeaderName); got != test.wantStatus {
+				t.Errorf("%s = %q, want: %q", GRPCStatusHeaderName, got, test.wantStatus)
+			}
+			if got := resp.Header().Get(GRPCMessageHeaderName); got != test.wantMessage {
+				t.Errorf("%s = %q, want: %q", GRPCMessageHeaderName, got, test.wantMessage)
+			}
+		})
+	}
+}
+
+func TestGRPCCode(t *testing.T) {
+	for code, want := range map[int]codes.Code{
+		http.StatusBadRequest:          codes.Internal,
+		http.StatusUnauthorized:        codes.Unauthenticated,
+		http.StatusForbidden:           codes.PermissionDenied,
+		http.StatusNotFound:            codes.Unimplemented,
+		http.StatusRequestTimeout:      codes.DeadlineExceeded,
+		http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+		http.StatusTooManyRequests:     codes.Unavailable,
+		http.StatusBadGateway:          codes.Unavailable,
+		http.StatusServiceUnavailable:  codes.Unavailable,
+		http.StatusInternalServerError: codes.Unknown,
+	} {
+		if got := GRPCCode(code); got != want {
+			t.Errorf("GRPCCode(%d) = %v, want: %v", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"knative.dev/pkg/websocket"
+)
+
+var (
+	_ http.Flusher        = (*streamWriter)(nil)
+	_ http.Hijacker       = (*streamWriter)(nil)
+	_ http.ResponseWriter = (*streamWriter)(nil)
+)
+
+// eventStreamContentType is the content type of server-sent events.
+const eventStreamContentType = "text/event-stream"
+
+// StreamHandler passes the requests on to NextHandler and detects the ones
+// that turn into long-lived streams: the connections upgraded to another
+// protocol, e.g. WebSocket, and the responses of server-sent events.
+type StreamHandler struct {
+	NextHandler http.Handler
+
+	// IdleTimeout ends the streams that haven't transferred any data for
+	// this long. 0 means streams are never ended for being idle.
+	IdleTimeout time.Duration
+
+	// OnStreamStart is called once a request turned into a stream and
+	// OnStreamEnd once that stream ended. Both are optional.
+	OnStreamStart func(r *http.Request)
+	OnStreamEnd   func(r *http.Request)
+}
+
+// ServeHTTP implements http.Handler.
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	r = r.WithContext(ctx)
+
+	sw := &streamWriter{
+		writer:  w,
+		handler: h,
+		request: r,
+		cancel:  cancel,
+	}
+	defer sw.end()
+	h.NextHandler.ServeHTTP(sw, r)
+}
+
+// streamWriter is an http.ResponseWriter that notices when the response
+// turns into a stream.
+type streamWriter struct {
+	writer  http.ResponseWriter
+	handler *StreamHandler
+	request *http.Request
+	// cancel cancels the context of the request, to end an idle stream of
+	// server-sent events.
+	cancel context.CancelFunc
+
+	checkedHeader bool
+	streaming     bool
+	idleTimer     *time.Timer
+}
+
+// start marks the request as stream.
+func (sw *streamWriter) start() {
+	sw.streaming = true
+	if sw.handler.OnStreamStart != nil {
+		sw.handler.OnStreamStart(sw.request)
+	}
+}
+
+// end is called once the request was handled.
+func (sw *streamWriter) end() {
+	if !sw.streaming {
+		return
+	}
+	if sw.idleTimer != nil {
+		sw.idleTimer.Stop()
+	}
+	if sw.handler.OnStreamEnd != nil {
+		sw.handler.OnStreamEnd(sw.request)
+	}
+}
+
+// checkHeader starts the stream if the response is one of server-sent events.
+func (sw *streamWriter) checkHeader() {
+	if sw.checkedHeader {
+		return
+	}
+	sw.checkedHeader = true
+	if !strings.HasPrefix(sw.writer.Header().Get("Content-Type"), eventStreamContentType) {
+		return
+	}
+	sw.start()
+	if sw.handler.IdleTimeout > 0 {
+		sw.idleTimer = time.AfterFunc(sw.handler.IdleTimeout, sw.cancel)
+	}
+}
+
+// Header returns the header map that will be sent by WriteHeader.
+func (sw *streamWriter) Header() http.Header {
+	return sw.writer.Header()
+}
+
+// Write writes the data to the connection as part of an HTTP reply.
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	sw.checkHeader()
+	if sw.idleTimer != nil {
+		sw.idleTimer.Reset(sw.handler.IdleTimeout)
+	}
+	return sw.writer.Write(p)
+}
+
+// WriteHeader sends an HTTP response header with the provided status code.
+func (sw *streamWriter) WriteHeader(code int) {
+	sw.checkHeader()
+	sw.writer.WriteHeader(code)
+}
+
+// Flush flushes the buffer to the client.
+func (sw *streamWriter) Flush() {
+	if f, ok := sw.writer.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack calls Hijack() on the wrapped http.ResponseWriter if it implements
+// http.Hijacker interface and starts the stream on the hijacked connection.
+func (sw *streamWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	c, rw, err := websocket.HijackIfPossible(sw.writer)
+	if err != nil {
+		return nil, nil, err
+	}
+	sw.start()
+	if sw.handler.IdleTimeout > 0 {
+		c = newIdleTimeoutConn(c, sw.handler.IdleTimeout)
+	}
+	return c, rw, nil
+}
+
+// idleTimeoutConn is a net.Conn that times out once no data was read from or
+// written to it for the timeout.
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func newIdleTimeoutConn(c net.Conn, timeout time.Duration) net.Conn {
+	c.SetDeadline(time.Now().Add(timeout))
+	return &idleTimeoutConn{Conn: c, timeout: timeout}
+}
+
+// Read reads data from the connection, extending the deadline.
+func (c *idleTimeoutConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.Conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+	return n, err
+}
+
+// Write writes data to the connection, extending the deadline.
+func (c *idleTimeoutConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.Conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+	return n, err
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStreamHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		wantStreams int32
+	}{{
+		name:        "plain response",
+		contentType: "text/plain",
+	}, {
+		name:        "server-sent events",
+		contentType: "text/event-stream; charset=utf-8",
+		wantStreams: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var started, ended int32
+			h := &StreamHandler{
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", test.contentType)
+					w.Write([]byte("data: hello\n\n"))
+					if got := atomic.LoadInt32(&started); got != test.wantStreams {
+						t.Errorf("Started streams = %d, want: %d", got, test.wantStreams)
+					}
+					if got := atomic.LoadInt32(&ended); got != 0 {
+						t.Errorf("Ended streams = %d before the handler returned, want: 0", got)
+					}
+				}),
+				OnStreamStart: func(*http.Request) { atomic.AddInt32(&started, 1) },
+				OnStreamEnd:   func(*http.Request) { atomic.AddInt32(&ended, 1) },
+			}
+
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if got := atomic.LoadInt32(&ended); got != test.wantStreams {
+				t.Errorf("Ended streams = %d, want: %d", got, test.wantStreams)
+			}
+		})
+	}
+}
+
+func TestStreamHandlerEventStreamIdleTimeout(t *testing.T) {
+	h := &StreamHandler{
+		NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("data: hello\n\n"))
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+				t.Error("The idle stream was not ended")
+			}
+		}),
+		IdleTimeout: 50 * time.Millisecond,
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestStreamHandlerHijackIdleTimeout(t *testing.T) {
+	var started, ended int32
+	server := httptest.NewServer(&StreamHandler{
+		NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Hijack() = %v", err)
+				return
+			}
+			defer conn.Close()
+			rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n\r\n")
+			rw.Flush()
+			// Echo until the connection idles out.
+			io.Copy(conn, conn)
+		}),
+		IdleTimeout:   100 * time.Millisecond,
+		OnStreamStart: func(*http.Request) { atomic.AddInt32(&started, 1) },
+		OnStreamEnd:   func(*http.Request) { atomic.AddInt32(&ended, 1) },
+	})
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n"))
+
+	br := bufio.NewReader(conn)
+	if status, err := br.ReadString('\n'); err != nil || status != "HTTP/1.1 101 Switching Protocols\r\n" {
+		t.Fatalf("Status line = %q, %v", status, err)
+	}
+	br.ReadString('\n')
+
+	// Data keeps the stream alive beyond the idle timeout.
+	for i := 0; i < 3; i++ {
+		time.Sleep(60 * time.Millisecond)
+		conn.Write([]byte("ping\n"))
+		if got, err := br.ReadString('\n'); err != nil || got != "ping\n" {
+			t.Fatalf("Echo = %q, %v", got, err)
+		}
+	}
+
+	// Then the idle stream is closed by the server.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := br.ReadByte(); err != io.EOF {
+		t.Errorf("ReadByte() = %v, want: %v", err, io.EOF)
+	}
+	if got := atomic.LoadInt32(&started); got != 1 {
+		t.Errorf("Started streams = %d, want: 1", got)
+	}
+	// The handler returns after the connection was closed.
+	for i := 0; atomic.LoadInt32(&ended) != 1 && i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&ended); got != 1 {
+		t.Errorf("Ended streams = %d, want: 1", got)
+	}
+}
@@ -42,6 +42,25 @@ const (
 	// uses to mark requests going through it.
 	ProxyHeaderName = "K-Proxy-Request"
 
+	// RequestPriorityHeaderName is the name of the header carrying the
+	// priority class of a request, see serving.RequestPriorityAnnotationKey.
+	// The ingress replaces or removes the header of the callers.
+	RequestPriorityHeaderName = "Knative-Request-Priority"
+
+	// MirrorPercentHeaderName is the name of the header carrying the
+	// percentage of the requests of a route that are mirrored, see
+	// v1beta1.TrafficMirror.
+	MirrorPercentHeaderName = "Knative-Mirror-Percent"
+
+	// RequestTimeoutHeaderName is the name of the header callers set to a
+	// duration, e.g. "2.5s", to shorten the time their request may take.
+	// It can't extend the timeout of the revision.
+	RequestTimeoutHeaderName = "Knative-Request-Timeout"
+
+	// MirrorHostSuffix is the suffix the ingress appends to the host of
+	// the copies of mirrored requests.
+	MirrorHostSuffix = "-shadow"
+
 	// OriginalHostHeader is used to avoid Istio host based routing rules
 	// in Activator.
 	// The header contains the original Host value that can be rewritten
@@ -90,6 +109,10 @@ const (
 	// user-agent.  So we augment the probes with this header.
 	KubeletProbeHeaderName = "K-Kubelet-Probe"
 
+	// QueueProxyTLSServerName is the name the certificates the queue-proxies
+	// serve TLS with are verified for, since they're reached by pod IP.
+	QueueProxyTLSServerName = "queue-proxy"
+
 	// DefaultConnTimeout specifies a short default connection timeout
 	// to avoid hitting the issue fixed in
 	// https://github.com/kubernetes/kubernetes/pull/72534 but only
@@ -120,6 +143,11 @@ var (
 	// HTTPProtocolKey is the name of the configuration entry that
 	// specifies the HTTP endpoint behavior of Knative ingress.
 	HTTPProtocolKey = "httpProtocol"
+
+	// QueueProxyTLSSecretKey is the name of the configuration entry that
+	// specifies the Secret holding the certificate the queue-proxies serve
+	// TLS with.
+	QueueProxyTLSSecretKey = "queueProxyTLSSecret"
 )
 
 // DomainTemplateValues are the available properties people can choose from
@@ -167,6 +195,12 @@ type Config struct {
 
 	// DefaultCertificateClass specifies the default Certificate class.
 	DefaultCertificateClass string
+
+	// QueueProxyTLSSecret is the name of the Secret in the namespace of
+	// the revisions holding the certificate the queue-proxies of the
+	// revisions created while it's set serve TLS with. If empty, the new
+	// revisions serve cleartext.
+	QueueProxyTLSSecret string
 }
 
 // HTTPProtocol indicates a type of HTTP endpoint behavior
@@ -263,6 +297,7 @@ func NewConfigFromConfigMap(configMap *corev1.ConfigMap) (*Config, error) {
 	}
 
 	nc.AutoTLS = strings.ToLower(configMap.Data[AutoTLSKey]) == "enabled"
+	nc.QueueProxyTLSSecret = strings.TrimSpace(configMap.Data[QueueProxyTLSSecretKey])
 
 	switch strings.ToLower(configMap.Data[HTTPProtocolKey]) {
 	case string(HTTPEnabled):
@@ -350,6 +385,12 @@ func KnativeProxyHeader(r *http.Request) string {
 	return r.Header.Get(ProxyHeaderName)
 }
 
+// IsMirroredRequest returns true if the request is a copy of a request that
+// is mirrored by the ingress.
+func IsMirroredRequest(r *http.Request) bool {
+	return strings.HasSuffix(r.Host, MirrorHostSuffix)
+}
+
 // IsProbe returns true if the request is a Kubernetes probe or a Knative probe,
 // i.e. non-empty ProbeHeaderName header.
 func IsProbe(r *http.Request) bool {
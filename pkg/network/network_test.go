@@ -437,6 +437,28 @@ func TestConfiguration(t *testing.T) {
 				AutoTLSKey:               "enabled",
 			},
 		},
+	}, {
+		name:    "network configuration with queue-proxy TLS",
+		wantErr: false,
+		wantConfig: &Config{
+			IstioOutboundIPRanges:      "*",
+			DefaultClusterIngressClass: "istio.ingress.networking.knative.dev",
+			DefaultCertificateClass:    CertManagerCertificateClassName,
+			DomainTemplate:             DefaultDomainTemplate,
+			TagTemplate:                DefaultTagTemplate,
+			HTTPProtocol:               HTTPEnabled,
+			QueueProxyTLSSecret:        "queue-proxy-certs",
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				IstioOutboundIPRangesKey: "*",
+				QueueProxyTLSSecretKey:   " queue-proxy-certs ",
+			},
+		},
 	}, {
 		name:    "network configuration with Auto TLS disabled",
 		wantErr: false,
@@ -676,6 +698,20 @@ func TestKnativeProxyHeader(t *testing.T) {
 	}
 }
 
+func TestIsMirroredRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	if err != nil {
+		t.Fatalf("Error building request: %v", err)
+	}
+	if IsMirroredRequest(req) {
+		t.Error("Not mirrored but counted as such")
+	}
+	req.Host = "example.com" + MirrorHostSuffix
+	if !IsMirroredRequest(req) {
+		t.Error("Mirrored but not counted as such")
+	}
+}
+
 func TestIsProbe(t *testing.T) {
 	// Not a probe
 	req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader serves a certificate and key read from files, e.g. mounted
+// from a Secret, and reads them again once they changed, so that rotated
+// certificates are picked up without a restart.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	// mux guards the fields below.
+	mux     sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// NewCertReloader returns a CertReloader for the certificate and key in the
+// given PEM files. It fails if they can't be loaded.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	c := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	modTime, err := c.lastModified()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.load(modTime); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate returns the current certificate. It is meant to be used as
+// tls.Config.GetCertificate. If the files changed but can't be loaded, e.g.
+// while they're being updated, the previous certificate is returned.
+func (c *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if modTime, err := c.lastModified(); err == nil && c.changed(modTime) {
+		c.load(modTime)
+	}
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.cert, nil
+}
+
+// lastModified returns the later of the modification times of the files.
+func (c *CertReloader) lastModified() (time.Time, error) {
+	var modTime time.Time
+	for _, f := range []string{c.certFile, c.keyFile} {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+	}
+	return modTime, nil
+}
+
+func (c *CertReloader) changed(modTime time.Time) bool {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return !modTime.Equal(c.modTime)
+}
+
+func (c *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.cert = &cert
+	c.modTime = modTime
+	return nil
+}
+
+// NewQueueProxyTLSConfig returns the TLS config to verify the certificates the
+// queue-proxies serve with. They're verified against the CAs in the given PEM
+// file, or against the system's CAs if it's empty.
+func NewQueueProxyTLSConfig(caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		// The queue-proxies are reached by pod IP.
+		ServerName: QueueProxyTLSServerName,
+	}
+	if caFile == "" {
+		return cfg, nil
+	}
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RootCAs = x509.NewCertPool()
+	if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return cfg, nil
+}
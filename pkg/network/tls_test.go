@@ -0,0 +1,190 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate for the queue-proxy server name
+// and its key to dir and returns the paths of the files.
+func writeCert(t *testing.T, dir string, serial int64) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: QueueProxyTLSServerName},
+		DNSNames:              []string{QueueProxyTLSServerName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() = %v", err)
+	}
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := NewCertReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")); err == nil {
+		t.Error("NewCertReloader() = nil, wanted an error for missing files")
+	}
+
+	certFile, keyFile := writeCert(t, dir, 1)
+	c, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() = %v", err)
+	}
+	serial := func() int64 {
+		cert, err := c.GetCertificate(nil)
+		if err != nil {
+			t.Fatalf("GetCertificate() = %v", err)
+		}
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatalf("ParseCertificate() = %v", err)
+		}
+		return parsed.SerialNumber.Int64()
+	}
+	if got, want := serial(), int64(1); got != want {
+		t.Errorf("Serial = %d, want: %d", got, want)
+	}
+
+	// Rotate the certificate.
+	writeCert(t, dir, 2)
+	later := time.Now().Add(time.Minute)
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatalf("Chtimes() = %v", err)
+		}
+	}
+	if got, want := serial(), int64(2); got != want {
+		t.Errorf("Serial after rotation = %d, want: %d", got, want)
+	}
+
+	// A broken update keeps the previous certificate.
+	if err := ioutil.WriteFile(keyFile, []byte("garbage"), 0600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if err := os.Chtimes(keyFile, later.Add(time.Minute), later.Add(time.Minute)); err != nil {
+		t.Fatalf("Chtimes() = %v", err)
+	}
+	if got, want := serial(), int64(2); got != want {
+		t.Errorf("Serial after broken update = %d, want: %d", got, want)
+	}
+}
+
+func TestAutoTLSTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeCert(t, dir, 1)
+	c, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() = %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.TLS = &tls.Config{GetCertificate: c.GetCertificate}
+	// Don't log the failing handshake below.
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	// The self-signed certificate is its own CA.
+	cfg, err := NewQueueProxyTLSConfig(certFile)
+	if err != nil {
+		t.Fatalf("NewQueueProxyTLSConfig() = %v", err)
+	}
+	resp, err := (&http.Client{Transport: NewAutoTLSTransport(cfg)}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	resp.Body.Close()
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Errorf("StatusCode = %d, want: %d", got, want)
+	}
+
+	// Without the CA the certificate isn't trusted.
+	cfg, err = NewQueueProxyTLSConfig("")
+	if err != nil {
+		t.Fatalf("NewQueueProxyTLSConfig() = %v", err)
+	}
+	if _, err := (&http.Client{Transport: NewAutoTLSTransport(cfg)}).Get(server.URL); err == nil {
+		t.Error("Get() = nil, wanted an error for an untrusted certificate")
+	}
+}
+
+func TestNewQueueProxyTLSConfigErrors(t *testing.T) {
+	if _, err := NewQueueProxyTLSConfig("/does/not/exist"); err == nil {
+		t.Error("NewQueueProxyTLSConfig() = nil, wanted an error for a missing file")
+	}
+
+	f, err := ioutil.TempFile("", "ca")
+	if err != nil {
+		t.Fatalf("TempFile() = %v", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("no certificates")
+	f.Close()
+	if _, err := NewQueueProxyTLSConfig(f.Name()); err == nil {
+		t.Error("NewQueueProxyTLSConfig() = nil, wanted an error for a file without certificates")
+	}
+}
@@ -17,11 +17,13 @@ package network
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"net"
 	"net/http"
 	"time"
 
+	"golang.org/x/net/http2"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
@@ -113,5 +115,37 @@ func NewAutoTransport() http.RoundTripper {
 	return newAutoTransport(newHTTPTransport(DefaultConnTimeout, false /*disable keep-alives*/), NewH2CTransport())
 }
 
+func newHTTPSTransport(cfg *tls.Config, disableKeepAlives bool) http.RoundTripper {
+	t := newHTTPTransport(DefaultConnTimeout, disableKeepAlives).(*http.Transport)
+	t.TLSClientConfig = cfg
+	return t
+}
+
+func newH2Transport(cfg *tls.Config) http.RoundTripper {
+	return &http2.Transport{
+		TLSClientConfig: cfg,
+		DialTLS: func(netw, addr string, cfg *tls.Config) (net.Conn, error) {
+			d := &net.Dialer{
+				Timeout:   DefaultConnTimeout,
+				KeepAlive: 5 * time.Second,
+				DualStack: true,
+			}
+			return tls.DialWithDialer(d, netw, addr, cfg)
+		},
+	}
+}
+
+// NewProberTLSTransport creates a RoundTripper like NewProberTransport, which
+// speaks TLS and verifies the servers with the given config.
+func NewProberTLSTransport(cfg *tls.Config) http.RoundTripper {
+	return newAutoTransport(newHTTPSTransport(cfg, true /*disable keep-alives*/), newH2Transport(cfg))
+}
+
+// NewAutoTLSTransport creates a RoundTripper like NewAutoTransport, which
+// speaks TLS and verifies the servers with the given config.
+func NewAutoTLSTransport(cfg *tls.Config) http.RoundTripper {
+	return newAutoTransport(newHTTPSTransport(cfg, false /*disable keep-alives*/), newH2Transport(cfg))
+}
+
 // AutoTransport uses h2c for HTTP2 requests and falls back to `http.DefaultTransport` for all others
 var AutoTransport = NewAutoTransport()
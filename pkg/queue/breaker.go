@@ -21,9 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 var (
+	// ErrRequestQueueFull indicates that the pending request queue of the breaker is full.
+	ErrRequestQueueFull = errors.New("pending request queue is full")
 	// ErrUpdateCapacity indicates that the capacity could not be updated as wished.
 	ErrUpdateCapacity = errors.New("failed to add all capacity to the breaker")
 	// ErrRelease indicates that release was called more often than acquire.
@@ -35,6 +38,12 @@ type BreakerParams struct {
 	QueueDepth      int
 	MaxConcurrency  int
 	InitialCapacity int
+	// FairQueue enables weighted fair queuing of the requests waiting for
+	// capacity. If nil, they are served in the order of their arrival.
+	FairQueue *FairQueueParams
+	// Priority enables admitting the requests waiting for capacity by their
+	// priority classes, see WithPriority.
+	Priority *PriorityParams
 }
 
 // Breaker is a component that enforces a concurrency limit on the
@@ -44,6 +53,10 @@ type BreakerParams struct {
 type Breaker struct {
 	pendingRequests chan struct{}
 	sem             *semaphore
+	fairQueue       *fairQueue
+	// waiting is the number of requests waiting for capacity. It should
+	// only be manipulated by `sync/atomic` methods.
+	waiting int32
 }
 
 // NewBreaker creates a Breaker with the desired queue depth,
@@ -58,11 +71,22 @@ func NewBreaker(params BreakerParams) *Breaker {
 	if params.InitialCapacity < 0 || params.InitialCapacity > params.MaxConcurrency {
 		panic(fmt.Sprintf("Initial capacity must be between 0 and max concurrency. Got %v.", params.InitialCapacity))
 	}
+	if params.FairQueue != nil && params.FairQueue.KeyQueueDepth < 0 {
+		panic(fmt.Sprintf("Key queue depth must be 0 or greater. Got %v.", params.FairQueue.KeyQueueDepth))
+	}
 	sem := newSemaphore(params.MaxConcurrency, params.InitialCapacity)
-	return &Breaker{
+	b := &Breaker{
 		pendingRequests: make(chan struct{}, params.QueueDepth+params.MaxConcurrency),
 		sem:             sem,
 	}
+	if params.FairQueue != nil || params.Priority != nil {
+		var fairParams FairQueueParams
+		if params.FairQueue != nil {
+			fairParams = *params.FairQueue
+		}
+		b.fairQueue = newFairQueue(fairParams, params.Priority)
+	}
+	return b
 }
 
 // Maybe conditionally executes thunk based on the Breaker concurrency
@@ -70,10 +94,17 @@ func NewBreaker(params BreakerParams) *Breaker {
 // already consumed, Maybe returns immediately without calling thunk. If
 // the thunk was executed, Maybe returns true, else false.
 func (b *Breaker) Maybe(ctx context.Context, thunk func()) bool {
+	return b.Try(ctx, thunk) == nil
+}
+
+// Try executes thunk like Maybe, but returns why thunk was not executed:
+// ErrRequestQueueFull, ErrKeyQueueFull, ErrWaitBudgetExceeded or the error
+// of the context.
+func (b *Breaker) Try(ctx context.Context, thunk func()) error {
 	select {
 	default:
 		// Pending request queue is full.  Report failure.
-		return false
+		return ErrRequestQueueFull
 	case b.pendingRequests <- struct{}{}:
 		// Pending request has capacity.
 		// Defer releasing pending request queue.
@@ -82,8 +113,8 @@ func (b *Breaker) Maybe(ctx context.Context, thunk func()) bool {
 		}()
 
 		// Wait for capacity in the active queue.
-		if !b.sem.acquire(ctx) {
-			return false
+		if err := b.acquire(ctx); err != nil {
+			return err
 		}
 		// Defer releasing capacity in the active.
 		// It's safe to ignore the error returned by release since we
@@ -94,10 +125,29 @@ func (b *Breaker) Maybe(ctx context.Context, thunk func()) bool {
 		// Do the thing.
 		thunk()
 		// Report success
-		return true
+		return nil
 	}
 }
 
+// acquire waits for capacity in the active queue and keeps count of the
+// waiting requests meanwhile.
+func (b *Breaker) acquire(ctx context.Context) error {
+	atomic.AddInt32(&b.waiting, 1)
+	defer atomic.AddInt32(&b.waiting, -1)
+	if b.fairQueue != nil {
+		return b.fairQueue.acquire(ctx, b.sem)
+	}
+	if !b.sem.acquire(ctx) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Waiting returns the number of requests waiting for capacity.
+func (b *Breaker) Waiting() int {
+	return int(atomic.LoadInt32(&b.waiting))
+}
+
 // UpdateConcurrency updates the maximum number of in-flight requests.
 func (b *Breaker) UpdateConcurrency(size int) error {
 	return b.sem.updateCapacity(size)
@@ -48,6 +48,9 @@ func TestBreakerInvalidConstructor(t *testing.T) {
 	}, {
 		"InitialCapacity out-of-bounds",
 		BreakerParams{QueueDepth: 1, MaxConcurrency: 5, InitialCapacity: 6},
+	}, {
+		"KeyQueueDepth negative",
+		BreakerParams{QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 1, FairQueue: &FairQueueParams{KeyQueueDepth: -1}},
 	}}
 
 	for _, test := range tests {
@@ -76,6 +79,10 @@ func TestBreakerOverload(t *testing.T) {
 	reqs.request()
 	reqs.expectFailure(t)
 
+	if err := b.Try(context.Background(), func() {}); err != ErrRequestQueueFull {
+		t.Errorf("Try() = %v, want %v", err, ErrRequestQueueFull)
+	}
+
 	// The remainer should succeed.
 	reqs.processSuccessfully(t)
 	reqs.processSuccessfully(t)
@@ -159,6 +166,29 @@ func TestBreakerCancel(t *testing.T) {
 	reqs.processSuccessfully(t)
 }
 
+func TestBreakerWaiting(t *testing.T) {
+	params := BreakerParams{QueueDepth: 2, MaxConcurrency: 1, InitialCapacity: 1}
+	b := NewBreaker(params)
+	reqs := newRequestor(b)
+
+	// The first request gets capacity right away, the second one waits.
+	reqs.request()
+	reqs.request()
+	deadline := time.Now().Add(semAcquireTimeout)
+	for b.Waiting() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Waiting() = %d, want 1", b.Waiting())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	reqs.processSuccessfully(t)
+	reqs.processSuccessfully(t)
+	if got := b.Waiting(); got != 0 {
+		t.Errorf("Waiting() = %d, want 0", got)
+	}
+}
+
 func TestBreakerUpdateConcurrency(t *testing.T) {
 	params := BreakerParams{QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 0}
 	b := NewBreaker(params)
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"net/http"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// customMetricScrapeTimeout bounds a single scrape of the user container, so
+// that a slow container does not hold up the stat reporting.
+const customMetricScrapeTimeout = ReporterReportingPeriod / 2
+
+// CustomMetricScraper scrapes a custom metric the user container exposes
+// in the Prometheus text format.
+type CustomMetricScraper struct {
+	httpClient *http.Client
+	url        string
+	name       string
+}
+
+// NewCustomMetricScraper creates a CustomMetricScraper reading the gauge with
+// the given name from the given URL. Untyped metrics are read as gauges.
+func NewCustomMetricScraper(url, name string) *CustomMetricScraper {
+	return &CustomMetricScraper{
+		httpClient: &http.Client{Timeout: customMetricScrapeTimeout},
+		url:        url,
+		name:       name,
+	}
+}
+
+// Scrape returns the value of the custom metric, summed over all its series.
+func (s *CustomMetricScraper) Scrape() (float64, error) {
+	resp, err := s.httpClient.Get(s.url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return 0, fmt.Errorf("GET request for URL %q returned HTTP status %v", s.url, resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	metricFamilies, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("reading text format failed: %v", err)
+	}
+	family, ok := metricFamilies[s.name]
+	if !ok || len(family.Metric) == 0 {
+		return 0, fmt.Errorf("could not find value for %s in response", s.name)
+	}
+
+	var sum float64
+	for _, m := range family.Metric {
+		switch family.GetType() {
+		case dto.MetricType_GAUGE:
+			sum += m.GetGauge().GetValue()
+		case dto.MetricType_UNTYPED:
+			sum += m.GetUntyped().GetValue()
+		default:
+			return 0, fmt.Errorf("custom metric %s is a %v, want a gauge", s.name, family.GetType())
+		}
+	}
+	return sum, nil
+}
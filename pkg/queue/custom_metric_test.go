@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCustomMetricScraper(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    float64
+		wantErr string
+	}{{
+		name:   "gauge",
+		status: http.StatusOK,
+		body: `# TYPE queue_depth gauge
+queue_depth 12
+`,
+		want: 12,
+	}, {
+		name:   "series are summed",
+		status: http.StatusOK,
+		body: `# TYPE queue_depth gauge
+queue_depth{queue="a"} 3
+queue_depth{queue="b"} 4
+other_metric 100
+`,
+		want: 7,
+	}, {
+		name:   "untyped",
+		status: http.StatusOK,
+		body:   "queue_depth 5\n",
+		want:   5,
+	}, {
+		name:   "counter",
+		status: http.StatusOK,
+		body: `# TYPE queue_depth counter
+queue_depth 5
+`,
+		wantErr: "custom metric queue_depth is a COUNTER, want a gauge",
+	}, {
+		name:    "missing",
+		status:  http.StatusOK,
+		body:    "other_metric 100\n",
+		wantErr: "could not find value for queue_depth in response",
+	}, {
+		name:    "not text format",
+		status:  http.StatusOK,
+		body:    "{}",
+		wantErr: "reading text format failed",
+	}, {
+		name:    "error status",
+		status:  http.StatusInternalServerError,
+		wantErr: "returned HTTP status 500",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/metrics" {
+					t.Errorf("Path = %q, want /metrics", r.URL.Path)
+				}
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+			defer server.Close()
+
+			got, err := NewCustomMetricScraper(server.URL+"/metrics", "queue_depth").Scrape()
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Scrape() = %v, want an error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scrape() = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("Scrape() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCustomMetricScraperTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(2 * customMetricScrapeTimeout):
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	if _, err := NewCustomMetricScraper(server.URL, "queue_depth").Scrape(); err == nil {
+		t.Error("Scrape() = nil, want a timeout error")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultPreStopDelay is how long the queue-proxy keeps accepting
+	// requests once it's asked to terminate, unless configured otherwise.
+	DefaultPreStopDelay = 20 * time.Second
+
+	// drainNotifyTimeout bounds the request notifying the user container
+	// that the pod drains.
+	drainNotifyTimeout = 5 * time.Second
+)
+
+// ErrDrainTimeout indicates that requests were still in flight once the drain
+// timeout expired and were cut.
+var ErrDrainTimeout = errors.New("requests still in flight after the drain timeout were cut")
+
+// DrainParams defines how the queue-proxy drains the requests once it's
+// asked to terminate.
+type DrainParams struct {
+	// PreStopDelay is how long requests are still accepted, giving the
+	// network time to stop routing requests to the pod.
+	PreStopDelay time.Duration
+	// Timeout is how long the requests in flight may take to complete after
+	// the PreStopDelay. 0 means no limit.
+	Timeout time.Duration
+	// NotifyURL, if not empty, is sent a POST request once draining begins.
+	NotifyURL string
+}
+
+// Drain notifies the user container, keeps serving requests for the pre-stop
+// delay and then shuts the server down, which waits for the requests in
+// flight to complete until the timeout. Requests still in flight by then are
+// cut and ErrDrainTimeout is returned.
+func Drain(server *http.Server, params DrainParams) error {
+	notifyErr := make(chan error, 1)
+	if params.NotifyURL != "" {
+		go func() {
+			notifyErr <- notifyDrain(params.NotifyURL)
+		}()
+	} else {
+		notifyErr <- nil
+	}
+
+	time.Sleep(params.PreStopDelay)
+
+	ctx := context.Background()
+	if params.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, params.Timeout)
+		defer cancel()
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		if err != context.DeadlineExceeded {
+			return err
+		}
+		server.Close()
+		return ErrDrainTimeout
+	}
+	return <-notifyErr
+}
+
+func notifyDrain(url string) error {
+	client := &http.Client{Timeout: drainNotifyTimeout}
+	resp, err := client.Post(url, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to notify the user container of draining: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("the user container answered the drain notification with status %d", resp.StatusCode)
+	}
+	return nil
+}
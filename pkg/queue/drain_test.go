@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestTime time.Duration
+		timeout     time.Duration
+		wantErr     error
+		wantStatus  int
+	}{{
+		name:        "requests complete",
+		requestTime: 50 * time.Millisecond,
+		timeout:     time.Second,
+		wantStatus:  http.StatusOK,
+	}, {
+		name:        "no timeout",
+		requestTime: 50 * time.Millisecond,
+		wantStatus:  http.StatusOK,
+	}, {
+		name:        "requests cut",
+		requestTime: time.Second,
+		timeout:     50 * time.Millisecond,
+		wantErr:     ErrDrainTimeout,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			started := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-time.After(test.requestTime):
+				case <-r.Context().Done():
+				}
+			}))
+			defer server.Close()
+
+			status := make(chan int, 1)
+			go func() {
+				resp, err := http.Get(server.URL)
+				if err != nil {
+					status <- 0
+					return
+				}
+				resp.Body.Close()
+				status <- resp.StatusCode
+			}()
+			<-started
+
+			if err := Drain(server.Config, DrainParams{
+				PreStopDelay: 10 * time.Millisecond,
+				Timeout:      test.timeout,
+			}); err != test.wantErr {
+				t.Errorf("Drain() = %v, want: %v", err, test.wantErr)
+			}
+			if got := <-status; got != test.wantStatus {
+				t.Errorf("Status = %d, want: %d", got, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDrainPreStopDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	done := make(chan error)
+	go func() {
+		done <- Drain(server.Config, DrainParams{PreStopDelay: 200 * time.Millisecond})
+	}()
+
+	// Requests are still accepted during the pre-stop delay.
+	time.Sleep(50 * time.Millisecond)
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() during the pre-stop delay = %v", err)
+	}
+	resp.Body.Close()
+
+	if err := <-done; err != nil {
+		t.Errorf("Drain() = %v", err)
+	}
+}
+
+func TestDrainNotify(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{{
+		name:   "notified",
+		status: http.StatusOK,
+	}, {
+		name:    "notification failed",
+		status:  http.StatusInternalServerError,
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notified := make(chan string, 1)
+			user := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				notified <- r.Method + " " + r.URL.Path
+				w.WriteHeader(test.status)
+			}))
+			defer user.Close()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer server.Close()
+
+			err := Drain(server.Config, DrainParams{NotifyURL: user.URL + "/drain"})
+			if (err != nil) != test.wantErr {
+				t.Errorf("Drain() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if got, want := <-notified, "POST /drain"; got != want {
+				t.Errorf("Notification = %q, want: %q", got, want)
+			}
+		})
+	}
+}
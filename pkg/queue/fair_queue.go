@@ -0,0 +1,273 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrKeyQueueFull indicates that the queue of the request's fair queuing
+// key is full.
+var ErrKeyQueueFull = errors.New("pending request queue of the key is full")
+
+// FairQueueParams defines the weighted fair queuing of the requests that wait
+// for capacity in a Breaker.
+type FairQueueParams struct {
+	// KeyQueueDepth is the maximal number of requests with the same key
+	// waiting for capacity. 0 leaves the keys limited by the QueueDepth only.
+	KeyQueueDepth int
+	// Weights are the shares of the capacity the keys get relative to each
+	// other while requests are waiting. Keys without a weight have weight 1.
+	Weights map[string]float64
+}
+
+type fairQueueKey struct{}
+
+// WithFairQueueKey attaches the key the request is queued by in a Breaker with
+// fair queuing to the context. Requests without a key share the empty key.
+func WithFairQueueKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, fairQueueKey{}, key)
+}
+
+// FairQueueKeyFrom returns the fair queuing key attached to the context.
+func FairQueueKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(fairQueueKey{}).(string)
+	return key
+}
+
+// fairWaiter is a request waiting for a token.
+type fairWaiter struct {
+	key      string
+	priority Priority
+	// tag is the virtual time at which the request finishes in the ideal
+	// fluid system. Requests are granted tokens in the order of their tags.
+	tag float64
+	// seq keeps requests with equal tags in the order of their arrival.
+	seq uint64
+	// ready is closed once a token was handed to the waiter.
+	ready   chan struct{}
+	granted bool
+	index   int
+}
+
+// waiterHeap is a container/heap of the waiters ordered by their priorities
+// and then by their tags.
+type waiterHeap []*fairWaiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	if h[i].tag != h[j].tag {
+		return h[i].tag < h[j].tag
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x interface{}) {
+	w := x.(*fairWaiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return w
+}
+
+// fairQueue hands the tokens of a semaphore to the waiting requests in the
+// order of their priority classes and, within a class, by weighted fair
+// queuing over their keys, so that a key with many requests waiting can not
+// starve the others.
+//
+// Every waiting request competes for the tokens of the semaphore, but passes
+// the token it receives on to the request first in line. This way no
+// goroutine is needed to dispatch the tokens.
+type fairQueue struct {
+	keyQueueDepth int
+	weights       map[string]float64
+	// byPriority is whether the requests are admitted by their priority
+	// class, they are all of normal priority otherwise.
+	byPriority            bool
+	lowPriorityWaitBudget time.Duration
+
+	// mux guards all the fields below.
+	mux     sync.Mutex
+	waiters waiterHeap
+	seq     uint64
+	// virtualTime is the tag of the request last handed a token, per
+	// priority class.
+	virtualTime map[Priority]float64
+	// lastTag is the tag of the last request per priority class and key,
+	// queued is the number of waiting requests per key. They are dropped once
+	// no request of the key waits anymore.
+	lastTag map[classKey]float64
+	queued  map[string]int
+}
+
+// classKey identifies the requests of a key within a priority class.
+type classKey struct {
+	priority Priority
+	key      string
+}
+
+func newFairQueue(params FairQueueParams, priorityParams *PriorityParams) *fairQueue {
+	q := &fairQueue{
+		keyQueueDepth: params.KeyQueueDepth,
+		weights:       params.Weights,
+		virtualTime:   make(map[Priority]float64),
+		lastTag:       make(map[classKey]float64),
+		queued:        make(map[string]int),
+	}
+	if priorityParams != nil {
+		q.byPriority = true
+		q.lowPriorityWaitBudget = priorityParams.LowPriorityWaitBudget
+	}
+	return q
+}
+
+func (q *fairQueue) weight(key string) float64 {
+	if w, ok := q.weights[key]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// acquire receives a token from the semaphore for the request, potentially
+// blocking while requests of other keys or of higher priority are first in
+// line. Low priority requests give up once their wait budget is used up.
+func (q *fairQueue) acquire(ctx context.Context, sem *semaphore) error {
+	key := FairQueueKeyFrom(ctx)
+	priority := PriorityNormal
+	if q.byPriority {
+		priority = PriorityFrom(ctx)
+	}
+
+	q.mux.Lock()
+	if q.waiters.Len() == 0 {
+		// Nobody is waiting, there's nothing to be fair about.
+		select {
+		case <-sem.queue:
+			q.mux.Unlock()
+			return nil
+		default:
+		}
+	}
+	if q.keyQueueDepth > 0 && q.queued[key] >= q.keyQueueDepth {
+		q.mux.Unlock()
+		return ErrKeyQueueFull
+	}
+	ck := classKey{priority: priority, key: key}
+	w := &fairWaiter{
+		key:      key,
+		priority: priority,
+		tag:      math.Max(q.virtualTime[priority], q.lastTag[ck]) + 1/q.weight(key),
+		seq:      q.seq,
+		ready:    make(chan struct{}),
+	}
+	q.seq++
+	q.lastTag[ck] = w.tag
+	q.queued[key]++
+	heap.Push(&q.waiters, w)
+	q.mux.Unlock()
+
+	var budgetCh <-chan time.Time
+	if priority == PriorityLow && q.lowPriorityWaitBudget > 0 {
+		timer := time.NewTimer(q.lowPriorityWaitBudget)
+		defer timer.Stop()
+		budgetCh = timer.C
+	}
+
+	for {
+		select {
+		case <-w.ready:
+			return nil
+		case <-sem.queue:
+			q.mux.Lock()
+			q.handOff(sem)
+			granted := w.granted
+			q.mux.Unlock()
+			if granted {
+				return nil
+			}
+		case <-budgetCh:
+			q.leave(w, sem)
+			return ErrWaitBudgetExceeded
+		case <-ctx.Done():
+			q.leave(w, sem)
+			return ctx.Err()
+		}
+	}
+}
+
+// leave removes the request from the queue when it stops waiting before it
+// was handed a token.
+func (q *fairQueue) leave(w *fairWaiter, sem *semaphore) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if w.granted {
+		// Pass on the token we were handed meanwhile.
+		q.handOff(sem)
+		return
+	}
+	heap.Remove(&q.waiters, w.index)
+	q.dequeued(w)
+}
+
+// handOff hands a token to the request first in line, or returns it to the
+// semaphore if no request waits. `mux` must be held to call it.
+func (q *fairQueue) handOff(sem *semaphore) {
+	if q.waiters.Len() == 0 {
+		// It's safe to ignore the error, since the token was acquired.
+		sem.release()
+		return
+	}
+	w := heap.Pop(&q.waiters).(*fairWaiter)
+	q.virtualTime[w.priority] = w.tag
+	q.dequeued(w)
+	w.granted = true
+	close(w.ready)
+}
+
+// dequeued updates the bookkeeping of the key once the request stops
+// waiting. `mux` must be held to call it.
+func (q *fairQueue) dequeued(w *fairWaiter) {
+	q.queued[w.key]--
+	if q.queued[w.key] == 0 {
+		delete(q.queued, w.key)
+		for _, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+			delete(q.lastTag, classKey{priority: p, key: w.key})
+		}
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fairBreaker is a breaker with a capacity of one that queues fairly and
+// records the order in which the requests are executed.
+type fairBreaker struct {
+	*Breaker
+
+	mux   sync.Mutex
+	order []string
+	wg    sync.WaitGroup
+}
+
+func newFairBreaker(fairParams *FairQueueParams, priorityParams *PriorityParams) *fairBreaker {
+	return &fairBreaker{
+		Breaker: NewBreaker(BreakerParams{
+			QueueDepth:      10,
+			MaxConcurrency:  1,
+			InitialCapacity: 1,
+			FairQueue:       fairParams,
+			Priority:        priorityParams,
+		}),
+	}
+}
+
+func keyed(key string) context.Context {
+	return WithFairQueueKey(context.Background(), key)
+}
+
+// block occupies the capacity until the returned function is called.
+func (b *fairBreaker) block(t *testing.T) func() {
+	t.Helper()
+	started, unblock := make(chan struct{}), make(chan struct{})
+	go b.Maybe(context.Background(), func() {
+		close(started)
+		<-unblock
+	})
+	select {
+	case <-started:
+	case <-time.After(semAcquireTimeout):
+		t.Fatal("Timed out waiting for the blocking request")
+	}
+	return func() { close(unblock) }
+}
+
+// enqueue sends a request with the context and waits for it to be queued.
+func (b *fairBreaker) enqueue(t *testing.T, ctx context.Context, name string) {
+	t.Helper()
+	want := b.waiting() + 1
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.Maybe(ctx, func() {
+			b.mux.Lock()
+			defer b.mux.Unlock()
+			b.order = append(b.order, name)
+		})
+	}()
+	b.waitFor(t, want)
+}
+
+func (b *fairBreaker) waiting() int {
+	b.fairQueue.mux.Lock()
+	defer b.fairQueue.mux.Unlock()
+	return b.fairQueue.waiters.Len()
+}
+
+func (b *fairBreaker) waitFor(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(semAcquireTimeout)
+	for b.waiting() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d requests to be queued, got %d", n, b.waiting())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFairQueueOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		want    []string
+	}{{
+		name: "equal weights",
+		want: []string{"a1", "b1", "a2", "b2", "a3", "a4"},
+	}, {
+		name:    "weighted",
+		weights: map[string]float64{"a": 2},
+		want:    []string{"a1", "a2", "b1", "a3", "a4", "b2"},
+	}, {
+		name:    "weight below one",
+		weights: map[string]float64{"a": 0.5},
+		want:    []string{"b1", "a1", "b2", "a2", "a3", "a4"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := newFairBreaker(&FairQueueParams{Weights: test.weights}, nil)
+			unblock := b.block(t)
+
+			for _, name := range []string{"a1", "a2", "a3", "a4"} {
+				b.enqueue(t, keyed("a"), name)
+			}
+			for _, name := range []string{"b1", "b2"} {
+				b.enqueue(t, keyed("b"), name)
+			}
+			unblock()
+			b.wg.Wait()
+
+			if !cmp.Equal(b.order, test.want) {
+				t.Errorf("Execution order = %v, want %v", b.order, test.want)
+			}
+			if len(b.fairQueue.queued) != 0 || len(b.fairQueue.lastTag) != 0 {
+				t.Errorf("Key bookkeeping was not cleaned up: queued = %v, lastTag = %v",
+					b.fairQueue.queued, b.fairQueue.lastTag)
+			}
+		})
+	}
+}
+
+func TestFairQueueKeyQueueDepth(t *testing.T) {
+	b := newFairBreaker(&FairQueueParams{KeyQueueDepth: 1}, nil)
+	unblock := b.block(t)
+	defer unblock()
+
+	b.enqueue(t, keyed("a"), "a1")
+	err := b.Try(keyed("a"), func() {})
+	if err != ErrKeyQueueFull {
+		t.Errorf("Try() = %v, want %v", err, ErrKeyQueueFull)
+	}
+	// Other keys can still queue.
+	b.enqueue(t, keyed("b"), "b1")
+}
+
+func TestFairQueueCancel(t *testing.T) {
+	b := newFairBreaker(&FairQueueParams{}, nil)
+	unblock := b.block(t)
+
+	ctx, cancel := context.WithCancel(keyed("a"))
+	errCh := make(chan error)
+	go func() {
+		errCh <- b.Try(ctx, func() {
+			t.Error("Cancelled request was executed")
+		})
+	}()
+	b.waitFor(t, 1)
+	b.enqueue(t, keyed("b"), "b1")
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != context.Canceled {
+			t.Errorf("Try() = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(semAcquireTimeout):
+		t.Fatal("Timed out waiting for the cancelled request")
+	}
+	b.waitFor(t, 1)
+
+	unblock()
+	b.wg.Wait()
+	if want := []string{"b1"}; !cmp.Equal(b.order, want) {
+		t.Errorf("Execution order = %v, want %v", b.order, want)
+	}
+
+	// The capacity was handed back.
+	if err := b.Try(context.Background(), func() {}); err != nil {
+		t.Errorf("Try() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+
+	"knative.dev/serving/pkg/network"
+)
+
+// MirrorSamplingHandler drops the copies of mirrored requests that are not
+// sampled. The ingress mirrors all the requests of a route and passes the
+// percentage of them to keep in the network.MirrorPercentHeaderName header.
+// Dropped copies are answered with 204 No Content without reaching the user
+// container, the ingress discards the responses to the copies anyway. The
+// header is set on all the requests of the route, so it's removed before any
+// request reaches the user container.
+func MirrorSamplingHandler(h http.Handler) http.Handler {
+	return mirrorSamplingHandler(h, rand.Intn)
+}
+
+func mirrorSamplingHandler(h http.Handler, intn func(int) int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if network.IsMirroredRequest(r) {
+			if percent, err := strconv.Atoi(r.Header.Get(network.MirrorPercentHeaderName)); err == nil && intn(100) >= percent {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		r.Header.Del(network.MirrorPercentHeaderName)
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"knative.dev/serving/pkg/network"
+)
+
+func TestMirrorSamplingHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		percent  string
+		roll     int
+		wantCode int
+	}{{
+		name:     "not mirrored",
+		host:     "example.com",
+		percent:  "10",
+		roll:     50,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "mirrored without percentage",
+		host:     "example.com" + network.MirrorHostSuffix,
+		roll:     50,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "mirrored and sampled",
+		host:     "example.com" + network.MirrorHostSuffix,
+		percent:  "10",
+		roll:     9,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "mirrored and dropped",
+		host:     "example.com" + network.MirrorHostSuffix,
+		percent:  "10",
+		roll:     10,
+		wantCode: http.StatusNoContent,
+	}, {
+		name:     "mirrored with invalid percentage",
+		host:     "example.com" + network.MirrorHostSuffix,
+		percent:  "ten",
+		roll:     99,
+		wantCode: http.StatusOK,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called := false
+			h := mirrorSamplingHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				if got := r.Header.Get(network.MirrorPercentHeaderName); got != "" {
+					t.Errorf("%s = %q, want it removed", network.MirrorPercentHeaderName, got)
+				}
+			}), func(int) int { return test.roll })
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.Host = test.host
+			if test.percent != "" {
+				req.Header.Set(network.MirrorPercentHeaderName, test.percent)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != test.wantCode {
+				t.Errorf("Code = %d, want: %d", rec.Code, test.wantCode)
+			}
+			if want := test.wantCode == http.StatusOK; called != want {
+				t.Errorf("Called = %v, want: %v", called, want)
+			}
+		})
+	}
+}
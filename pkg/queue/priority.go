@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"knative.dev/serving/pkg/apis/serving"
+	pkghttp "knative.dev/serving/pkg/http"
+	"knative.dev/serving/pkg/network"
+)
+
+// ErrWaitBudgetExceeded indicates that a low priority request was shed,
+// since it waited for capacity longer than its wait budget.
+var ErrWaitBudgetExceeded = errors.New("low priority request exceeded its wait budget")
+
+// Priority is the class of a request. The requests waiting for capacity in a
+// Breaker that admits by priority are admitted in the order of their
+// priority classes.
+type Priority int
+
+const (
+	// PriorityLow is the priority of requests that are shed once they
+	// waited for longer than the wait budget.
+	PriorityLow Priority = iota - 1
+	// PriorityNormal is the priority of requests by default.
+	PriorityNormal
+	// PriorityHigh is the priority of requests admitted before all others.
+	PriorityHigh
+)
+
+// ParsePriority parses the priority class of a request, as given by the
+// network.RequestPriorityHeaderName header. An empty class is normal.
+func ParsePriority(s string) (Priority, error) {
+	switch s {
+	case serving.RequestPriorityHigh:
+		return PriorityHigh, nil
+	case serving.RequestPriorityNormal, "":
+		return PriorityNormal, nil
+	case serving.RequestPriorityLow:
+		return PriorityLow, nil
+	}
+	return PriorityNormal, fmt.Errorf("unknown request priority %q", s)
+}
+
+// PriorityParams defines the admission of the requests waiting for capacity
+// in a Breaker by their priority.
+type PriorityParams struct {
+	// LowPriorityWaitBudget is how long low priority requests wait for
+	// capacity before they are shed. 0 means they wait as long as their
+	// context permits.
+	LowPriorityWaitBudget time.Duration
+}
+
+// RequestPriority returns the priority class of the request, as given by
+// the last network.RequestPriorityHeaderName header. Unknown classes are
+// treated as normal.
+func RequestPriority(r *http.Request) Priority {
+	p, _ := ParsePriority(pkghttp.LastHeaderValue(r.Header, network.RequestPriorityHeaderName))
+	return p
+}
+
+type priorityKey struct{}
+
+// WithPriority attaches the priority class of the request to the context.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom returns the priority class attached to the context, which is
+// PriorityNormal by default.
+func PriorityFrom(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return p
+}
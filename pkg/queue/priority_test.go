@@ -0,0 +1,146 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"knative.dev/serving/pkg/network"
+)
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Priority
+		wantErr bool
+	}{{
+		in:   "",
+		want: PriorityNormal,
+	}, {
+		in:   "low",
+		want: PriorityLow,
+	}, {
+		in:   "normal",
+		want: PriorityNormal,
+	}, {
+		in:   "high",
+		want: PriorityHigh,
+	}, {
+		in:      "urgent",
+		want:    PriorityNormal,
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := ParsePriority(test.in)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ParsePriority() = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("ParsePriority() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRequestPriority(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	if got, want := RequestPriority(req), PriorityNormal; got != want {
+		t.Errorf("RequestPriority() = %v, want %v", got, want)
+	}
+	req.Header.Add(network.RequestPriorityHeaderName, "high")
+	req.Header.Add(network.RequestPriorityHeaderName, "low")
+	if got, want := RequestPriority(req), PriorityLow; got != want {
+		t.Errorf("RequestPriority() = %v, want %v", got, want)
+	}
+}
+
+func prioritized(key string, p Priority) context.Context {
+	return WithPriority(keyed(key), p)
+}
+
+func TestPriorityOrder(t *testing.T) {
+	b := newFairBreaker(&FairQueueParams{}, &PriorityParams{})
+	unblock := b.block(t)
+
+	b.enqueue(t, prioritized("a", PriorityLow), "low-a")
+	b.enqueue(t, prioritized("a", PriorityNormal), "normal-a1")
+	b.enqueue(t, prioritized("a", PriorityNormal), "normal-a2")
+	b.enqueue(t, prioritized("b", PriorityNormal), "normal-b")
+	b.enqueue(t, prioritized("b", PriorityHigh), "high-b")
+	unblock()
+	b.wg.Wait()
+
+	// Fair queuing applies within a priority class.
+	want := []string{"high-b", "normal-a1", "normal-b", "normal-a2", "low-a"}
+	if !cmp.Equal(b.order, want) {
+		t.Errorf("Execution order = %v, want %v", b.order, want)
+	}
+}
+
+func TestPriorityDisabled(t *testing.T) {
+	b := newFairBreaker(&FairQueueParams{}, nil)
+	unblock := b.block(t)
+
+	b.enqueue(t, prioritized("a", PriorityLow), "low-a")
+	b.enqueue(t, prioritized("b", PriorityHigh), "high-b")
+	unblock()
+	b.wg.Wait()
+
+	// Without PriorityParams the requests are of the same class.
+	want := []string{"low-a", "high-b"}
+	if !cmp.Equal(b.order, want) {
+		t.Errorf("Execution order = %v, want %v", b.order, want)
+	}
+}
+
+func TestLowPriorityWaitBudget(t *testing.T) {
+	b := newFairBreaker(nil, &PriorityParams{LowPriorityWaitBudget: 50 * time.Millisecond})
+	unblock := b.block(t)
+
+	b.enqueue(t, WithPriority(context.Background(), PriorityNormal), "normal")
+	start := time.Now()
+	err := b.Try(WithPriority(context.Background(), PriorityLow), func() {
+		t.Error("Shed request was executed")
+	})
+	if err != ErrWaitBudgetExceeded {
+		t.Errorf("Try() = %v, want %v", err, ErrWaitBudgetExceeded)
+	}
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("Low priority request was shed after %v, want at least 50ms", waited)
+	}
+
+	// Requests of normal priority keep waiting.
+	b.waitFor(t, 1)
+	unblock()
+	b.wg.Wait()
+	if want := []string{"normal"}; !cmp.Equal(b.order, want) {
+		t.Errorf("Execution order = %v, want %v", b.order, want)
+	}
+
+	// Low priority requests are admitted right away if there is capacity.
+	if err := b.Try(WithPriority(context.Background(), PriorityLow), func() {}); err != nil {
+		t.Errorf("Try() = %v, want nil", err)
+	}
+}
@@ -58,6 +58,12 @@ var (
 	averageProxiedConcurrentRequestsGV = newGV(
 		"queue_average_proxied_concurrent_requests",
 		"Number of proxied requests currently being handled by this pod")
+	customMetricGV = newGV(
+		"queue_custom_metric",
+		"Value of the custom metric reported by the user container")
+	averageConcurrentStreamsGV = newGV(
+		"queue_average_concurrent_streams",
+		"Number of streams, e.g. WebSocket connections, currently being handled by this pod")
 )
 
 func newGV(n, h string) *prometheus.GaugeVec {
@@ -90,7 +96,7 @@ func NewPrometheusStatsReporter(namespace, config, revision, pod string) (*Prome
 	}
 
 	registry := prometheus.NewRegistry()
-	for _, gv := range []*prometheus.GaugeVec{operationsPerSecondGV, proxiedOperationsPerSecondGV, averageConcurrentRequestsGV, averageProxiedConcurrentRequestsGV} {
+	for _, gv := range []*prometheus.GaugeVec{operationsPerSecondGV, proxiedOperationsPerSecondGV, averageConcurrentRequestsGV, averageProxiedConcurrentRequestsGV, customMetricGV, averageConcurrentStreamsGV} {
 		if err := registry.Register(gv); err != nil {
 			return nil, fmt.Errorf("register metric failed: %v", err)
 		}
@@ -118,6 +124,8 @@ func (r *PrometheusStatsReporter) Report(stat *autoscaler.Stat) error {
 	proxiedOperationsPerSecondGV.With(r.labels).Set(stat.ProxiedRequestCount)
 	averageConcurrentRequestsGV.With(r.labels).Set(stat.AverageConcurrentRequests)
 	averageProxiedConcurrentRequestsGV.With(r.labels).Set(stat.AverageProxiedConcurrentRequests)
+	customMetricGV.With(r.labels).Set(stat.CustomMetric)
+	averageConcurrentStreamsGV.With(r.labels).Set(stat.AverageConcurrentStreams)
 
 	return nil
 }
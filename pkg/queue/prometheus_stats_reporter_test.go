@@ -98,6 +98,28 @@ func TestReporter_Report(t *testing.T) {
 	testReportWithProxiedRequests(t, &autoscaler.Stat{RequestCount: 39, AverageConcurrentRequests: 3, ProxiedRequestCount: 15, AverageProxiedConcurrentRequests: 2}, 39, 3, 15, 2)
 }
 
+func TestReporter_ReportCustomMetric(t *testing.T) {
+	reporter, err := NewPrometheusStatsReporter(namespace, config, revision, pod)
+	if err != nil {
+		t.Fatalf("NewPrometheusStatsReporter() = %v", err)
+	}
+	if err := reporter.Report(&autoscaler.Stat{CustomMetric: 17}); err != nil {
+		t.Error(err)
+	}
+	checkData(t, customMetricGV, 17)
+}
+
+func TestReporter_ReportStreams(t *testing.T) {
+	reporter, err := NewPrometheusStatsReporter(namespace, config, revision, pod)
+	if err != nil {
+		t.Fatalf("NewPrometheusStatsReporter() = %v", err)
+	}
+	if err := reporter.Report(&autoscaler.Stat{AverageConcurrentStreams: 4}); err != nil {
+		t.Error(err)
+	}
+	checkData(t, averageConcurrentStreamsGV, 4)
+}
+
 func testReportWithProxiedRequests(t *testing.T, stat *autoscaler.Stat, reqCount, concurrency, proxiedCount, proxiedConcurrency float64) {
 	t.Helper()
 	reporter, err := NewPrometheusStatsReporter(namespace, config, revision, pod)
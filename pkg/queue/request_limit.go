@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	pkghttp "knative.dev/serving/pkg/http"
+)
+
+// ErrRequestBodyTooLarge is returned when reading a request body past the
+// limit of a RequestLimitHandler.
+var ErrRequestBodyTooLarge = errors.New("request body too large")
+
+// RequestLimits are the maximal sizes of the requests a revision accepts.
+type RequestLimits struct {
+	// MaxBodyBytes is the maximal size of the request bodies. 0 leaves them
+	// unlimited.
+	MaxBodyBytes int64
+	// MaxHeaderBytes is the maximal summed size of the names and values of
+	// the request headers. 0 leaves them unlimited.
+	MaxHeaderBytes int
+}
+
+type limitedBodyKey struct{}
+
+// RequestLimitHandler rejects the requests exceeding the limits before they
+// reach `h`. Requests with too large headers are answered with 431 Request
+// Header Fields Too Large, requests announcing too large bodies with 413
+// Request Entity Too Large. Bodies of unknown length are streamed and fail to
+// be read once they pass the limit, see RequestBodyTooLarge, so `h` sees the
+// beginning of those.
+func RequestLimitHandler(h http.Handler, limits RequestLimits) http.Handler {
+	if limits.MaxBodyBytes <= 0 && limits.MaxHeaderBytes <= 0 {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limits.MaxHeaderBytes > 0 && headerSize(r.Header) > limits.MaxHeaderBytes {
+			pkghttp.Error(w, r, "request header fields too large", http.StatusRequestHeaderFieldsTooLarge)
+			return
+		}
+		if limits.MaxBodyBytes > 0 && r.Body != nil && r.Body != http.NoBody {
+			if r.ContentLength > limits.MaxBodyBytes {
+				pkghttp.Error(w, r, "request entity too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			body := &limitedBody{ReadCloser: r.Body, remaining: limits.MaxBodyBytes}
+			r = r.WithContext(context.WithValue(r.Context(), limitedBodyKey{}, body))
+			r.Body = body
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// RequestBodyTooLarge tells whether reading the body of the request failed
+// because it passed the limit of a RequestLimitHandler. Proxies use it to
+// answer such requests with 413 Request Entity Too Large rather than with
+// 502 Bad Gateway.
+func RequestBodyTooLarge(r *http.Request) bool {
+	body, ok := r.Context().Value(limitedBodyKey{}).(*limitedBody)
+	return ok && atomic.LoadInt32(&body.exceeded) == 1
+}
+
+// headerSize is the summed size of the names and values of the header.
+func headerSize(h http.Header) int {
+	size := 0
+	for name, values := range h {
+		for _, v := range values {
+			size += len(name) + len(v)
+		}
+	}
+	return size
+}
+
+// limitedBody fails with ErrRequestBodyTooLarge once more than `remaining`
+// bytes are read from it.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	// exceeded is set to 1 once the limit was passed. It's read from the
+	// goroutine handling the request, while the body might be read by the
+	// transport's.
+	exceeded int32
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if atomic.LoadInt32(&b.exceeded) == 1 {
+		return 0, ErrRequestBodyTooLarge
+	}
+	// Read one byte more than allowed to tell bodies of exactly the limit
+	// from larger ones.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		atomic.StoreInt32(&b.exceeded, 1)
+		return int(b.remaining), ErrRequestBodyTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
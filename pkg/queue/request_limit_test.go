@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRequestLimitHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		limits        RequestLimits
+		header        string
+		body          string
+		contentLength int64
+		wantCode      int
+		wantBody      string
+	}{{
+		name:          "no limits",
+		header:        strings.Repeat("a", 100),
+		body:          strings.Repeat("b", 100),
+		contentLength: 100,
+		wantCode:      http.StatusOK,
+		wantBody:      strings.Repeat("b", 100),
+	}, {
+		name:          "within limits",
+		limits:        RequestLimits{MaxBodyBytes: 10, MaxHeaderBytes: 20},
+		header:        "value",
+		body:          strings.Repeat("b", 10),
+		contentLength: 10,
+		wantCode:      http.StatusOK,
+		wantBody:      strings.Repeat("b", 10),
+	}, {
+		name:          "header too large",
+		limits:        RequestLimits{MaxBodyBytes: 10, MaxHeaderBytes: 20},
+		header:        strings.Repeat("a", 20),
+		body:          "body",
+		contentLength: 4,
+		wantCode:      http.StatusRequestHeaderFieldsTooLarge,
+	}, {
+		name:          "content length too large",
+		limits:        RequestLimits{MaxBodyBytes: 10},
+		body:          strings.Repeat("b", 11),
+		contentLength: 11,
+		wantCode:      http.StatusRequestEntityTooLarge,
+	}, {
+		name:          "unknown length within limit",
+		limits:        RequestLimits{MaxBodyBytes: 10},
+		body:          strings.Repeat("b", 10),
+		contentLength: -1,
+		wantCode:      http.StatusOK,
+		wantBody:      strings.Repeat("b", 10),
+	}, {
+		name:          "unknown length too large",
+		limits:        RequestLimits{MaxBodyBytes: 10},
+		body:          strings.Repeat("b", 11),
+		contentLength: -1,
+		wantCode:      http.StatusRequestEntityTooLarge,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := RequestLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					if !RequestBodyTooLarge(r) {
+						t.Errorf("RequestBodyTooLarge() = false for error %v", err)
+					}
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+					return
+				}
+				w.Write(body)
+			}), test.limits)
+
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", strings.NewReader(test.body))
+			req.ContentLength = test.contentLength
+			if test.header != "" {
+				req.Header.Set("X", test.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != test.wantCode {
+				t.Errorf("Code = %d, want: %d", rec.Code, test.wantCode)
+			}
+			if got := rec.Body.String(); test.wantCode == http.StatusOK && got != test.wantBody {
+				t.Errorf("Body = %q, want: %q", got, test.wantBody)
+			}
+		})
+	}
+}
+
+func TestRequestLimitHandlerProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if RequestBodyTooLarge(r) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	h := RequestLimitHandler(proxy, RequestLimits{MaxBodyBytes: 1024})
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", strings.NewReader(strings.Repeat("b", 4096)))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got, want := rec.Code, http.StatusRequestEntityTooLarge; got != want {
+		t.Errorf("Code = %d, want: %d", got, want)
+	}
+}
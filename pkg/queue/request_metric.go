@@ -46,6 +46,9 @@ func NewRequestMetricHandler(h http.Handler, r stats.StatsReporter) (http.Handle
 func (h *requestMetricHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	rr := pkghttp.NewResponseRecorder(w, http.StatusOK)
 	startTime := time.Now()
+	// timeoutKind is set by a TimeoutHandler handling the request.
+	var timeoutKind string
+	r = r.WithContext(withTimeoutKind(r.Context(), &timeoutKind))
 
 	defer func() {
 		// Filter probe requests for revision metrics.
@@ -56,6 +59,9 @@ func (h *requestMetricHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		// If ServeHTTP panics, recover, record the failure and panic again.
 		err := recover()
 		latency := time.Since(startTime)
+		if timeoutKind != "" {
+			h.statsReporter.ReportTimeout(timeoutKind)
+		}
 		if err != nil {
 			h.sendRequestMetrics(http.StatusInternalServerError, latency)
 			panic(err)
@@ -113,6 +113,31 @@ func TestRequestMetricHandlerPanickingHandler(t *testing.T) {
 	handler.ServeHTTP(resp, req)
 }
 
+func TestRequestMetricHandlerTimeout(t *testing.T) {
+	baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	})
+	r := &fakeStatsReporter{}
+	handler, err := NewRequestMetricHandler(TimeoutHandler(baseHandler, Timeouts{
+		FirstByte: 10 * time.Second,
+		Total:     time.Millisecond,
+	}, ""), r)
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", bytes.NewBufferString("test"))
+	handler.ServeHTTP(resp, req)
+
+	if got, want := r.lastRespCode, http.StatusGatewayTimeout; got != want {
+		t.Errorf("Response code got %v, want %v", got, want)
+	}
+	if got, want := r.lastTimeoutKind, TotalTimeout; got != want {
+		t.Errorf("Timeout kind got %q, want %q", got, want)
+	}
+}
+
 // fakeStatsReporter just record the last stat it received and the times it
 // calls ReportRequestCount and ReportResponseTime
 type fakeStatsReporter struct {
@@ -121,6 +146,7 @@ type fakeStatsReporter struct {
 	lastRespCode        int
 	lastReqCount        int64
 	lastReqLatency      time.Duration
+	lastTimeoutKind     string
 }
 
 func (r *fakeStatsReporter) ReportRequestCount(responseCode int, v int64) error {
@@ -136,3 +162,8 @@ func (r *fakeStatsReporter) ReportResponseTime(responseCode int, d time.Duration
 	r.lastReqLatency = d
 	return nil
 }
+
+func (r *fakeStatsReporter) ReportTimeout(kind string) error {
+	r.lastTimeoutKind = kind
+	return nil
+}
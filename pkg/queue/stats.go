@@ -40,6 +40,15 @@ const (
 	ProxiedIn
 	// ProxiedOut represents a finished proxied request.
 	ProxiedOut
+	// StreamIn represents an incoming request turning into a stream, e.g. a
+	// WebSocket connection or server-sent events.
+	StreamIn
+	// StreamOut represents a finished stream.
+	StreamOut
+	// ProxiedStreamIn represents a proxied request turning into a stream.
+	ProxiedStreamIn
+	// ProxiedStreamOut represents a finished proxied stream.
+	ProxiedStreamOut
 )
 
 // Channels is a structure for holding the channels for driving Stats.
@@ -59,8 +68,10 @@ type Stats struct {
 	ch      Channels
 }
 
-// NewStats instantiates a new instance of Stats.
-func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
+// NewStats instantiates a new instance of Stats. If excludeStreams is set, the
+// requests that turned into streams don't count towards the concurrency
+// anymore.
+func NewStats(podName string, channels Channels, startedAt time.Time, excludeStreams bool) *Stats {
 	s := &Stats{
 		podName: podName,
 		ch:      channels,
@@ -72,11 +83,13 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 			proxiedCount       float64
 			concurrency        int32
 			proxiedConcurrency int32
+			streamConcurrency  int32
 		)
 
 		lastChange := startedAt
 		timeOnConcurrency := make(map[int32]time.Duration)
 		timeOnProxiedConcurrency := make(map[int32]time.Duration)
+		timeOnStreamConcurrency := make(map[int32]time.Duration)
 
 		// Updates the lastChanged/timeOnConcurrency state
 		// Note: Due to nature of the channels used below, the ReportChan
@@ -88,6 +101,7 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 				durationSinceChange := time.Sub(lastChange)
 				timeOnConcurrency[concurrency] += durationSinceChange
 				timeOnProxiedConcurrency[proxiedConcurrency] += durationSinceChange
+				timeOnStreamConcurrency[streamConcurrency] += durationSinceChange
 				lastChange = time
 			}
 		}
@@ -110,6 +124,26 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 					fallthrough
 				case ReqOut:
 					concurrency--
+				case ProxiedStreamIn:
+					if excludeStreams {
+						proxiedConcurrency--
+					}
+					fallthrough
+				case StreamIn:
+					streamConcurrency++
+					if excludeStreams {
+						concurrency--
+					}
+				case ProxiedStreamOut:
+					if excludeStreams {
+						proxiedConcurrency++
+					}
+					fallthrough
+				case StreamOut:
+					streamConcurrency--
+					if excludeStreams {
+						concurrency++
+					}
 				}
 			case now := <-s.ch.ReportChan:
 				updateState(now)
@@ -121,6 +155,7 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 					AverageProxiedConcurrentRequests: weightedAverage(timeOnProxiedConcurrency),
 					RequestCount:                     requestCount,
 					ProxiedRequestCount:              proxiedCount,
+					AverageConcurrentStreams:         weightedAverage(timeOnStreamConcurrency),
 				}
 				// Send the stat to another goroutine to transmit
 				// so we can continue bucketing stats.
@@ -129,6 +164,7 @@ func NewStats(podName string, channels Channels, startedAt time.Time) *Stats {
 				// Reset the stat counts which have been reported.
 				timeOnConcurrency = make(map[int32]time.Duration)
 				timeOnProxiedConcurrency = make(map[int32]time.Duration)
+				timeOnStreamConcurrency = make(map[int32]time.Duration)
 				requestCount = 0
 				proxiedCount = 0
 			}
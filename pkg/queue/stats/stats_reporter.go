@@ -37,6 +37,7 @@ var defaultLatencyDistribution = view.Distribution(5, 10, 20, 40, 60, 80, 100, 1
 type StatsReporter interface {
 	ReportRequestCount(responseCode int, v int64) error
 	ReportResponseTime(responseCode int, d time.Duration) error
+	ReportTimeout(kind string) error
 }
 
 // Reporter holds cached metric objects to report autoscaler metrics
@@ -49,12 +50,15 @@ type Reporter struct {
 	revisionTagKey       tag.Key
 	responseCodeKey      tag.Key
 	responseCodeClassKey tag.Key
+	timeoutKindKey       tag.Key
 	countMetric          *stats.Int64Measure
 	latencyMetric        *stats.Float64Measure
+	timeoutMetric        *stats.Int64Measure
 }
 
-// NewStatsReporter creates a reporter that collects and reports queue proxy metrics
-func NewStatsReporter(ns, service, config, rev string, countMetric *stats.Int64Measure, latencyMetric *stats.Float64Measure) (*Reporter, error) {
+// NewStatsReporter creates a reporter that collects and reports queue proxy metrics.
+// The timeoutMetric may be nil if the reporter doesn't see any timeouts.
+func NewStatsReporter(ns, service, config, rev string, countMetric *stats.Int64Measure, latencyMetric *stats.Float64Measure, timeoutMetric *stats.Int64Measure) (*Reporter, error) {
 	if ns == "" {
 		return nil, errors.New("namespace must not be empty")
 	}
@@ -90,23 +94,35 @@ func NewStatsReporter(ns, service, config, rev string, countMetric *stats.Int64M
 	if err != nil {
 		return nil, err
 	}
+	timeoutKindTag, err := tag.NewKey("timeout_kind")
+	if err != nil {
+		return nil, err
+	}
 
 	// Create view to see our measurements.
-	err = view.Register(
-		&view.View{
+	views := []*view.View{
+		{
 			Description: "The number of requests that are routed to queue-proxy",
 			Measure:     countMetric,
 			Aggregation: view.Sum(),
 			TagKeys:     []tag.Key{nsTag, svcTag, configTag, revTag, responseCodeTag, responseCodeClassTag},
 		},
-		&view.View{
+		{
 			Description: "The response time in millisecond",
 			Measure:     latencyMetric,
 			Aggregation: defaultLatencyDistribution,
 			TagKeys:     []tag.Key{nsTag, svcTag, configTag, revTag, responseCodeTag, responseCodeClassTag},
 		},
-	)
-	if err != nil {
+	}
+	if timeoutMetric != nil {
+		views = append(views, &view.View{
+			Description: "The number of requests that timed out",
+			Measure:     timeoutMetric,
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{nsTag, svcTag, configTag, revTag, timeoutKindTag},
+		})
+	}
+	if err := view.Register(views...); err != nil {
 		return nil, err
 	}
 
@@ -131,8 +147,10 @@ func NewStatsReporter(ns, service, config, rev string, countMetric *stats.Int64M
 		revisionTagKey:       revTag,
 		responseCodeKey:      responseCodeTag,
 		responseCodeClassKey: responseCodeClassTag,
+		timeoutKindKey:       timeoutKindTag,
 		countMetric:          countMetric,
 		latencyMetric:        latencyMetric,
+		timeoutMetric:        timeoutMetric,
 	}, nil
 }
 
@@ -182,6 +200,24 @@ func (r *Reporter) ReportResponseTime(responseCode int, d time.Duration) error {
 	return nil
 }
 
+// ReportTimeout captures a request timing out, by the kind of the timeout.
+func (r *Reporter) ReportTimeout(kind string) error {
+	if !r.initialized {
+		return errors.New("StatsReporter is not initialized yet")
+	}
+	if r.timeoutMetric == nil {
+		return nil
+	}
+
+	ctx, err := tag.New(r.ctx, tag.Insert(r.timeoutKindKey, kind))
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, r.timeoutMetric.M(1))
+	return nil
+}
+
 // responseCodeClass converts response code to a string of response code class.
 // e.g. The response code class is "5xx" for response code 503.
 func responseCodeClass(responseCode int) string {
@@ -34,6 +34,7 @@ const (
 	testRev     = "helloworld-go-00001"
 	countName   = "request_count"
 	latencyName = "request_latencies"
+	timeoutName = "request_timeout_count"
 )
 
 var (
@@ -45,6 +46,10 @@ var (
 		latencyName,
 		"The response time in millisecond",
 		stats.UnitMilliseconds)
+	timeoutMetric = stats.Int64(
+		timeoutName,
+		"The number of requests that timed out in queue-proxy",
+		stats.UnitDimensionless)
 )
 
 func TestNewStatsReporter_negative(t *testing.T) {
@@ -80,7 +85,7 @@ func TestNewStatsReporter_negative(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			if _, err := NewStatsReporter(test.namespace, testSvc, test.config, test.revision, countMetric, latencyMetric); err.Error() != test.result.Error() {
+			if _, err := NewStatsReporter(test.namespace, testSvc, test.config, test.revision, countMetric, latencyMetric, timeoutMetric); err.Error() != test.result.Error() {
 				t.Errorf("%+v, got: '%+v'", test.errorMsg, err)
 			}
 		})
@@ -93,7 +98,7 @@ func TestReporter_Report(t *testing.T) {
 		t.Error("Reporter.ReportRequestCount() expected an error for Report call before init. Got success.")
 	}
 
-	r, err := NewStatsReporter(testNs, testSvc, testConf, testRev, countMetric, latencyMetric)
+	r, err := NewStatsReporter(testNs, testSvc, testConf, testRev, countMetric, latencyMetric, timeoutMetric)
 	if err != nil {
 		t.Fatalf("Unexpected error from NewStatsReporter() = %v", err)
 	}
@@ -124,10 +129,21 @@ func TestReporter_Report(t *testing.T) {
 	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportResponseTime(200, 300*time.Millisecond) })
 	metricstest.CheckDistributionData(t, "request_latencies", wantTags, 3, 100, 300)
 
+	// Timeouts are counted by their kind.
+	expectSuccess(t, "ReportTimeout", func() error { return r.ReportTimeout("idle") })
+	expectSuccess(t, "ReportTimeout", func() error { return r.ReportTimeout("idle") })
+	metricstest.CheckSumData(t, timeoutName, map[string]string{
+		metricskey.LabelNamespaceName:     testNs,
+		metricskey.LabelServiceName:       testSvc,
+		metricskey.LabelConfigurationName: testConf,
+		metricskey.LabelRevisionName:      testRev,
+		"timeout_kind":                    "idle",
+	}, 2)
+
 	unregisterViews(r)
 
 	// Test reporter with empty service name
-	r, err = NewStatsReporter(testNs, "" /*service name*/, testConf, testRev, countMetric, latencyMetric)
+	r, err = NewStatsReporter(testNs, "" /*service name*/, testConf, testRev, countMetric, latencyMetric, timeoutMetric)
 	if err != nil {
 		t.Fatalf("Unexpected error from NewStatsReporter() = %v", err)
 	}
@@ -145,6 +161,15 @@ func TestReporter_Report(t *testing.T) {
 	metricstest.CheckSumData(t, "request_count", wantTags, 1)
 
 	unregisterViews(r)
+
+	// Test reporter without timeouts
+	r, err = NewStatsReporter(testNs, testSvc, testConf, testRev, countMetric, latencyMetric, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewStatsReporter() = %v", err)
+	}
+	expectSuccess(t, "ReportTimeout", func() error { return r.ReportTimeout("idle") })
+
+	unregisterViews(r)
 }
 
 func expectSuccess(t *testing.T, funcName string, f func() error) {
@@ -158,7 +183,7 @@ func unregisterViews(r *Reporter) error {
 	if !r.initialized {
 		return errors.New("reporter is not initialized")
 	}
-	metricstest.Unregister(countName, latencyName)
+	metricstest.Unregister(countName, latencyName, timeoutName)
 	r.initialized = false
 	return nil
 }
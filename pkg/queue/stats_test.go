@@ -257,6 +257,65 @@ func TestTwoRequestsOneProxied(t *testing.T) {
 	}
 }
 
+func TestOneStream(t *testing.T) {
+	now := time.Now()
+	s := newTestStats(now)
+	s.requestStart(now)
+	now = now.Add(500 * time.Millisecond)
+	s.streamStart(now)
+	now = now.Add(500 * time.Millisecond)
+	got := s.report(now)
+	want := &autoscaler.Stat{
+		Time:                      &now,
+		PodName:                   podName,
+		AverageConcurrentRequests: 1.0,
+		RequestCount:              1,
+		AverageConcurrentStreams:  0.5,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected stat (-want +got): %v", diff)
+	}
+}
+
+func TestStreamsExcluded(t *testing.T) {
+	now := time.Now()
+	s := newTestStatsExcludingStreams(now)
+	s.requestStart(now)
+	s.proxiedStart(now)
+	now = now.Add(500 * time.Millisecond)
+	s.streamStart(now)
+	s.proxiedStreamStart(now)
+	now = now.Add(500 * time.Millisecond)
+	got := s.report(now)
+	want := &autoscaler.Stat{
+		Time:                             &now,
+		PodName:                          podName,
+		AverageConcurrentRequests:        1.0,
+		AverageProxiedConcurrentRequests: 0.5,
+		RequestCount:                     2,
+		ProxiedRequestCount:              1,
+		AverageConcurrentStreams:         1.0,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected stat (-want +got): %v", diff)
+	}
+
+	// The requests count again once their streams ended.
+	s.streamEnd(now)
+	s.proxiedStreamEnd(now)
+	now = now.Add(time.Second)
+	got = s.report(now)
+	want = &autoscaler.Stat{
+		Time:                             &now,
+		PodName:                          podName,
+		AverageConcurrentRequests:        2.0,
+		AverageProxiedConcurrentRequests: 1.0,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected stat (-want +got): %v", diff)
+	}
+}
+
 // Test type to hold the bi-directional time channels
 type testStats struct {
 	Stats
@@ -264,13 +323,21 @@ type testStats struct {
 }
 
 func newTestStats(now time.Time) *testStats {
+	return newTestStatsWith(now, false /*excludeStreams*/)
+}
+
+func newTestStatsExcludingStreams(now time.Time) *testStats {
+	return newTestStatsWith(now, true /*excludeStreams*/)
+}
+
+func newTestStatsWith(now time.Time, excludeStreams bool) *testStats {
 	reportBiChan := make(chan time.Time)
 	ch := Channels{
 		ReqChan:    make(chan ReqEvent),
 		ReportChan: (<-chan time.Time)(reportBiChan),
 		StatChan:   make(chan *autoscaler.Stat),
 	}
-	s := NewStats(podName, ch, now)
+	s := NewStats(podName, ch, now, excludeStreams)
 	t := &testStats{
 		Stats:        *s,
 		reportBiChan: reportBiChan,
@@ -294,6 +361,22 @@ func (s *testStats) proxiedEnd(now time.Time) {
 	s.ch.ReqChan <- ReqEvent{Time: now, EventType: ProxiedOut}
 }
 
+func (s *testStats) streamStart(now time.Time) {
+	s.ch.ReqChan <- ReqEvent{Time: now, EventType: StreamIn}
+}
+
+func (s *testStats) streamEnd(now time.Time) {
+	s.ch.ReqChan <- ReqEvent{Time: now, EventType: StreamOut}
+}
+
+func (s *testStats) proxiedStreamStart(now time.Time) {
+	s.ch.ReqChan <- ReqEvent{Time: now, EventType: ProxiedStreamIn}
+}
+
+func (s *testStats) proxiedStreamEnd(now time.Time) {
+	s.ch.ReqChan <- ReqEvent{Time: now, EventType: ProxiedStreamOut}
+}
+
 func (s *testStats) report(now time.Time) *autoscaler.Stat {
 	s.reportBiChan <- now
 	return <-s.ch.StatChan
@@ -25,38 +25,99 @@ import (
 	"sync"
 	"time"
 
+	"google.golang.org/grpc/codes"
 	"knative.dev/pkg/websocket"
+	pkghttp "knative.dev/serving/pkg/http"
+	"knative.dev/serving/pkg/network"
 )
 
 var defaultTimeoutBody = "<html><head><title>Timeout</title></head><body><h1>Timeout</h1></body></html>"
 
+// The kinds of timeouts a TimeoutHandler enforces.
+const (
+	// FirstByteTimeout is the time limit in which the first byte of the
+	// response must be written.
+	FirstByteTimeout = "first_byte"
+	// TotalTimeout is the time limit in which the whole response must be
+	// written.
+	TotalTimeout = "total"
+	// IdleTimeout is the time limit between two writes of the response.
+	IdleTimeout = "idle"
+)
+
+// Timeouts are the time limits of a TimeoutHandler. Zero leaves the
+// respective time unlimited, except for FirstByte.
+type Timeouts struct {
+	// FirstByte is the time limit in which the first byte of the response
+	// must be written.
+	FirstByte time.Duration
+	// Total is the time limit in which the whole response must be written.
+	Total time.Duration
+	// Idle is the time limit between two writes of the response, once the
+	// first byte was written.
+	Idle time.Duration
+}
+
+type timeoutKindKey struct{}
+
+// withTimeoutKind attaches a slot to the context, in which a TimeoutHandler
+// records the kind of a timeout of the request.
+func withTimeoutKind(ctx context.Context, kind *string) context.Context {
+	return context.WithValue(ctx, timeoutKindKey{}, kind)
+}
+
+func recordTimeout(ctx context.Context, kind string) {
+	if slot, ok := ctx.Value(timeoutKindKey{}).(*string); ok {
+		*slot = kind
+	}
+}
+
 // TimeToFirstByteTimeoutHandler returns a Handler that runs `h` with the
 // given time limit in which the first byte of the response must be written.
+// See TimeoutHandler.
+func TimeToFirstByteTimeoutHandler(h http.Handler, dt time.Duration, msg string) http.Handler {
+	return TimeoutHandler(h, Timeouts{FirstByte: dt}, msg)
+}
+
+// TimeoutHandler returns a Handler that runs `h` with the given time limits.
 //
 // The new Handler calls h.ServeHTTP to handle each request, but if a
-// call runs for longer than its time limit, the handler responds with
-// a 503 Service Unavailable error and the given message in its body.
-// (If msg is empty, a suitable default message will be sent.)
+// call runs for longer than its time limit to the first byte, the handler
+// responds with a 503 Service Unavailable error and the given message in its
+// body. (If msg is empty, a suitable default message will be sent.) If the
+// call runs for longer than its total time limit before writing, the
+// handler responds with 504 Gateway Timeout instead. gRPC requests are
+// answered with the status DEADLINE_EXCEEDED in both cases.
 // After such a timeout, writes by h to its ResponseWriter will return
 // ErrHandlerTimeout.
 //
+// A response that is already being written when it runs out of its total
+// time limit, or that isn't written to for longer than the idle time limit,
+// is aborted by panicking with http.ErrAbortHandler, so that the client can
+// tell the response is incomplete. Hijacked connections are not subject to
+// these limits.
+//
+// Callers can shorten the total time limit of a request with the
+// network.RequestTimeoutHeaderName header, but not extend it past the time
+// limit to the first byte.
+//
 // A panic from the underlying handler is propagated as-is to be able to
 // make use of custom panic behavior by HTTP handlers. See
 // https://golang.org/pkg/net/http/#Handler.
 //
 // The implementation is largely inspired by http.TimeoutHandler.
-func TimeToFirstByteTimeoutHandler(h http.Handler, dt time.Duration, msg string) http.Handler {
+func TimeoutHandler(h http.Handler, timeouts Timeouts, msg string) http.Handler {
 	return &timeoutHandler{
-		handler: h,
-		body:    msg,
-		dt:      dt,
+		handler:  h,
+		body:     msg,
+		timeouts: timeouts,
 	}
 }
 
 type timeoutHandler struct {
-	handler http.Handler
-	body    string
-	dt      time.Duration
+	handler  http.Handler
+	body     string
+	timeouts Timeouts
 }
 
 func (h *timeoutHandler) errorBody() string {
@@ -66,17 +127,34 @@ func (h *timeoutHandler) errorBody() string {
 	return defaultTimeoutBody
 }
 
+// totalTimeout returns the total time limit of the request, taking the
+// deadline of the caller into account.
+func (h *timeoutHandler) totalTimeout(r *http.Request) time.Duration {
+	total := h.timeouts.Total
+	d, err := time.ParseDuration(r.Header.Get(network.RequestTimeoutHeaderName))
+	if err != nil || d <= 0 {
+		return total
+	}
+	if d > h.timeouts.FirstByte {
+		d = h.timeouts.FirstByte
+	}
+	if total == 0 || d < total {
+		return d
+	}
+	return total
+}
+
 func (h *timeoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx, cancelCtx := context.WithCancel(r.Context())
 	defer cancelCtx()
 
 	done := make(chan struct{})
 	// The recovery value of a panic is written to this channel to be
-	// propagated (panicked with) again.
-	panicChan := make(chan interface{})
-	defer close(panicChan)
+	// propagated (panicked with) again. It's buffered, so that the handler
+	// can still panic once we stopped waiting for it.
+	panicChan := make(chan interface{}, 1)
 
-	tw := &timeoutWriter{w: w}
+	tw := &timeoutWriter{w: w, grpc: pkghttp.IsGRPC(r), wrote: make(chan struct{}, 1)}
 	go func() {
 		// The defer statements are executed in LIFO order,
 		// so recover will execute first, then only, the channel will be closed.
@@ -89,18 +167,70 @@ func (h *timeoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handler.ServeHTTP(tw, r.WithContext(ctx))
 	}()
 
-	timeout := time.NewTimer(h.dt)
-	defer timeout.Stop()
+	firstByte := time.NewTimer(h.timeouts.FirstByte)
+	defer firstByte.Stop()
+	firstByteC := firstByte.C
+
+	var totalC <-chan time.Time
+	if total := h.totalTimeout(r); total > 0 {
+		timer := time.NewTimer(total)
+		defer timer.Stop()
+		totalC = timer.C
+	}
+
+	// The idle timer is started by the first write.
+	var idle *time.Timer
+	var idleC <-chan time.Time
+	defer func() {
+		if idle != nil {
+			idle.Stop()
+		}
+	}()
+
 	for {
 		select {
 		case p := <-panicChan:
 			panic(p)
 		case <-done:
 			return
-		case <-timeout.C:
-			if tw.TimeoutAndWriteError(h.errorBody()) {
+		case <-firstByteC:
+			firstByteC = nil
+			if tw.TimeoutAndWriteError(h.errorBody(), http.StatusServiceUnavailable) {
+				recordTimeout(r.Context(), FirstByteTimeout)
+				return
+			}
+		case <-totalC:
+			totalC = nil
+			if tw.TimeoutAndWriteError(h.errorBody(), http.StatusGatewayTimeout) {
+				recordTimeout(r.Context(), TotalTimeout)
 				return
 			}
+			if tw.TimeoutAndAbort() {
+				recordTimeout(r.Context(), TotalTimeout)
+				panic(http.ErrAbortHandler)
+			}
+		case <-tw.wrote:
+			if h.timeouts.Idle <= 0 {
+				continue
+			}
+			if idle == nil {
+				idle = time.NewTimer(h.timeouts.Idle)
+				idleC = idle.C
+				continue
+			}
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(h.timeouts.Idle)
+		case <-idleC:
+			idleC = nil
+			if tw.TimeoutAndAbort() {
+				recordTimeout(r.Context(), IdleTimeout)
+				panic(http.ErrAbortHandler)
+			}
 		}
 	}
 }
@@ -114,10 +244,17 @@ func (h *timeoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // the response is allowed to continue.
 type timeoutWriter struct {
 	w http.ResponseWriter
+	// grpc is whether the request is a gRPC request, which is answered
+	// with a gRPC status rather than an error body.
+	grpc bool
+	// wrote is signaled on writes, so that the idle time limit can be
+	// reset.
+	wrote chan struct{}
 
 	mu        sync.Mutex
 	timedOut  bool
 	wroteOnce bool
+	hijacked  bool
 }
 
 var _ http.Flusher = (*timeoutWriter)(nil)
@@ -125,14 +262,32 @@ var _ http.Flusher = (*timeoutWriter)(nil)
 var _ http.ResponseWriter = (*timeoutWriter)(nil)
 
 func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+
 	tw.w.(http.Flusher).Flush()
 }
 
 // Hijack calls Hijack() on the wrapped http.ResponseWriter if it implements
 // http.Hijacker interface, which is required for net/http/httputil/reverseproxy
 // to handle connection upgrade/switching protocol.  Otherwise returns an error.
+// A hijacked connection counts as written, so that it outlives the timeout.
 func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	return websocket.HijackIfPossible(tw.w)
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+
+	c, rw, err := websocket.HijackIfPossible(tw.w)
+	if err == nil {
+		tw.wroteOnce = true
+		tw.hijacked = true
+	}
+	return c, rw, err
 }
 
 func (tw *timeoutWriter) Header() http.Header { return tw.w.Header() }
@@ -145,6 +300,7 @@ func (tw *timeoutWriter) Write(p []byte) (int, error) {
 	}
 
 	tw.wroteOnce = true
+	tw.signalWrite()
 	return tw.w.Write(p)
 }
 
@@ -156,22 +312,36 @@ func (tw *timeoutWriter) WriteHeader(code int) {
 	}
 
 	tw.wroteOnce = true
+	tw.signalWrite()
 	tw.w.WriteHeader(code)
 }
 
-// TimeoutAndError writes an error to the response write if
-// nothing has been written on the writer before. Returns whether
+// signalWrite notifies the handler of a write without blocking. A pending
+// notification covers the writes following it.
+func (tw *timeoutWriter) signalWrite() {
+	select {
+	case tw.wrote <- struct{}{}:
+	default:
+	}
+}
+
+// TimeoutAndWriteError writes an error with the given code to the response
+// writer if nothing has been written on the writer before. Returns whether
 // an error was written or not.
 //
 // If this writes an error, all subsequent calls to Write will
 // result in http.ErrHandlerTimeout.
-func (tw *timeoutWriter) TimeoutAndWriteError(msg string) bool {
+func (tw *timeoutWriter) TimeoutAndWriteError(msg string, code int) bool {
 	tw.mu.Lock()
 	defer tw.mu.Unlock()
 
 	if !tw.wroteOnce {
-		tw.w.WriteHeader(http.StatusServiceUnavailable)
-		io.WriteString(tw.w, msg)
+		if tw.grpc {
+			pkghttp.GRPCError(tw.w, codes.DeadlineExceeded, msg)
+		} else {
+			tw.w.WriteHeader(code)
+			io.WriteString(tw.w, msg)
+		}
 
 		tw.timedOut = true
 		return true
@@ -179,3 +349,20 @@ func (tw *timeoutWriter) TimeoutAndWriteError(msg string) bool {
 
 	return false
 }
+
+// TimeoutAndAbort stops the writes to a response that was written to before,
+// unless the connection was hijacked. Returns whether the response has to be
+// aborted.
+//
+// If this returns true, all subsequent calls to Write will result in
+// http.ErrHandlerTimeout.
+func (tw *timeoutWriter) TimeoutAndAbort() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.hijacked {
+		return false
+	}
+	tw.timedOut = true
+	return true
+}
@@ -18,9 +18,14 @@ package queue
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
+
+	"google.golang.org/grpc/codes"
+	pkghttp "knative.dev/serving/pkg/http"
+	"knative.dev/serving/pkg/network"
 )
 
 func TestTimeToFirstByteTimeoutHandler(t *testing.T) {
@@ -34,6 +39,7 @@ func TestTimeToFirstByteTimeoutHandler(t *testing.T) {
 		timeout        time.Duration
 		handler        func(mux *sync.Mutex, writeErrors chan error) http.Handler
 		timeoutMessage string
+		grpc           bool
 		wantStatus     int
 		wantBody       string
 		wantWriteError bool
@@ -89,6 +95,21 @@ func TestTimeToFirstByteTimeoutHandler(t *testing.T) {
 		wantStatus:     http.StatusServiceUnavailable,
 		wantBody:       "request timeout",
 		wantWriteError: true,
+	}, {
+		name:    "grpc timeout",
+		timeout: failingTimeout,
+		handler: func(mux *sync.Mutex, writeErrors chan error) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mux.Lock()
+				defer mux.Unlock()
+				_, werr := w.Write([]byte("hi"))
+				writeErrors <- werr
+			})
+		},
+		timeoutMessage: "request timeout",
+		grpc:           true,
+		wantStatus:     http.StatusOK,
+		wantWriteError: true,
 	}, {
 		name:    "propagate panic",
 		timeout: longTimeout,
@@ -107,6 +128,9 @@ func TestTimeToFirstByteTimeoutHandler(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
+			if test.grpc {
+				req.Header.Set("Content-Type", "application/grpc")
+			}
 
 			var reqMux sync.Mutex
 			writeErrors := make(chan error, 1)
@@ -133,6 +157,12 @@ func TestTimeToFirstByteTimeoutHandler(t *testing.T) {
 				t.Errorf("Handler returned unexpected body: got %q want %q", rr.Body.String(), test.wantBody)
 			}
 
+			if test.grpc {
+				if got, want := rr.Header().Get(pkghttp.GRPCStatusHeaderName), strconv.Itoa(int(codes.DeadlineExceeded)); got != want {
+					t.Errorf("Handler returned unexpected gRPC status: got %q want %q", got, want)
+				}
+			}
+
 			if test.wantWriteError {
 				err := <-writeErrors
 				if err != http.ErrHandlerTimeout {
@@ -142,3 +172,153 @@ func TestTimeToFirstByteTimeoutHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeoutHandler(t *testing.T) {
+	const (
+		failingTimeout = 1 * time.Millisecond
+		longTimeout    = 10 * time.Second
+	)
+
+	blocking := func(release chan struct{}) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		})
+	}
+	writeThenBlock := func(release chan struct{}) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hi"))
+			<-release
+		})
+	}
+
+	tests := []struct {
+		name       string
+		timeouts   Timeouts
+		deadline   string
+		handler    func(release chan struct{}) http.Handler
+		wantStatus int
+		wantBody   string
+		wantKind   string
+		wantAbort  bool
+	}{{
+		name:       "total timeout",
+		timeouts:   Timeouts{FirstByte: longTimeout, Total: failingTimeout},
+		handler:    blocking,
+		wantStatus: http.StatusGatewayTimeout,
+		wantBody:   defaultTimeoutBody,
+		wantKind:   TotalTimeout,
+	}, {
+		name:       "caller deadline",
+		timeouts:   Timeouts{FirstByte: longTimeout},
+		deadline:   "1ms",
+		handler:    blocking,
+		wantStatus: http.StatusGatewayTimeout,
+		wantBody:   defaultTimeoutBody,
+		wantKind:   TotalTimeout,
+	}, {
+		name:       "caller deadline shortens total timeout",
+		timeouts:   Timeouts{FirstByte: longTimeout, Total: longTimeout},
+		deadline:   "1ms",
+		handler:    blocking,
+		wantStatus: http.StatusGatewayTimeout,
+		wantBody:   defaultTimeoutBody,
+		wantKind:   TotalTimeout,
+	}, {
+		name:       "caller deadline capped",
+		timeouts:   Timeouts{FirstByte: failingTimeout},
+		deadline:   "1h",
+		handler:    blocking,
+		wantStatus: http.StatusServiceUnavailable,
+		wantBody:   defaultTimeoutBody,
+		wantKind:   FirstByteTimeout,
+	}, {
+		name:     "invalid caller deadline",
+		timeouts: Timeouts{FirstByte: longTimeout},
+		deadline: "soon",
+		handler: func(chan struct{}) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("hi"))
+			})
+		},
+		wantStatus: http.StatusOK,
+		wantBody:   "hi",
+	}, {
+		name:       "total timeout while writing",
+		timeouts:   Timeouts{FirstByte: longTimeout, Total: 20 * time.Millisecond},
+		handler:    writeThenBlock,
+		wantStatus: http.StatusOK,
+		wantBody:   "hi",
+		wantKind:   TotalTimeout,
+		wantAbort:  true,
+	}, {
+		name:       "idle timeout",
+		timeouts:   Timeouts{FirstByte: longTimeout, Idle: 20 * time.Millisecond},
+		handler:    writeThenBlock,
+		wantStatus: http.StatusOK,
+		wantBody:   "hi",
+		wantKind:   IdleTimeout,
+		wantAbort:  true,
+	}, {
+		name:     "writes keep idle timeout off",
+		timeouts: Timeouts{FirstByte: longTimeout, Idle: 50 * time.Millisecond},
+		handler: func(chan struct{}) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for i := 0; i < 5; i++ {
+					w.Write([]byte("hi"))
+					time.Sleep(20 * time.Millisecond)
+				}
+			})
+		},
+		wantStatus: http.StatusOK,
+		wantBody:   "hihihihihi",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			release := make(chan struct{})
+			defer close(release)
+
+			var kind string
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(withTimeoutKind(req.Context(), &kind))
+			if test.deadline != "" {
+				req.Header.Set(network.RequestTimeoutHeaderName, test.deadline)
+			}
+			rr := httptest.NewRecorder()
+			handler := TimeoutHandler(test.handler(release), test.timeouts, "")
+
+			func() {
+				defer func() {
+					recovered := recover()
+					if test.wantAbort && recovered != http.ErrAbortHandler {
+						t.Errorf("Recovered = %v, want: %v", recovered, http.ErrAbortHandler)
+					} else if !test.wantAbort && recovered != nil {
+						t.Errorf("Unexpected panic: %v", recovered)
+					}
+				}()
+				handler.ServeHTTP(rr, req)
+			}()
+
+			if got, want := rr.Code, test.wantStatus; got != want {
+				t.Errorf("Code = %d, want: %d", got, want)
+			}
+			if got, want := rr.Body.String(), test.wantBody; got != want {
+				t.Errorf("Body = %q, want: %q", got, want)
+			}
+			if got, want := kind, test.wantKind; got != want {
+				t.Errorf("Timeout kind = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTimeoutWriterFlushAfterTimeout(t *testing.T) {
+	rec := httptest.NewRecorder()
+	tw := &timeoutWriter{w: rec, wrote: make(chan struct{}, 1)}
+	if !tw.TimeoutAndAbort() {
+		t.Fatal("TimeoutAndAbort() = false, want true")
+	}
+	tw.Flush()
+	if rec.Flushed {
+		t.Error("Flushed = true, want no flush after the timeout")
+	}
+}
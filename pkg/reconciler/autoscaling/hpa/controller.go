@@ -31,6 +31,7 @@ import (
 	"knative.dev/pkg/controller"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/autoscaler/bucket"
 	"knative.dev/serving/pkg/reconciler"
 	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
 	"knative.dev/serving/pkg/reconciler/autoscaling/config"
@@ -61,6 +62,7 @@ func NewController(
 			ServiceLister:     serviceInformer.Lister(),
 			MetricLister:      metricInformer.Lister(),
 			PSInformerFactory: psInformerFactory,
+			Ownership:         bucket.OwnershipFromContext(ctx),
 		},
 		hpaLister: hpaInformer.Lister(),
 	}
@@ -89,6 +91,12 @@ func NewController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	// Reconcile all PAs when this replica starts or stops leading a bucket,
+	// to pick up or let go of the PAs of the bucket.
+	c.Ownership.Watch(func() {
+		controller.SendGlobalUpdates(paInformer.Informer(), paHandler)
+	})
+
 	c.Logger.Info("Setting up ConfigMap receivers")
 	configsToResync := []interface{}{
 		&autoscaler.Config{},
@@ -59,6 +59,11 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 	ctx = c.ConfigStore.ToContext(ctx)
 	logger.Debug("Reconcile hpa-class PodAutoscaler")
 
+	if !c.Owns(namespace, name) {
+		logger.Debug("PA is reconciled by another autoscaler replica")
+		return nil
+	}
+
 	original, err := c.PALister.PodAutoscalers(namespace).Get(name)
 	if errors.IsNotFound(err) {
 		logger.Debug("PA no longer exists")
@@ -18,6 +18,7 @@ package resources
 
 import (
 	"math"
+	"strings"
 
 	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
 	corev1 "k8s.io/api/core/v1"
@@ -59,18 +60,37 @@ func MakeHPA(pa *v1alpha1.PodAutoscaler, config *autoscaler.Config) *autoscaling
 		hpa.Spec.MinReplicas = &min
 	}
 
-	switch pa.Metric() {
-	case autoscaling.CPU:
+	metric := pa.Metric()
+	switch {
+	case metric == autoscaling.CPU:
+		if target, ok := pa.Target(); ok {
+			hpa.Spec.Metrics = []autoscalingv2beta1.MetricSpec{resourceMetric(corev1.ResourceCPU, target)}
+		}
+	case metric == autoscaling.Memory:
+		if target, ok := pa.Target(); ok {
+			hpa.Spec.Metrics = []autoscalingv2beta1.MetricSpec{resourceMetric(corev1.ResourceMemory, target)}
+		}
+	case strings.HasPrefix(metric, autoscaling.CustomMetricPrefix):
 		if target, ok := pa.Target(); ok {
 			hpa.Spec.Metrics = []autoscalingv2beta1.MetricSpec{{
-				Type: autoscalingv2beta1.ResourceMetricSourceType,
-				Resource: &autoscalingv2beta1.ResourceMetricSource{
-					Name:                     corev1.ResourceCPU,
-					TargetAverageUtilization: ptr.Int32(int32(math.Ceil(target))),
+				Type: autoscalingv2beta1.PodsMetricSourceType,
+				Pods: &autoscalingv2beta1.PodsMetricSource{
+					MetricName:         strings.TrimPrefix(metric, autoscaling.CustomMetricPrefix),
+					TargetAverageValue: *quantity(target),
 				},
 			}}
 		}
-	case autoscaling.Concurrency:
+	case strings.HasPrefix(metric, autoscaling.ExternalMetricsAPIPrefix):
+		if target, ok := pa.Target(); ok {
+			hpa.Spec.Metrics = []autoscalingv2beta1.MetricSpec{{
+				Type: autoscalingv2beta1.ExternalMetricSourceType,
+				External: &autoscalingv2beta1.ExternalMetricSource{
+					MetricName:         strings.TrimPrefix(metric, autoscaling.ExternalMetricsAPIPrefix),
+					TargetAverageValue: quantity(target),
+				},
+			}}
+		}
+	case metric == autoscaling.Concurrency:
 		t, _ := aresources.ResolveConcurrency(pa, config)
 		target := int64(math.Ceil(t))
 		hpa.Spec.Metrics = []autoscalingv2beta1.MetricSpec{{
@@ -89,3 +109,20 @@ func MakeHPA(pa *v1alpha1.PodAutoscaler, config *autoscaler.Config) *autoscaling
 	}
 	return hpa
 }
+
+// resourceMetric targets the average utilization of the requested resource, in percent.
+func resourceMetric(name corev1.ResourceName, target float64) autoscalingv2beta1.MetricSpec {
+	return autoscalingv2beta1.MetricSpec{
+		Type: autoscalingv2beta1.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta1.ResourceMetricSource{
+			Name:                     name,
+			TargetAverageUtilization: ptr.Int32(int32(math.Ceil(target))),
+		},
+	}
+}
+
+// quantity converts the target into a Quantity, keeping fractional targets exact
+// to the thousandth.
+func quantity(target float64) *resource.Quantity {
+	return resource.NewMilliQuantity(int64(math.Round(target*1000)), resource.DecimalSI)
+}
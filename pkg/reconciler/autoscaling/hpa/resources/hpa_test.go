@@ -85,6 +85,45 @@ func TestMakeHPA(t *testing.T) {
 					TargetAverageUtilization: ptr.Int32(1983),
 				},
 			})),
+	}, {
+		name: "with metric=memory and target=75",
+		pa:   pa(WithTargetAnnotation("75"), WithMetricAnnotation(autoscaling.Memory)),
+		want: hpa(
+			withAnnotationValue(autoscaling.MetricAnnotationKey, autoscaling.Memory),
+			withAnnotationValue(autoscaling.TargetAnnotationKey, "75"),
+			withMetric(autoscalingv2beta1.MetricSpec{
+				Type: autoscalingv2beta1.ResourceMetricSourceType,
+				Resource: &autoscalingv2beta1.ResourceMetricSource{
+					Name:                     corev1.ResourceMemory,
+					TargetAverageUtilization: ptr.Int32(75),
+				},
+			})),
+	}, {
+		name: "with a custom metrics API metric",
+		pa:   pa(WithTargetAnnotation("2.5"), WithMetricAnnotation("custom/http_requests")),
+		want: hpa(
+			withAnnotationValue(autoscaling.MetricAnnotationKey, "custom/http_requests"),
+			withAnnotationValue(autoscaling.TargetAnnotationKey, "2.5"),
+			withMetric(autoscalingv2beta1.MetricSpec{
+				Type: autoscalingv2beta1.PodsMetricSourceType,
+				Pods: &autoscalingv2beta1.PodsMetricSource{
+					MetricName:         "http_requests",
+					TargetAverageValue: resource.MustParse("2500m"),
+				},
+			})),
+	}, {
+		name: "with an external metrics API metric",
+		pa:   pa(WithTargetAnnotation("30"), WithMetricAnnotation("external/queue_messages_ready")),
+		want: hpa(
+			withAnnotationValue(autoscaling.MetricAnnotationKey, "external/queue_messages_ready"),
+			withAnnotationValue(autoscaling.TargetAnnotationKey, "30"),
+			withMetric(autoscalingv2beta1.MetricSpec{
+				Type: autoscalingv2beta1.ExternalMetricSourceType,
+				External: &autoscalingv2beta1.ExternalMetricSource{
+					MetricName:         "queue_messages_ready",
+					TargetAverageValue: resource.NewQuantity(30, resource.DecimalSI),
+				},
+			})),
 	}, {
 		name: "with metric=concurrency",
 		pa:   pa(WithMetricAnnotation(autoscaling.Concurrency)),
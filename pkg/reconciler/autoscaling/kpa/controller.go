@@ -31,6 +31,7 @@ import (
 	"knative.dev/pkg/controller"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/autoscaler/bucket"
 	"knative.dev/serving/pkg/reconciler"
 	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
 	"knative.dev/serving/pkg/reconciler/autoscaling/config"
@@ -62,6 +63,7 @@ func NewController(
 			ServiceLister:     serviceInformer.Lister(),
 			MetricLister:      metricInformer.Lister(),
 			PSInformerFactory: psInformerFactory,
+			Ownership:         bucket.OwnershipFromContext(ctx),
 		},
 		endpointsLister: endpointsInformer.Lister(),
 		deciders:        deciders,
@@ -101,6 +103,12 @@ func NewController(
 	// Have the Deciders enqueue the PAs whose decisions have changed.
 	deciders.Watch(impl.EnqueueKey)
 
+	// Reconcile all PAs when this replica starts or stops leading a bucket,
+	// to pick up or let go of the PAs of the bucket.
+	c.Ownership.Watch(func() {
+		controller.SendGlobalUpdates(paInformer.Informer(), paHandler)
+	})
+
 	c.Logger.Info("Setting up ConfigMap receivers")
 	configsToResync := []interface{}{
 		&autoscaler.Config{},
@@ -69,6 +69,12 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 
 	logger.Debug("Reconcile kpa-class PodAutoscaler")
 
+	if !c.Owns(namespace, name) {
+		logger.Debug("PA is reconciled by another autoscaler replica")
+		// The replica leading the bucket of the PA runs its decider.
+		return c.deciders.Delete(ctx, namespace, name)
+	}
+
 	original, err := c.PALister.PodAutoscalers(namespace).Get(name)
 	if errors.IsNotFound(err) {
 		logger.Debug("PA no longer exists")
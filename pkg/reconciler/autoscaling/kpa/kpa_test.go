@@ -58,6 +58,7 @@ import (
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/autoscaler/bucket"
 	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
 	"knative.dev/serving/pkg/reconciler/autoscaling/config"
 	"knative.dev/serving/pkg/reconciler/autoscaling/kpa/resources"
@@ -984,8 +985,8 @@ func TestGlobalResyncOnUpdateAutoscalerConfigMap(t *testing.T) {
 	// Wait for decider to be created.
 	if decider, err := pollDeciders(fakeDeciders, testNamespace, testRevision, nil); err != nil {
 		t.Fatalf("Failed to get decider: %v", err)
-	} else if got, want := decider.Spec.TargetConcurrency, defaultConcurrencyTarget*defaultTU; got != want {
-		t.Fatalf("TargetConcurrency = %v, want %v", got, want)
+	} else if got, want := decider.Spec.TargetValue, defaultConcurrencyTarget*defaultTU; got != want {
+		t.Fatalf("TargetValue = %v, want %v", got, want)
 	}
 
 	concurrencyTargetAfterUpdate := 100.0
@@ -1001,12 +1002,12 @@ func TestGlobalResyncOnUpdateAutoscalerConfigMap(t *testing.T) {
 
 	// Wait for decider to be updated with the new values from the configMap.
 	cond := func(d *autoscaler.Decider) bool {
-		return d.Spec.TargetConcurrency == concurrencyTargetAfterUpdate
+		return d.Spec.TargetValue == concurrencyTargetAfterUpdate
 	}
 	if decider, err := pollDeciders(fakeDeciders, testNamespace, testRevision, cond); err != nil {
 		t.Fatalf("Failed to get decider: %v", err)
-	} else if got, want := decider.Spec.TargetConcurrency, concurrencyTargetAfterUpdate*defaultTU; got != want {
-		t.Fatalf("TargetConcurrency = %v, want %v", got, want)
+	} else if got, want := decider.Spec.TargetValue, concurrencyTargetAfterUpdate*defaultTU; got != want {
+		t.Fatalf("TargetValue = %v, want %v", got, want)
 	}
 }
 
@@ -1313,6 +1314,48 @@ func TestScaleFailure(t *testing.T) {
 	}
 }
 
+func TestReconcileNotOwned(t *testing.T) {
+	defer logtesting.ClearAll()
+	ctx, _ := SetupFakeContext(t)
+	ownership := &testOwnership{}
+	ctx = bucket.WithOwnership(ctx, ownership)
+
+	fakeDeciders := newTestDeciders()
+	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders, presources.NewPodScalableInformerFactory(ctx))
+	if got, want := ownership.watchers, 1; got != want {
+		t.Errorf("Watch() calls = %d, want %d", got, want)
+	}
+
+	rev := newTestRevision(testNamespace, testRevision)
+	kpa := revisionresources.MakePA(rev)
+	fakepainformer.Get(ctx).Informer().GetIndexer().Add(kpa)
+	fakeDeciders.Create(ctx, resources.MakeDecider(ctx, kpa, defaultConfig().Autoscaler, "svc"))
+
+	if err := ctl.Reconciler.Reconcile(context.Background(), testNamespace+"/"+testRevision); err != nil {
+		t.Errorf("Reconcile() = %v", err)
+	}
+	if _, err := fakeDeciders.Get(ctx, testNamespace, testRevision); !apierrors.IsNotFound(err) {
+		t.Errorf("Decider of a PA owned by another replica was not deleted, Get() = %v", err)
+	}
+	skss, err := fakeservingclient.Get(ctx).NetworkingV1alpha1().ServerlessServices(testNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	if len(skss.Items) != 0 {
+		t.Errorf("SKSs = %v, want none for a PA owned by another replica", skss.Items)
+	}
+}
+
+// testOwnership owns no bucket.
+type testOwnership struct {
+	watchers int
+}
+
+func (o *testOwnership) Bucket(types.NamespacedName) string { return "bucket" }
+func (o *testOwnership) IsLeader(string) bool               { return false }
+func (o *testOwnership) Holder(string) (string, bool)       { return "", false }
+func (o *testOwnership) Watch(func())                       { o.watchers++ }
+
 func pollDeciders(deciders *testDeciders, namespace, name string, cond func(*autoscaler.Decider) bool) (decider *autoscaler.Decider, err error) {
 	wait.PollImmediate(10*time.Millisecond, 3*time.Second, func() (bool, error) {
 		decider, err = deciders.Get(context.Background(), namespace, name)
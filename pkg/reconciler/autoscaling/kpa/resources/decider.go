@@ -43,17 +43,22 @@ type Deciders interface {
 }
 
 // MakeDecider constructs a Decider resource from a PodAutoscaler taking
-// into account the PA's ContainerConcurrency and the relevant
-// autoscaling annotation.
+// into account the PA's ContainerConcurrency, its scaling metric and the
+// relevant autoscaling annotation.
 func MakeDecider(ctx context.Context, pa *v1alpha1.PodAutoscaler, config *autoscaler.Config, svc string) *autoscaler.Decider {
 	panicThresholdPercentage := config.PanicThresholdPercentage
 	if x, ok := pa.PanicThresholdPercentage(); ok {
 		panicThresholdPercentage = x
 	}
 
-	target, total := resources.ResolveConcurrency(pa, config)
+	target, total := resources.ResolveMetricTarget(pa, config)
 	panicThreshold := target * panicThresholdPercentage / 100.0
 
+	maxScaleDownRate := config.MaxScaleDownRate
+	if x, ok := pa.MaxScaleDownRate(); ok {
+		maxScaleDownRate = x
+	}
+
 	tbc := config.TargetBurstCapacity
 	if x, ok := pa.TargetBC(); ok {
 		tbc = x
@@ -63,8 +68,11 @@ func MakeDecider(ctx context.Context, pa *v1alpha1.PodAutoscaler, config *autosc
 		Spec: autoscaler.DeciderSpec{
 			TickInterval:        config.TickInterval,
 			MaxScaleUpRate:      config.MaxScaleUpRate,
-			TargetConcurrency:   target,
-			TotalConcurrency:    total,
+			MaxScaleDownRate:    maxScaleDownRate,
+			Algorithm:           pa.Algorithm(),
+			ScalingMetric:       pa.Metric(),
+			TargetValue:         target,
+			TotalValue:          total,
 			TargetBurstCapacity: tbc,
 			PanicThreshold:      panicThreshold,
 			StableWindow:        resources.StableWindow(pa, config),
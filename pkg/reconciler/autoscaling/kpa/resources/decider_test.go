@@ -98,6 +98,30 @@ func TestMakeDecider(t *testing.T) {
 		want: decider(
 			withTarget(10.0), withPanicThreshold(40.0), withTotal(10),
 			withTargetAnnotation("10"), withPanicThresholdPercentageAnnotation("400")),
+	}, {
+		name: "with rps metric",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS)),
+		want: decider(withMetric(autoscaling.RPS), withMetricAnnotation(autoscaling.RPS),
+			withTarget(150), withTotal(200), withPanicThreshold(300)),
+		cfgOpt: func(c autoscaler.Config) *autoscaler.Config {
+			c.RPSTargetFraction = 0.75
+			return &c
+		},
+	}, {
+		name: "with rps metric and target annotation",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS), WithTargetAnnotation("10")),
+		want: decider(withMetric(autoscaling.RPS), withMetricAnnotation(autoscaling.RPS),
+			withTarget(10), withTotal(10), withPanicThreshold(20), withTargetAnnotation("10")),
+	}, {
+		name: "with max scale down rate annotation",
+		pa:   pa(withMaxScaleDownRateAnnotation("4")),
+		want: decider(withTarget(100.0), withPanicThreshold(200.0), withTotal(100),
+			withDeciderMaxScaleDownRateAnnotation("4"), withMaxScaleDownRate(4)),
+	}, {
+		name: "with algorithm annotation",
+		pa:   pa(withAlgorithmAnnotation(autoscaling.PredictiveAlgorithm)),
+		want: decider(withTarget(100.0), withPanicThreshold(200.0), withTotal(100),
+			withDeciderAlgorithmAnnotation(autoscaling.PredictiveAlgorithm), withAlgorithm(autoscaling.PredictiveAlgorithm)),
 	}, {
 		name: "with service name",
 		pa:   pa(WithTargetAnnotation("10"), WithPanicThresholdPercentageAnnotation("400")),
@@ -154,6 +178,30 @@ func withDeciderTBCAnnotation(tbc string) DeciderOption {
 	}
 }
 
+func withMaxScaleDownRateAnnotation(rate string) PodAutoscalerOption {
+	return func(pa *v1alpha1.PodAutoscaler) {
+		pa.Annotations[autoscaling.MaxScaleDownRateAnnotationKey] = rate
+	}
+}
+
+func withDeciderMaxScaleDownRateAnnotation(rate string) DeciderOption {
+	return func(d *autoscaler.Decider) {
+		d.Annotations[autoscaling.MaxScaleDownRateAnnotationKey] = rate
+	}
+}
+
+func withAlgorithmAnnotation(algorithm string) PodAutoscalerOption {
+	return func(pa *v1alpha1.PodAutoscaler) {
+		pa.Annotations[autoscaling.AlgorithmAnnotationKey] = algorithm
+	}
+}
+
+func withDeciderAlgorithmAnnotation(algorithm string) DeciderOption {
+	return func(d *autoscaler.Decider) {
+		d.Annotations[autoscaling.AlgorithmAnnotationKey] = algorithm
+	}
+}
+
 func decider(options ...DeciderOption) *autoscaler.Decider {
 	m := &autoscaler.Decider{
 		ObjectMeta: metav1.ObjectMeta{
@@ -165,9 +213,12 @@ func decider(options ...DeciderOption) *autoscaler.Decider {
 		},
 		Spec: autoscaler.DeciderSpec{
 			MaxScaleUpRate:      config.MaxScaleUpRate,
+			MaxScaleDownRate:    config.MaxScaleDownRate,
+			Algorithm:           autoscaling.StablePanicAlgorithm,
 			TickInterval:        config.TickInterval,
-			TargetConcurrency:   100,
-			TotalConcurrency:    100,
+			ScalingMetric:       autoscaling.Concurrency,
+			TargetValue:         100,
+			TotalValue:          100,
 			TargetBurstCapacity: 211,
 			PanicThreshold:      200,
 			StableWindow:        config.StableWindow,
@@ -187,15 +238,39 @@ func withTargetBurstCapacity(tbc float64) DeciderOption {
 	}
 }
 
+func withMaxScaleDownRate(rate float64) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Spec.MaxScaleDownRate = rate
+	}
+}
+
+func withAlgorithm(algorithm string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Spec.Algorithm = algorithm
+	}
+}
+
 func withTotal(total float64) DeciderOption {
 	return func(decider *autoscaler.Decider) {
-		decider.Spec.TotalConcurrency = total
+		decider.Spec.TotalValue = total
 	}
 }
 
 func withTarget(target float64) DeciderOption {
 	return func(decider *autoscaler.Decider) {
-		decider.Spec.TargetConcurrency = target
+		decider.Spec.TargetValue = target
+	}
+}
+
+func withMetric(metric string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Spec.ScalingMetric = metric
+	}
+}
+
+func withMetricAnnotation(metric string) DeciderOption {
+	return func(decider *autoscaler.Decider) {
+		decider.Annotations[autoscaling.MetricAnnotationKey] = metric
 	}
 }
 
@@ -226,8 +301,11 @@ var config = &autoscaler.Config{
 	EnableScaleToZero:                  true,
 	ContainerConcurrencyTargetFraction: 1.0,
 	ContainerConcurrencyTargetDefault:  100.0,
+	RPSTargetFraction:                  1.0,
+	RPSTargetDefault:                   200.0,
 	TargetBurstCapacity:                211.0,
 	MaxScaleUpRate:                     10.0,
+	MaxScaleDownRate:                   2.0,
 	StableWindow:                       60 * time.Second,
 	PanicThresholdPercentage:           200,
 	PanicWindow:                        6 * time.Second,
@@ -27,6 +27,7 @@ import (
 	"knative.dev/pkg/apis/duck"
 	"knative.dev/pkg/injection/clients/dynamicclient"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
 
 	"knative.dev/serving/pkg/activator"
 	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
@@ -54,6 +55,11 @@ const (
 	// re-enque for the configured grace period.
 	reenqeuePeriod = 1 * time.Second
 
+	// scheduleHorizon bounds how far ahead the next start of a scheduled
+	// scale window is looked for. The PA is re-enqueued at the horizon at
+	// the latest to look again.
+	scheduleHorizon = 24 * time.Hour
+
 	// TODO(#3456): Remove this buffer once KPA does pod failure diagnostics.
 	//
 	// KPA will scale the Deployment down to zero if it fails to activate after ProgressDeadlineSeconds,
@@ -88,6 +94,9 @@ type scaler struct {
 	// For async probes.
 	probeManager asyncProber
 	enqueueCB    func(interface{}, time.Duration)
+
+	// For evaluating scheduled scale windows.
+	clock system.Clock
 }
 
 // newScaler creates a scaler.
@@ -110,6 +119,7 @@ func newScaler(ctx context.Context, psInformerFactory duck.InformerFactory, enqu
 			enqueueCB(arg, reenqeuePeriod)
 		}, transport),
 		enqueueCB: enqueueCB,
+		clock:     system.RealClock{},
 	}
 	return ks
 }
@@ -142,6 +152,54 @@ func applyBounds(min, max, x int32) int32 {
 	return x
 }
 
+// applySchedule overrides the given scale bounds with the first scheduled scale
+// window of the PA which is active at the given time. It records the active
+// window in the PA status and re-enqueues the PA for the next time a window
+// starts or ends.
+func (ks *scaler) applySchedule(pa *pav1alpha1.PodAutoscaler, min, max int32, now time.Time) (int32, int32) {
+	windows := pa.ScaleSchedule()
+	if len(windows) == 0 {
+		pa.Status.ScaleWindow = nil
+		return min, max
+	}
+
+	var active *pav1alpha1.ScaleWindowStatus
+	next := now.Add(scheduleHorizon)
+	for i := range windows {
+		w := &windows[i]
+		if end, ok := w.ActiveUntil(now); ok {
+			if active == nil {
+				active = &pav1alpha1.ScaleWindowStatus{
+					Schedule: w.Schedule,
+					MinScale: w.MinScale,
+					MaxScale: w.MaxScale,
+					EndTime:  metav1.NewTime(end),
+				}
+				if w.MinScale != 0 {
+					min = w.MinScale
+				}
+				if w.MaxScale != 0 {
+					max = w.MaxScale
+				}
+			}
+			if end.Before(next) {
+				next = end
+			}
+		}
+		if start, ok := w.NextStart(now, next.Sub(now)); ok {
+			next = start
+		}
+	}
+	pa.Status.ScaleWindow = active
+	ks.enqueueCB(pa, next.Sub(now))
+
+	// The static maxScale still applies to windows which only set minScale.
+	if max != 0 && min > max {
+		min = max
+	}
+	return min, max
+}
+
 func (ks *scaler) handleScaleToZero(pa *pav1alpha1.PodAutoscaler, sks *nv1a1.ServerlessService, desiredScale int32, config *autoscaler.Config) (int32, bool) {
 	if desiredScale != 0 {
 		return desiredScale, true
@@ -259,12 +317,14 @@ func (ks *scaler) applyScale(ctx context.Context, pa *pav1alpha1.PodAutoscaler,
 func (ks *scaler) Scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, sks *nv1a1.ServerlessService, desiredScale int32) (int32, error) {
 	logger := logging.FromContext(ctx)
 
+	min, max := pa.ScaleBounds()
+	min, max = ks.applySchedule(pa, min, max, ks.clock.Now())
+
 	if desiredScale < 0 && !pa.Status.IsActivating() {
 		logger.Debug("Metrics are not yet being collected.")
 		return desiredScale, nil
 	}
 
-	min, max := pa.ScaleBounds()
 	if newScale := applyBounds(min, max, desiredScale); newScale != desiredScale {
 		logger.Debugf("Adjusting desiredScale to meet the min and max bounds before applying: %d -> %d", desiredScale, newScale)
 		desiredScale = newScale
@@ -26,6 +26,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+
 	// These are the fake informers we want setup.
 	fakedynamicclient "knative.dev/pkg/injection/clients/dynamicclient/fake"
 	fakeservingclient "knative.dev/serving/pkg/client/injection/client/fake"
@@ -34,6 +36,7 @@ import (
 	"knative.dev/pkg/apis/duck"
 	"knative.dev/pkg/logging"
 	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/system"
 	_ "knative.dev/pkg/system/testing"
 	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/apis/autoscaling"
@@ -66,6 +69,23 @@ const (
 	testRevision  = "test-revision"
 )
 
+// scheduleNow is a Monday at 09:00 UTC.
+var scheduleNow = time.Date(2019, 9, 2, 9, 0, 0, 0, time.UTC)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
+
+func withScaleSchedule(schedule string) func(*pav1alpha1.PodAutoscaler) {
+	return func(pa *pav1alpha1.PodAutoscaler) {
+		pa.Annotations[autoscaling.ScaleScheduleAnnotationKey] = schedule
+	}
+}
+
 func TestScaler(t *testing.T) {
 	defer logtesting.ClearAll()
 	tests := []struct {
@@ -292,6 +312,42 @@ func TestScaler(t *testing.T) {
 		scaleTo:       10,
 		wantReplicas:  10,
 		wantScaling:   true,
+	}, {
+		label:         "scales up to scheduled minScale",
+		startReplicas: 1,
+		scaleTo:       1,
+		wantReplicas:  5,
+		wantScaling:   true,
+		paMutation:    withScaleSchedule(`[{"schedule": "0 8 * * 1-5", "duration": "10h", "minScale": 5}]`),
+		wantCBCount:   1,
+	}, {
+		label:         "scheduled minScale prevents scale to zero",
+		startReplicas: 1,
+		scaleTo:       0,
+		wantReplicas:  5,
+		wantScaling:   true,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkActive(k, time.Now().Add(-stableWindow))
+			withScaleSchedule(`[{"schedule": "0 8 * * 1-5", "duration": "10h", "minScale": 5}]`)(k)
+		},
+		wantCBCount: 1,
+	}, {
+		label:         "scales up to scheduled maxScale",
+		startReplicas: 1,
+		scaleTo:       10,
+		maxScale:      8,
+		wantReplicas:  3,
+		wantScaling:   true,
+		paMutation:    withScaleSchedule(`[{"schedule": "0 9 * * *", "duration": "1h", "maxScale": 3}]`),
+		wantCBCount:   1,
+	}, {
+		label:         "inactive scheduled window keeps bounds",
+		startReplicas: 1,
+		scaleTo:       2,
+		wantReplicas:  2,
+		wantScaling:   true,
+		paMutation:    withScaleSchedule(`[{"schedule": "0 20 * * *", "duration": "1h", "minScale": 5}]`),
+		wantCBCount:   1,
 	}, {
 		label:         "negative scale does not scale",
 		startReplicas: 12,
@@ -322,6 +378,7 @@ func TestScaler(t *testing.T) {
 			}
 			cp := &countingProber{}
 			revisionScaler.probeManager = cp
+			revisionScaler.clock = fakeClock{scheduleNow}
 
 			// We test like this because the dynamic client's fake doesn't properly handle
 			// patch modes prior to 1.13 (where vaikas added JSON Patch support).
@@ -370,6 +427,93 @@ func TestScaler(t *testing.T) {
 	}
 }
 
+func TestScalerApplySchedule(t *testing.T) {
+	tests := []struct {
+		label           string
+		schedule        string
+		min, max        int32
+		wantMin         int32
+		wantMax         int32
+		wantScaleWindow *pav1alpha1.ScaleWindowStatus
+		wantEnqueue     time.Duration
+	}{{
+		label:       "no schedule",
+		min:         1,
+		max:         10,
+		wantMin:     1,
+		wantMax:     10,
+		wantEnqueue: -1,
+	}, {
+		label:    "active window",
+		schedule: `[{"schedule": "0 8 * * 1-5", "duration": "10h", "minScale": 5}]`,
+		min:      1,
+		max:      10,
+		wantMin:  5,
+		wantMax:  10,
+		wantScaleWindow: &pav1alpha1.ScaleWindowStatus{
+			Schedule: "0 8 * * 1-5",
+			MinScale: 5,
+			EndTime:  metav1.NewTime(scheduleNow.Add(9 * time.Hour)),
+		},
+		wantEnqueue: 9 * time.Hour,
+	}, {
+		label:       "window starting later",
+		schedule:    `[{"schedule": "30 9 * * *", "duration": "1h", "minScale": 5}]`,
+		wantEnqueue: 30 * time.Minute,
+	}, {
+		label:       "window starting after the horizon",
+		schedule:    `[{"schedule": "0 8 1 1 *", "duration": "1h", "minScale": 5}]`,
+		wantEnqueue: scheduleHorizon,
+	}, {
+		label: "first active window applies, minScale capped by maxScale",
+		schedule: `[{"schedule": "0 9 * * *", "duration": "2h", "minScale": 20},` +
+			`{"schedule": "0 8 * * *", "duration": "2h", "maxScale": 3}]`,
+		max:     10,
+		wantMin: 10,
+		wantMax: 10,
+		wantScaleWindow: &pav1alpha1.ScaleWindowStatus{
+			Schedule: "0 9 * * *",
+			MinScale: 20,
+			EndTime:  metav1.NewTime(scheduleNow.Add(2 * time.Hour)),
+		},
+		wantEnqueue: time.Hour,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.label, func(t *testing.T) {
+			var gotEnqueue time.Duration = -1
+			ks := &scaler{
+				clock: fakeClock{scheduleNow},
+				enqueueCB: func(_ interface{}, d time.Duration) {
+					gotEnqueue = d
+				},
+			}
+			pa := &pav1alpha1.PodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+				},
+				Status: pav1alpha1.PodAutoscalerStatus{
+					ScaleWindow: &pav1alpha1.ScaleWindowStatus{Schedule: "stale"},
+				},
+			}
+			if test.schedule != "" {
+				withScaleSchedule(test.schedule)(pa)
+			}
+
+			gotMin, gotMax := ks.applySchedule(pa, test.min, test.max, scheduleNow)
+			if gotMin != test.wantMin || gotMax != test.wantMax {
+				t.Errorf("applySchedule() = (%d, %d), want: (%d, %d)", gotMin, gotMax, test.wantMin, test.wantMax)
+			}
+			if !cmp.Equal(pa.Status.ScaleWindow, test.wantScaleWindow) {
+				t.Errorf("ScaleWindow (-want, +got): %s", cmp.Diff(test.wantScaleWindow, pa.Status.ScaleWindow))
+			}
+			if gotEnqueue != test.wantEnqueue {
+				t.Errorf("Enqueue after %v, want: %v", gotEnqueue, test.wantEnqueue)
+			}
+		})
+	}
+}
+
 func TestDisableScaleToZero(t *testing.T) {
 	defer logtesting.ClearAll()
 	tests := []struct {
@@ -426,6 +570,7 @@ func TestDisableScaleToZero(t *testing.T) {
 				dynamicClient:     fakedynamicclient.Get(ctx),
 				logger:            logging.FromContext(ctx),
 				psInformerFactory: presources.NewPodScalableInformerFactory(ctx),
+				clock:             system.RealClock{},
 			}
 			pa := newKPA(t, fakeservingclient.Get(ctx), revision)
 			paMarkActive(pa, time.Now())
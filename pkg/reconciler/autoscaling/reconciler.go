@@ -29,6 +29,7 @@ import (
 	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/networking"
 	nv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler/bucket"
 	listers "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
 	nlisters "knative.dev/serving/pkg/client/listers/networking/v1alpha1"
 	"knative.dev/serving/pkg/reconciler"
@@ -42,6 +43,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 )
 
@@ -54,6 +56,14 @@ type Base struct {
 	MetricLister      listers.MetricLister
 	ConfigStore       reconciler.ConfigStore
 	PSInformerFactory duck.InformerFactory
+	// Ownership tells which PAs this autoscaler replica reconciles.
+	// If nil, it reconciles all of them.
+	Ownership bucket.Ownership
+}
+
+// Owns returns whether this autoscaler replica reconciles the PA.
+func (c *Base) Owns(namespace, name string) bool {
+	return c.Ownership == nil || bucket.Owns(c.Ownership, types.NamespacedName{Namespace: namespace, Name: name})
 }
 
 // ReconcileSKS reconciles a ServerlessService based on the given PodAutoscaler.
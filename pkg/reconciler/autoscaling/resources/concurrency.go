@@ -19,6 +19,7 @@ package resources
 import (
 	"math"
 
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/autoscaler"
 )
@@ -52,3 +53,44 @@ func ResolveConcurrency(pa *v1alpha1.PodAutoscaler, config *autoscaler.Config) (
 
 	return target, total
 }
+
+// ResolveMetricTarget takes the knobs of the PA's scaling metric from multiple
+// locations and resolves them to the final value to be used by the autoscaler.
+// `target` is the value of the metric per pod the autoscaler will aim for;
+// `total` is the maximum value of the metric that is permitted on the pod.
+func ResolveMetricTarget(pa *v1alpha1.PodAutoscaler, config *autoscaler.Config) (target float64, total float64) {
+	if pa.Metric() == autoscaling.RPS {
+		return resolveRPS(pa, config)
+	}
+	if _, ok := autoscaling.CustomMetricName(pa.Metric()); ok {
+		return resolveCustomMetric(pa)
+	}
+	return ResolveConcurrency(pa, config)
+}
+
+// resolveRPS resolves the target and total requests per second per pod.
+// Unlike concurrency, RPS is not bounded by the container, so the annotation
+// target is taken as is.
+func resolveRPS(pa *v1alpha1.PodAutoscaler, config *autoscaler.Config) (target float64, total float64) {
+	total = config.RPSTargetDefault
+	if annotationTarget, ok := pa.Target(); ok {
+		total = annotationTarget
+	}
+	tu := config.RPSTargetFraction
+	if v, ok := pa.TargetUtilization(); ok {
+		tu = v
+	}
+	return math.Max(1, total*tu), total
+}
+
+// resolveCustomMetric resolves the target and total value of the custom metric
+// per pod. There is no default for an application defined metric, so the
+// target annotation is required and only scaled by an explicit target utilization.
+func resolveCustomMetric(pa *v1alpha1.PodAutoscaler) (target float64, total float64) {
+	total, _ = pa.Target()
+	tu := 1.0
+	if v, ok := pa.TargetUtilization(); ok {
+		tu = v
+	}
+	return math.Max(1, total*tu), total
+}
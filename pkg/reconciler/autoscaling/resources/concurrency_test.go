@@ -130,6 +130,16 @@ func TestResolveConcurrency(t *testing.T) {
 		pa:      pa(WithPAContainerConcurrency(1), WithTargetAnnotation("10")),
 		wantTgt: 1,
 		wantTot: 1,
+	}, {
+		name:    "custom metric with target annotation",
+		pa:      pa(WithMetricAnnotation("custom/queue_depth"), WithTargetAnnotation("10")),
+		wantTgt: 10,
+		wantTot: 10,
+	}, {
+		name:    "custom metric with target and TU annotations",
+		pa:      pa(WithMetricAnnotation("custom/queue_depth"), WithTargetAnnotation("10"), withTU("50")),
+		wantTgt: 5,
+		wantTot: 10,
 	}}
 
 	for _, tc := range cases {
@@ -150,3 +160,80 @@ func withTU(tu string) PodAutoscalerOption {
 		pa.Annotations[autoscaling.TargetUtilizationPercentageKey] = tu
 	}
 }
+
+func TestResolveMetricTarget(t *testing.T) {
+	cases := []struct {
+		name    string
+		pa      *v1alpha1.PodAutoscaler
+		cfgOpt  func(autoscaler.Config) *autoscaler.Config
+		wantTgt float64
+		wantTot float64
+	}{{
+		name:    "concurrency defaults",
+		pa:      pa(),
+		wantTgt: 100,
+		wantTot: 100,
+	}, {
+		name: "rps defaults",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS)),
+		cfgOpt: func(c autoscaler.Config) *autoscaler.Config {
+			c.RPSTargetDefault = 200
+			c.RPSTargetFraction = 0.7
+			return &c
+		},
+		wantTgt: 140,
+		wantTot: 200,
+	}, {
+		name: "rps with target annotation",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS), WithTargetAnnotation("50")),
+		cfgOpt: func(c autoscaler.Config) *autoscaler.Config {
+			c.RPSTargetDefault = 200
+			c.RPSTargetFraction = 0.7
+			return &c
+		},
+		wantTgt: 35,
+		wantTot: 50,
+	}, {
+		name: "rps with target and TU annotations",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS), WithTargetAnnotation("50"), withTU("50")),
+		cfgOpt: func(c autoscaler.Config) *autoscaler.Config {
+			c.RPSTargetDefault = 200
+			c.RPSTargetFraction = 0.7
+			return &c
+		},
+		wantTgt: 25,
+		wantTot: 50,
+	}, {
+		name: "rps target not less than 1",
+		pa:   pa(WithMetricAnnotation(autoscaling.RPS), WithTargetAnnotation("1")),
+		cfgOpt: func(c autoscaler.Config) *autoscaler.Config {
+			c.RPSTargetFraction = 0.7
+			return &c
+		},
+		wantTgt: 1,
+		wantTot: 1,
+	}, {
+		name:    "custom metric with target annotation",
+		pa:      pa(WithMetricAnnotation("custom/queue_depth"), WithTargetAnnotation("10")),
+		wantTgt: 10,
+		wantTot: 10,
+	}, {
+		name:    "custom metric with target and TU annotations",
+		pa:      pa(WithMetricAnnotation("custom/queue_depth"), WithTargetAnnotation("10"), withTU("50")),
+		wantTgt: 5,
+		wantTot: 10,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config
+			if tc.cfgOpt != nil {
+				cfg = tc.cfgOpt(*cfg)
+			}
+			gotTgt, gotTot := ResolveMetricTarget(tc.pa, cfg)
+			if gotTgt != tc.wantTgt || gotTot != tc.wantTot {
+				t.Errorf("ResolveMetricTarget(%v, %v) = (%v, %v), want (%v, %v)", tc.pa, cfg, gotTgt, gotTot, tc.wantTgt, tc.wantTot)
+			}
+		})
+	}
+}
@@ -22,12 +22,18 @@ import (
 	"knative.dev/serving/pkg/apis/autoscaling"
 	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	nv1a1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/reconciler/autoscaling/resources/names"
 	"knative.dev/serving/pkg/resources"
 )
 
-// MakeSKS makes an SKS resource from the PA and operation mode.
+// MakeSKS makes an SKS resource from the PA and operation mode. The
+// queue-proxies of revisions serving TLS don't accept the cleartext
+// requests of the ingress, so those are always proxied by the activator.
 func MakeSKS(pa *pav1alpha1.PodAutoscaler, mode nv1a1.ServerlessServiceOperationMode) *nv1a1.ServerlessService {
+	if pa.Annotations[serving.QueueProxyTLSSecretAnnotationKey] != "" {
+		mode = nv1a1.SKSOperationModeProxy
+	}
 	return &nv1a1.ServerlessService{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      names.SKS(pa.Name),
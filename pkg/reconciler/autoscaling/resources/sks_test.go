@@ -92,3 +92,19 @@ func TestMakeSKS(t *testing.T) {
 		t.Errorf("MakeSKS = %#v, want: %#v, diff: %s", got, want, cmp.Diff(got, want))
 	}
 }
+
+func TestMakeSKSQueueProxyTLS(t *testing.T) {
+	pa := &pav1a1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "here",
+			Name:      "with-you",
+			Annotations: map[string]string{
+				serving.QueueProxyTLSSecretAnnotationKey: "queue-proxy-certs",
+			},
+		},
+	}
+
+	if got, want := MakeSKS(pa, nv1a1.SKSOperationModeServe).Spec.Mode, nv1a1.SKSOperationModeProxy; got != want {
+		t.Errorf("MakeSKS().Spec.Mode = %v, want: %v", got, want)
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	"knative.dev/pkg/configmap"
 	"knative.dev/serving/pkg/gc"
+	"knative.dev/serving/pkg/network"
 )
 
 type cfgKey struct{}
@@ -29,6 +30,7 @@ type cfgKey struct{}
 // +k8s:deepcopy-gen=false
 type Config struct {
 	RevisionGC *gc.Config
+	Network    *network.Config
 }
 
 func FromContext(ctx context.Context) *Config {
@@ -51,6 +53,7 @@ func (s *Store) ToContext(ctx context.Context) context.Context {
 func (s *Store) Load() *Config {
 	return &Config{
 		RevisionGC: s.UntypedLoad(gc.ConfigName).(*gc.Config).DeepCopy(),
+		Network:    s.UntypedLoad(network.ConfigName).(*network.Config).DeepCopy(),
 	}
 }
 
@@ -60,7 +63,8 @@ func NewStore(logger configmap.Logger, minRevisionTimeout time.Duration) *Store
 			"configuration",
 			logger,
 			configmap.Constructors{
-				gc.ConfigName: gc.NewConfigFromConfigMapFunc(logger, minRevisionTimeout),
+				gc.ConfigName:      gc.NewConfigFromConfigMapFunc(logger, minRevisionTimeout),
+				network.ConfigName: network.NewConfigFromConfigMap,
 			},
 		),
 	}
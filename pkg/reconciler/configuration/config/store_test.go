@@ -25,6 +25,7 @@ import (
 
 	logtesting "knative.dev/pkg/logging/testing"
 	"knative.dev/serving/pkg/gc"
+	"knative.dev/serving/pkg/network"
 
 	. "knative.dev/pkg/configmap/testing"
 )
@@ -34,8 +35,10 @@ func TestStoreLoadWithContext(t *testing.T) {
 	store := NewStore(logtesting.TestLogger(t), 10*time.Hour)
 
 	gcConfig := ConfigMapFromTestFile(t, "config-gc")
+	networkConfig := ConfigMapFromTestFile(t, "config-network")
 
 	store.OnConfigChanged(gcConfig)
+	store.OnConfigChanged(networkConfig)
 
 	config := FromContext(store.ToContext(context.Background()))
 
@@ -45,4 +48,11 @@ func TestStoreLoadWithContext(t *testing.T) {
 			t.Errorf("Unexpected controller config (-want, +got): %v", diff)
 		}
 	})
+
+	t.Run("network", func(t *testing.T) {
+		expected, _ := network.NewConfigFromConfigMap(networkConfig)
+		if diff := cmp.Diff(expected, config.Network); diff != "" {
+			t.Errorf("Unexpected controller config (-want, +got): %v", diff)
+		}
+	})
 }
@@ -268,6 +268,13 @@ func (c *Reconciler) createRevision(ctx context.Context, config *v1alpha1.Config
 	logger := logging.FromContext(ctx)
 
 	rev := resources.MakeRevision(config)
+	// The queue-proxies of a revision keep serving the way they started to
+	// for as long as the revision lives.
+	if secret := configns.FromContext(ctx).Network.QueueProxyTLSSecret; secret != "" {
+		if _, ok := rev.Annotations[serving.QueueProxyTLSSecretAnnotationKey]; !ok {
+			rev.Annotations[serving.QueueProxyTLSSecretAnnotationKey] = secret
+		}
+	}
 	created, err := c.ServingClientSet.ServingV1alpha1().Revisions(config.Namespace).Create(rev)
 	if err != nil {
 		return nil, err
@@ -36,9 +36,11 @@ import (
 	"knative.dev/pkg/controller"
 	logtesting "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 	"knative.dev/serving/pkg/gc"
+	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/configuration/config"
 	"knative.dev/serving/pkg/reconciler/configuration/resources"
@@ -411,6 +413,41 @@ func TestReconcile(t *testing.T) {
 	}))
 }
 
+func TestReconcileQueueProxyTLS(t *testing.T) {
+	table := TableTest{{
+		Name: "create revision serving tls",
+		Objects: []runtime.Object{
+			cfg("tls", "foo", 1234),
+		},
+		WantCreates: []runtime.Object{
+			rev("tls", "foo", 1234, func(rev *v1alpha1.Revision) {
+				rev.Annotations[serving.QueueProxyTLSSecretAnnotationKey] = "queue-proxy-certs"
+			}),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: cfg("tls", "foo", 1234, WithLatestCreated("tls-00001"), WithObservedGen),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", "Created Revision %q", "tls-00001"),
+		},
+		Key: "foo/tls",
+	}}
+
+	defer logtesting.ClearAll()
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		cfg := ReconcilerTestConfig()
+		cfg.Network.QueueProxyTLSSecret = "queue-proxy-certs"
+		return &Reconciler{
+			Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
+			configurationLister: listers.GetConfigurationLister(),
+			revisionLister:      listers.GetRevisionLister(),
+			configStore: &testConfigStore{
+				config: cfg,
+			},
+		}
+	}))
+}
+
 func TestGCReconcile(t *testing.T) {
 	now := time.Now()
 	tenMinutesAgo := now.Add(-10 * time.Minute)
@@ -600,6 +637,7 @@ func ReconcilerTestConfig() *config.Config {
 			StaleRevisionCreateDelay: 5 * time.Minute,
 			StaleRevisionTimeout:     5 * time.Minute,
 		},
+		Network: &network.Config{},
 	}
 }
 
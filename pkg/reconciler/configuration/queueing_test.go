@@ -32,6 +32,7 @@ import (
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 	fakeservingclient "knative.dev/serving/pkg/client/injection/client/fake"
 	"knative.dev/serving/pkg/gc"
+	"knative.dev/serving/pkg/network"
 
 	. "knative.dev/pkg/reconciler/testing"
 )
@@ -91,6 +92,12 @@ func TestNewConfigurationCallsSyncHandler(t *testing.T) {
 			Namespace: system.Namespace(),
 		},
 		Data: map[string]string{},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      network.ConfigName,
+			Namespace: system.Namespace(),
+		},
+		Data: map[string]string{},
 	})
 
 	ctrl := NewController(ctx, configMapWatcher)
@@ -18,6 +18,8 @@ package resources
 
 import (
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -150,21 +152,10 @@ func makePortSelector(ios intstr.IntOrString) v1alpha3.PortSelector {
 func makeVirtualServiceRoute(hosts sets.String, http *v1alpha1.HTTPIngressPath, gateways sets.String) *v1alpha3.HTTPRoute {
 	matches := []v1alpha3.HTTPMatchRequest{}
 	for _, host := range hosts.List() {
-		matches = append(matches, makeMatch(host, http.Path, gateways))
+		matches = append(matches, makeMatch(host, http, gateways))
 	}
 	weights := []v1alpha3.HTTPRouteDestination{}
 	for _, split := range http.Splits {
-
-		var h *v1alpha3.Headers
-
-		if len(split.AppendHeaders) > 0 {
-			h = &v1alpha3.Headers{
-				Request: &v1alpha3.HeaderOperations{
-					Add: split.AppendHeaders,
-				},
-			}
-		}
-
 		weights = append(weights, v1alpha3.HTTPRouteDestination{
 			Destination: v1alpha3.Destination{
 				Host: network.GetServiceHostname(
@@ -172,7 +163,7 @@ func makeVirtualServiceRoute(hosts sets.String, http *v1alpha1.HTTPIngressPath,
 				Port: makePortSelector(split.ServicePort),
 			},
 			Weight:  split.Percent,
-			Headers: h,
+			Headers: makeSplitHeaders(split.AppendHeaders),
 		})
 	}
 
@@ -185,6 +176,31 @@ func makeVirtualServiceRoute(hosts sets.String, http *v1alpha1.HTTPIngressPath,
 		}
 	}
 
+	var mirror *v1alpha3.Destination
+	if http.Mirror != nil {
+		mirror = &v1alpha3.Destination{
+			Host: network.GetServiceHostname(
+				http.Mirror.ServiceName, http.Mirror.ServiceNamespace),
+			Port: makePortSelector(http.Mirror.ServicePort),
+		}
+		// Istio mirrors all the requests of a route. The percentage of the
+		// copies to keep is passed on for the queue-proxy to sample them, and
+		// always set so that callers can't pass their own. The queue-proxy
+		// removes it before the requests reach the user containers.
+		percent := http.Mirror.Percent
+		if percent == 0 {
+			percent = 100
+		}
+		if h == nil {
+			h = &v1alpha3.Headers{
+				Request: &v1alpha3.HeaderOperations{},
+			}
+		}
+		h.Request.Set = map[string]string{
+			network.MirrorPercentHeaderName: strconv.Itoa(percent),
+		}
+	}
+
 	return &v1alpha3.HTTPRoute{
 		Match:   matches,
 		Route:   weights,
@@ -194,10 +210,32 @@ func makeVirtualServiceRoute(hosts sets.String, http *v1alpha1.HTTPIngressPath,
 			PerTryTimeout: http.Retries.PerTryTimeout.Duration.String(),
 		},
 		Headers:          h,
+		Mirror:           mirror,
 		WebsocketUpgrade: true,
 	}
 }
 
+// makeSplitHeaders adds the headers of a split to its requests. The priority
+// class of the requests is only ever the one of the Route, so the priority
+// header of the callers is overwritten or removed.
+func makeSplitHeaders(appendHeaders map[string]string) *v1alpha3.Headers {
+	ops := &v1alpha3.HeaderOperations{}
+	for name, value := range appendHeaders {
+		if name == network.RequestPriorityHeaderName {
+			ops.Set = map[string]string{name: value}
+			continue
+		}
+		if ops.Add == nil {
+			ops.Add = make(map[string]string, len(appendHeaders))
+		}
+		ops.Add[name] = value
+	}
+	if ops.Set == nil {
+		ops.Remove = []string{network.RequestPriorityHeaderName}
+	}
+	return &v1alpha3.Headers{Request: ops}
+}
+
 func keepLocalHostnames(hosts sets.String) sets.String {
 	localSvcSuffix := ".svc." + network.GetClusterDomainName()
 	retained := sets.NewString()
@@ -226,23 +264,81 @@ func expandedHosts(hosts sets.String) sets.String {
 	return expanded
 }
 
-func makeMatch(host string, pathRegExp string, gateways sets.String) v1alpha3.HTTPMatchRequest {
+func makeMatch(host string, http *v1alpha1.HTTPIngressPath, gateways sets.String) v1alpha3.HTTPMatchRequest {
 	match := v1alpha3.HTTPMatchRequest{
 		Gateways: gateways.List(),
 		Authority: &istiov1alpha1.StringMatch{
 			Regex: hostRegExp(host),
 		},
+		Headers: makeHeaderMatches(http),
 	}
 	// Empty pathRegExp is considered match all path. We only need to
 	// consider pathRegExp when it's non-empty.
-	if pathRegExp != "" {
+	if http.Path != "" {
 		match.URI = &istiov1alpha1.StringMatch{
-			Regex: pathRegExp,
+			Regex: http.Path,
 		}
 	}
 	return match
 }
 
+// makeHeaderMatches matches the headers, cookies and query parameters of the
+// path. Cookies and query parameters are matched by regular expressions over
+// the Cookie header and the :path pseudo header respectively.
+func makeHeaderMatches(http *v1alpha1.HTTPIngressPath) map[string]istiov1alpha1.StringMatch {
+	if len(http.Headers) == 0 && len(http.Cookies) == 0 && len(http.QueryParameters) == 0 {
+		return nil
+	}
+	matches := make(map[string]istiov1alpha1.StringMatch, len(http.Headers)+2)
+	for name, value := range http.Headers {
+		// Istio wants the header names in lower case.
+		matches[strings.ToLower(name)] = istiov1alpha1.StringMatch{Exact: value}
+	}
+	if len(http.Cookies) > 0 {
+		matches["cookie"] = istiov1alpha1.StringMatch{
+			Regex: "^" + pairsRegExp(http.Cookies, `;\s*`) + "$",
+		}
+	}
+	if len(http.QueryParameters) > 0 {
+		matches[":path"] = istiov1alpha1.StringMatch{
+			Regex: `^[^?]*\?` + pairsRegExp(http.QueryParameters, "&") + "$",
+		}
+	}
+	return matches
+}
+
+// pairsRegExp returns an RE2 regular expression to match a list of
+// name=value pairs, separated by sep, that holds all of the given pairs.
+// RE2 has no lookaheads, so the expression is the alternation of the pairs
+// in every order they can be listed in, which is why the number of cookies
+// and query parameters to match is capped by validation.
+func pairsRegExp(pairs map[string]string, sep string) string {
+	quoted := make([]string, 0, len(pairs))
+	for name, value := range pairs {
+		quoted = append(quoted, regexp.QuoteMeta(name+"="+value))
+	}
+	sort.Strings(quoted)
+
+	var orders []string
+	permute(quoted, 0, func(order []string) {
+		orders = append(orders, "(.*"+sep+")?"+strings.Join(order, "("+sep+".*)?"+sep)+"("+sep+".*)?")
+	})
+	return "(" + strings.Join(orders, "|") + ")"
+}
+
+// permute calls f with every permutation of s[k:], with s[:k] fixed.
+func permute(s []string, k int, f func([]string)) {
+	if k == len(s) {
+		f(s)
+		return
+	}
+	for i := k; i < len(s); i++ {
+		s[k], s[i] = s[i], s[k]
+		permute(s, k+1, f)
+		s[k], s[i] = s[i], s[k]
+	}
+}
+
 // Should only match 1..65535, but for simplicity it matches 0-99999.
 const portMatch = `(?::\d{1,5})?`
 
@@ -17,6 +17,7 @@ limitations under the License.
 package resources
 
 import (
+	"regexp"
 	"testing"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/network"
 )
 
 var (
@@ -237,6 +239,7 @@ func TestMakeMeshVirtualServiceSpec_CorrectRoutes(t *testing.T) {
 					Add: map[string]string{
 						"ugh": "blah",
 					},
+					Remove: []string{network.RequestPriorityHeaderName},
 				},
 			},
 		}},
@@ -364,6 +367,7 @@ func TestMakeIngressVirtualServiceSpec_CorrectRoutes(t *testing.T) {
 					Add: map[string]string{
 						"ugh": "blah",
 					},
+					Remove: []string{network.RequestPriorityHeaderName},
 				},
 			},
 		}},
@@ -391,7 +395,8 @@ func TestMakeIngressVirtualServiceSpec_CorrectRoutes(t *testing.T) {
 				Host: "v1-service.test-ns.svc.cluster.local",
 				Port: v1alpha3.PortSelector{Number: 80},
 			},
-			Weight: 100,
+			Weight:  100,
+			Headers: removePriorityHeader,
 		}},
 		Headers: &v1alpha3.Headers{
 			Request: &v1alpha3.HeaderOperations{
@@ -446,7 +451,8 @@ func TestMakeVirtualServiceRoute_Vanilla(t *testing.T) {
 				Host: "revision-service.test-ns.svc.cluster.local",
 				Port: v1alpha3.PortSelector{Number: 80},
 			},
-			Weight: 100,
+			Weight:  100,
+			Headers: removePriorityHeader,
 		}},
 		Timeout: defaultMaxRevisionTimeout.String(),
 		Retries: &v1alpha3.HTTPRetry{
@@ -495,24 +501,200 @@ func TestMakeVirtualServiceRoute_TwoTargets(t *testing.T) {
 				Host: "revision-service.test-ns.svc.cluster.local",
 				Port: v1alpha3.PortSelector{Number: 80},
 			},
-			Weight: 90,
+			Weight:  90,
+			Headers: removePriorityHeader,
 		}, {
 			Destination: v1alpha3.Destination{
 				Host: "new-revision-service.test-ns.svc.cluster.local",
 				Port: v1alpha3.PortSelector{Name: "test-port"},
 			},
-			Weight: 10,
+			Weight:  10,
+			Headers: removePriorityHeader,
+		}},
+		Timeout: defaultMaxRevisionTimeout.String(),
+		Retries: &v1alpha3.HTTPRetry{
+			Attempts:      networking.DefaultRetryCount,
+			PerTryTimeout: defaultMaxRevisionTimeout.String(),
+		},
+		WebsocketUpgrade: true,
+	}
+	if diff := cmp.Diff(&expected, route); diff != "" {
+		t.Errorf("Unexpected route  (-want +got): %v", diff)
+	}
+}
+
+// A target with a part of its traffic mirrored.
+func TestMakeVirtualServiceRoute_Mirror(t *testing.T) {
+	ingressPath := &v1alpha1.HTTPIngressPath{
+		Splits: []v1alpha1.IngressBackendSplit{{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceNamespace: "test-ns",
+				ServiceName:      "revision-service",
+				ServicePort:      intstr.FromInt(80),
+			},
+			Percent: 100,
+		}},
+		AppendHeaders: map[string]string{
+			"ugh": "blah",
+		},
+		Timeout: &metav1.Duration{Duration: defaultMaxRevisionTimeout},
+		Retries: &v1alpha1.HTTPRetry{
+			PerTryTimeout: &metav1.Duration{Duration: defaultMaxRevisionTimeout},
+			Attempts:      networking.DefaultRetryCount,
+		},
+		Mirror: &v1alpha1.IngressBackendMirror{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceNamespace: "test-ns",
+				ServiceName:      "new-revision-service",
+				ServicePort:      intstr.FromInt(81),
+			},
+			Percent: 10,
+		},
+	}
+	route := makeVirtualServiceRoute(sets.NewString("test.org"), ingressPath, sets.NewString("gateway-1"))
+	expected := v1alpha3.HTTPRoute{
+		Match: []v1alpha3.HTTPMatchRequest{{
+			Gateways:  []string{"gateway-1"},
+			Authority: &istiov1alpha1.StringMatch{Regex: `^test\.org(?::\d{1,5})?$`},
+		}},
+		Route: []v1alpha3.HTTPRouteDestination{{
+			Destination: v1alpha3.Destination{
+				Host: "revision-service.test-ns.svc.cluster.local",
+				Port: v1alpha3.PortSelector{Number: 80},
+			},
+			Weight:  100,
+			Headers: removePriorityHeader,
 		}},
 		Timeout: defaultMaxRevisionTimeout.String(),
 		Retries: &v1alpha3.HTTPRetry{
 			Attempts:      networking.DefaultRetryCount,
 			PerTryTimeout: defaultMaxRevisionTimeout.String(),
 		},
+		Headers: &v1alpha3.Headers{
+			Request: &v1alpha3.HeaderOperations{
+				Add: map[string]string{
+					"ugh": "blah",
+				},
+				Set: map[string]string{
+					network.MirrorPercentHeaderName: "10",
+				},
+			},
+		},
+		Mirror: &v1alpha3.Destination{
+			Host: "new-revision-service.test-ns.svc.cluster.local",
+			Port: v1alpha3.PortSelector{Number: 81},
+		},
 		WebsocketUpgrade: true,
 	}
 	if diff := cmp.Diff(&expected, route); diff != "" {
 		t.Errorf("Unexpected route  (-want +got): %v", diff)
 	}
+
+	// All the traffic is mirrored, the percentage is still set so callers
+	// can't pass their own.
+	ingressPath.AppendHeaders = nil
+	ingressPath.Mirror.Percent = 0
+	route = makeVirtualServiceRoute(sets.NewString("test.org"), ingressPath, sets.NewString("gateway-1"))
+	if got, want := route.Headers.Request.Set[network.MirrorPercentHeaderName], "100"; got != want {
+		t.Errorf("%s = %q, want: %q", network.MirrorPercentHeaderName, got, want)
+	}
+	if route.Mirror == nil {
+		t.Error("Mirror = nil, want the mirror destination")
+	}
+}
+
+// removePriorityHeader are the headers of a split without a priority class.
+var removePriorityHeader = &v1alpha3.Headers{
+	Request: &v1alpha3.HeaderOperations{
+		Remove: []string{network.RequestPriorityHeaderName},
+	},
+}
+
+func TestMakeVirtualServiceRoute_Match(t *testing.T) {
+	ingressPath := &v1alpha1.HTTPIngressPath{
+		Headers: map[string]string{
+			"X-Canary": "true",
+		},
+		Cookies: map[string]string{
+			"canary": "always",
+			"beta":   "1",
+		},
+		QueryParameters: map[string]string{
+			"canary": "true",
+		},
+		Splits: []v1alpha1.IngressBackendSplit{{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceNamespace: "test-ns",
+				ServiceName:      "revision-service",
+				ServicePort:      intstr.FromInt(80),
+			},
+			Percent: 100,
+		}},
+		Timeout: &metav1.Duration{Duration: defaultMaxRevisionTimeout},
+		Retries: &v1alpha1.HTTPRetry{
+			PerTryTimeout: &metav1.Duration{Duration: defaultMaxRevisionTimeout},
+			Attempts:      networking.DefaultRetryCount,
+		},
+	}
+	route := makeVirtualServiceRoute(sets.NewString("test.org"), ingressPath, sets.NewString("gateway-1"))
+	expected := []v1alpha3.HTTPMatchRequest{{
+		Gateways:  []string{"gateway-1"},
+		Authority: &istiov1alpha1.StringMatch{Regex: `^test\.org(?::\d{1,5})?$`},
+		Headers: map[string]istiov1alpha1.StringMatch{
+			"x-canary": {Exact: "true"},
+			"cookie": {Regex: `^((.*;\s*)?beta=1(;\s*.*)?;\s*canary=always(;\s*.*)?|` +
+				`(.*;\s*)?canary=always(;\s*.*)?;\s*beta=1(;\s*.*)?)$`},
+			":path": {Regex: `^[^?]*\?((.*&)?canary=true(&.*)?)$`},
+		},
+	}}
+	if diff := cmp.Diff(expected, route.Match); diff != "" {
+		t.Errorf("Unexpected match (-want +got): %v", diff)
+	}
+}
+
+func TestMakeHeaderMatches_RegExps(t *testing.T) {
+	matches := makeHeaderMatches(&v1alpha1.HTTPIngressPath{
+		Cookies: map[string]string{
+			"canary": "always",
+			"beta":   "1",
+		},
+		QueryParameters: map[string]string{
+			"canary": "true",
+			"beta":   "1",
+		},
+	})
+
+	re := regexp.MustCompile(matches["cookie"].Regex)
+	for cookie, want := range map[string]bool{
+		"beta=1; canary=always":             true,
+		"canary=always;beta=1":              true,
+		"a=b; canary=always; c=d; beta=1":   true,
+		"canary=always; beta=1; session=xy": true,
+		"canary=always":                     false,
+		"beta=1; canary=always2":            false,
+		"xbeta=1; canary=always":            false,
+		"beta=12; canary=always":            false,
+	} {
+		if got := re.MatchString(cookie); got != want {
+			t.Errorf("MatchString(%q) = %v, want %v", cookie, got, want)
+		}
+	}
+
+	re = regexp.MustCompile(matches[":path"].Regex)
+	for path, want := range map[string]bool{
+		"/?beta=1&canary=true":             true,
+		"/foo?canary=true&beta=1":          true,
+		"/?a=b&canary=true&c=d&beta=1&e=f": true,
+		"/?canary=true":                    false,
+		"/?beta=1&canary=truex":            false,
+		"/?xbeta=1&canary=true":            false,
+		"/canary=true?beta=1":              false,
+		"/beta=1":                          false,
+	} {
+		if got := re.MatchString(path); got != want {
+			t.Errorf("MatchString(%q) = %v, want %v", path, got, want)
+		}
+	}
 }
 
 func TestGetHosts_Duplicate(t *testing.T) {
@@ -591,3 +773,19 @@ func makeGatewayMap(publicGateways []string, privateGateways []string) map[v1alp
 		v1alpha1.IngressVisibilityClusterLocal: sets.NewString(privateGateways...),
 	}
 }
+
+func TestMakeSplitHeaders(t *testing.T) {
+	got := makeSplitHeaders(map[string]string{
+		"ugh":                             "blah",
+		network.RequestPriorityHeaderName: "high",
+	})
+	want := &v1alpha3.Headers{
+		Request: &v1alpha3.HeaderOperations{
+			Set: map[string]string{network.RequestPriorityHeaderName: "high"},
+			Add: map[string]string{"ugh": "blah"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected headers (-want +got): %v", diff)
+	}
+}
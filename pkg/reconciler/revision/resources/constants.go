@@ -41,4 +41,9 @@ var (
 	// See https://knative.dev/serving/pull/1124#issuecomment-397120430
 	// for how CPU and memory values were calculated.
 	queueContainerCPU = resource.MustParse("25m")
+
+	// queueContainerMemory is requested when the HPA scales on memory
+	// without a request derived from the resource percentage annotation,
+	// as the HPA needs every container of the pod to request memory.
+	queueContainerMemory = resource.MustParse("50Mi")
 )
@@ -17,6 +17,7 @@ limitations under the License.
 package resources
 
 import (
+	"math"
 	"strconv"
 
 	"knative.dev/pkg/kmeta"
@@ -45,6 +46,8 @@ const (
 	varLogVolumePath   = "/var/log"
 	internalVolumeName = "knative-internal"
 	internalVolumePath = "/var/knative-internal"
+	tlsVolumeName      = "knative-queue-proxy-tls"
+	tlsVolumePath      = "/var/lib/knative/queue-proxy-tls"
 )
 
 var (
@@ -109,6 +112,56 @@ func rewriteUserProbe(p *corev1.Probe, userPort int) {
 }
 
 func makePodSpec(rev *v1alpha1.Revision, loggingConfig *logging.Config, tracingConfig *tracingconfig.Config, observabilityConfig *metrics.ObservabilityConfig, autoscalerConfig *autoscaler.Config, deploymentConfig *deployment.Config) *corev1.PodSpec {
+	userContainer := makeServingContainer(rev)
+
+	// The serving container keeps its position among the sidecars, the
+	// queue-proxy always comes last.
+	containers := []corev1.Container{*userContainer}
+	if len(rev.Spec.Containers) > 1 {
+		containers = make([]corev1.Container, 0, len(rev.Spec.Containers)+1)
+		servingIdx := serving.ServingContainerIndex(rev.Spec.Containers)
+		for i := range rev.Spec.Containers {
+			if i == servingIdx {
+				containers = append(containers, *userContainer)
+			} else {
+				containers = append(containers, *makeSidecarContainer(rev, &rev.Spec.Containers[i]))
+			}
+		}
+	}
+	containers = append(containers,
+		*makeQueueContainer(rev, loggingConfig, tracingConfig, observabilityConfig, autoscalerConfig, deploymentConfig))
+
+	podSpec := &corev1.PodSpec{
+		Containers:                    containers,
+		Volumes:                       append([]corev1.Volume{varLogVolume}, rev.Spec.Volumes...),
+		ServiceAccountName:            rev.Spec.ServiceAccountName,
+		TerminationGracePeriodSeconds: terminationGracePeriod(rev),
+	}
+
+	// Add the Knative internal volume only if /var/log collection is enabled
+	if observabilityConfig.EnableVarLogCollection {
+		podSpec.Volumes = append(podSpec.Volumes, internalVolume)
+	}
+
+	return podSpec
+}
+
+// terminationGracePeriod gives the pods of the revision the time to drain
+// their requests when they're terminated. Revisions that don't configure
+// their drain keep the revision's timeout, so that they aren't redeployed.
+func terminationGracePeriod(rev *v1alpha1.Revision) *int64 {
+	_, hasDelay := rev.Annotations[serving.PreStopDelayAnnotationKey]
+	_, hasTimeout := rev.Annotations[serving.DrainTimeoutAnnotationKey]
+	if !hasDelay && !hasTimeout {
+		return rev.Spec.TimeoutSeconds
+	}
+	preStopDelay, drainTimeout := drainTimes(rev)
+	return ptr.Int64(int64(math.Ceil((preStopDelay + drainTimeout).Seconds())))
+}
+
+// makeServingContainer builds the container receiving the requests proxied by
+// the queue-proxy. It gets the user port and the PORT env var.
+func makeServingContainer(rev *v1alpha1.Revision) *corev1.Container {
 	userContainer := rev.Spec.GetContainer().DeepCopy()
 	// Adding or removing an overwritten corev1.Container field here? Don't forget to
 	// update the fieldmasks / validations in pkg/apis/serving
@@ -145,23 +198,60 @@ func makePodSpec(rev *v1alpha1.Revision, loggingConfig *logging.Config, tracingC
 
 	// If the client provides probes, we should fill in the port for them.
 	rewriteUserProbe(userContainer.LivenessProbe, userPortInt)
+	return userContainer
+}
 
-	podSpec := &corev1.PodSpec{
-		Containers: []corev1.Container{
-			*userContainer,
-			*makeQueueContainer(rev, loggingConfig, tracingConfig, observabilityConfig, autoscalerConfig, deploymentConfig),
-		},
-		Volumes:                       append([]corev1.Volume{varLogVolume}, rev.Spec.Volumes...),
-		ServiceAccountName:            rev.Spec.ServiceAccountName,
-		TerminationGracePeriodSeconds: rev.Spec.TimeoutSeconds,
+// makeSidecarContainer builds a container next to the serving container. Its
+// probes and env are left as the user specified them, since the queue-proxy
+// neither probes nor proxies to it. Like the serving container, it's only
+// terminated once the queue-proxy drained the requests, which the serving
+// container may need it for.
+func makeSidecarContainer(rev *v1alpha1.Revision, c *corev1.Container) *corev1.Container {
+	sidecar := c.DeepCopy()
+	sidecar.Lifecycle = userLifecycle
+	// Explicitly disable stdin and tty allocation
+	sidecar.Stdin = false
+	sidecar.TTY = false
+
+	// Prefer imageDigest from revision if available
+	for _, status := range rev.Status.ContainerStatuses {
+		if status.Name == sidecar.Name && status.ImageDigest != "" {
+			sidecar.Image = status.ImageDigest
+		}
 	}
 
-	// Add the Knative internal volume only if /var/log collection is enabled
-	if observabilityConfig.EnableVarLogCollection {
-		podSpec.Volumes = append(podSpec.Volumes, internalVolume)
+	if sidecar.TerminationMessagePolicy == "" {
+		sidecar.TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
 	}
+	return sidecar
+}
 
-	return podSpec
+// withQueueProxyTLS mounts the Secret holding the certificate into the
+// queue-proxy, which makes it serve TLS.
+func withQueueProxyTLS(podSpec *corev1.PodSpec, secretName string) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: tlsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if c.Name != QueueContainerName {
+			continue
+		}
+		c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+			Name:      tlsVolumeName,
+			MountPath: tlsVolumePath,
+			ReadOnly:  true,
+		})
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "QUEUE_SERVING_CERT_DIR",
+			Value: tlsVolumePath,
+		})
+	}
 }
 
 func getUserPort(rev *v1alpha1.Revision) int32 {
@@ -222,6 +312,11 @@ func MakeDeployment(rev *v1alpha1.Revision,
 		}
 	}
 
+	podSpec := makePodSpec(rev, loggingConfig, tracingConfig, observabilityConfig, autoscalerConfig, deploymentConfig)
+	if secret := rev.Annotations[serving.QueueProxyTLSSecretAnnotationKey]; secret != "" {
+		withQueueProxyTLS(podSpec, secret)
+	}
+
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      names.Deployment(rev),
@@ -242,7 +337,7 @@ func MakeDeployment(rev *v1alpha1.Revision,
 					Labels:      makeLabels(rev),
 					Annotations: podTemplateAnnotations,
 				},
-				Spec: *makePodSpec(rev, loggingConfig, tracingConfig, observabilityConfig, autoscalerConfig, deploymentConfig),
+				Spec: *podSpec,
 			},
 		},
 	}
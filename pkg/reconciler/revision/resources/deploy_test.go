@@ -32,6 +32,7 @@ import (
 	"knative.dev/pkg/ptr"
 	"knative.dev/pkg/system"
 	_ "knative.dev/pkg/system/testing"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
@@ -159,6 +160,39 @@ var (
 		}, {
 			Name:  "INTERNAL_VOLUME_PATH",
 			Value: internalVolumePath,
+		}, {
+			Name:  "ENABLE_REQUEST_PRIORITY",
+			Value: "false",
+		}, {
+			Name:  "LOW_PRIORITY_WAIT_BUDGET",
+			Value: "0s",
+		}, {
+			Name:  "STREAM_IDLE_TIMEOUT",
+			Value: "0s",
+		}, {
+			Name:  "EXCLUDE_STREAMS_FROM_CONCURRENCY",
+			Value: "false",
+		}, {
+			Name:  "PRE_STOP_DELAY",
+			Value: "20s",
+		}, {
+			Name:  "DRAIN_TIMEOUT",
+			Value: "45s",
+		}, {
+			Name:  "DRAIN_NOTIFY_PATH",
+			Value: "",
+		}, {
+			Name:  "MAX_REQUEST_BODY_BYTES",
+			Value: "0",
+		}, {
+			Name:  "MAX_REQUEST_HEADER_BYTES",
+			Value: "0",
+		}, {
+			Name:  "MAX_REQUEST_DURATION",
+			Value: "0s",
+		}, {
+			Name:  "RESPONSE_IDLE_TIMEOUT",
+			Value: "0s",
 		}},
 	}
 
@@ -407,6 +441,126 @@ func TestMakePodSpec(t *testing.T) {
 					withEnvVar("SERVING_READINESS_PROBE", ""),
 				),
 			}),
+	}, {
+		name: "sidecar keeps its probes and env",
+		rev: revision(
+			withContainerConcurrency(1),
+			func(revision *v1alpha1.Revision) {
+				revision.Spec.DeprecatedContainer = nil
+				revision.Spec.Containers = []corev1.Container{{
+					Name:  "sidecar",
+					Image: "sidecar",
+					Env: []corev1.EnvVar{{
+						Name:  "FOO",
+						Value: "bar",
+					}},
+					ReadinessProbe: &corev1.Probe{
+						Handler: corev1.Handler{
+							HTTPGet: &corev1.HTTPGetAction{
+								Path: "/ready",
+								Port: intstr.FromInt(9090),
+							},
+						},
+					},
+				}, {
+					Name:  containerName,
+					Image: "busybox",
+					Ports: []corev1.ContainerPort{{
+						ContainerPort: 8888,
+					}},
+				}}
+			},
+		),
+		lc: &logging.Config{},
+		tc: &tracingconfig.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: podSpec(
+			[]corev1.Container{{
+				Name:  "sidecar",
+				Image: "sidecar",
+				Env: []corev1.EnvVar{{
+					Name:  "FOO",
+					Value: "bar",
+				}},
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{
+							Path: "/ready",
+							Port: intstr.FromInt(9090),
+						},
+					},
+				},
+				Lifecycle:                userLifecycle,
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			},
+				userContainer(
+					func(container *corev1.Container) {
+						container.Ports[0].ContainerPort = 8888
+					},
+					withEnvVar("PORT", "8888"),
+				),
+				queueContainer(
+					withEnvVar("CONTAINER_CONCURRENCY", "1"),
+					withEnvVar("USER_PORT", "8888"),
+					withEnvVar("SERVING_READINESS_PROBE", ""),
+				),
+			}),
+	}, {
+		name: "drain configured",
+		rev: revision(
+			withContainerConcurrency(1),
+			func(revision *v1alpha1.Revision) {
+				revision.Annotations = map[string]string{
+					serving.PreStopDelayAnnotationKey:    "5s",
+					serving.DrainTimeoutAnnotationKey:    "1m30s",
+					serving.DrainNotifyPathAnnotationKey: "/drain",
+				}
+			},
+		),
+		lc: &logging.Config{},
+		tc: &tracingconfig.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: podSpec(
+			[]corev1.Container{
+				userContainer(),
+				queueContainer(
+					withEnvVar("CONTAINER_CONCURRENCY", "1"),
+					withEnvVar("PRE_STOP_DELAY", "5s"),
+					withEnvVar("DRAIN_TIMEOUT", "1m30s"),
+					withEnvVar("DRAIN_NOTIFY_PATH", "/drain"),
+					withEnvVar("SERVING_READINESS_PROBE", ""),
+				),
+			}, func(ps *corev1.PodSpec) {
+				ps.TerminationGracePeriodSeconds = refInt64(95)
+			}),
+	}, {
+		name: "drain notification only",
+		rev: revision(
+			withContainerConcurrency(1),
+			func(revision *v1alpha1.Revision) {
+				revision.Annotations = map[string]string{
+					serving.DrainNotifyPathAnnotationKey: "/drain",
+				}
+			},
+		),
+		lc: &logging.Config{},
+		tc: &tracingconfig.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: podSpec(
+			[]corev1.Container{
+				userContainer(),
+				queueContainer(
+					withEnvVar("CONTAINER_CONCURRENCY", "1"),
+					withEnvVar("DRAIN_NOTIFY_PATH", "/drain"),
+					withEnvVar("SERVING_READINESS_PROBE", ""),
+				),
+			}),
 	}, {
 		name: "volumes passed through",
 		rev: revision(
@@ -500,6 +654,59 @@ func TestMakePodSpec(t *testing.T) {
 					withEnvVar("SERVING_READINESS_PROBE", ""),
 				),
 			}),
+	}, {
+		name: "sidecar digest resolved",
+		rev: revision(
+			withContainerConcurrency(1),
+			func(revision *v1alpha1.Revision) {
+				revision.Spec.DeprecatedContainer = nil
+				revision.Spec.Containers = []corev1.Container{{
+					Name:  containerName,
+					Image: "busybox",
+					Ports: []corev1.ContainerPort{{
+						ContainerPort: 8888,
+					}},
+				}, {
+					Name:  "sidecar",
+					Image: "sidecar",
+				}}
+				revision.Status = v1alpha1.RevisionStatus{
+					ImageDigest: "busybox@sha256:deadbeef",
+					ContainerStatuses: []v1beta1.ContainerStatus{{
+						Name:        containerName,
+						ImageDigest: "busybox@sha256:deadbeef",
+					}, {
+						Name:        "sidecar",
+						ImageDigest: "sidecar@sha256:deadbeef",
+					}},
+				}
+			},
+		),
+		lc: &logging.Config{},
+		tc: &tracingconfig.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: podSpec(
+			[]corev1.Container{
+				userContainer(
+					func(container *corev1.Container) {
+						container.Image = "busybox@sha256:deadbeef"
+						container.Ports[0].ContainerPort = 8888
+					},
+					withEnvVar("PORT", "8888"),
+				), {
+					Name:                     "sidecar",
+					Image:                    "sidecar@sha256:deadbeef",
+					Lifecycle:                userLifecycle,
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				},
+				queueContainer(
+					withEnvVar("CONTAINER_CONCURRENCY", "1"),
+					withEnvVar("USER_PORT", "8888"),
+					withEnvVar("SERVING_READINESS_PROBE", ""),
+				),
+			}),
 	}, {
 		name: "concurrency=1 with owner",
 		rev: revision(
@@ -755,6 +962,9 @@ func TestMakePodSpec(t *testing.T) {
 				return x.Cmp(y) == 0
 			})
 
+			if test.rev.Spec.DeprecatedContainer == nil {
+				t.Skip("already specified via podspec")
+			}
 			// Same test, but via podspec.
 			test.rev.Spec.Containers = []corev1.Container{
 				*test.rev.Spec.DeprecatedContainer,
@@ -872,3 +1082,64 @@ func TestMakeDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeDeploymentHPAMemory(t *testing.T) {
+	rev := revision(withoutLabels, func(revision *v1alpha1.Revision) {
+		revision.Annotations = map[string]string{
+			autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+			autoscaling.MetricAnnotationKey: autoscaling.Memory,
+		}
+		revision.Spec.GetContainer().Resources.Requests = corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		}
+	})
+
+	got := MakeDeployment(rev, &logging.Config{}, &tracingconfig.Config{}, &network.Config{},
+		&metrics.ObservabilityConfig{}, &autoscaler.Config{}, &deployment.Config{})
+	// The HPA fails to compute the memory utilization of the pod unless
+	// every one of its containers requests memory.
+	for _, c := range got.Spec.Template.Spec.Containers {
+		if _, ok := c.Resources.Requests[corev1.ResourceMemory]; !ok {
+			t.Errorf("Container %q requests no memory", c.Name)
+		}
+	}
+}
+
+func TestMakeDeploymentQueueProxyTLS(t *testing.T) {
+	rev := revision(withoutLabels, func(revision *v1alpha1.Revision) {
+		revision.Annotations = map[string]string{
+			serving.QueueProxyTLSSecretAnnotationKey: "queue-proxy-certs",
+		}
+	})
+	lc, tc, oc, ac, cc := &logging.Config{}, &tracingconfig.Config{}, &metrics.ObservabilityConfig{}, &autoscaler.Config{}, &deployment.Config{}
+
+	want := makeDeployment(func(deploy *appsv1.Deployment) {
+		deploy.ObjectMeta.Annotations[serving.QueueProxyTLSSecretAnnotationKey] = "queue-proxy-certs"
+		deploy.Spec.Template.ObjectMeta.Annotations[serving.QueueProxyTLSSecretAnnotationKey] = "queue-proxy-certs"
+	})
+	want.Spec.Template.Spec = *makePodSpec(rev, lc, tc, oc, ac, cc)
+	want.Spec.Template.Spec.Volumes = append(want.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "knative-queue-proxy-tls",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: "queue-proxy-certs",
+			},
+		},
+	})
+	qp := &want.Spec.Template.Spec.Containers[1]
+	qp.VolumeMounts = append(qp.VolumeMounts, corev1.VolumeMount{
+		Name:      "knative-queue-proxy-tls",
+		MountPath: "/var/lib/knative/queue-proxy-tls",
+		ReadOnly:  true,
+	})
+	qp.Env = append(qp.Env, corev1.EnvVar{
+		Name:  "QUEUE_SERVING_CERT_DIR",
+		Value: "/var/lib/knative/queue-proxy-tls",
+	})
+
+	// The revision keeps serving TLS regardless of config-network.
+	got := MakeDeployment(rev, lc, tc, &network.Config{}, oc, ac, cc)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(resource.Quantity{})); diff != "" {
+		t.Errorf("MakeDeployment (-want, +got) = %v", diff)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -29,6 +30,7 @@ import (
 	pkgmetrics "knative.dev/pkg/metrics"
 	"knative.dev/pkg/ptr"
 	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
@@ -95,6 +97,12 @@ func createQueueResources(annotations map[string]string, userContainer *corev1.C
 		}
 	}
 
+	if _, ok := resourceRequests[corev1.ResourceMemory]; !ok &&
+		annotations[autoscaling.ClassAnnotationKey] == autoscaling.HPA &&
+		annotations[autoscaling.MetricAnnotationKey] == autoscaling.Memory {
+		resourceRequests[corev1.ResourceMemory] = queueContainerMemory
+	}
+
 	resources := corev1.ResourceRequirements{
 		Requests: resourceRequests,
 	}
@@ -200,6 +208,8 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, t
 		ts = *rev.Spec.TimeoutSeconds
 	}
 
+	preStopDelay, drainTimeout := drainTimes(rev)
+
 	// We need to configure only one serving port for the Queue proxy, since
 	// we know the protocol that is being used by this application.
 	ports := queueNonServingPorts
@@ -221,7 +231,7 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, t
 	// TODO(joshrider) bubble up error instead of squashing it here
 	probeJSON, _ := readiness.EncodeProbe(rp)
 
-	return &corev1.Container{
+	c := &corev1.Container{
 		Name:            QueueContainerName,
 		Image:           deploymentConfig.QueueSidecarImage,
 		Resources:       createQueueResources(rev.GetAnnotations(), rev.Spec.GetContainer()),
@@ -309,11 +319,103 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, t
 		}, {
 			Name:  "INTERNAL_VOLUME_PATH",
 			Value: internalVolumePath,
+		}, {
+			Name:  "ENABLE_REQUEST_PRIORITY",
+			Value: strconv.FormatBool(deploymentConfig.EnableRequestPriority),
+		}, {
+			Name:  "LOW_PRIORITY_WAIT_BUDGET",
+			Value: deploymentConfig.LowPriorityWaitBudget.String(),
+		}, {
+			Name:  "STREAM_IDLE_TIMEOUT",
+			Value: deploymentConfig.StreamIdleTimeout.String(),
+		}, {
+			Name:  "EXCLUDE_STREAMS_FROM_CONCURRENCY",
+			Value: strconv.FormatBool(deploymentConfig.ExcludeStreamsFromConcurrency),
+		}, {
+			Name:  "PRE_STOP_DELAY",
+			Value: preStopDelay.String(),
+		}, {
+			Name:  "DRAIN_TIMEOUT",
+			Value: drainTimeout.String(),
+		}, {
+			Name:  "DRAIN_NOTIFY_PATH",
+			Value: rev.Annotations[serving.DrainNotifyPathAnnotationKey],
+		}, {
+			Name:  "MAX_REQUEST_BODY_BYTES",
+			Value: requestLimit(rev.Annotations, serving.MaxRequestBodyBytesAnnotationKey),
+		}, {
+			Name:  "MAX_REQUEST_HEADER_BYTES",
+			Value: requestLimit(rev.Annotations, serving.MaxRequestHeaderBytesAnnotationKey),
+		}, {
+			Name:  "MAX_REQUEST_DURATION",
+			Value: requestTimeout(rev.Annotations, serving.MaxRequestDurationAnnotationKey).String(),
+		}, {
+			Name:  "RESPONSE_IDLE_TIMEOUT",
+			Value: requestTimeout(rev.Annotations, serving.ResponseIdleTimeoutAnnotationKey).String(),
 		}, {
 			Name:  "SERVING_READINESS_PROBE",
 			Value: probeJSON,
 		}},
 	}
+	c.Env = append(c.Env, makeCustomMetricEnv(rev.GetAnnotations())...)
+	return c
+}
+
+// drainTimes returns how long the pods of the revision keep accepting
+// requests once they're asked to terminate and how long the requests in
+// flight may take to complete after that.
+func drainTimes(rev *v1alpha1.Revision) (time.Duration, time.Duration) {
+	preStopDelay := queue.DefaultPreStopDelay
+	if d, err := time.ParseDuration(rev.Annotations[serving.PreStopDelayAnnotationKey]); err == nil {
+		preStopDelay = d
+	}
+	var drainTimeout time.Duration
+	if rev.Spec.TimeoutSeconds != nil {
+		drainTimeout = time.Duration(*rev.Spec.TimeoutSeconds) * time.Second
+	}
+	if d, err := time.ParseDuration(rev.Annotations[serving.DrainTimeoutAnnotationKey]); err == nil {
+		drainTimeout = d
+	}
+	return preStopDelay, drainTimeout
+}
+
+// requestLimit returns the request size limit set by the annotation, or 0
+// for no limit.
+func requestLimit(annotations map[string]string, key string) string {
+	if v, ok := annotations[key]; ok {
+		return v
+	}
+	return "0"
+}
+
+// requestTimeout returns the request timeout set by the annotation, or 0 for
+// no timeout.
+func requestTimeout(annotations map[string]string, key string) time.Duration {
+	d, _ := time.ParseDuration(annotations[key])
+	return d
+}
+
+// makeCustomMetricEnv tells the queue-proxy which metric to scrape from the
+// user container, if the KPA scales the revision on a custom metric.
+func makeCustomMetricEnv(annotations map[string]string) []corev1.EnvVar {
+	if class, ok := annotations[autoscaling.ClassAnnotationKey]; ok && class != autoscaling.KPA {
+		return nil
+	}
+	name, ok := autoscaling.CustomMetricName(annotations[autoscaling.MetricAnnotationKey])
+	if !ok {
+		return nil
+	}
+	path := autoscaling.CustomMetricPathDefault
+	if p, ok := annotations[autoscaling.CustomMetricPathAnnotationKey]; ok {
+		path = p
+	}
+	return []corev1.EnvVar{{
+		Name:  "CUSTOM_METRIC_NAME",
+		Value: name,
+	}, {
+		Name:  "CUSTOM_METRIC_PATH",
+		Value: path,
+	}}
 }
 func applyReadinessProbeDefaults(p *corev1.Probe, port int32) {
 	switch {
@@ -35,6 +35,7 @@ import (
 	"knative.dev/pkg/ptr"
 	"knative.dev/pkg/system"
 	_ "knative.dev/pkg/system/testing"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
@@ -369,6 +370,189 @@ func TestMakeQueueContainer(t *testing.T) {
 				"SERVING_REQUEST_METRICS_BACKEND": "prometheus",
 			}),
 		},
+	}, {
+		name: "custom metric",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					autoscaling.MetricAnnotationKey: "custom/queue_depth",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 1,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		tc: &tracingconfig.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{
+				"CUSTOM_METRIC_NAME": "queue_depth",
+				"CUSTOM_METRIC_PATH": "/metrics",
+			}),
+		},
+	}, {
+		name: "custom metric path",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					autoscaling.MetricAnnotationKey:           "custom/queue_depth",
+					autoscaling.CustomMetricPathAnnotationKey: "/stats",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 1,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		tc: &tracingconfig.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{
+				"CUSTOM_METRIC_NAME": "queue_depth",
+				"CUSTOM_METRIC_PATH": "/stats",
+			}),
+		},
+	}, {
+		// The HPA takes custom metrics from the custom metrics API, the
+		// queue-proxy has nothing to scrape.
+		name: "custom metric with hpa",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey:  autoscaling.HPA,
+					autoscaling.MetricAnnotationKey: "custom/queue_depth",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 1,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		tc: &tracingconfig.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{}),
+		},
+	}, {
+		name: "request limits",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					serving.MaxRequestBodyBytesAnnotationKey:   "1048576",
+					serving.MaxRequestHeaderBytesAnnotationKey: "8192",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 1,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		tc: &tracingconfig.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{
+				"MAX_REQUEST_BODY_BYTES":   "1048576",
+				"MAX_REQUEST_HEADER_BYTES": "8192",
+			}),
+		},
+	}, {
+		name: "request timeouts",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					serving.MaxRequestDurationAnnotationKey:  "1h",
+					serving.ResponseIdleTimeoutAnnotationKey: "30s",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 1,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		tc: &tracingconfig.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{
+				"MAX_REQUEST_DURATION":  "1h0m0s",
+				"RESPONSE_IDLE_TIMEOUT": "30s",
+			}),
+		},
 	}}
 
 	for _, test := range tests {
@@ -1020,28 +1204,39 @@ func TestTCPProbeGeneration(t *testing.T) {
 }
 
 var defaultEnv = map[string]string{
-	"SERVING_NAMESPACE":               "foo",
-	"SERVING_SERVICE":                 "",
-	"SERVING_CONFIGURATION":           "",
-	"SERVING_REVISION":                "bar",
-	"CONTAINER_CONCURRENCY":           "1",
-	"REVISION_TIMEOUT_SECONDS":        "45",
-	"SERVING_LOGGING_CONFIG":          "",
-	"SERVING_LOGGING_LEVEL":           "",
-	"TRACING_CONFIG_ENABLE":           "false",
-	"TRACING_CONFIG_ZIPKIN_ENDPOINT":  "",
-	"TRACING_CONFIG_SAMPLE_RATE":      "0.000000",
-	"TRACING_CONFIG_DEBUG":            "false",
-	"SERVING_REQUEST_LOG_TEMPLATE":    "",
-	"SERVING_REQUEST_METRICS_BACKEND": "",
-	"USER_PORT":                       strconv.Itoa(v1alpha1.DefaultUserPort),
-	"SYSTEM_NAMESPACE":                system.Namespace(),
-	"METRICS_DOMAIN":                  pkgmetrics.Domain(),
-	"QUEUE_SERVING_PORT":              "8012",
-	"USER_CONTAINER_NAME":             containerName,
-	"ENABLE_VAR_LOG_COLLECTION":       "false",
-	"VAR_LOG_VOLUME_NAME":             varLogVolumeName,
-	"INTERNAL_VOLUME_PATH":            internalVolumePath,
+	"SERVING_NAMESPACE":                "foo",
+	"SERVING_SERVICE":                  "",
+	"SERVING_CONFIGURATION":            "",
+	"SERVING_REVISION":                 "bar",
+	"CONTAINER_CONCURRENCY":            "1",
+	"REVISION_TIMEOUT_SECONDS":         "45",
+	"SERVING_LOGGING_CONFIG":           "",
+	"SERVING_LOGGING_LEVEL":            "",
+	"TRACING_CONFIG_ENABLE":            "false",
+	"TRACING_CONFIG_ZIPKIN_ENDPOINT":   "",
+	"TRACING_CONFIG_SAMPLE_RATE":       "0.000000",
+	"TRACING_CONFIG_DEBUG":             "false",
+	"SERVING_REQUEST_LOG_TEMPLATE":     "",
+	"SERVING_REQUEST_METRICS_BACKEND":  "",
+	"USER_PORT":                        strconv.Itoa(v1alpha1.DefaultUserPort),
+	"SYSTEM_NAMESPACE":                 system.Namespace(),
+	"METRICS_DOMAIN":                   pkgmetrics.Domain(),
+	"QUEUE_SERVING_PORT":               "8012",
+	"USER_CONTAINER_NAME":              containerName,
+	"ENABLE_VAR_LOG_COLLECTION":        "false",
+	"VAR_LOG_VOLUME_NAME":              varLogVolumeName,
+	"INTERNAL_VOLUME_PATH":             internalVolumePath,
+	"ENABLE_REQUEST_PRIORITY":          "false",
+	"LOW_PRIORITY_WAIT_BUDGET":         "0s",
+	"STREAM_IDLE_TIMEOUT":              "0s",
+	"EXCLUDE_STREAMS_FROM_CONCURRENCY": "false",
+	"PRE_STOP_DELAY":                   "20s",
+	"DRAIN_TIMEOUT":                    "45s",
+	"DRAIN_NOTIFY_PATH":                "",
+	"MAX_REQUEST_BODY_BYTES":           "0",
+	"MAX_REQUEST_HEADER_BYTES":         "0",
+	"MAX_REQUEST_DURATION":             "0s",
+	"RESPONSE_IDLE_TIMEOUT":            "0s",
 }
 
 func probeJSON(probe *corev1.Probe) string {
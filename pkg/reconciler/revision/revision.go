@@ -33,6 +33,7 @@ import (
 	cachinglisters "knative.dev/caching/pkg/client/listers/caching/v1alpha1"
 	"knative.dev/pkg/controller"
 	commonlogging "knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 	palisters "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
@@ -124,8 +125,10 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 }
 
 func (c *Reconciler) reconcileDigest(ctx context.Context, rev *v1alpha1.Revision) error {
-	// The image digest has already been resolved.
-	if rev.Status.ImageDigest != "" {
+	// The image digests have already been resolved. Revisions with sidecars
+	// hold the digests of all their containers.
+	if rev.Status.ImageDigest != "" &&
+		(len(rev.Spec.Containers) < 2 || len(rev.Status.ContainerStatuses) == len(rev.Spec.Containers)) {
 		return nil
 	}
 
@@ -136,16 +139,40 @@ func (c *Reconciler) reconcileDigest(ctx context.Context, rev *v1alpha1.Revision
 		// ImagePullSecrets: Not possible via RevisionSpec, since we
 		// don't expose such a field.
 	}
-	digest, err := c.resolver.Resolve(rev.Spec.GetContainer().Image,
-		opt, cfgs.Deployment.RegistriesSkippingTagResolving)
+	resolve := func(image string) (string, error) {
+		digest, err := c.resolver.Resolve(image, opt, cfgs.Deployment.RegistriesSkippingTagResolving)
+		if err != nil {
+			rev.Status.MarkContainerMissing(
+				v1alpha1.RevisionContainerMissingMessage(image, err.Error()))
+		}
+		return digest, err
+	}
+
+	digest, err := resolve(rev.Spec.GetContainer().Image)
 	if err != nil {
-		rev.Status.MarkContainerMissing(
-			v1alpha1.RevisionContainerMissingMessage(
-				rev.Spec.GetContainer().Image, err.Error()))
 		return err
 	}
 
+	var statuses []v1beta1.ContainerStatus
+	if len(rev.Spec.Containers) > 1 {
+		servingIdx := serving.ServingContainerIndex(rev.Spec.Containers)
+		statuses = make([]v1beta1.ContainerStatus, 0, len(rev.Spec.Containers))
+		for i, container := range rev.Spec.Containers {
+			containerDigest := digest
+			if i != servingIdx {
+				if containerDigest, err = resolve(container.Image); err != nil {
+					return err
+				}
+			}
+			statuses = append(statuses, v1beta1.ContainerStatus{
+				Name:        container.Name,
+				ImageDigest: containerDigest,
+			})
+		}
+	}
+
 	rev.Status.ImageDigest = digest
+	rev.Status.ContainerStatuses = statuses
 
 	return nil
 }
@@ -59,6 +59,7 @@ import (
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
 	"knative.dev/serving/pkg/autoscaler"
 	"knative.dev/serving/pkg/deployment"
 	"knative.dev/serving/pkg/network"
@@ -269,6 +270,62 @@ func (r *errorResolver) Resolve(_ string, _ k8schain.Options, _ sets.String) (st
 	return "", errors.New(r.error)
 }
 
+// suffixResolver resolves the images to digests by appending a suffix.
+type suffixResolver struct {
+	suffix string
+}
+
+func (r *suffixResolver) Resolve(image string, _ k8schain.Options, _ sets.String) (string, error) {
+	return image + r.suffix, nil
+}
+
+func TestResolveSidecarDigests(t *testing.T) {
+	ctx, _, controller, _ := newTestController(t)
+	controller.Reconciler.(*Reconciler).resolver = &suffixResolver{"@sha256:deadbeef"}
+
+	rev := testRevision()
+	rev.Spec.Containers[0].Ports = []corev1.ContainerPort{{
+		ContainerPort: 8888,
+	}}
+	rev.Spec.Containers = append(rev.Spec.Containers, corev1.Container{
+		Name:  "sidecar",
+		Image: "gcr.io/repo/sidecar",
+	})
+	config := testConfiguration()
+	rev.OwnerReferences = append(rev.OwnerReferences, *kmeta.NewControllerRef(config))
+
+	createRevision(t, ctx, controller, rev)
+
+	rev, err := fakeservingclient.Get(ctx).ServingV1alpha1().Revisions(testNamespace).Get(rev.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get revision: %v", err)
+	}
+	if got, want := rev.Status.ImageDigest, "gcr.io/repo/image@sha256:deadbeef"; got != want {
+		t.Errorf("ImageDigest = %q, want: %q", got, want)
+	}
+	wantStatuses := []v1beta1.ContainerStatus{{
+		Name:        rev.Spec.Containers[0].Name,
+		ImageDigest: "gcr.io/repo/image@sha256:deadbeef",
+	}, {
+		Name:        "sidecar",
+		ImageDigest: "gcr.io/repo/sidecar@sha256:deadbeef",
+	}}
+	if diff := cmp.Diff(wantStatuses, rev.Status.ContainerStatuses); diff != "" {
+		t.Errorf("Unexpected container statuses diff (-want +got): %v", diff)
+	}
+
+	deployment, err := fakekubeclient.Get(ctx).AppsV1().Deployments(testNamespace).Get(
+		fmt.Sprintf("%s-deployment", rev.Name), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get serving deployment: %v", err)
+	}
+	for _, c := range deployment.Spec.Template.Spec.Containers {
+		if c.Name != resources.QueueContainerName && !strings.HasSuffix(c.Image, "@sha256:deadbeef") {
+			t.Errorf("Image of container %q = %q, want a digest", c.Name, c.Image)
+		}
+	}
+}
+
 func TestResolutionFailed(t *testing.T) {
 	ctx, _, controller, _ := newTestController(t)
 
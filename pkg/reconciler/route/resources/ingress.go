@@ -30,6 +30,7 @@ import (
 	"knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	servingv1alpha1 "knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/reconciler/route/domains"
 	"knative.dev/serving/pkg/reconciler/route/resources/labels"
 	"knative.dev/serving/pkg/reconciler/route/resources/names"
@@ -56,7 +57,7 @@ func MakeClusterIngress(
 	clusterLocalServices sets.String,
 	ingressClass string,
 ) (v1alpha1.IngressAccessor, error) {
-	spec, err := MakeIngressSpec(ctx, r, tls, clusterLocalServices, tc.Targets)
+	spec, err := MakeIngressSpec(ctx, r, tls, clusterLocalServices, tc.Targets, tc.Mirror)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +88,7 @@ func MakeIngress(
 	clusterLocalServices sets.String,
 	ingressClass string,
 ) (v1alpha1.IngressAccessor, error) {
-	spec, err := MakeIngressSpec(ctx, r, tls, clusterLocalServices, tc.Targets)
+	spec, err := MakeIngressSpec(ctx, r, tls, clusterLocalServices, tc.Targets, tc.Mirror)
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +116,7 @@ func MakeIngressSpec(
 	tls []v1alpha1.IngressTLS,
 	clusterLocalServices sets.String,
 	targets map[string]traffic.RevisionTargets,
+	mirror *traffic.RevisionTarget,
 ) (v1alpha1.IngressSpec, error) {
 	// Domain should have been specified in route status
 	// before calling this func.
@@ -140,8 +142,16 @@ func MakeIngressSpec(
 			return v1alpha1.IngressSpec{}, err
 		}
 
-		rules = append(rules, *makeIngressRule(
-			routeDomains, r.Namespace, isClusterLocal, targets[name]))
+		rule := makeIngressRule(routeDomains, r.Namespace, isClusterLocal, targets[name], routeHeaders(r))
+		// Only the traffic of the Route is mirrored, not the traffic that
+		// is sent to its tags.
+		if name == traffic.DefaultTarget {
+			rule.HTTP.Paths[0].Mirror = makeIngressMirror(r.Namespace, mirror)
+			// The requests that match the rules of the tags are routed to
+			// them ahead of splitting the traffic.
+			rule.HTTP.Paths = append(makeMatchPaths(r.Namespace, names, targets, routeHeaders(r)), rule.HTTP.Paths...)
+		}
+		rules = append(rules, *rule)
 	}
 
 	defaultDomain, err := domains.HostnameFromTemplate(ctx, r.Name, "")
@@ -190,7 +200,58 @@ func routeDomains(ctx context.Context, targetName string, r *servingv1alpha1.Rou
 	return ruleDomains, nil
 }
 
-func makeIngressRule(domains []string, ns string, isClusterLocal bool, targets traffic.RevisionTargets) *v1alpha1.IngressRule {
+// routeHeaders returns the headers the Route adds to the requests it routes.
+func routeHeaders(r *servingv1alpha1.Route) map[string]string {
+	priority, ok := r.Annotations[serving.RequestPriorityAnnotationKey]
+	if !ok {
+		return nil
+	}
+	return map[string]string{
+		network.RequestPriorityHeaderName: priority,
+	}
+}
+
+func makeIngressRule(domains []string, ns string, isClusterLocal bool, targets traffic.RevisionTargets,
+	headers map[string]string) *v1alpha1.IngressRule {
+	visibility := v1alpha1.IngressVisibilityExternalIP
+	if isClusterLocal {
+		visibility = v1alpha1.IngressVisibilityClusterLocal
+	}
+
+	return &v1alpha1.IngressRule{
+		Hosts:      domains,
+		Visibility: visibility,
+		HTTP: &v1alpha1.HTTPIngressRuleValue{
+			Paths: []v1alpha1.HTTPIngressPath{{
+				Splits: makeIngressSplits(ns, targets, headers),
+				// TODO(lichuqiang): #2201, plumbing to config timeout and retries.
+			}},
+		},
+	}
+}
+
+// makeMatchPaths returns a path for each of the match rules of the tagged
+// targets, which routes the requests matching the rule to the target.
+func makeMatchPaths(ns string, names []string, targets map[string]traffic.RevisionTargets,
+	headers map[string]string) []v1alpha1.HTTPIngressPath {
+	var paths []v1alpha1.HTTPIngressPath
+	for _, name := range names {
+		if name == traffic.DefaultTarget || len(targets[name]) == 0 {
+			continue
+		}
+		for _, m := range targets[name][0].Match {
+			paths = append(paths, v1alpha1.HTTPIngressPath{
+				Headers:         m.Headers,
+				Cookies:         m.Cookies,
+				QueryParameters: m.QueryParameters,
+				Splits:          makeIngressSplits(ns, targets[name], headers),
+			})
+		}
+	}
+	return paths
+}
+
+func makeIngressSplits(ns string, targets traffic.RevisionTargets, headers map[string]string) []v1alpha1.IngressBackendSplit {
 	// Optimistically allocate |targets| elements.
 	splits := make([]v1alpha1.IngressBackendSplit, 0, len(targets))
 	for _, t := range targets {
@@ -207,27 +268,29 @@ func makeIngressRule(domains []string, ns string, isClusterLocal bool, targets t
 				ServicePort: intstr.FromInt(int(networking.ServicePort(t.Protocol))),
 			},
 			Percent: t.Percent,
-			AppendHeaders: map[string]string{
+			AppendHeaders: resources.UnionMaps(headers, map[string]string{
 				activator.RevisionHeaderName:      t.TrafficTarget.RevisionName,
 				activator.RevisionHeaderNamespace: ns,
-			},
+			}),
 		})
 	}
+	return splits
+}
 
-	visibility := v1alpha1.IngressVisibilityExternalIP
-	if isClusterLocal {
-		visibility = v1alpha1.IngressVisibilityClusterLocal
+// makeIngressMirror returns the backend the traffic is mirrored to. The
+// requests are only mirrored to active revisions, since the activator can't
+// tell which revision a mirrored request is meant for.
+func makeIngressMirror(ns string, mirror *traffic.RevisionTarget) *v1alpha1.IngressBackendMirror {
+	if mirror == nil || !mirror.Active {
+		return nil
 	}
-
-	return &v1alpha1.IngressRule{
-		Hosts:      domains,
-		Visibility: visibility,
-		HTTP: &v1alpha1.HTTPIngressRuleValue{
-			Paths: []v1alpha1.HTTPIngressPath{{
-				Splits: splits,
-				// TODO(lichuqiang): #2201, plumbing to config timeout and retries.
-			}},
+	return &v1alpha1.IngressBackendMirror{
+		IngressBackend: v1alpha1.IngressBackend{
+			ServiceNamespace: ns,
+			ServiceName:      mirror.ServiceName,
+			ServicePort:      intstr.FromInt(int(networking.ServicePort(mirror.Protocol))),
 		},
+		Percent: mirror.Percent,
 	}
 }
 
@@ -167,7 +167,7 @@ func TestMakeClusterIngressSpec_CorrectRules(t *testing.T) {
 		Visibility: netv1alpha1.IngressVisibilityExternalIP,
 	}}
 
-	ci, err := MakeIngressSpec(getContext(), r, nil, getServiceVisibility(), targets)
+	ci, err := MakeIngressSpec(getContext(), r, nil, getServiceVisibility(), targets, nil)
 	if err != nil {
 		t.Errorf("Unexpected error %v", err)
 	}
@@ -177,6 +177,165 @@ func TestMakeClusterIngressSpec_CorrectRules(t *testing.T) {
 	}
 }
 
+func TestMakeClusterIngressSpec_Mirror(t *testing.T) {
+	targets := map[string]traffic.RevisionTargets{
+		traffic.DefaultTarget: {{
+			TrafficTarget: v1beta1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      "v1",
+				Percent:           100,
+			},
+			ServiceName: "jobim",
+			Active:      true,
+		}},
+		"v1": {{
+			TrafficTarget: v1beta1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      "v1",
+				Percent:           100,
+			},
+			ServiceName: "jobim",
+			Active:      true,
+		}},
+	}
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-route",
+			Namespace: "test-ns",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		mirror *traffic.RevisionTarget
+		want   *netv1alpha1.IngressBackendMirror
+	}{{
+		name: "no mirror",
+	}, {
+		name: "active mirror",
+		mirror: &traffic.RevisionTarget{
+			TrafficTarget: v1beta1.TrafficTarget{
+				RevisionName: "v2",
+				Percent:      10,
+			},
+			ServiceName: "gilberto",
+			Protocol:    networking.ProtocolH2C,
+			Active:      true,
+		},
+		want: &netv1alpha1.IngressBackendMirror{
+			IngressBackend: netv1alpha1.IngressBackend{
+				ServiceNamespace: "test-ns",
+				ServiceName:      "gilberto",
+				ServicePort:      intstr.FromInt(81),
+			},
+			Percent: 10,
+		},
+	}, {
+		name: "inactive mirror",
+		mirror: &traffic.RevisionTarget{
+			TrafficTarget: v1beta1.TrafficTarget{
+				RevisionName: "v2",
+				Percent:      10,
+			},
+			ServiceName: "gilberto",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := MakeIngressSpec(getContext(), r, nil, getServiceVisibility(), targets, test.mirror)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			if got := spec.Rules[0].HTTP.Paths[0].Mirror; !cmp.Equal(test.want, got) {
+				t.Errorf("Unexpected mirror (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+			// The traffic of the tags is never mirrored.
+			if got := spec.Rules[1].HTTP.Paths[0].Mirror; got != nil {
+				t.Errorf("Mirror of the tag = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestMakeClusterIngressSpec_Match(t *testing.T) {
+	targets := map[string]traffic.RevisionTargets{
+		traffic.DefaultTarget: {{
+			TrafficTarget: v1beta1.TrafficTarget{
+				RevisionName: "v1",
+				Percent:      100,
+			},
+			ServiceName: "jobim",
+			Active:      true,
+		}},
+		"canary": {{
+			TrafficTarget: v1beta1.TrafficTarget{
+				Tag:          "canary",
+				RevisionName: "v2",
+				Percent:      100,
+				Match: []v1beta1.TrafficMatch{{
+					Headers: map[string]string{"X-Canary": "true"},
+				}, {
+					Cookies: map[string]string{"canary": "always"},
+				}},
+			},
+			ServiceName: "gilberto",
+			Active:      true,
+		}},
+	}
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-route",
+			Namespace: "test-ns",
+		},
+	}
+	canarySplits := []netv1alpha1.IngressBackendSplit{{
+		IngressBackend: netv1alpha1.IngressBackend{
+			ServiceNamespace: "test-ns",
+			ServiceName:      "gilberto",
+			ServicePort:      intstr.FromInt(80),
+		},
+		Percent: 100,
+		AppendHeaders: map[string]string{
+			"Knative-Serving-Revision":  "v2",
+			"Knative-Serving-Namespace": "test-ns",
+		},
+	}}
+	expected := []netv1alpha1.HTTPIngressPath{{
+		Headers: map[string]string{"X-Canary": "true"},
+		Splits:  canarySplits,
+	}, {
+		Cookies: map[string]string{"canary": "always"},
+		Splits:  canarySplits,
+	}, {
+		Splits: []netv1alpha1.IngressBackendSplit{{
+			IngressBackend: netv1alpha1.IngressBackend{
+				ServiceNamespace: "test-ns",
+				ServiceName:      "jobim",
+				ServicePort:      intstr.FromInt(80),
+			},
+			Percent: 100,
+			AppendHeaders: map[string]string{
+				"Knative-Serving-Revision":  "v1",
+				"Knative-Serving-Namespace": "test-ns",
+			},
+		}},
+	}}
+
+	spec, err := MakeIngressSpec(getContext(), r, nil, getServiceVisibility(), targets, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	// The rule of the Route's URL comes first.
+	if got := spec.Rules[0].HTTP.Paths; !cmp.Equal(expected, got) {
+		t.Errorf("Unexpected paths (-want, +got): %s", cmp.Diff(expected, got))
+	}
+	// The rule of the tag's URL doesn't match anything.
+	if got := spec.Rules[1].HTTP.Paths; len(got) != 1 {
+		t.Errorf("Paths of the tag = %v, want a single path", got)
+	}
+}
+
 func TestMakeClusterIngressSpec_CorrectVisibility(t *testing.T) {
 	cases := []struct {
 		name               string
@@ -213,7 +372,7 @@ func TestMakeClusterIngressSpec_CorrectVisibility(t *testing.T) {
 	}}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			ci, err := MakeIngressSpec(getContext(), &c.route, nil, c.serviceVisibility, nil)
+			ci, err := MakeIngressSpec(getContext(), &c.route, nil, c.serviceVisibility, nil, nil)
 			if err != nil {
 				t.Errorf("Unexpected error %v", err)
 			}
@@ -318,7 +477,7 @@ func TestMakeClusterIngressSpec_CorrectRuleVisibility(t *testing.T) {
 	}}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			ci, err := MakeIngressSpec(getContext(), &c.route, nil, c.serviceVisibility, c.targets)
+			ci, err := MakeIngressSpec(getContext(), &c.route, nil, c.serviceVisibility, c.targets, nil)
 			if err != nil {
 				t.Errorf("Unexpected error %v", err)
 			}
@@ -434,7 +593,7 @@ func TestMakeClusterIngressRule_Vanilla(t *testing.T) {
 		Active:      true,
 	}}
 	domains := []string{"a.com", "b.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, false, targets, nil)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{
 			"a.com",
@@ -464,6 +623,53 @@ func TestMakeClusterIngressRule_Vanilla(t *testing.T) {
 	}
 }
 
+// One active target of a Route with a request priority.
+func TestMakeClusterIngressRule_RequestPriority(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           100,
+		},
+		ServiceName: "chocolate",
+		Active:      true,
+	}}
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				serving.RequestPriorityAnnotationKey: serving.RequestPriorityLow,
+			},
+		},
+	}
+	domains := []string{"a.com"}
+	rule := makeIngressRule(domains, ns, false, targets, routeHeaders(r))
+	expected := netv1alpha1.IngressRule{
+		Hosts: []string{"a.com"},
+		HTTP: &netv1alpha1.HTTPIngressRuleValue{
+			Paths: []netv1alpha1.HTTPIngressPath{{
+				Splits: []netv1alpha1.IngressBackendSplit{{
+					IngressBackend: netv1alpha1.IngressBackend{
+						ServiceNamespace: "test-ns",
+						ServiceName:      "chocolate",
+						ServicePort:      intstr.FromInt(80),
+					},
+					Percent: 100,
+					AppendHeaders: map[string]string{
+						"Knative-Serving-Revision":  "revision",
+						"Knative-Serving-Namespace": "test-ns",
+						"Knative-Request-Priority":  "low",
+					},
+				}},
+			}},
+		},
+		Visibility: netv1alpha1.IngressVisibilityExternalIP,
+	}
+
+	if !cmp.Equal(&expected, rule) {
+		t.Errorf("Unexpected rule (-want, +got): %s", cmp.Diff(&expected, rule))
+	}
+}
+
 // One active target and a target of zero percent.
 func TestMakeClusterIngressRule_ZeroPercentTarget(t *testing.T) {
 	targets := []traffic.RevisionTarget{{
@@ -484,7 +690,7 @@ func TestMakeClusterIngressRule_ZeroPercentTarget(t *testing.T) {
 	}}
 	domains := []string{"test.org"}
 	ns := "test-ns"
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, false, targets, nil)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{"test.org"},
 		HTTP: &netv1alpha1.HTTPIngressRuleValue{
@@ -531,7 +737,7 @@ func TestMakeClusterIngressRule_TwoTargets(t *testing.T) {
 		Active:      true,
 	}}
 	domains := []string{"test.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, false, targets, nil)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{"test.org"},
 		HTTP: &netv1alpha1.HTTPIngressRuleValue{
@@ -581,7 +787,7 @@ func TestMakeClusterIngressRule_InactiveTarget(t *testing.T) {
 		Active:      false,
 	}}
 	domains := []string{"a.com", "b.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, false, targets, nil)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{
 			"a.com",
@@ -630,7 +836,7 @@ func TestMakeClusterIngressRule_TwoInactiveTargets(t *testing.T) {
 		Active:      false,
 	}}
 	domains := []string{"a.com", "b.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, false, targets, nil)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{
 			"a.com",
@@ -688,7 +894,7 @@ func TestMakeClusterIngressRule_ZeroPercentTargetInactive(t *testing.T) {
 		Active: false,
 	}}
 	domains := []string{"test.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, false, targets, nil)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{"test.org"},
 		HTTP: &netv1alpha1.HTTPIngressRuleValue{
@@ -421,6 +421,21 @@ func (c *Reconciler) configureTraffic(ctx context.Context, r *v1alpha1.Route, cl
 
 	r.Status.MarkTrafficAssigned()
 
+	switch {
+	case t.MirrorErr != nil:
+		// The mirror is dropped until its revision becomes routable, the
+		// traffic of the Route is not affected.
+		c.Recorder.Eventf(r, corev1.EventTypeWarning, "MirrorNotRoutable",
+			"Not mirroring the traffic of Route %q: %v", r.Name, t.MirrorErr)
+		r.Status.MarkMirrorNotRoutable(t.MirrorErr.Error())
+	case t.Mirror == nil:
+		r.Status.MarkMirrorNotConfigured()
+	case !t.Mirror.Active:
+		// The mirror is dropped while its revision is scaled to zero.
+		r.Status.MarkMirrorInactive(t.Mirror.RevisionName)
+	default:
+		r.Status.MarkMirrorActive(t.Mirror.RevisionName)
+	}
 	return t, nil
 }
 
@@ -61,6 +61,13 @@ type Config struct {
 	// is used to populate the Route.Status.TrafficTarget field.
 	revisionTargets RevisionTargets
 
+	// Mirror is the Revision a copy of the traffic is sent to, with the
+	// percentage of the traffic that is mirrored. Nil if the Route doesn't
+	// mirror its traffic.
+	Mirror *RevisionTarget
+	// MirrorErr is why the mirror of the Route was dropped, if it was.
+	MirrorErr TargetError
+
 	// The referred `Configuration`s and `Revision`s.
 	Configurations map[string]*v1alpha1.Configuration
 	Revisions      map[string]*v1alpha1.Revision
@@ -75,6 +82,7 @@ func BuildTrafficConfiguration(configLister listers.ConfigurationLister, revList
 	r *v1alpha1.Route) (*Config, error) {
 	builder := newBuilder(configLister, revLister, r.Namespace, len(r.Spec.Traffic))
 	builder.applySpecTraffic(r.Spec.Traffic)
+	builder.applyMirror(r.Spec.Mirror)
 	return builder.build()
 }
 
@@ -129,6 +137,11 @@ type configBuilder struct {
 	// revisions contains all the referred Revision, keyed by their name.
 	revisions map[string]*v1alpha1.Revision
 
+	// mirror is the Revision the traffic is mirrored to.
+	mirror *RevisionTarget
+	// mirrorErr is why the mirror was dropped. It doesn't fail the traffic.
+	mirrorErr TargetError
+
 	// TargetError are deferred until we got a complete list of all referred targets.
 	deferredTargetErr TargetError
 }
@@ -158,6 +171,39 @@ func (t *configBuilder) applySpecTraffic(traffic []v1alpha1.TrafficTarget) error
 	return nil
 }
 
+// applyMirror resolves the Revision the Route mirrors its traffic to. A
+// missing or unready mirror only drops the mirror, the traffic of the Route
+// is configured regardless.
+func (t *configBuilder) applyMirror(mirror *v1beta1.TrafficMirror) error {
+	if mirror == nil {
+		return nil
+	}
+	percent := 100
+	if mirror.Percent != nil {
+		percent = int(*mirror.Percent)
+	}
+	rev, err := t.getRevision(mirror.RevisionName)
+	if err == nil && !rev.Status.IsReady() {
+		err = errUnreadyRevision(rev)
+	}
+	if err, ok := err.(TargetError); err != nil && ok {
+		t.mirrorErr = err
+		return nil
+	} else if err != nil {
+		return err
+	}
+	t.mirror = &RevisionTarget{
+		TrafficTarget: v1beta1.TrafficTarget{
+			RevisionName: mirror.RevisionName,
+			Percent:      percent,
+		},
+		Active:      !rev.Status.IsActivationRequired(),
+		Protocol:    rev.GetProtocol(),
+		ServiceName: rev.Status.ServiceName,
+	}
+	return nil
+}
+
 func (t *configBuilder) getConfiguration(name string) (*v1alpha1.Configuration, error) {
 	if _, ok := t.configurations[name]; !ok {
 		config, err := t.configLister.Configurations(t.namespace).Get(name)
@@ -305,10 +351,13 @@ func (t *configBuilder) build() (*Config, error) {
 	if t.deferredTargetErr != nil {
 		t.targets = nil
 		t.revisionTargets = nil
+		t.mirror = nil
 	}
 	return &Config{
 		Targets:         consolidateAll(t.targets),
 		revisionTargets: t.revisionTargets,
+		Mirror:          t.mirror,
+		MirrorErr:       t.mirrorErr,
 		Configurations:  t.configurations,
 		Revisions:       t.revisions,
 	}, t.deferredTargetErr
@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"knative.dev/pkg/ptr"
 	net "knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
@@ -920,6 +921,98 @@ func TestBuildTrafficConfiguration_MissingRevision(t *testing.T) {
 	}
 }
 
+func TestBuildTrafficConfiguration_Mirror(t *testing.T) {
+	tts := []v1alpha1.TrafficTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			RevisionName: goodOldRev.Name,
+			Percent:      100,
+		},
+	}}
+	expected := &Config{
+		Targets: map[string]RevisionTargets{
+			DefaultTarget: {{
+				TrafficTarget: v1beta1.TrafficTarget{
+					ConfigurationName: goodConfig.Name,
+					RevisionName:      goodOldRev.Name,
+					Percent:           100,
+				},
+				Active:   true,
+				Protocol: net.ProtocolHTTP1,
+			}},
+		},
+		revisionTargets: []RevisionTarget{{
+			TrafficTarget: v1beta1.TrafficTarget{
+				ConfigurationName: goodConfig.Name,
+				RevisionName:      goodOldRev.Name,
+				Percent:           100,
+			},
+			Active:   true,
+			Protocol: net.ProtocolHTTP1,
+		}},
+		Mirror: &RevisionTarget{
+			TrafficTarget: v1beta1.TrafficTarget{
+				RevisionName: goodNewRev.Name,
+				Percent:      10,
+			},
+			Active:   true,
+			Protocol: net.ProtocolH2C,
+		},
+		Configurations: map[string]*v1alpha1.Configuration{goodConfig.Name: goodConfig},
+		Revisions: map[string]*v1alpha1.Revision{
+			goodOldRev.Name: goodOldRev,
+			goodNewRev.Name: goodNewRev,
+		},
+	}
+	r := testRouteWithTrafficTargets(tts)
+	r.Spec.Mirror = &v1beta1.TrafficMirror{
+		RevisionName: goodNewRev.Name,
+		Percent:      ptr.Int64(10),
+	}
+	if tc, err := BuildTrafficConfiguration(configLister, revLister, r); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	} else if got, want := tc, expected; !cmp.Equal(want, got, cmpOpts...) {
+		t.Errorf("Unexpected traffic diff (-want +got): %v", cmp.Diff(want, got, cmpOpts...))
+	}
+}
+
+func TestBuildTrafficConfiguration_NotRoutableMirror(t *testing.T) {
+	tts := []v1alpha1.TrafficTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			RevisionName: goodNewRev.Name,
+			Percent:      100,
+		},
+	}}
+	for _, name := range []string{unreadyRev.Name, "missing-revision"} {
+		t.Run(name, func(t *testing.T) {
+			r := testRouteWithTrafficTargets(tts)
+			r.Spec.Mirror = &v1beta1.TrafficMirror{RevisionName: name}
+			tc, err := BuildTrafficConfiguration(configLister, revLister, r)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			// Only the mirror is dropped, the traffic is configured.
+			if tc.Mirror != nil {
+				t.Errorf("Mirror = %v, want nil", tc.Mirror)
+			}
+			if tc.MirrorErr == nil {
+				t.Error("MirrorErr = nil, wanted an error")
+			}
+			want := RevisionTargets{{
+				TrafficTarget: v1beta1.TrafficTarget{
+					ConfigurationName: goodConfig.Name,
+					RevisionName:      goodNewRev.Name,
+					Percent:           100,
+				},
+				Active:   true,
+				Protocol: net.ProtocolH2C,
+			}}
+			if got := tc.Targets[DefaultTarget]; !cmp.Equal(want, got, cmpOpts...) {
+				t.Errorf("Unexpected traffic diff (-want +got): %v", cmp.Diff(want, got, cmpOpts...))
+			}
+		})
+	}
+}
+
 func TestRoundTripping(t *testing.T) {
 	tts := []v1alpha1.TrafficTarget{{
 		TrafficTarget: v1beta1.TrafficTarget{